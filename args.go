@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -66,15 +67,44 @@ type Argument interface {
 	Usage() string
 }
 
+// DocGenerationArgument is an Argument documented in the help output and
+// the docs
+type DocGenerationArgument interface {
+	Argument
+
+	// GetName returns the name of the argument
+	GetName() string
+	// GetDescription returns the description of the argument
+	GetDescription() string
+	// GetTypeName returns the name of the type of the values
+	GetTypeName() string
+	// GetOccurrences returns the min and max number of values, the max is
+	// -1 for an unlimited number
+	GetOccurrences() (int, int)
+}
+
+// argumentValues is implemented by arguments providing the values they
+// were parsed into, see Command.StringArg
+type argumentValues interface {
+	GetName() string
+	// parsedValues returns the parsed values as []T
+	parsedValues() any
+	// parsedValue returns the first parsed value or the default as T
+	parsedValue() any
+}
+
 type ArgumentBase[T any, C any, VC ValueCreator[T, C]] struct {
-	Name        string `json:"name"`      // the name of this argument
-	Value       T      `json:"value"`     // the default value of this argument
-	Destination *T     `json:"-"`         // the destination point for this argument
-	Values      *[]T   `json:"-"`         // all the values of this argument, only if multiple are supported
-	UsageText   string `json:"usageText"` // the usage text to show
-	Min         int    `json:"minTimes"`  // the min num of occurrences of this argument
-	Max         int    `json:"maxTimes"`  // the max num of occurrences of this argument, set to -1 for unlimited
-	Config      C      `json:"config"`    // config for this argument similar to Flag Config
+	Name        string `json:"name"`        // the name of this argument
+	Value       T      `json:"value"`       // the default value of this argument
+	Destination *T     `json:"-"`           // the destination point for this argument
+	Values      *[]T   `json:"-"`           // all the values of this argument, only if multiple are supported
+	UsageText   string `json:"usageText"`   // the usage text to show
+	Description string `json:"description"` // the description shown in the help output and the docs
+	Min         int    `json:"minTimes"`    // the min num of occurrences of this argument
+	Max         int    `json:"maxTimes"`    // the max num of occurrences of this argument, set to -1 for unlimited
+	Config      C      `json:"config"`      // config for this argument similar to Flag Config
+
+	values []T // values of the last parse
 }
 
 func (a *ArgumentBase[T, C, VC]) Usage() string {
@@ -89,14 +119,49 @@ func (a *ArgumentBase[T, C, VC]) Usage() string {
 		} else {
 			usageFormat = "[%[1]s ...]"
 		}
+	} else if a.Min == 1 && a.Max == 1 {
+		usageFormat = "%[1]s"
 	} else {
 		usageFormat = "%[1]s [%[1]s ...]"
 	}
 	return fmt.Sprintf(usageFormat, a.Name)
 }
 
+// GetName returns the name of the argument
+func (a *ArgumentBase[T, C, VC]) GetName() string {
+	return a.Name
+}
+
+// GetDescription returns the description of the argument
+func (a *ArgumentBase[T, C, VC]) GetDescription() string {
+	return a.Description
+}
+
+// GetTypeName returns the name of the type of the values, e.g. int64
+func (a *ArgumentBase[T, C, VC]) GetTypeName() string {
+	var t T
+	return fmt.Sprintf("%T", t)
+}
+
+// GetOccurrences returns the min and max number of values
+func (a *ArgumentBase[T, C, VC]) GetOccurrences() (int, int) {
+	return a.Min, a.Max
+}
+
+func (a *ArgumentBase[T, C, VC]) parsedValues() any {
+	return a.values
+}
+
+func (a *ArgumentBase[T, C, VC]) parsedValue() any {
+	if len(a.values) > 0 {
+		return a.values[0]
+	}
+	return a.Value
+}
+
 func (a *ArgumentBase[T, C, VC]) Parse(s []string) ([]string, error) {
 	tracef("calling arg%[1] parse with args %[2]", &a.Name, s)
+	a.values = nil
 	if a.Max == 0 {
 		fmt.Printf("WARNING args %s has max 0, not parsing argument", a.Name)
 		return s, nil
@@ -114,7 +179,7 @@ func (a *ArgumentBase[T, C, VC]) Parse(s []string) ([]string, error) {
 
 	for _, arg := range s {
 		if err := value.Set(arg); err != nil {
-			return s, err
+			return s, fmt.Errorf(tr("invalid value %[1]q for arg %[2]s: %[3]w"), arg, a.Name, err)
 		}
 		values = append(values, value.Get().(T))
 		count++
@@ -123,9 +188,10 @@ func (a *ArgumentBase[T, C, VC]) Parse(s []string) ([]string, error) {
 		}
 	}
 	if count < a.Min {
-		return s, fmt.Errorf("sufficient count of arg %s not provided, given %d expected %d", a.Name, count, a.Min)
+		return s, fmt.Errorf(tr("sufficient count of arg %s not provided, given %d expected %d"), a.Name, count, a.Min)
 	}
 
+	a.values = values
 	if a.Values == nil {
 		a.Values = &values
 	} else if count > 0 {
@@ -151,3 +217,109 @@ type (
 	TimestampArg = ArgumentBase[time.Time, TimestampConfig, timestampValue]
 	UintArg      = ArgumentBase[uint64, IntegerConfig, uintValue]
 )
+
+// lookupArgValues returns the values the argument of the command with the
+// name was parsed into
+func lookupArgValues[T any](cmd *Command, name string) []T {
+	for _, arg := range cmd.Arguments {
+		if av, ok := arg.(argumentValues); ok && av.GetName() == name {
+			values, _ := av.parsedValues().([]T)
+			return values
+		}
+	}
+
+	tracef("arg %[1]q NOT available (cmd=%[2]q)", name, cmd.Name)
+	return nil
+}
+
+// lookupArgValue returns the first value the argument of the command with
+// the name was parsed into or its default value
+func lookupArgValue[T any](cmd *Command, name string) T {
+	for _, arg := range cmd.Arguments {
+		if av, ok := arg.(argumentValues); ok && av.GetName() == name {
+			value, _ := av.parsedValue().(T)
+			return value
+		}
+	}
+
+	tracef("arg %[1]q NOT available (cmd=%[2]q)", name, cmd.Name)
+	var zero T
+	return zero
+}
+
+// StringArg returns the value of the StringArg with the name
+func (cmd *Command) StringArg(name string) string {
+	return lookupArgValue[string](cmd, name)
+}
+
+// StringArgs returns the values of the StringArg with the name
+func (cmd *Command) StringArgs(name string) []string {
+	return lookupArgValues[string](cmd, name)
+}
+
+// IntArg returns the value of the IntArg with the name
+func (cmd *Command) IntArg(name string) int64 {
+	return lookupArgValue[int64](cmd, name)
+}
+
+// IntArgs returns the values of the IntArg with the name
+func (cmd *Command) IntArgs(name string) []int64 {
+	return lookupArgValues[int64](cmd, name)
+}
+
+// UintArg returns the value of the UintArg with the name
+func (cmd *Command) UintArg(name string) uint64 {
+	return lookupArgValue[uint64](cmd, name)
+}
+
+// UintArgs returns the values of the UintArg with the name
+func (cmd *Command) UintArgs(name string) []uint64 {
+	return lookupArgValues[uint64](cmd, name)
+}
+
+// FloatArg returns the value of the FloatArg with the name
+func (cmd *Command) FloatArg(name string) float64 {
+	return lookupArgValue[float64](cmd, name)
+}
+
+// FloatArgs returns the values of the FloatArg with the name
+func (cmd *Command) FloatArgs(name string) []float64 {
+	return lookupArgValues[float64](cmd, name)
+}
+
+// TimestampArg returns the value of the TimestampArg with the name
+func (cmd *Command) TimestampArg(name string) time.Time {
+	return lookupArgValue[time.Time](cmd, name)
+}
+
+// TimestampArgs returns the values of the TimestampArg with the name
+func (cmd *Command) TimestampArgs(name string) []time.Time {
+	return lookupArgValues[time.Time](cmd, name)
+}
+
+// StringMapArg returns the value of the StringMapArg with the name
+func (cmd *Command) StringMapArg(name string) map[string]string {
+	return lookupArgValue[map[string]string](cmd, name)
+}
+
+// DescribedArguments returns the arguments of the command with a
+// description, which are listed in the help output
+func (cmd *Command) DescribedArguments() []DocGenerationArgument {
+	var args []DocGenerationArgument
+	for _, arg := range cmd.Arguments {
+		if da, ok := arg.(DocGenerationArgument); ok && da.GetDescription() != "" {
+			args = append(args, da)
+		}
+	}
+	return args
+}
+
+// argumentsUsage returns the usage of the arguments of the command, e.g.
+// "src [src ...] dst"
+func (cmd *Command) argumentsUsage() string {
+	usages := make([]string, 0, len(cmd.Arguments))
+	for _, arg := range cmd.Arguments {
+		usages = append(usages, arg.Usage())
+	}
+	return strings.Join(usages, " ")
+}
@@ -1,11 +1,15 @@
 package cli
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"io"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -121,7 +125,7 @@ func TestArgsUsage(t *testing.T) {
 			name:     "one",
 			min:      1,
 			max:      1,
-			expected: "ia [ia ...]",
+			expected: "ia",
 		},
 		{
 			name:     "many",
@@ -218,3 +222,128 @@ func TestUnboundedArgs(t *testing.T) {
 		})
 	}
 }
+
+func buildArgumentsTestCommand() *Command {
+	return &Command{
+		Name:   "serve",
+		Writer: io.Discard,
+		Arguments: []Argument{
+			&StringArg{Name: "path", Min: 1, Max: 1, Description: "directory to serve"},
+			&IntArg{Name: "ports", Min: 0, Max: -1, Description: "ports to listen on"},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+}
+
+func TestArgumentsAccessors(t *testing.T) {
+	cmd := buildArgumentsTestCommand()
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"serve", "/srv", "80", "443"}))
+	assert.Equal(t, "/srv", cmd.StringArg("path"))
+	assert.Equal(t, []string{"/srv"}, cmd.StringArgs("path"))
+	assert.Equal(t, int64(80), cmd.IntArg("ports"))
+	assert.Equal(t, []int64{80, 443}, cmd.IntArgs("ports"))
+	assert.Empty(t, cmd.StringArg("nope"))
+	assert.Nil(t, cmd.IntArgs("path"), "the type has to match")
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"serve", "/var/www"}))
+	assert.Equal(t, "/var/www", cmd.StringArg("path"))
+	assert.Empty(t, cmd.IntArgs("ports"), "values of previous runs are reset")
+}
+
+func TestArgumentsArity(t *testing.T) {
+	tests := []struct {
+		args []string
+		err  string
+	}{
+		{args: []string{"serve"}, err: "sufficient count of arg path not provided, given 0 expected 1"},
+		{args: []string{"serve", "/srv", "http"}, err: `invalid value "http" for arg ports: strconv.ParseInt: parsing "http": invalid syntax`},
+	}
+
+	for _, test := range tests {
+		t.Run(strings.Join(test.args, " "), func(t *testing.T) {
+			cmd := buildArgumentsTestCommand()
+			assert.EqualError(t, cmd.Run(buildTestContext(t), test.args), test.err)
+		})
+	}
+
+	cmd := buildMinimalTestCommand()
+	cmd.Arguments = []Argument{&StringArg{Name: "src", Min: 1, Max: 2}, &StringArg{Name: "dst", Min: 1, Max: 1}}
+	assert.EqualError(t, cmd.Run(buildTestContext(t), []string{"cp", "a", "b", "c", "d"}), `unexpected arguments ["d"], expected src [src ...] dst`)
+}
+
+func TestArgumentsHelp(t *testing.T) {
+	out := &bytes.Buffer{}
+	cmd := buildArgumentsTestCommand()
+	cmd.Writer = out
+	cmd.Commands = []*Command{{
+		Name:      "check",
+		Arguments: []Argument{&StringArg{Name: "file", Min: 0, Max: 1}},
+	}}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"serve", "--help"}))
+	assert.Contains(t, out.String(), "USAGE:\n   serve [global options] [command [command options]] path [ports ...]\n")
+	assert.Contains(t, out.String(), "ARGUMENTS:\n   path   directory to serve\n   ports  ports to listen on\n")
+
+	out.Reset()
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"serve", "check", "--help"}))
+	assert.Contains(t, out.String(), "serve check [command [command options]] [file]\n")
+	assert.NotContains(t, out.String(), "ARGUMENTS:", "arguments without description aren't listed")
+}
+
+func TestPassthroughArgs(t *testing.T) {
+	var invoked string
+	record := func(_ context.Context, cmd *Command) error {
+		invoked = cmd.Name
+		return nil
+	}
+
+	cmd := &Command{
+		Name:                  "app",
+		EnablePassthroughArgs: true,
+		Writer:                io.Discard,
+		Action:                record,
+		Commands: []*Command{{
+			Name:                  "exec",
+			EnablePassthroughArgs: true,
+			Flags:                 []Flag{&BoolFlag{Name: "verbose"}},
+			Arguments:             []Argument{&StringArg{Name: "container", Min: 0, Max: 1}},
+			Action:                record,
+		}},
+	}
+
+	invokedCmd, err := cmd.Parse(buildTestContext(t), []string{"app", "exec", "--verbose", "--", "ls", "-la", "--", "a b"})
+	require.NoError(t, err)
+	assert.True(t, invokedCmd.Bool("verbose"))
+	assert.Equal(t, []string{"ls", "-la", "--", "a b"}, invokedCmd.PassthroughArgs())
+	assert.Equal(t, 0, invokedCmd.NArg())
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "exec", "box", "--", "--verbose"}))
+	exec := cmd.Commands[0]
+	assert.Equal(t, "exec", invoked)
+	assert.False(t, exec.Bool("verbose"), "flags aren't parsed after the terminator")
+	assert.Equal(t, "box", exec.StringArg("container"))
+	assert.Equal(t, []string{"--verbose"}, exec.PassthroughArgs())
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--", "exec"}))
+	assert.Equal(t, "app", invoked, "passthrough args aren't subcommands")
+	assert.Equal(t, []string{"exec"}, cmd.PassthroughArgs())
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "exec"}))
+	assert.Nil(t, exec.PassthroughArgs())
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "exec", "--"}))
+	assert.Equal(t, []string{}, exec.PassthroughArgs())
+}
+
+func TestPassthroughArgsUsage(t *testing.T) {
+	out := &bytes.Buffer{}
+	cmd := &Command{
+		Name:                  "app",
+		Writer:                out,
+		EnablePassthroughArgs: true,
+		Arguments:             []Argument{&StringArg{Name: "container", Min: 1, Max: 1}},
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--help"}))
+	assert.Contains(t, out.String(), "app [global options] [command [command options]] container [-- ARGS...]\n")
+}
@@ -0,0 +1,116 @@
+//go:build !urfave_cli_no_docs
+
+package cli
+
+import (
+	"io"
+	"strings"
+	"text/template"
+)
+
+// AsciiDocTemplate is the template used by ToAsciiDoc. The template is
+// executed for the root command, the "command" template for every visible
+// command in turn. The data is the one of HTMLDocTemplate.
+var AsciiDocTemplate = `{{define "command"}}[#{{.ID}}]
+{{heading .Level}} {{.Title}}
+{{if .Usage}}
+{{.Usage}}
+{{end}}{{if .Version}}
+Version: {{.Version}}
+{{end}}{{if .Since}}
+Since: {{.Since}}
+{{end}}{{if .Aliases}}
+Aliases: {{literals .Aliases}}
+{{end}}{{if .Description}}
+{{.Description}}
+{{end}}
+.Usage
+----
+{{.UsageText}}
+----
+{{if .Examples}}
+.Examples
+{{range $i, $e := .Examples}}{{if $i}}
+{{end}}{{if .Description}}{{.Description}}
+
+{{end}}----
+$ {{.Command}}{{if .Output}}
+{{.Output}}{{end}}
+----
+{{end}}{{end}}{{if .Arguments}}
+.Arguments
+{{range .Arguments}}{{literals .Usage}}::
+{{if .Description}}{{.Description}}{{else}}{empty}{{end}}
+{{end}}{{end}}{{if .Flags}}
+.Options
+{{range .Flags}}[[{{.ID}}]]{{literals .Names}}::
+{{.Usage}}{{if .Choices}} (one of: {{literals .Choices}}){{end}}{{if .Default}} (default: {{literals .Default}}){{end}}{{if .EnvVars}} [env: {{literals .EnvVars}}]{{end}}{{if .Requires}} (requires {{literals .Requires}}){{end}}{{if .Conflicts}} (conflicts with {{literals .Conflicts}}){{end}}{{if .Examples}} (e.g. {{literals .Examples}}){{end}}{{if .Since}} (since {{.Since}}){{end}}{{if .SeeAlso}} (see also: {{join .SeeAlso ", "}}){{end}}
+{{end}}{{end}}{{if .Commands}}
+.Commands
+{{range .Commands}}* <<{{.ID}},{{.Name}}>>{{if .Usage}}: {{.Usage}}{{end}}
+{{end}}{{end}}{{if .SeeAlso}}
+.See Also
+{{range .SeeAlso}}* {{.}}
+{{end}}{{end}}{{range .Commands}}
+{{template "command" .}}{{end}}{{end}}{{template "command" .}}`
+
+// ToAsciiDoc renders the command tree as a single AsciiDoc document using
+// AsciiDocTemplate, e.g. for Antora or Asciidoctor. Every visible command
+// becomes a section nested in the section of its parent like the pages of
+// ToMkDocs, flags and sections can be referenced by their anchors.
+func (cmd *Command) ToAsciiDoc() (string, error) {
+	var b strings.Builder
+	if err := cmd.ToAsciiDocToWriter(&b); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// ToAsciiDocToWriter writes the document of ToAsciiDoc to the writer while
+// rendering it
+func (cmd *Command) ToAsciiDocToWriter(w io.Writer) error {
+	cmd.loadCommands()
+
+	funcMap := template.FuncMap{
+		"heading":  asciiDocHeading,
+		"literals": asciiDocLiterals,
+		"join":     strings.Join,
+	}
+	for name, fn := range cmd.lineageTemplateFuncs() {
+		funcMap[name] = fn
+	}
+
+	t, err := template.New("asciidoc").Funcs(funcMap).Parse(AsciiDocTemplate)
+	if err != nil {
+		return err
+	}
+
+	return t.Execute(w, newDocsTemplateCommand(newDocsPage(cmd, nil, 1)))
+}
+
+// asciiDocHeading returns the marker of the section titles of the nesting
+// level, the root command being the document title
+func asciiDocHeading(level int) string {
+	// AsciiDoc supports up to five section levels below the title
+	if level > 5 {
+		level = 5
+	}
+	return strings.Repeat("=", level+1)
+}
+
+// asciiDocLiterals renders a string or a list of strings as literal
+// monospace text, which isn't subject to formatting
+func asciiDocLiterals(v any) string {
+	var items []string
+	switch v := v.(type) {
+	case string:
+		items = []string{v}
+	case []string:
+		items = append([]string{}, v...)
+	}
+
+	for i, item := range items {
+		items[i] = "`+" + item + "+`"
+	}
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,51 @@
+//go:build !urfave_cli_no_docs
+
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToAsciiDoc(t *testing.T) {
+	cmd := buildExtendedTestCommand()
+
+	res, err := cmd.ToAsciiDoc()
+
+	require.NoError(t, err)
+	expectFileContent(t, "testdata/expected-doc-full.adoc", res)
+}
+
+func TestToAsciiDocMetadata(t *testing.T) {
+	cmd := &Command{
+		Name:    "app",
+		Version: "v1.0.0",
+		Commands: []*Command{{
+			Name:     "deploy",
+			Usage:    "deploy the app",
+			Since:    "v1.2.0",
+			Examples: []Example{{Description: "Deploy a tag", Command: "app deploy --tag v1", Output: "deployed v1\n"}},
+			SeeAlso:  []string{"https://example.com/deploy"},
+			Flags: []Flag{
+				&StringFlag{Name: "tag", Usage: "deploy the `TAG`", Sources: EnvVars("APP_TAG"), Since: "v1.3.0"},
+			},
+		}},
+	}
+
+	res, err := cmd.ToAsciiDoc()
+	require.NoError(t, err)
+	assert.Equal(t, "[#app]\n= app\n\nVersion: v1.0.0\n\n.Usage\n----\napp [command [command options]]\n----\n\n.Commands\n* <<app-deploy,deploy>>: deploy the app\n\n"+
+		"[#app-deploy]\n== app deploy\n\ndeploy the app\n\nSince: v1.2.0\n\n.Usage\n----\napp deploy [options]\n----\n\n.Examples\nDeploy a tag\n\n----\n$ app deploy --tag v1\ndeployed v1\n----\n\n"+
+		".Options\n[[app-deploy-tag]]`+--tag TAG+`::\ndeploy the TAG [env: `+APP_TAG+`] (since v1.3.0)\n\n.See Also\n* https://example.com/deploy\n", res)
+}
+
+func TestToAsciiDocTemplate(t *testing.T) {
+	defer func(old string) { AsciiDocTemplate = old }(AsciiDocTemplate)
+	AsciiDocTemplate = `{{define "command"}}{{heading .Level}} {{.Title}}{{range .Commands}} {{template "command" .}}{{end}}{{end}}{{template "command" .}}`
+
+	res, err := buildExtendedTestCommand().ToAsciiDoc()
+	require.NoError(t, err)
+	assert.Equal(t, "= greet == greet config === greet config sub-config == greet info == greet some-command == greet usage === greet usage sub-usage", res)
+}
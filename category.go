@@ -1,12 +1,28 @@
 package cli
 
-import "sort"
+import (
+	"math"
+	"sort"
+)
+
+// Category describes a category of commands. The categories listed by a
+// command in Categories are shown in the given order with their
+// description, both in the help output and the generated docs.
+type Category struct {
+	// Name is the name the commands use as their Category
+	Name string `json:"name"`
+	// Description is the short blurb shown below the name of the category
+	Description string `json:"description"`
+}
 
 // CommandCategories interface allows for category manipulation
 type CommandCategories interface {
 	// AddCommand adds a command to a category, creating a new category if necessary.
 	AddCommand(category string, command *Command)
-	// Categories returns a slice of categories sorted by name
+	// Categories returns a slice of categories, the uncategorized commands
+	// first, then the categories in the order they are listed by the
+	// Categories of the command or its ancestors and the other categories
+	// sorted by name
 	Categories() []CommandCategory
 }
 
@@ -17,7 +33,27 @@ func newCommandCategories() CommandCategories {
 	return &ret
 }
 
+// categorize returns the categories of the given subcommands of the
+// command, described and sorted by the categories listed by the command
+// and its ancestors, the lineage of the command
+func categorize(lineage []*Command, commands []*Command) *commandCategories {
+	categories := newCommandCategories().(*commandCategories)
+	for _, subCmd := range commands {
+		categories.AddCommand(subCmd.Category, subCmd)
+	}
+
+	for _, category := range *categories {
+		category.describe(lineage)
+	}
+
+	sort.Sort(categories)
+	return categories
+}
+
 func (c *commandCategories) Less(i, j int) bool {
+	if ri, rj := (*c)[i].rank(), (*c)[j].rank(); ri != rj {
+		return ri < rj
+	}
 	return lexicographicLess((*c)[i].Name(), (*c)[j].Name())
 }
 
@@ -53,12 +89,17 @@ func (c *commandCategories) Categories() []CommandCategory {
 type CommandCategory interface {
 	// Name returns the category name string
 	Name() string
+	// Description returns the description of the category, if any
+	Description() string
 	// VisibleCommands returns a slice of the Commands with Hidden=false
 	VisibleCommands() []*Command
 }
 
 type commandCategory struct {
-	name     string
+	name        string
+	description string
+	// position in the listed categories starting at 1, 0 if not listed
+	order    int
 	commands []*Command
 }
 
@@ -66,6 +107,37 @@ func (c *commandCategory) Name() string {
 	return c.name
 }
 
+func (c *commandCategory) Description() string {
+	return c.description
+}
+
+// describe sets the description and order of the category from the first
+// command of the lineage listing it
+func (c *commandCategory) describe(lineage []*Command) {
+	for _, cmd := range lineage {
+		for i, category := range cmd.Categories {
+			if category.Name == c.name {
+				c.description = category.Description
+				c.order = i + 1
+				return
+			}
+		}
+	}
+}
+
+// rank returns the position of the category among the others, the
+// uncategorized commands go first and the categories not listed last
+func (c *commandCategory) rank() int {
+	switch {
+	case c.name == "":
+		return 0
+	case c.order > 0:
+		return c.order
+	default:
+		return math.MaxInt
+	}
+}
+
 func (c *commandCategory) VisibleCommands() []*Command {
 	if c.commands == nil {
 		c.commands = []*Command{}
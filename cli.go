@@ -20,41 +20,14 @@
 //
 //		cmd.Run(context.Background(), os.Args)
 //	}
+//
+// Optional subsystems can be left out of minimal binaries with build tags:
+//
+//   - urfave_cli_no_docs drops the static site documentation exporters
+//   - urfave_cli_no_completion drops the embedded shell completion scripts
+//     and the fish completion generator, the completion command then fails
+//   - urfave_cli_no_suggest replaces the similarity based flag and command
+//     suggestions with unambiguous prefix matching
+//   - urfave_cli_tracing compiles in the trace output for debugging the
+//     package, enabled at runtime with URFAVE_CLI_TRACING=on
 package cli
-
-import (
-	"fmt"
-	"os"
-	"runtime"
-	"strings"
-)
-
-var isTracingOn = os.Getenv("URFAVE_CLI_TRACING") == "on"
-
-func tracef(format string, a ...any) {
-	if !isTracingOn {
-		return
-	}
-
-	if !strings.HasSuffix(format, "\n") {
-		format = format + "\n"
-	}
-
-	pc, file, line, _ := runtime.Caller(1)
-	cf := runtime.FuncForPC(pc)
-
-	fmt.Fprintf(
-		os.Stderr,
-		strings.Join([]string{
-			"## URFAVE CLI TRACE ",
-			file,
-			":",
-			fmt.Sprintf("%v", line),
-			" ",
-			fmt.Sprintf("(%s)", cf.Name()),
-			" ",
-			format,
-		}, ""),
-		a...,
-	)
-}
@@ -0,0 +1,67 @@
+// Package cliconfig provides YAML and TOML config files flag values are
+// read from, see cli.ConfigFile.
+//
+//	cfg := cliconfig.YAML("app.yaml", "/etc/app/app.yaml")
+//
+//	&cli.StringFlag{
+//		Name:    "region",
+//		Sources: cli.NewValueSourceChain(cli.EnvVar("APP_REGION"), cfg.Key("cloud.region")),
+//	}
+//
+// It is a module of its own, so the YAML and TOML parsers are only
+// dependencies of the programs using this package and not of every program
+// using urfave/cli.
+package cliconfig
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/urfave/cli/v3"
+)
+
+// YAML returns a config file decoded as YAML, read from the first of the
+// paths that exists
+func YAML(paths ...string) *cli.ConfigFile {
+	return &cli.ConfigFile{Paths: paths, Decode: DecodeYAML}
+}
+
+// TOML returns a config file decoded as TOML, read from the first of the
+// paths that exists
+func TOML(paths ...string) *cli.ConfigFile {
+	return &cli.ConfigFile{Paths: paths, Decode: DecodeTOML}
+}
+
+// DecodeYAML is the cli.ConfigDecoder of YAML files
+func DecodeYAML(data []byte) (map[string]any, error) {
+	var m map[string]any
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return normalize(m).(map[string]any), nil
+}
+
+// normalize converts maps with non-string keys, which YAML allows, to maps
+// with string keys so nested keys can be looked up
+func normalize(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalize(item)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for key, item := range v {
+			m[fmt.Sprint(key)] = normalize(item)
+		}
+		return m
+	case []any:
+		for i, item := range v {
+			v[i] = normalize(item)
+		}
+		return v
+	}
+	return v
+}
@@ -0,0 +1,87 @@
+package cliconfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/urfave/cli/v3"
+)
+
+func TestYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+cloud:
+  region: eu-west-1
+  zones: [a, b]
+  1: numeric key
+timeout: 5s
+since: 2024-01-02T03:04:05Z
+`), 0o644))
+	cfg := YAML(path)
+
+	cmd := &cli.Command{
+		Name: "app",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "region", Sources: cli.NewValueSourceChain(cli.EnvVar("APP_REGION"), cfg.Key("cloud.region"))},
+			&cli.StringSliceFlag{Name: "zone", Sources: cli.NewValueSourceChain(cfg.Key("cloud.zones"))},
+			&cli.DurationFlag{Name: "timeout", Sources: cli.NewValueSourceChain(cfg.Key("timeout"))},
+			&cli.TimestampFlag{Name: "since", Config: cli.TimestampConfig{Layout: time.RFC3339}, Sources: cli.NewValueSourceChain(cfg.Key("since"))},
+		},
+		Env:    cli.MapEnv{},
+		Action: func(context.Context, *cli.Command) error { return nil },
+	}
+
+	require.NoError(t, cmd.Run(context.Background(), []string{"app"}))
+	assert.Equal(t, "eu-west-1", cmd.String("region"))
+	assert.Equal(t, []string{"a", "b"}, cmd.StringSlice("zone"))
+	assert.Equal(t, 5*time.Second, cmd.Duration("timeout"))
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), cmd.Timestamp("since"))
+
+	v, ok := cfg.Key("cloud.1").Lookup()
+	assert.True(t, ok)
+	assert.Equal(t, "numeric key", v)
+}
+
+func TestYAMLBroken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("cloud: [a"), 0o644))
+
+	cfg := YAML(path)
+	_, ok := cfg.Key("cloud").Lookup()
+	assert.False(t, ok)
+	assert.ErrorContains(t, cfg.Err(), "could not decode config file")
+}
+
+func TestTOML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.toml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+title = "app" # comment
+
+[server]
+port = 8_080
+hosts = [
+  "a.example.com",
+  "b.example.com", # trailing comma
+]
+`), 0o644))
+	cfg := TOML(path)
+
+	cmd := &cli.Command{
+		Name: "app",
+		Flags: []cli.Flag{
+			&cli.IntFlag{Name: "port", Sources: cli.NewValueSourceChain(cfg.Key("server.port"))},
+			&cli.StringSliceFlag{Name: "host", Sources: cli.NewValueSourceChain(cfg.Key("server.hosts"))},
+		},
+		Action: func(context.Context, *cli.Command) error { return nil },
+	}
+
+	require.NoError(t, cmd.Run(context.Background(), []string{"app", "--port", "9090"}))
+	assert.Equal(t, int64(9090), cmd.Int("port"))
+	assert.Equal(t, []string{"a.example.com", "b.example.com"}, cmd.StringSlice("host"))
+}
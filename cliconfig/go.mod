@@ -0,0 +1,17 @@
+module github.com/urfave/cli/v3/cliconfig
+
+go 1.18
+
+replace github.com/urfave/cli/v3 => ../
+
+require (
+	github.com/BurntSushi/toml v1.3.2
+	github.com/stretchr/testify v1.8.4
+	github.com/urfave/cli/v3 v3.0.0-alpha9
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+)
@@ -0,0 +1,54 @@
+package cliconfig
+
+import (
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// DecodeTOML is the cli.ConfigDecoder of TOML files, decoded by
+// github.com/BurntSushi/toml. Dates and times are converted back to
+// strings in the format of RFC 3339, which the timestamp flags parse with
+// their layouts.
+func DecodeTOML(data []byte) (map[string]any, error) {
+	var m map[string]any
+	if _, err := toml.Decode(string(data), &m); err != nil {
+		return nil, err
+	}
+	return formatTimes(m).(map[string]any), nil
+}
+
+// formatTimes converts the dates and times of a decoded TOML document to
+// strings, keeping local dates, times and datetimes without an offset
+func formatTimes(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = formatTimes(item)
+		}
+		return v
+	case []map[string]any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = formatTimes(item)
+		}
+		return items
+	case []any:
+		for i, item := range v {
+			v[i] = formatTimes(item)
+		}
+		return v
+	case time.Time:
+		// the locations of local values are named by the decoder
+		switch v.Location().String() {
+		case "datetime-local":
+			return v.Format("2006-01-02T15:04:05.999999999")
+		case "date-local":
+			return v.Format("2006-01-02")
+		case "time-local":
+			return v.Format("15:04:05.999999999")
+		}
+		return v.Format(time.RFC3339Nano)
+	}
+	return v
+}
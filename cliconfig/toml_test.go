@@ -0,0 +1,97 @@
+package cliconfig
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeTOML(t *testing.T) {
+	m, err := DecodeTOML([]byte(`
+# a comment
+str = "tab\tquote\" \u00e9"
+literal = 'C:\path'
+multi = """
+first \
+  second"""
+multiLiteral = '''
+raw \n'''
+int = -42
+hex = 0xff
+oct = 0o17
+bin = 0b101
+float = 6.5e-1
+inf = -inf
+bool = true
+date = 1979-05-27
+datetime = 1979-05-27 07:32:00Z
+localDatetime = 1979-05-27T07:32:00.5
+time = 07:32:00
+nested.dotted = 1
+"quoted key" = 2
+inline = { a = 1, b = { c = "d" } }
+array = [ [1, 2], ["x"] ]
+
+[table.sub]
+key = "value"
+
+[[items]]
+name = "one"
+
+[[items]]
+name = "two"
+
+[items.meta]
+tag = "x"
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "tab\tquote\" é", m["str"])
+	assert.Equal(t, `C:\path`, m["literal"])
+	assert.Equal(t, "first second", m["multi"])
+	assert.Equal(t, `raw \n`, m["multiLiteral"])
+	assert.Equal(t, int64(-42), m["int"])
+	assert.Equal(t, int64(255), m["hex"])
+	assert.Equal(t, int64(15), m["oct"])
+	assert.Equal(t, int64(5), m["bin"])
+	assert.Equal(t, 0.65, m["float"])
+	assert.Equal(t, math.Inf(-1), m["inf"])
+	assert.Equal(t, true, m["bool"])
+	assert.Equal(t, "1979-05-27", m["date"])
+	assert.Equal(t, "1979-05-27T07:32:00Z", m["datetime"])
+	assert.Equal(t, "1979-05-27T07:32:00.5", m["localDatetime"])
+	assert.Equal(t, "07:32:00", m["time"])
+	assert.Equal(t, map[string]any{"dotted": int64(1)}, m["nested"])
+	assert.Equal(t, int64(2), m["quoted key"])
+	assert.Equal(t, map[string]any{"a": int64(1), "b": map[string]any{"c": "d"}}, m["inline"])
+	assert.Equal(t, []any{[]any{int64(1), int64(2)}, []any{"x"}}, m["array"])
+	assert.Equal(t, map[string]any{"sub": map[string]any{"key": "value"}}, m["table"])
+	assert.Equal(t, []any{
+		map[string]any{"name": "one"},
+		map[string]any{"name": "two", "meta": map[string]any{"tag": "x"}},
+	}, m["items"])
+}
+
+func TestDecodeTOMLErrors(t *testing.T) {
+	for input, expected := range map[string]string{
+		"a = 1\na = 2":             "line 2",
+		"a = 1 b = 2":              "line 1",
+		"a = 012":                  "line 1",
+		"a = 1\n[a]":               "line 2",
+		"a = \"\\q\"":              "line 1",
+		"\n\na = 1 # ok\nb = nope": "line 4",
+	} {
+		_, err := DecodeTOML([]byte(input))
+		if assert.Error(t, err, input) {
+			assert.Contains(t, err.Error(), expected, input)
+		}
+	}
+
+	// errors at the end of the input are reported without a line
+	for _, input := range []string{"key", "a = \"open", "a = [1, 2"} {
+		_, err := DecodeTOML([]byte(input))
+		assert.Error(t, err, input)
+	}
+}
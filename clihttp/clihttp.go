@@ -0,0 +1,152 @@
+// Package clihttp exposes a command tree behind an http.Handler, e.g. for
+// internal tooling portals. Commands are run with the arguments of a
+// request and their output is captured and returned along with the exit
+// code:
+//
+//	POST /run
+//	{"args": ["db", "migrate", "--dry-run"], "stdin": ""}
+//
+//	200 OK
+//	{"exitCode": 0, "stdout": "...", "stderr": ""}
+//
+// Only commands in the allowlist of the Handler can be run.
+package clihttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+// DefaultMaxRequestSize is the maximum size of request bodies if the
+// Handler doesn't set one
+const DefaultMaxRequestSize = 1 << 20
+
+// RunRequest is the body of a run request
+type RunRequest struct {
+	// Args are the arguments following the program name
+	Args []string `json:"args"`
+	// Stdin is the standard input of the command
+	Stdin string `json:"stdin,omitempty"`
+}
+
+// RunResponse is the body of the response to a run request
+type RunResponse struct {
+	// ExitCode is the code the program would have exited with
+	ExitCode int `json:"exitCode"`
+	// Stdout is the captured standard output
+	Stdout string `json:"stdout"`
+	// Stderr is the captured standard error
+	Stderr string `json:"stderr"`
+	// Error is the error the command returned, if any
+	Error string `json:"error,omitempty"`
+}
+
+// errorResponse is the body of responses to invalid requests
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Handler serves the commands of a command tree over HTTP
+type Handler struct {
+	// New returns the root command to run a request with. Commands keep
+	// the state of their last run, so a new command tree must be returned
+	// for every call.
+	New func() *cli.Command
+	// Allow lists the full names of the commands which may be run, e.g.
+	// "app db migrate". Subcommands of allowed commands are not allowed
+	// implicitly. Nothing may be run if empty.
+	Allow []string
+	// MaxRequestSize limits the size of request bodies, defaults to
+	// DefaultMaxRequestSize
+	MaxRequestSize int64
+}
+
+// ServeHTTP handles POST requests to /run, mount the handler with
+// http.StripPrefix to serve it below another path
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/run" {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "not found"})
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+
+	limit := h.MaxRequestSize
+	if limit <= 0 {
+		limit = DefaultMaxRequestSize
+	}
+
+	var req RunRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit)).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+
+	resp, status, err := h.run(r.Context(), &req)
+	if err != nil {
+		writeJSON(w, status, errorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// run executes the request if the command it resolves to is allowed
+func (h *Handler) run(ctx context.Context, req *RunRequest) (*RunResponse, int, error) {
+	if h.New == nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("no command")
+	}
+
+	cmd := h.New()
+	args := append([]string{cmd.Name}, req.Args...)
+
+	// resolve on a separate tree as parsing leaves state behind
+	res, err := h.New().Resolve(ctx, args)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	name := res.Command.FullName()
+	if !h.allowed(name) {
+		return nil, http.StatusForbidden, fmt.Errorf("command %q is not allowed", name)
+	}
+
+	var stdout, stderr strings.Builder
+
+	code, err := cmd.Execute(ctx, args, strings.NewReader(req.Stdin), &stdout, &stderr)
+
+	resp := &RunResponse{
+		ExitCode: code,
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+	}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+
+	return resp, http.StatusOK, nil
+}
+
+func (h *Handler) allowed(name string) bool {
+	for _, allowed := range h.Allow {
+		if allowed == name {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,154 @@
+package clihttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/urfave/cli/v3"
+)
+
+func newCommand() *cli.Command {
+	return &cli.Command{
+		Name: "app",
+		Commands: []*cli.Command{
+			{
+				Name: "greet",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "name", Value: "world"},
+				},
+				Action: func(_ context.Context, cmd *cli.Command) error {
+					fmt.Fprintf(cmd.Root().Writer, "hello %s\n", cmd.String("name"))
+					return nil
+				},
+			},
+			{
+				Name: "echo",
+				Action: func(_ context.Context, cmd *cli.Command) error {
+					_, err := io.Copy(cmd.Root().Writer, cmd.Root().Reader)
+					return err
+				},
+			},
+			{
+				Name: "fail",
+				Action: func(context.Context, *cli.Command) error {
+					return cli.Exit("broken", 4)
+				},
+			},
+			{
+				Name: "boom",
+				Action: func(context.Context, *cli.Command) error {
+					return errors.New("boom")
+				},
+			},
+			{
+				Name: "admin",
+				Commands: []*cli.Command{
+					{Name: "reset", Action: func(context.Context, *cli.Command) error { return nil }},
+				},
+			},
+		},
+	}
+}
+
+func newHandler() *Handler {
+	return &Handler{
+		New:   newCommand,
+		Allow: []string{"app greet", "app echo", "app fail", "app boom", "app admin"},
+	}
+}
+
+func post(t *testing.T, h http.Handler, body string) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/run", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	return rec, got
+}
+
+func TestRun(t *testing.T) {
+	h := newHandler()
+
+	rec, got := post(t, h, `{"args": ["greet", "--name", "gopher"]}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, map[string]any{"exitCode": 0.0, "stdout": "hello gopher\n", "stderr": ""}, got)
+
+	// every request runs on a fresh command tree
+	_, got = post(t, h, `{"args": ["greet"]}`)
+	assert.Equal(t, "hello world\n", got["stdout"])
+
+	_, got = post(t, h, `{"args": ["echo"], "stdin": "input"}`)
+	assert.Equal(t, "input", got["stdout"])
+}
+
+func TestRunErrors(t *testing.T) {
+	h := newHandler()
+
+	rec, got := post(t, h, `{"args": ["fail"]}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 4.0, got["exitCode"])
+	assert.Equal(t, "broken\n", got["stderr"])
+
+	_, got = post(t, h, `{"args": ["boom"]}`)
+	assert.Equal(t, 1.0, got["exitCode"])
+	assert.Equal(t, "boom", got["error"])
+}
+
+func TestRunNotAllowed(t *testing.T) {
+	h := newHandler()
+
+	for _, args := range []string{`[]`, `["admin", "reset"]`, `["help", "greet"]`} {
+		rec, got := post(t, h, `{"args": `+args+`}`)
+		assert.Equal(t, http.StatusForbidden, rec.Code, args)
+		assert.Contains(t, got["error"], "is not allowed", args)
+	}
+}
+
+func TestInvalidRequests(t *testing.T) {
+	h := newHandler()
+
+	rec, _ := post(t, h, `{"args": "greet"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec, _ = post(t, h, `{"args": ["greet", "--unknown"]}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	h.MaxRequestSize = 8
+	rec, _ = post(t, h, `{"args": ["greet"]}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/run", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, http.MethodPost, rec.Header().Get("Allow"))
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/other", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestStripPrefix(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/cli/", http.StripPrefix("/cli", newHandler()))
+
+	req := httptest.NewRequest(http.MethodPost, "/cli/run", strings.NewReader(`{"args": ["greet"]}`))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "hello world")
+}
@@ -0,0 +1,227 @@
+// Package cliplugin implements a plugin protocol for subcommands provided
+// by separate executables. Unlike external commands, plugins export the
+// schema of their commands and flags, so the host parses the arguments and
+// provides proper help and completion, and plugins receive the typed flag
+// values.
+//
+// The protocol uses JSON over the arguments and standard output, so plugins
+// can be written in any language:
+//
+//   - called with SchemaArg, the plugin prints its Schema
+//   - called with InvokeArg and a Request, the plugin runs the requested
+//     command with the input and output of the host
+//
+// Plugins written with urfave/cli call Serve in their main function.
+package cliplugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	// SchemaArg asks a plugin to print its schema
+	SchemaArg = "--cli-plugin-schema"
+	// InvokeArg asks a plugin to run the command of the request following
+	// it as the next argument
+	InvokeArg = "--cli-plugin-invoke"
+)
+
+// Request is the invocation of a plugin command parsed by the host
+type Request struct {
+	// Commands are the commands from the plugin root to the invoked
+	// command along with the flags set on them
+	Commands []RequestCommand `json:"commands"`
+	// Args are the positional arguments
+	Args []string `json:"args"`
+}
+
+// RequestCommand is a command of a request and the values of its flags
+// which have been set, keyed by flag name. Values are JSON strings, bools,
+// numbers, durations as strings, slices as arrays and maps as objects.
+type RequestCommand struct {
+	Name  string         `json:"name"`
+	Flags map[string]any `json:"flags,omitempty"`
+}
+
+// Load asks the plugin executable for its schema and returns the command
+// running it, to be added to the Commands of the host
+func Load(ctx context.Context, path string) (*cli.Command, error) {
+	out, err := exec.CommandContext(ctx, path, SchemaArg).Output()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load schema of plugin %s: %w", path, err)
+	}
+
+	var schema Schema
+	if err := json.Unmarshal(out, &schema); err != nil {
+		return nil, fmt.Errorf("invalid schema of plugin %s: %w", path, err)
+	}
+
+	return newHostCommand(path, schema, nil)
+}
+
+// newHostCommand returns the command of the host for the schema, levels are
+// the schemas of the ancestors up to the plugin root
+func newHostCommand(path string, schema Schema, levels []Schema) (*cli.Command, error) {
+	levels = append(levels[:len(levels):len(levels)], schema)
+
+	cmd := &cli.Command{
+		Name:        schema.Name,
+		Aliases:     schema.Aliases,
+		Usage:       schema.Usage,
+		Description: schema.Description,
+		ArgsUsage:   schema.ArgsUsage,
+		Category:    schema.Category,
+		Hidden:      schema.Hidden,
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return invoke(ctx, path, cmd, levels)
+		},
+	}
+
+	for _, fs := range schema.Flags {
+		fl, err := fs.flag()
+		if err != nil {
+			return nil, err
+		}
+
+		cmd.Flags = append(cmd.Flags, fl)
+	}
+
+	for _, sub := range schema.Commands {
+		subCmd, err := newHostCommand(path, sub, levels)
+		if err != nil {
+			return nil, err
+		}
+
+		cmd.Commands = append(cmd.Commands, subCmd)
+	}
+
+	return cmd, nil
+}
+
+// invoke runs the plugin with the request for the command, which has been
+// parsed using the schemas of the levels
+func invoke(ctx context.Context, path string, cmd *cli.Command, levels []Schema) error {
+	lineage := cmd.Lineage()[:len(levels)]
+
+	req := Request{Args: cmd.Args().Slice()}
+
+	for i, schema := range levels {
+		level := lineage[len(levels)-1-i]
+		rc := RequestCommand{Name: schema.Name, Flags: map[string]any{}}
+
+		for _, fs := range schema.Flags {
+			if level.IsSet(fs.Name) {
+				rc.Flags[fs.Name] = level.Value(fs.Name)
+			}
+		}
+
+		req.Commands = append(req.Commands, rc)
+	}
+
+	data, err := json.Marshal(requestJSON(req))
+	if err != nil {
+		return err
+	}
+
+	c := exec.CommandContext(ctx, path, InvokeArg, string(data))
+	c.Stdin = cmd.Root().Reader
+	c.Stdout = cmd.Root().Writer
+	c.Stderr = cmd.Root().ErrWriter
+
+	err = c.Run()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return cli.Exit("", exitErr.ExitCode())
+	}
+
+	return err
+}
+
+// requestJSON encodes durations as strings instead of nanoseconds
+func requestJSON(req Request) Request {
+	for _, rc := range req.Commands {
+		for name, v := range rc.Flags {
+			if s, ok := v.(fmt.Stringer); ok {
+				rc.Flags[name] = s.String()
+			}
+		}
+	}
+
+	return req
+}
+
+// Serve runs the plugin command. When called by a host it prints the
+// schema of the command or runs the requested command, otherwise the
+// command is run with os.Args, so the plugin works standalone as well.
+func Serve(ctx context.Context, cmd *cli.Command) error {
+	if len(os.Args) > 1 && os.Args[1] == SchemaArg {
+		w := cmd.Writer
+		if w == nil {
+			w = os.Stdout
+		}
+
+		return json.NewEncoder(w).Encode(SchemaOf(cmd))
+	}
+
+	if len(os.Args) > 2 && os.Args[1] == InvokeArg {
+		dec := json.NewDecoder(strings.NewReader(os.Args[2]))
+		dec.UseNumber()
+
+		var req Request
+		if err := dec.Decode(&req); err != nil {
+			return fmt.Errorf("invalid plugin request: %w", err)
+		}
+
+		return cmd.Run(ctx, requestArgs(req))
+	}
+
+	return cmd.Run(ctx, os.Args)
+}
+
+// requestArgs returns the arguments running the command of the request
+func requestArgs(req Request) []string {
+	var args []string
+
+	for _, rc := range req.Commands {
+		args = append(args, rc.Name)
+
+		names := make([]string, 0, len(rc.Flags))
+		for name := range rc.Flags {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			switch v := rc.Flags[name].(type) {
+			case []any:
+				for _, e := range v {
+					args = append(args, fmt.Sprintf("--%s=%v", name, e))
+				}
+			case map[string]any:
+				keys := make([]string, 0, len(v))
+				for k := range v {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+
+				for _, k := range keys {
+					args = append(args, fmt.Sprintf("--%s=%s=%v", name, k, v[k]))
+				}
+			default:
+				args = append(args, fmt.Sprintf("--%s=%v", name, v))
+			}
+		}
+	}
+
+	return append(append(args, "--"), req.Args...)
+}
@@ -0,0 +1,121 @@
+package cliplugin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/urfave/cli/v3"
+)
+
+const pluginEnv = "CLIPLUGIN_TEST_PLUGIN"
+
+// TestMain runs the test binary as the plugin if requested
+func TestMain(m *testing.M) {
+	if os.Getenv(pluginEnv) != "" {
+		if err := Serve(context.Background(), newPluginCommand()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+func newPluginCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "db",
+		Usage: "manage the database",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "dsn", Usage: "database to connect to", Value: "postgres://localhost", Persistent: true},
+		},
+		Commands: []*cli.Command{
+			{
+				Name:      "migrate",
+				Usage:     "apply migrations",
+				ArgsUsage: "<version>",
+				Flags: []cli.Flag{
+					&cli.IntFlag{Name: "steps", Aliases: []string{"n"}, Value: 1},
+					&cli.BoolFlag{Name: "dry"},
+					&cli.DurationFlag{Name: "timeout", Value: time.Minute},
+					&cli.StringSliceFlag{Name: "skip"},
+					&cli.StringMapFlag{Name: "var"},
+				},
+				Action: func(_ context.Context, cmd *cli.Command) error {
+					fmt.Fprintf(cmd.Root().Writer, "dsn=%s steps=%d dry=%t timeout=%s skip=%v var=%v args=%v steps-set=%t\n",
+						cmd.String("dsn"), cmd.Int("steps"), cmd.Bool("dry"), cmd.Duration("timeout"),
+						cmd.StringSlice("skip"), cmd.StringMap("var"), cmd.Args().Slice(), cmd.IsSet("steps"))
+
+					if cmd.Args().First() == "fail" {
+						return cli.Exit("migration failed", 4)
+					}
+
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func TestSchemaOf(t *testing.T) {
+	schema := SchemaOf(newPluginCommand())
+
+	assert.Equal(t, "db", schema.Name)
+	assert.Equal(t, []FlagSchema{{Name: "dsn", Type: "string", Usage: "database to connect to", Default: "postgres://localhost", Persistent: true}}, schema.Flags)
+	require.Len(t, schema.Commands, 1)
+	require.Len(t, schema.Commands[0].Flags, 5)
+	assert.Equal(t, FlagSchema{Name: "timeout", Type: "duration", Default: "1m0s"}, schema.Commands[0].Flags[2])
+}
+
+func TestPlugin(t *testing.T) {
+	t.Setenv(pluginEnv, "1")
+
+	out := &bytes.Buffer{}
+
+	// persistent flag values are kept between runs of a command
+	newHost := func() *cli.Command {
+		plugin, err := Load(context.Background(), os.Args[0])
+		require.NoError(t, err)
+
+		return &cli.Command{
+			Name:      "app",
+			Writer:    out,
+			ErrWriter: out,
+			Commands:  []*cli.Command{plugin},
+		}
+	}
+
+	require.NoError(t, newHost().Run(context.Background(), []string{
+		"app", "db", "migrate", "-n", "3", "--dry", "--timeout", "90s",
+		"--skip", "a", "--skip", "b", "--var", "k=v", "--dsn", "sqlite://", "42",
+	}))
+	assert.Equal(t, "dsn=sqlite:// steps=3 dry=true timeout=1m30s skip=[a b] var=map[k:v] args=[42] steps-set=true\n", out.String())
+
+	out.Reset()
+	require.NoError(t, newHost().Run(context.Background(), []string{"app", "db", "migrate", "--", "-1"}))
+	assert.Equal(t, "dsn=postgres://localhost steps=1 dry=false timeout=1m0s skip=[] var=map[] args=[-1] steps-set=false\n", out.String())
+
+	out.Reset()
+	code, err := newHost().RunForTest(context.Background(), []string{"app", "db", "migrate", "fail"})
+	require.Error(t, err)
+	assert.Equal(t, 4, code)
+	assert.Contains(t, out.String(), "migration failed")
+
+	out.Reset()
+	require.NoError(t, newHost().Run(context.Background(), []string{"app", "db", "migrate", "--help"}))
+	assert.Contains(t, out.String(), "app db migrate [command [command options]] <version>")
+	assert.Contains(t, out.String(), "--steps value, -n value")
+	assert.Contains(t, out.String(), "(default: 1m0s)")
+
+	err = newHost().Run(context.Background(), []string{"app", "db", "migrate", "--steps", "many"})
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), `invalid value "many" for flag -steps`), err.Error())
+}
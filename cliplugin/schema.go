@@ -0,0 +1,181 @@
+package cliplugin
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+// Schema describes a command of a plugin with its flags and subcommands
+type Schema struct {
+	Name        string       `json:"name"`
+	Aliases     []string     `json:"aliases,omitempty"`
+	Usage       string       `json:"usage,omitempty"`
+	Description string       `json:"description,omitempty"`
+	ArgsUsage   string       `json:"argsUsage,omitempty"`
+	Category    string       `json:"category,omitempty"`
+	Hidden      bool         `json:"hidden,omitempty"`
+	Flags       []FlagSchema `json:"flags,omitempty"`
+	Commands    []Schema     `json:"commands,omitempty"`
+}
+
+// FlagSchema describes a flag of a plugin command. Type is one of string,
+// bool, int, uint, float, duration, stringSlice, intSlice, uintSlice,
+// floatSlice and stringMap.
+type FlagSchema struct {
+	Name       string   `json:"name"`
+	Aliases    []string `json:"aliases,omitempty"`
+	Type       string   `json:"type"`
+	Usage      string   `json:"usage,omitempty"`
+	Default    any      `json:"default,omitempty"`
+	Required   bool     `json:"required,omitempty"`
+	Hidden     bool     `json:"hidden,omitempty"`
+	Persistent bool     `json:"persistent,omitempty"`
+}
+
+// SchemaOf returns the schema of the command and its subcommands. Flags of
+// types not listed in FlagSchema are left out.
+func SchemaOf(cmd *cli.Command) Schema {
+	s := Schema{
+		Name:        cmd.Name,
+		Aliases:     cmd.Aliases,
+		Usage:       cmd.Usage,
+		Description: cmd.Description,
+		ArgsUsage:   cmd.ArgsUsage,
+		Category:    cmd.Category,
+		Hidden:      cmd.Hidden,
+	}
+
+	for _, fl := range cmd.Flags {
+		if fs, ok := flagSchemaOf(fl); ok {
+			s.Flags = append(s.Flags, fs)
+		}
+	}
+
+	for _, sub := range cmd.Commands {
+		s.Commands = append(s.Commands, SchemaOf(sub))
+	}
+
+	return s
+}
+
+func flagSchemaOf(fl cli.Flag) (FlagSchema, bool) {
+	var fs FlagSchema
+
+	switch f := fl.(type) {
+	case *cli.StringFlag:
+		fs = FlagSchema{Name: f.Name, Aliases: f.Aliases, Type: "string", Usage: f.Usage, Default: f.Value, Required: f.Required, Hidden: f.Hidden, Persistent: f.Persistent}
+	case *cli.BoolFlag:
+		fs = FlagSchema{Name: f.Name, Aliases: f.Aliases, Type: "bool", Usage: f.Usage, Default: f.Value, Required: f.Required, Hidden: f.Hidden, Persistent: f.Persistent}
+	case *cli.IntFlag:
+		fs = FlagSchema{Name: f.Name, Aliases: f.Aliases, Type: "int", Usage: f.Usage, Default: f.Value, Required: f.Required, Hidden: f.Hidden, Persistent: f.Persistent}
+	case *cli.UintFlag:
+		fs = FlagSchema{Name: f.Name, Aliases: f.Aliases, Type: "uint", Usage: f.Usage, Default: f.Value, Required: f.Required, Hidden: f.Hidden, Persistent: f.Persistent}
+	case *cli.FloatFlag:
+		fs = FlagSchema{Name: f.Name, Aliases: f.Aliases, Type: "float", Usage: f.Usage, Default: f.Value, Required: f.Required, Hidden: f.Hidden, Persistent: f.Persistent}
+	case *cli.DurationFlag:
+		fs = FlagSchema{Name: f.Name, Aliases: f.Aliases, Type: "duration", Usage: f.Usage, Default: f.Value.String(), Required: f.Required, Hidden: f.Hidden, Persistent: f.Persistent}
+	case *cli.StringSliceFlag:
+		fs = FlagSchema{Name: f.Name, Aliases: f.Aliases, Type: "stringSlice", Usage: f.Usage, Default: f.Value, Required: f.Required, Hidden: f.Hidden, Persistent: f.Persistent}
+	case *cli.IntSliceFlag:
+		fs = FlagSchema{Name: f.Name, Aliases: f.Aliases, Type: "intSlice", Usage: f.Usage, Default: f.Value, Required: f.Required, Hidden: f.Hidden, Persistent: f.Persistent}
+	case *cli.UintSliceFlag:
+		fs = FlagSchema{Name: f.Name, Aliases: f.Aliases, Type: "uintSlice", Usage: f.Usage, Default: f.Value, Required: f.Required, Hidden: f.Hidden, Persistent: f.Persistent}
+	case *cli.FloatSliceFlag:
+		fs = FlagSchema{Name: f.Name, Aliases: f.Aliases, Type: "floatSlice", Usage: f.Usage, Default: f.Value, Required: f.Required, Hidden: f.Hidden, Persistent: f.Persistent}
+	case *cli.StringMapFlag:
+		fs = FlagSchema{Name: f.Name, Aliases: f.Aliases, Type: "stringMap", Usage: f.Usage, Default: f.Value, Required: f.Required, Hidden: f.Hidden, Persistent: f.Persistent}
+	default:
+		return fs, false
+	}
+
+	if fs.Type == "duration" && fs.Default == time.Duration(0).String() {
+		fs.Default = nil
+	}
+
+	return fs, true
+}
+
+// flag returns the cli flag described by the schema
+func (fs FlagSchema) flag() (cli.Flag, error) {
+	switch fs.Type {
+	case "string":
+		f := &cli.StringFlag{Name: fs.Name, Aliases: fs.Aliases, Usage: fs.Usage, Required: fs.Required, Hidden: fs.Hidden, Persistent: fs.Persistent}
+		f.Value, _ = fs.Default.(string)
+		return f, nil
+	case "bool":
+		f := &cli.BoolFlag{Name: fs.Name, Aliases: fs.Aliases, Usage: fs.Usage, Required: fs.Required, Hidden: fs.Hidden, Persistent: fs.Persistent}
+		f.Value, _ = fs.Default.(bool)
+		return f, nil
+	case "int":
+		f := &cli.IntFlag{Name: fs.Name, Aliases: fs.Aliases, Usage: fs.Usage, Required: fs.Required, Hidden: fs.Hidden, Persistent: fs.Persistent}
+		if n, ok := fs.Default.(float64); ok {
+			f.Value = int64(n)
+		}
+		return f, nil
+	case "uint":
+		f := &cli.UintFlag{Name: fs.Name, Aliases: fs.Aliases, Usage: fs.Usage, Required: fs.Required, Hidden: fs.Hidden, Persistent: fs.Persistent}
+		if n, ok := fs.Default.(float64); ok {
+			f.Value = uint64(n)
+		}
+		return f, nil
+	case "float":
+		f := &cli.FloatFlag{Name: fs.Name, Aliases: fs.Aliases, Usage: fs.Usage, Required: fs.Required, Hidden: fs.Hidden, Persistent: fs.Persistent}
+		f.Value, _ = fs.Default.(float64)
+		return f, nil
+	case "duration":
+		f := &cli.DurationFlag{Name: fs.Name, Aliases: fs.Aliases, Usage: fs.Usage, Required: fs.Required, Hidden: fs.Hidden, Persistent: fs.Persistent}
+		if s, ok := fs.Default.(string); ok {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid default of flag %s: %w", fs.Name, err)
+			}
+			f.Value = d
+		}
+		return f, nil
+	case "stringSlice":
+		f := &cli.StringSliceFlag{Name: fs.Name, Aliases: fs.Aliases, Usage: fs.Usage, Required: fs.Required, Hidden: fs.Hidden, Persistent: fs.Persistent}
+		for _, v := range asSlice(fs.Default) {
+			f.Value = append(f.Value, fmt.Sprint(v))
+		}
+		return f, nil
+	case "intSlice":
+		f := &cli.IntSliceFlag{Name: fs.Name, Aliases: fs.Aliases, Usage: fs.Usage, Required: fs.Required, Hidden: fs.Hidden, Persistent: fs.Persistent}
+		for _, v := range asSlice(fs.Default) {
+			n, _ := v.(float64)
+			f.Value = append(f.Value, int64(n))
+		}
+		return f, nil
+	case "uintSlice":
+		f := &cli.UintSliceFlag{Name: fs.Name, Aliases: fs.Aliases, Usage: fs.Usage, Required: fs.Required, Hidden: fs.Hidden, Persistent: fs.Persistent}
+		for _, v := range asSlice(fs.Default) {
+			n, _ := v.(float64)
+			f.Value = append(f.Value, uint64(n))
+		}
+		return f, nil
+	case "floatSlice":
+		f := &cli.FloatSliceFlag{Name: fs.Name, Aliases: fs.Aliases, Usage: fs.Usage, Required: fs.Required, Hidden: fs.Hidden, Persistent: fs.Persistent}
+		for _, v := range asSlice(fs.Default) {
+			n, _ := v.(float64)
+			f.Value = append(f.Value, n)
+		}
+		return f, nil
+	case "stringMap":
+		f := &cli.StringMapFlag{Name: fs.Name, Aliases: fs.Aliases, Usage: fs.Usage, Required: fs.Required, Hidden: fs.Hidden, Persistent: fs.Persistent}
+		if m, ok := fs.Default.(map[string]any); ok {
+			f.Value = map[string]string{}
+			for k, v := range m {
+				f.Value[k] = fmt.Sprint(v)
+			}
+		}
+		return f, nil
+	default:
+		return nil, fmt.Errorf("flag %s has unsupported type %q", fs.Name, fs.Type)
+	}
+}
+
+func asSlice(v any) []any {
+	s, _ := v.([]any)
+	return s
+}
@@ -0,0 +1,72 @@
+package clitest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+)
+
+// parseRuns is the number of runs ParseAllocs averages over
+const parseRuns = 100
+
+// BenchmarkParse measures parsing the arguments, not including the program
+// name, with a command returned by newCmd. The command is created outside
+// of the measured time for every iteration, so only parsing is reported.
+//
+//	func BenchmarkParseServe(b *testing.B) {
+//		clitest.BenchmarkParse(b, newApp, "serve", "--port", "8080")
+//	}
+func BenchmarkParse(b *testing.B, newCmd func() *cli.Command, args ...string) {
+	b.Helper()
+
+	if _, err := parse(newCmd(), args); err != nil {
+		b.Fatalf("parsing %q failed: %v", args, err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		cmd := newCmd()
+		b.StartTimer()
+
+		_, _ = parse(cmd, args)
+	}
+}
+
+// ParseAllocs returns the average number of allocations of parsing the
+// arguments, not including the program name, with a command returned by
+// newCmd. Allocations of creating the command are not included.
+func ParseAllocs(newCmd func() *cli.Command, args ...string) float64 {
+	total := testing.AllocsPerRun(parseRuns, func() {
+		_, _ = parse(newCmd(), args)
+	})
+
+	create := testing.AllocsPerRun(parseRuns, func() {
+		_ = newCmd()
+	})
+
+	return total - create
+}
+
+// ExpectParseAllocs fails the test if parsing the arguments, not including
+// the program name, takes more than budget allocations on average, e.g. to
+// gate performance regressions in CI.
+func ExpectParseAllocs(t testing.TB, budget float64, newCmd func() *cli.Command, args ...string) {
+	t.Helper()
+
+	if _, err := parse(newCmd(), args); err != nil {
+		t.Fatalf("parsing %q failed: %v", args, err)
+		return
+	}
+
+	if allocs := ParseAllocs(newCmd, args...); allocs > budget {
+		t.Errorf("parsing %q took %.0f allocations, budget is %.0f", args, allocs, budget)
+	}
+}
+
+func parse(cmd *cli.Command, args []string) (*cli.Command, error) {
+	return cmd.Parse(context.Background(), append([]string{cmd.Name}, args...))
+}
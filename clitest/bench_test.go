@@ -0,0 +1,25 @@
+package clitest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAllocs(t *testing.T) {
+	allocs := ParseAllocs(newGreetCommand, "--name", "gopher")
+	assert.Greater(t, allocs, 0.0)
+
+	ExpectParseAllocs(t, allocs+10, newGreetCommand, "--name", "gopher")
+
+	rec := &recorder{TB: t}
+	ExpectParseAllocs(rec, 0, newGreetCommand, "--name", "gopher")
+	require.Len(t, rec.errors, 1)
+	assert.Contains(t, rec.errors[0], `parsing ["--name" "gopher"] took`)
+	assert.Contains(t, rec.errors[0], "budget is 0")
+}
+
+func BenchmarkGreetParse(b *testing.B) {
+	BenchmarkParse(b, newGreetCommand, "--name", "gopher")
+}
@@ -0,0 +1,160 @@
+// Package clitest provides a harness to run commands in tests, capturing
+// their output and exit code without terminating the test binary.
+//
+//	clitest.Run(t, cmd, "greet", "--name", "gopher").
+//		ExpectExitCode(0).
+//		ExpectStdoutContains("Hello gopher")
+package clitest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+)
+
+// Result is the outcome of a command run by Run
+type Result struct {
+	t testing.TB
+
+	// ExitCode is the code the program would have exited with
+	ExitCode int
+	// Err is the error returned by the run
+	Err error
+	// Stdout is the output written to the Writer of the command
+	Stdout string
+	// Stderr is the output written to the ErrWriter of the command
+	Stderr string
+}
+
+// Run runs the command with the given arguments, not including the program
+// name, and an empty stdin
+func Run(t testing.TB, cmd *cli.Command, args ...string) *Result {
+	t.Helper()
+
+	return RunWithStdin(t, cmd, strings.NewReader(""), args...)
+}
+
+// RunWithStdin runs the command with the given arguments, not including the
+// program name, reading stdin from the given reader. The Reader, Writer and
+// ErrWriter of the command are replaced for the duration of the run and
+// exiting is recorded in the ExitCode of the result instead.
+func RunWithStdin(t testing.TB, cmd *cli.Command, stdin io.Reader, args ...string) *Result {
+	t.Helper()
+
+	return run(t, cmd, stdin, nil, args)
+}
+
+// run runs the command, copying the error output to errTee if not nil
+func run(t testing.TB, cmd *cli.Command, stdin io.Reader, errTee io.Writer, args []string) *Result {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+
+	var errWriter io.Writer = &stderr
+	if errTee != nil {
+		errWriter = io.MultiWriter(&stderr, errTee)
+	}
+
+	origReader, origWriter, origErrWriter := cmd.Reader, cmd.Writer, cmd.ErrWriter
+	cmd.Reader, cmd.Writer, cmd.ErrWriter = stdin, &stdout, errWriter
+	defer func() {
+		cmd.Reader, cmd.Writer, cmd.ErrWriter = origReader, origWriter, origErrWriter
+	}()
+
+	code, err := cmd.RunForTest(context.Background(), append([]string{cmd.Name}, args...))
+
+	return &Result{
+		t:        t,
+		ExitCode: code,
+		Err:      err,
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+	}
+}
+
+// ExpectExitCode reports an error if the run did not exit with the code
+func (r *Result) ExpectExitCode(code int) *Result {
+	r.t.Helper()
+
+	if r.ExitCode != code {
+		r.t.Errorf("expected exit code %d, got %d (error: %v)", code, r.ExitCode, r.Err)
+	}
+
+	return r
+}
+
+// ExpectSuccess reports an error if the run returned an error
+func (r *Result) ExpectSuccess() *Result {
+	r.t.Helper()
+
+	if r.Err != nil {
+		r.t.Errorf("expected no error, got %q", r.Err)
+	}
+
+	return r
+}
+
+// ExpectErrorContains reports an error if the run did not return an error
+// containing the string
+func (r *Result) ExpectErrorContains(s string) *Result {
+	r.t.Helper()
+
+	if r.Err == nil {
+		r.t.Errorf("expected an error containing %q, got none", s)
+	} else if !strings.Contains(r.Err.Error(), s) {
+		r.t.Errorf("expected an error containing %q, got %q", s, r.Err)
+	}
+
+	return r
+}
+
+// ExpectStdout reports an error if the output is not equal to the string
+func (r *Result) ExpectStdout(s string) *Result {
+	r.t.Helper()
+
+	if r.Stdout != s {
+		r.t.Errorf("expected stdout %q, got %q", s, r.Stdout)
+	}
+
+	return r
+}
+
+// ExpectStdoutContains reports an error if the output does not contain the
+// string
+func (r *Result) ExpectStdoutContains(s string) *Result {
+	r.t.Helper()
+
+	if !strings.Contains(r.Stdout, s) {
+		r.t.Errorf("expected stdout containing %q, got %q", s, r.Stdout)
+	}
+
+	return r
+}
+
+// ExpectStderr reports an error if the error output is not equal to the
+// string
+func (r *Result) ExpectStderr(s string) *Result {
+	r.t.Helper()
+
+	if r.Stderr != s {
+		r.t.Errorf("expected stderr %q, got %q", s, r.Stderr)
+	}
+
+	return r
+}
+
+// ExpectStderrContains reports an error if the error output does not
+// contain the string
+func (r *Result) ExpectStderrContains(s string) *Result {
+	r.t.Helper()
+
+	if !strings.Contains(r.Stderr, s) {
+		r.t.Errorf("expected stderr containing %q, got %q", s, r.Stderr)
+	}
+
+	return r
+}
@@ -0,0 +1,93 @@
+package clitest
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/urfave/cli/v3"
+)
+
+// recorder records the errors reported by expectations
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func newGreetCommand() *cli.Command {
+	return &cli.Command{
+		Name: "greet",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "name", Value: "world"},
+		},
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			if cmd.String("name") == "nobody" {
+				return cli.Exit("nobody to greet", 3)
+			}
+
+			if cmd.String("name") == "-" {
+				line, _ := bufio.NewReader(cmd.Reader).ReadString('\n')
+				_ = cmd.Set("name", strings.TrimSpace(line))
+			}
+
+			fmt.Fprintf(cmd.Writer, "Hello %s\n", cmd.String("name"))
+			return nil
+		},
+	}
+}
+
+func TestRun(t *testing.T) {
+	cmd := newGreetCommand()
+
+	Run(t, cmd, "--name", "gopher").
+		ExpectSuccess().
+		ExpectExitCode(0).
+		ExpectStdout("Hello gopher\n").
+		ExpectStderr("")
+
+	Run(t, cmd, "--name", "nobody").
+		ExpectExitCode(3).
+		ExpectErrorContains("nobody to greet").
+		ExpectStderrContains("nobody to greet")
+
+	Run(t, cmd, "--nope").
+		ExpectExitCode(1).
+		ExpectErrorContains("flag provided but not defined").
+		ExpectStderrContains("Incorrect Usage")
+
+	assert.Nil(t, cmd.Writer)
+	assert.Nil(t, cmd.ErrWriter)
+}
+
+func TestRunWithStdin(t *testing.T) {
+	RunWithStdin(t, newGreetCommand(), strings.NewReader("stdin\n"), "--name", "-").
+		ExpectSuccess().
+		ExpectStdoutContains("Hello stdin")
+}
+
+func TestExpectationsReportErrors(t *testing.T) {
+	rec := &recorder{TB: t}
+
+	Run(rec, newGreetCommand()).
+		ExpectExitCode(2).
+		ExpectErrorContains("failed").
+		ExpectStdoutContains("Bye").
+		ExpectStderr("oops")
+
+	assert.Equal(t, []string{
+		"expected exit code 2, got 0 (error: <nil>)",
+		`expected an error containing "failed", got none`,
+		`expected stdout containing "Bye", got "Hello world\n"`,
+		`expected stderr "oops", got ""`,
+	}, rec.errors)
+}
@@ -0,0 +1,88 @@
+package clitest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+// fuzzParseTimeout is the time a single input may take to parse before it
+// is reported as a hang
+const fuzzParseTimeout = 10 * time.Second
+
+// FuzzParse fuzzes the argument parsing of the command returned by newCmd,
+// reporting inputs which panic or hang. A new command is created for every
+// input, which holds the arguments separated by newlines, not including the
+// program name. The corpus is seeded with the given seeds and the flags and
+// subcommands of the command. No actions are run, see cli.Command.Parse.
+//
+//	func FuzzApp(f *testing.F) {
+//		clitest.FuzzParse(f, newApp, "serve\n--port\n8080")
+//	}
+func FuzzParse(f *testing.F, newCmd func() *cli.Command, seeds ...string) {
+	f.Helper()
+
+	f.Add("")
+
+	for _, seed := range commandSeeds(newCmd(), nil) {
+		f.Add(seed)
+	}
+
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		cmd := newCmd()
+		args := append([]string{cmd.Name}, strings.Split(input, "\n")...)
+
+		done := make(chan any, 1)
+
+		go func() {
+			defer func() { done <- recover() }()
+
+			_, _ = cmd.Parse(context.Background(), args)
+		}()
+
+		select {
+		case r := <-done:
+			if r != nil {
+				t.Fatalf("parsing %q panicked: %v", args, r)
+			}
+		case <-time.After(fuzzParseTimeout):
+			t.Fatalf("parsing %q did not finish within %s", args, fuzzParseTimeout)
+		}
+	})
+}
+
+// commandSeeds returns seeds setting every flag of the command and its
+// subcommands, prefixed by the path of the command
+func commandSeeds(cmd *cli.Command, path []string) []string {
+	var seeds []string
+
+	for _, fl := range cmd.Flags {
+		for _, name := range fl.Names() {
+			prefix := "--"
+			if len(name) == 1 {
+				prefix = "-"
+			}
+
+			seeds = append(seeds,
+				strings.Join(append(path, prefix+name), "\n"),
+				strings.Join(append(path, prefix+name, "1"), "\n"),
+				strings.Join(append(path, prefix+name+"="), "\n"),
+			)
+		}
+	}
+
+	for _, sub := range cmd.Commands {
+		subPath := append(append([]string{}, path...), sub.Name)
+		seeds = append(seeds, strings.Join(subPath, "\n"))
+		seeds = append(seeds, commandSeeds(sub, subPath)...)
+	}
+
+	return seeds
+}
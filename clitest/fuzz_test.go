@@ -0,0 +1,42 @@
+package clitest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v3"
+)
+
+func newFuzzCommand() *cli.Command {
+	return &cli.Command{
+		Name: "app",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "name", Aliases: []string{"n"}},
+		},
+		Commands: []*cli.Command{
+			{
+				Name: "serve",
+				Flags: []cli.Flag{
+					&cli.IntFlag{Name: "port"},
+				},
+				Action: func(context.Context, *cli.Command) error {
+					panic("actions are not run when parsing")
+				},
+			},
+		},
+	}
+}
+
+func TestCommandSeeds(t *testing.T) {
+	assert.Equal(t, []string{
+		"--name", "--name\n1", "--name=",
+		"-n", "-n\n1", "-n=",
+		"serve",
+		"serve\n--port", "serve\n--port\n1", "serve\n--port=",
+	}, commandSeeds(newFuzzCommand(), nil))
+}
+
+func FuzzCommandParse(f *testing.F) {
+	FuzzParse(f, newFuzzCommand, "serve\n--port\n8080")
+}
@@ -0,0 +1,113 @@
+package clitest
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+)
+
+// UpdateGoldenEnvVar is the environment variable which, set to a true value
+// like "1", makes ExpectGolden write the golden files instead of comparing
+// them
+const UpdateGoldenEnvVar = "UPDATE_GOLDEN"
+
+// updateGolden returns true if the golden files are to be updated, i.e.
+// UpdateGoldenEnvVar is set or the test package defines an -update flag
+// which is set. No flag is defined by this package, so it doesn't conflict
+// with the one of the test package.
+func updateGolden() bool {
+	if v, err := strconv.ParseBool(os.Getenv(UpdateGoldenEnvVar)); err == nil && v {
+		return true
+	}
+
+	if f := flag.Lookup("update"); f != nil {
+		if g, ok := f.Value.(flag.Getter); ok {
+			v, _ := g.Get().(bool)
+			return v
+		}
+	}
+
+	return false
+}
+
+// ExpectGolden reports an error if got differs from the content of the
+// golden file, ignoring windows line endings. With UPDATE_GOLDEN=1, or when
+// run with -update if the test package defines that flag, the golden file
+// is written with got instead.
+func ExpectGolden(t testing.TB, file, got string) {
+	t.Helper()
+
+	got = normalizeNewlines(got)
+
+	if updateGolden() {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatalf("unable to create directory of golden file: %v", err)
+		}
+		if err := os.WriteFile(file, []byte(got), 0o644); err != nil {
+			t.Fatalf("unable to update golden file: %v", err)
+		}
+		return
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("unable to read golden file, set "+UpdateGoldenEnvVar+"=1 to create it: %v", err)
+	}
+
+	if want := normalizeNewlines(string(data)); got != want {
+		t.Errorf("output differs from golden file %s, set "+UpdateGoldenEnvVar+"=1 to update it\n--- want\n%s\n--- got\n%s", file, want, got)
+	}
+}
+
+// ExpectStdoutGolden reports an error if the output differs from the
+// content of the golden file, see ExpectGolden
+func (r *Result) ExpectStdoutGolden(file string) *Result {
+	r.t.Helper()
+
+	ExpectGolden(r.t, file, r.Stdout)
+
+	return r
+}
+
+// ExpectStderrGolden reports an error if the error output differs from the
+// content of the golden file, see ExpectGolden
+func (r *Result) ExpectStderrGolden(file string) *Result {
+	r.t.Helper()
+
+	ExpectGolden(r.t, file, r.Stderr)
+
+	return r
+}
+
+func normalizeNewlines(s string) string {
+	return strings.ReplaceAll(s, "\r\n", "\n")
+}
+
+// ExpectInvocationGolden records the invocation of the command with the
+// given arguments, not including the program name, and compares its JSON
+// encoding with the content of the golden file, see ExpectGolden and
+// cli.Command.Record. No actions are run.
+func ExpectInvocationGolden(t testing.TB, cmd *cli.Command, file string, args ...string) {
+	t.Helper()
+
+	inv, err := cmd.Record(context.Background(), append([]string{cmd.Name}, args...))
+	if err != nil {
+		t.Fatalf("unable to record invocation: %v", err)
+		return
+	}
+
+	data, err := json.MarshalIndent(inv, "", "  ")
+	if err != nil {
+		t.Fatalf("unable to encode invocation: %v", err)
+		return
+	}
+
+	ExpectGolden(t, file, string(data)+"\n")
+}
@@ -0,0 +1,61 @@
+package clitest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpectGolden(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "help.golden")
+	require.NoError(t, os.WriteFile(file, []byte("Hello world\r\n"), 0o644))
+
+	Run(t, newGreetCommand()).ExpectStdoutGolden(file)
+
+	rec := &recorder{TB: t}
+	Run(rec, newGreetCommand(), "--name", "gopher").ExpectStdoutGolden(file)
+	require.Len(t, rec.errors, 1)
+	assert.Contains(t, rec.errors[0], "output differs from golden file")
+}
+
+func TestExpectGoldenUpdate(t *testing.T) {
+	t.Setenv(UpdateGoldenEnvVar, "1")
+
+	file := filepath.Join(t.TempDir(), "testdata", "help.golden")
+
+	Run(t, newGreetCommand(), "--help").ExpectStdoutGolden(file)
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "USAGE:")
+}
+
+// update is the flag test packages commonly define for golden files, which
+// panics as redefined flag if clitest defines it as well
+var update = flag.Bool("update", false, "update golden files")
+
+func TestExpectGoldenUpdateFlag(t *testing.T) {
+	*update = true
+	defer func() { *update = false }()
+
+	file := filepath.Join(t.TempDir(), "help.golden")
+
+	Run(t, newGreetCommand(), "--help").ExpectStdoutGolden(file)
+
+	assert.FileExists(t, file)
+}
+
+func TestExpectInvocationGolden(t *testing.T) {
+	file := filepath.Join("testdata", "greet-invocation.golden")
+
+	ExpectInvocationGolden(t, newGreetCommand(), file, "--name", "gopher", "extra")
+
+	rec := &recorder{TB: t}
+	ExpectInvocationGolden(rec, newGreetCommand(), file, "extra")
+	require.Len(t, rec.errors, 1)
+	assert.Contains(t, rec.errors[0], `"origin": "default"`)
+}
@@ -0,0 +1,112 @@
+package clitest
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+)
+
+// Script answers the prompts of a command, e.g. for missing required flags,
+// with scripted responses in tests
+type Script struct {
+	t testing.TB
+
+	steps []scriptStep
+	next  int
+
+	// output written since the last response
+	output  bytes.Buffer
+	pending []byte
+
+	echoDisabled bool
+}
+
+type scriptStep struct {
+	prompt    string
+	response  string
+	sensitive bool
+}
+
+// NewScript returns an empty script
+func NewScript() *Script {
+	return &Script{}
+}
+
+// Expect adds a step answering the prompt containing the given text with
+// the response
+func (s *Script) Expect(prompt, response string) *Script {
+	s.steps = append(s.steps, scriptStep{prompt: prompt, response: response})
+	return s
+}
+
+// ExpectSecret adds a step like Expect, which additionally requires the
+// echo of the input to be disabled, as for sensitive flags
+func (s *Script) ExpectSecret(prompt, response string) *Script {
+	s.steps = append(s.steps, scriptStep{prompt: prompt, response: response, sensitive: true})
+	return s
+}
+
+// Read implements io.Reader, returning the response of the next step once
+// all of the pending response has been read
+func (s *Script) Read(p []byte) (int, error) {
+	if len(s.pending) == 0 {
+		if s.next >= len(s.steps) {
+			return 0, io.EOF
+		}
+
+		step := s.steps[s.next]
+		s.next++
+
+		if output := s.output.String(); !strings.Contains(output, step.prompt) {
+			s.t.Errorf("expected prompt %q, got %q", step.prompt, output)
+		}
+		if step.sensitive && !s.echoDisabled {
+			s.t.Errorf("expected echo to be disabled for prompt %q", step.prompt)
+		}
+
+		s.output.Reset()
+		s.pending = []byte(step.response + "\n")
+	}
+
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+
+	return n, nil
+}
+
+// Write records the output the next prompt is expected in
+func (s *Script) Write(p []byte) (int, error) {
+	return s.output.Write(p)
+}
+
+// DisableEcho implements cli.EchoDisabler
+func (s *Script) DisableEcho() (func(), error) {
+	s.echoDisabled = true
+	return func() { s.echoDisabled = false }, nil
+}
+
+// RunScript runs the command like Run, answering its prompts according to
+// the script. The command is run as if in an interactive terminal unless it
+// has a Terminal set. Steps which have not been reached are reported as
+// errors.
+func RunScript(t testing.TB, cmd *cli.Command, script *Script, args ...string) *Result {
+	t.Helper()
+
+	script.t = t
+
+	if cmd.Terminal == nil {
+		cmd.Terminal = TTY(80)
+		defer func() { cmd.Terminal = nil }()
+	}
+
+	res := run(t, cmd, script, script, args)
+
+	for _, step := range script.steps[script.next:] {
+		t.Errorf("expected prompt %q was not reached", step.prompt)
+	}
+
+	return res
+}
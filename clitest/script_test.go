@@ -0,0 +1,53 @@
+package clitest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/urfave/cli/v3"
+)
+
+func newLoginCommand() *cli.Command {
+	return &cli.Command{
+		Name:          "login",
+		PromptMissing: true,
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "user", Usage: "user name", Required: true},
+			&cli.StringFlag{Name: "password", Required: true, Sensitive: true},
+		},
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			fmt.Fprintf(cmd.Writer, "%s:%s", cmd.String("user"), cmd.String("password"))
+			return nil
+		},
+	}
+}
+
+func TestRunScript(t *testing.T) {
+	script := NewScript().
+		Expect("user (user name): ", "gopher").
+		ExpectSecret("password: ", "hunter2")
+
+	RunScript(t, newLoginCommand(), script).
+		ExpectSuccess().
+		ExpectStdout("gopher:hunter2")
+}
+
+func TestRunScriptReportsErrors(t *testing.T) {
+	rec := &recorder{TB: t}
+
+	script := NewScript().
+		ExpectSecret("username", "gopher").
+		Expect("password", "hunter2").
+		Expect("token", "abc")
+
+	RunScript(rec, newLoginCommand(), script)
+
+	assert.Equal(t, []string{
+		`expected prompt "username", got "user (user name): "`,
+		`expected echo to be disabled for prompt "username"`,
+		`expected prompt "token" was not reached`,
+	}, rec.errors)
+}
@@ -0,0 +1,36 @@
+package clitest
+
+// FakeTerminal is a cli.Terminal with fixed properties, e.g. to run a
+// command as if in an interactive terminal of a certain width
+type FakeTerminal struct {
+	TTY     bool
+	Columns int
+	Color   bool
+}
+
+// TTY returns an interactive terminal with the given number of columns
+// supporting colors
+func TTY(columns int) *FakeTerminal {
+	return &FakeTerminal{TTY: true, Columns: columns, Color: true}
+}
+
+// Pipe returns a non-interactive terminal of unknown width without colors,
+// as if input and output were redirected
+func Pipe() *FakeTerminal {
+	return &FakeTerminal{}
+}
+
+// IsTTY implements cli.Terminal
+func (t *FakeTerminal) IsTTY() bool {
+	return t.TTY
+}
+
+// Width implements cli.Terminal
+func (t *FakeTerminal) Width() int {
+	return t.Columns
+}
+
+// ColorSupported implements cli.Terminal
+func (t *FakeTerminal) ColorSupported() bool {
+	return t.Color
+}
@@ -0,0 +1,27 @@
+package clitest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+)
+
+func TestFakeTerminal(t *testing.T) {
+	var _ cli.Terminal = &FakeTerminal{}
+
+	cmd := &cli.Command{
+		Name: "term",
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			fmt.Fprintf(cmd.Writer, "tty=%v width=%d color=%v", cmd.IsTTY(), cmd.TerminalWidth(), cmd.ColorSupported())
+			return nil
+		},
+	}
+
+	cmd.Terminal = TTY(80)
+	Run(t, cmd).ExpectStdout("tty=true width=80 color=true")
+
+	cmd.Terminal = Pipe()
+	Run(t, cmd).ExpectStdout("tty=false width=0 color=false")
+}
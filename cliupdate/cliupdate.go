@@ -0,0 +1,93 @@
+// Package cliupdate provides release endpoints for the UpdateCheck of a
+// command. It is kept out of the cli package so applications without an
+// update check don't link the HTTP client:
+//
+//	cmd := &cli.Command{
+//		Name:    "app",
+//		Version: version,
+//		UpdateCheck: &cli.UpdateCheck{
+//			Latest:       cliupdate.GitHubRelease("acme", "app"),
+//			OptOutEnvVar: "APP_NO_UPDATE_CHECK",
+//		},
+//	}
+package cliupdate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxResponseSize limits the bytes read from a release endpoint
+const maxResponseSize = 1 << 20
+
+// Endpoint returns a function fetching the latest version from the URL.
+// JSON responses are expected to hold the version in a "tag_name" (like
+// the GitHub releases API) or "version" field, any other response is
+// taken as the version in plain text. The client is http.DefaultClient if
+// nil.
+func Endpoint(url string, client *http.Client) func(ctx context.Context) (string, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return func(ctx context.Context) (string, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Accept", "application/json, text/plain")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("unexpected status %q from %s", resp.Status, url)
+		}
+
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+		if err != nil {
+			return "", err
+		}
+
+		return parseVersion(body)
+	}
+}
+
+// GitHubRelease returns a function fetching the tag of the latest release
+// of the GitHub repository
+func GitHubRelease(owner, repo string) func(ctx context.Context) (string, error) {
+	return Endpoint(fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/latest", owner, repo), nil)
+}
+
+func parseVersion(body []byte) (string, error) {
+	text := strings.TrimSpace(string(body))
+	if !strings.HasPrefix(text, "{") {
+		if text == "" {
+			return "", fmt.Errorf("empty version")
+		}
+		return text, nil
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(body, &release); err != nil {
+		return "", err
+	}
+
+	if release.TagName != "" {
+		return release.TagName, nil
+	}
+	if release.Version != "" {
+		return release.Version, nil
+	}
+	return "", fmt.Errorf("no tag_name or version in response")
+}
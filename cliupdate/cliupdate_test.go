@@ -0,0 +1,88 @@
+package cliupdate
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/urfave/cli/v3"
+)
+
+func TestEndpoint(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/github", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"tag_name": "v1.4.0", "name": "Release 1.4"}`))
+	})
+	mux.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"version": "2.0.1"}`))
+	})
+	mux.HandleFunc("/text", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("v3.0.0\n"))
+	})
+	mux.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	for _, testCase := range []struct {
+		path     string
+		expected string
+		err      string
+	}{
+		{path: "/github", expected: "v1.4.0"},
+		{path: "/json", expected: "2.0.1"},
+		{path: "/text", expected: "v3.0.0"},
+		{path: "/empty", err: "no tag_name or version in response"},
+		{path: "/missing", err: `unexpected status "404 Not Found" from ` + srv.URL + "/missing"},
+	} {
+		t.Run(testCase.path, func(t *testing.T) {
+			version, err := Endpoint(srv.URL+testCase.path, srv.Client())(context.Background())
+			if testCase.err != "" {
+				assert.EqualError(t, err, testCase.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, version)
+		})
+	}
+
+	t.Run("timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := Endpoint(srv.URL+"/slow", srv.Client())(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestEndpointUpdateCheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"tag_name": "v1.1.0"}`))
+	}))
+	defer srv.Close()
+
+	out := &bytes.Buffer{}
+	cmd := &cli.Command{
+		Name:    "app",
+		Version: "v1.0.0",
+		Writer:  out,
+		UpdateCheck: &cli.UpdateCheck{
+			StateDir: t.TempDir(),
+			Latest:   Endpoint(srv.URL, srv.Client()),
+		},
+	}
+
+	require.NoError(t, cmd.Run(context.Background(), []string{"app", "version", "--check"}))
+	assert.Equal(t, "app version v1.0.0\nA new version of app is available: v1.1.0\n", out.String())
+}
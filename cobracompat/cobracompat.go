@@ -0,0 +1,225 @@
+// Package cobracompat converts spf13/cobra command trees to urfave/cli
+// commands, lowering the cost of migrating a CLI between the frameworks.
+//
+// It is a module of its own, so cobra is only a dependency of the programs
+// using this package and not of every program using urfave/cli.
+package cobracompat
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/urfave/cli/v3"
+	"github.com/urfave/cli/v3/pflagcompat"
+)
+
+// Import converts the cobra command and its subcommands to a command,
+// returning descriptions of the constructs which could not be mapped.
+//
+// Names, aliases, descriptions, versions, groups, hidden and deprecated
+// commands, flags including persistent and required ones and args
+// validators are converted. Run and RunE become the Action, wrapped by
+// PreRun and PostRun, and the persistent hooks become Before and After.
+// Unlike cobra, the persistent hooks of every ancestor are run and they
+// receive the cobra command they are defined on. Flag values are set on
+// the original pflag values, so the hooks can read them as before.
+//
+//	cmd, issues := cobracompat.Import(rootCmd)
+//	for _, issue := range issues {
+//		log.Println(issue)
+//	}
+func Import(c *cobra.Command) (*cli.Command, []string) {
+	im := &importer{}
+
+	cmd := im.convert(c)
+	cmd.Version = c.Version
+
+	return cmd, im.issues
+}
+
+type importer struct {
+	issues []string
+}
+
+func (im *importer) unsupported(c *cobra.Command, format string, a ...any) {
+	im.issues = append(im.issues, c.CommandPath()+": "+fmt.Sprintf(format, a...))
+}
+
+func (im *importer) convert(c *cobra.Command) *cli.Command {
+	cmd := &cli.Command{
+		Name:            c.Name(),
+		Aliases:         c.Aliases,
+		Usage:           c.Short,
+		Description:     c.Long,
+		ArgsUsage:       argsUsage(c.Use),
+		Hidden:          c.Hidden || c.Deprecated != "",
+		Deprecated:      c.Deprecated,
+		SkipFlagParsing: c.DisableFlagParsing,
+		Flags: append(
+			pflagcompat.Flags(withoutBuiltins(c.LocalNonPersistentFlags())),
+			pflagcompat.PersistentFlags(withoutBuiltins(c.PersistentFlags()))...,
+		),
+	}
+
+	if c.GroupID != "" && c.HasParent() {
+		for _, group := range c.Parent().Groups() {
+			if group.ID == c.GroupID {
+				cmd.Category = group.Title
+			}
+		}
+	}
+
+	if len(c.ValidArgs) > 0 {
+		cmd.ShellComplete = func(_ context.Context, cmd *cli.Command) {
+			for _, arg := range c.ValidArgs {
+				fmt.Fprintln(cmd.Root().Writer, strings.SplitN(arg, "\t", 2)[0])
+			}
+		}
+	}
+
+	im.convertHooks(c, cmd)
+	im.reportUnsupported(c)
+
+	for _, sub := range c.Commands() {
+		cmd.Commands = append(cmd.Commands, im.convert(sub))
+	}
+
+	return cmd
+}
+
+// convertHooks converts the run functions of the cobra command
+func (im *importer) convertHooks(c *cobra.Command, cmd *cli.Command) {
+	if c.PersistentPreRun != nil || c.PersistentPreRunE != nil {
+		cmd.Before = func(ctx context.Context, cmd *cli.Command) error {
+			return runHook(ctx, c, cmd, c.PersistentPreRun, c.PersistentPreRunE)
+		}
+	}
+
+	if c.PersistentPostRun != nil || c.PersistentPostRunE != nil {
+		cmd.After = func(ctx context.Context, cmd *cli.Command) error {
+			return runHook(ctx, c, cmd, c.PersistentPostRun, c.PersistentPostRunE)
+		}
+	}
+
+	if c.Run == nil && c.RunE == nil {
+		return
+	}
+
+	cmd.Action = func(ctx context.Context, cmd *cli.Command) error {
+		if c.Args != nil {
+			if err := c.Args(c, cmd.Args().Slice()); err != nil {
+				return err
+			}
+		}
+
+		if err := runHook(ctx, c, cmd, c.PreRun, c.PreRunE); err != nil {
+			return err
+		}
+
+		if err := runHook(ctx, c, cmd, c.Run, c.RunE); err != nil {
+			return err
+		}
+
+		return runHook(ctx, c, cmd, c.PostRun, c.PostRunE)
+	}
+}
+
+// runHook runs the cobra hook with the positional arguments of the
+// command, preferring the variant returning an error like cobra does
+func runHook(ctx context.Context, c *cobra.Command, cmd *cli.Command, run func(*cobra.Command, []string), runE func(*cobra.Command, []string) error) error {
+	// merges the persistent flags of the ancestors into the flags of the
+	// command, which cobra does when parsing
+	_ = c.InheritedFlags()
+
+	c.SetContext(ctx)
+	c.SetOut(cmd.Root().Writer)
+	c.SetErr(cmd.Root().ErrWriter)
+
+	switch {
+	case runE != nil:
+		return runE(c, cmd.Args().Slice())
+	case run != nil:
+		run(c, cmd.Args().Slice())
+	}
+
+	return nil
+}
+
+// reportUnsupported records the constructs of the command which have no
+// equivalent
+func (im *importer) reportUnsupported(c *cobra.Command) {
+	if c.ValidArgsFunction != nil {
+		im.unsupported(c, "ValidArgsFunction is not converted, set ShellComplete instead")
+	}
+
+	if c.Example != "" {
+		im.unsupported(c, "Example is not converted")
+	}
+
+	if len(c.SuggestFor) > 0 {
+		im.unsupported(c, "SuggestFor is not converted")
+	}
+
+	if len(c.ArgAliases) > 0 {
+		im.unsupported(c, "ArgAliases are not converted")
+	}
+
+	if c.BashCompletionFunction != "" {
+		im.unsupported(c, "BashCompletionFunction is not converted")
+	}
+
+	if c.TraverseChildren {
+		im.unsupported(c, "TraverseChildren is not converted, flags must be given to the command defining them")
+	}
+
+	if c.FParseErrWhitelist.UnknownFlags {
+		im.unsupported(c, "unknown flags are not ignored")
+	}
+
+	if len(c.Annotations) > 0 {
+		keys := make([]string, 0, len(c.Annotations))
+		for key := range c.Annotations {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		im.unsupported(c, "annotations %s are not converted", strings.Join(keys, ", "))
+	}
+}
+
+// withoutBuiltins returns the flags of the set except the help and version
+// flags cobra adds, which cli provides itself
+func withoutBuiltins(fs *pflag.FlagSet) *pflag.FlagSet {
+	filtered := pflag.NewFlagSet("", pflag.ContinueOnError)
+
+	fs.VisitAll(func(f *pflag.Flag) {
+		if f.Name != "help" && f.Name != "version" {
+			filtered.AddFlag(f)
+		}
+	})
+
+	return filtered
+}
+
+// argsUsage returns the usage of the arguments from the one line usage of
+// a cobra command, e.g. "<file>" for "cat [flags] <file>"
+func argsUsage(use string) string {
+	fields := strings.Fields(use)
+	if len(fields) < 2 {
+		return ""
+	}
+
+	var args []string
+	for _, field := range fields[1:] {
+		if field != "[flags]" {
+			args = append(args, field)
+		}
+	}
+
+	return strings.Join(args, " ")
+}
@@ -0,0 +1,99 @@
+package cobracompat
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImport(t *testing.T) {
+	var calls []string
+
+	record := func(name string) func(*cobra.Command, []string) {
+		return func(c *cobra.Command, args []string) {
+			calls = append(calls, name+" "+c.Name())
+		}
+	}
+
+	root := &cobra.Command{
+		Use:               "app",
+		Short:             "an app",
+		Version:           "1.2.3",
+		PersistentPreRun:  record("persistent-pre"),
+		PersistentPostRun: record("persistent-post"),
+	}
+	root.PersistentFlags().StringP("config", "c", "app.yaml", "config file")
+	root.AddGroup(&cobra.Group{ID: "server", Title: "Server Commands"})
+
+	var port int
+	var config string
+	var args []string
+
+	serve := &cobra.Command{
+		Use:     "serve [flags] <addr>",
+		Aliases: []string{"s"},
+		Short:   "serve requests",
+		Long:    "Serve requests until interrupted.",
+		GroupID: "server",
+		Args:    cobra.ExactArgs(1),
+		Example: "app serve :8080",
+		PreRun:  record("pre"),
+		RunE: func(c *cobra.Command, a []string) error {
+			calls = append(calls, "run "+c.Name())
+			port, _ = c.Flags().GetInt("port")
+			config, _ = c.Flags().GetString("config")
+			args = a
+			return nil
+		},
+		PostRun: record("post"),
+	}
+	serve.Flags().IntP("port", "p", 80, "port to listen on")
+	require.NoError(t, serve.MarkFlagRequired("port"))
+
+	legacy := &cobra.Command{Use: "legacy", Deprecated: "use serve instead", Run: record("run")}
+
+	root.AddCommand(serve, legacy)
+
+	cmd, issues := Import(root)
+	assert.Equal(t, []string{"app serve: Example is not converted"}, issues)
+
+	assert.Equal(t, "app", cmd.Name)
+	assert.Equal(t, "an app", cmd.Usage)
+	assert.Equal(t, "1.2.3", cmd.Version)
+	assert.Nil(t, cmd.Action)
+	require.Len(t, cmd.Commands, 2)
+
+	sub := cmd.Command("serve")
+	require.NotNil(t, sub)
+	assert.Equal(t, "serve", sub.Name)
+	assert.Equal(t, []string{"s"}, sub.Aliases)
+	assert.Equal(t, "Serve requests until interrupted.", sub.Description)
+	assert.Equal(t, "<addr>", sub.ArgsUsage)
+	assert.Equal(t, "Server Commands", sub.Category)
+	assert.True(t, cmd.Command("legacy").Hidden)
+
+	out := &bytes.Buffer{}
+	cmd.Writer = out
+	cmd.ErrWriter = out
+
+	require.NoError(t, cmd.Run(context.Background(), []string{"app", "-c", "prod.yaml", "serve", "-p", "8080", ":8080"}))
+	assert.Equal(t, []string{"persistent-pre app", "pre serve", "run serve", "post serve", "persistent-post app"}, calls)
+	assert.Equal(t, 8080, port)
+	assert.Equal(t, "prod.yaml", config)
+	assert.Equal(t, []string{":8080"}, args)
+
+	err := cmd.Run(context.Background(), []string{"app", "serve", "-p", "8080"})
+	require.EqualError(t, err, "accepts 1 arg(s), received 0")
+
+	err = cmd.Run(context.Background(), []string{"app", "serve", ":8080"})
+	require.EqualError(t, err, `Required flag "port" not set`)
+
+	calls = nil
+	require.NoError(t, cmd.Run(context.Background(), []string{"app", "legacy"}))
+	assert.Equal(t, []string{"persistent-pre app", "run legacy", "persistent-post app"}, calls)
+	assert.Contains(t, out.String(), `warning: command "legacy" is deprecated, use serve instead`)
+}
@@ -0,0 +1,23 @@
+module github.com/urfave/cli/v3/cobracompat
+
+go 1.18
+
+replace (
+	github.com/urfave/cli/v3 => ../
+	github.com/urfave/cli/v3/pflagcompat => ../pflagcompat
+)
+
+require (
+	github.com/spf13/cobra v1.8.0
+	github.com/spf13/pflag v1.0.5
+	github.com/stretchr/testify v1.8.4
+	github.com/urfave/cli/v3 v3.0.0-alpha9
+	github.com/urfave/cli/v3/pflagcompat v0.0.0-00010101000000-000000000000
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
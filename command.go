@@ -9,8 +9,10 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
-	"sort"
 	"strings"
+	"sync"
+	"text/template"
+	"time"
 	"unicode"
 )
 
@@ -46,8 +48,21 @@ type Command struct {
 	DefaultCommand string `json:"defaultCommand"`
 	// The category the command is part of
 	Category string `json:"category"`
+	// The categories of the subcommands in the order they are shown along
+	// with their descriptions, inherited by the subcommands
+	Categories []Category `json:"categories"`
 	// List of child commands
 	Commands []*Command `json:"commands"`
+	// Factory builds the actual command the first time it is looked up,
+	// see Lazy. The built command inherits the name, aliases, usage,
+	// category and visibility of this command unless it sets its own.
+	// applicable to subcommands only
+	Factory func() *Command `json:"-"`
+	// CommandLoader builds subcommands by name on demand: the placeholders
+	// returned by Lazy without a factory, and names which are none of the
+	// Commands when they are invoked. It returns nil if there is no such
+	// command, so large generated command trees are only built as needed.
+	CommandLoader CommandLoaderFunc `json:"-"`
 	// List of flags to parse
 	Flags []Flag `json:"flags"`
 	// Boolean to hide built-in help command and help flag
@@ -58,6 +73,12 @@ type Command struct {
 	HideVersion bool `json:"hideVersion"`
 	// Boolean to enable shell completion commands
 	EnableShellCompletion bool `json:"-"`
+	// Boolean to enable the built-in dry-run flag on all commands
+	EnableDryRun bool `json:"enableDryRun"`
+	// Boolean to enable the built-in log-level and log-format flags on all
+	// commands and the injection of the configured logger into the context.
+	// Requires go1.21 or newer.
+	EnableLogging bool `json:"enableLogging"`
 	// Shell Completion generation command name
 	ShellCompletionCommandName string `json:"-"`
 	// The function to call when checking for shell command completions
@@ -70,6 +91,10 @@ type Command struct {
 	After AfterFunc `json:"-"`
 	// The function to call when this command is invoked
 	Action ActionFunc `json:"-"`
+	// A function validating the flags of the command once they have been
+	// parsed, run before Before and Action. Its errors are reported like
+	// missing required flags, errors joined by errors.Join one by one.
+	ValidateFlags ValidateFlagsFunc `json:"-"`
 	// Execute this function if the proper command cannot be found
 	CommandNotFound CommandNotFoundFunc `json:"-"`
 	// Execute this function if a usage error occurs.
@@ -78,6 +103,17 @@ type Command struct {
 	InvalidFlagAccessHandler InvalidFlagAccessFunc `json:"-"`
 	// Boolean to hide this command from help or completion
 	Hidden bool `json:"hidden"`
+	// Message of the warning emitted when the command is run, e.g. "use
+	// serve instead". Deprecated commands are annotated in the help output.
+	Deprecated string `json:"deprecated"`
+	// Examples are invocations illustrating the use of the command,
+	// documented in the help output and the docs
+	Examples []Example `json:"examples"`
+	// Since is the version of the program the command has been added in
+	Since string `json:"since"`
+	// SeeAlso are references to related commands or further documentation
+	// like URLs, documented in the help output and the docs
+	SeeAlso []string `json:"seeAlso"`
 	// List of all authors who contributed (string or fmt.Stringer)
 	// TODO: ~string | fmt.Stringer when interface unions are available
 	Authors []any `json:"authors"`
@@ -87,12 +123,20 @@ type Command struct {
 	Reader io.Reader `json:"-"`
 	// Writer writer to write output to
 	Writer io.Writer `json:"-"`
-	// ErrWriter writes error output
+	// ErrWriter writes error output, inherited by subcommands not setting
+	// their own
 	ErrWriter io.Writer `json:"-"`
+	// Execute this function to format errors written to ErrWriter,
+	// inherited by subcommands not setting their own
+	FormatError ErrorFormatFunc `json:"-"`
 	// ExitErrHandler processes any error encountered while running an App before
 	// it is returned to the caller. If no function is provided, HandleExitCoder
 	// is used as the default behavior.
 	ExitErrHandler ExitErrHandlerFunc `json:"-"`
+	// Exiter terminates the program on errors implementing ExitCoder,
+	// defaults to calling the package OsExiter
+	// applicable to root command only
+	Exiter Exiter `json:"-"`
 	// Other custom info
 	Metadata map[string]interface{} `json:"metadata"`
 	// Carries a function which returns app specific info.
@@ -105,17 +149,43 @@ type Command struct {
 	SliceFlagSeparator string `json:"sliceFlagSeparator"`
 	// DisableSliceFlagSeparator is used to disable SliceFlagSeparator, the default is false
 	DisableSliceFlagSeparator bool `json:"disableSliceFlagSeparator"`
+	// MapFlagKeyValueSeparator is used to customize the separator of keys and values
+	// of map flags, the default is "="
+	MapFlagKeyValueSeparator string `json:"mapFlagKeyValueSeparator"`
 	// Boolean to enable short-option handling so user can combine several
 	// single-character bool arguments into one
 	// i.e. foobar -o -v -> foobar -ov
 	UseShortOptionHandling bool `json:"useShortOptionHandling"`
+	// Whether to accept Windows style flags like /verbose and /out:file.txt
+	// in addition to -verbose and --out=file.txt, like FlagPrefixes "/"
+	// and FlagValueSeparators ":"
+	UseSlashFlags bool `json:"useSlashFlags"`
+	// FlagPrefixes are prefixes flags may be given with in addition to "-"
+	// and "--", e.g. "+" to accept +verbose. Arguments with the prefix not
+	// naming a flag, like /etc/hosts for the prefix "/", are left untouched.
+	FlagPrefixes []string `json:"flagPrefixes"`
+	// FlagValueSeparators are separators between the names and the values
+	// of flags in addition to "=", e.g. ":" to accept --out:file.txt
+	FlagValueSeparators []string `json:"flagValueSeparators"`
 	// Enable suggestions for commands and flags
 	Suggest bool `json:"suggest"`
+	// Whether to report all invalid flag values, missing required flags
+	// and argument errors at once instead of stopping at the first one
+	// applicable to root command only
+	CollectValidationErrors bool `json:"collectValidationErrors"`
+	// Whether to show only the usage of the flags causing a usage error
+	// instead of the whole help
+	// applicable to root command only
+	FlagUsageOnError bool `json:"flagUsageOnError"`
 	// Allows global flags set by libraries which use flag.XXXVar(...) directly
 	// to be parsed through this library
 	AllowExtFlags bool `json:"allowExtFlags"`
 	// Treat all flags as normal arguments if true
 	SkipFlagParsing bool `json:"skipFlagParsing"`
+	// Whether the arguments after the terminator "--" are passed through to
+	// the Action instead of being taken as positional arguments, see
+	// PassthroughArgs. They are shown as [-- ARGS...] in the usage.
+	EnablePassthroughArgs bool `json:"enablePassthroughArgs"`
 	// CustomHelpTemplate the text template for the command help topic.
 	// cli.go uses text/template to render templates. You can
 	// render custom help text by setting this variable.
@@ -126,11 +196,119 @@ type Command struct {
 	SuggestCommandFunc SuggestCommandFunc `json:"-"`
 	// Flag exclusion group
 	MutuallyExclusiveFlags []MutuallyExclusiveFlags `json:"mutuallyExclusiveFlags"`
+	// Groups of names of flags which have to be set together, so if one
+	// flag of a group is set all others have to be set as well
+	RequiredTogetherFlags [][]string `json:"requiredTogetherFlags"`
 	// Arguments to parse for this command
 	Arguments []Argument `json:"arguments"`
 	// Whether to read arguments from stdin
 	// applicable to root command only
 	ReadArgsFromStdin bool `json:"readArgsFromStdin"`
+	// Whether to add the error-format flag to every command, which allows
+	// to request errors to be written as json
+	// applicable to root command only
+	EnableErrorFormat bool `json:"enableErrorFormat"`
+	// Execute this function to rewrap any error before it is handled,
+	// printed or returned, e.g. to add hints or strip internal details
+	// applicable to root command only
+	DecorateError ErrorDecoratorFunc `json:"-"`
+	// Whether to prefix errors returned by actions with the full path of
+	// the command, e.g. "app db migrate: ..."
+	// applicable to root command only
+	ErrorsWithCommandPath bool `json:"errorsWithCommandPath"`
+	// Exit codes for errors which do not implement ExitCoder, the first
+	// matching exit status is used
+	// applicable to root command only
+	ExitCodes []ExitStatus `json:"-"`
+	// Exit code for errors neither implementing ExitCoder nor matching any
+	// of the ExitCodes, errors are not exited with if zero
+	// applicable to root command only
+	DefaultExitCode int `json:"defaultExitCode"`
+	// Exit code for usage errors like unknown or missing required flags,
+	// usage errors are not exited with if zero
+	// applicable to root command only
+	UsageErrorExitCode int `json:"usageErrorExitCode"`
+	// How warnings emitted via Warn are handled, printed by default
+	// applicable to root command only
+	WarningPolicy WarningPolicy `json:"warningPolicy"`
+	// Whether to add the strict flag to every command, which escalates
+	// warnings to errors
+	// applicable to root command only
+	EnableStrict bool `json:"enableStrict"`
+	// Signals cancelling the context passed to the functions of the run,
+	// like NotifyContext does, e.g. os.Interrupt and syscall.SIGTERM
+	// applicable to root command only
+	HandleSignals []os.Signal `json:"-"`
+	// Time given to a run cancelled by one of the HandleSignals to return,
+	// after which the After functions of the invoked command and its
+	// ancestors are run and the program exits. Zero waits for the run to
+	// return.
+	// applicable to root command only
+	ShutdownTimeout time.Duration `json:"shutdownTimeout"`
+	// Execute this function to determine the exit code of a run whose
+	// context created by NotifyContext or HandleSignals was cancelled by a
+	// signal, defaults to DefaultCancelExitCode
+	// applicable to root command only
+	CancelExitCode CancelExitCodeFunc `json:"-"`
+	// Terminal the command interacts with, defaults to inspecting the
+	// Reader and Writer and the environment
+	// applicable to root command only
+	Terminal Terminal `json:"-"`
+	// Whether to pipe the help output through the pager given by the PAGER
+	// environment variable, less by default, if the Terminal is interactive
+	// applicable to root command only
+	EnablePager bool `json:"enablePager"`
+	// Theme styling the help output and the error messages if the output
+	// supports colors, see DefaultTheme and MonochromeTheme
+	// applicable to root command only
+	Theme *Theme `json:"-"`
+	// Clock provides the current time to flags and actions, defaults to
+	// time.Now
+	// applicable to root command only
+	Clock Clock `json:"-"`
+	// Env is used to look up environment variables of flags and actions,
+	// defaults to the environment of the process, see DotEnvFiles to read
+	// .env files as well
+	// applicable to root command only
+	Env Environment `json:"-"`
+	// EnvPrefix derives the environment variable of every flag without
+	// Sources from its name, e.g. MYAPP_LOG_LEVEL for the flag log-level
+	// with the prefix "MYAPP"
+	// applicable to root command only
+	EnvPrefix string `json:"envPrefix"`
+	// Whether to add the executables named "<name>-<command>" in the
+	// ExternalCommandDirs and PATH as subcommands, like git does. The flags
+	// set on the root command are exported to them as environment variables
+	// like <NAME>_<FLAG>.
+	// applicable to root command only
+	EnableExternalCommands bool `json:"enableExternalCommands"`
+	// Directories searched for external commands before PATH
+	// applicable to root command only
+	ExternalCommandDirs []string `json:"externalCommandDirs"`
+	// Lock preventing the command from running concurrently
+	Lock *InstanceLock `json:"-"`
+	// Whether to prompt for missing required flags when the input is an
+	// interactive terminal, inherited by subcommands
+	PromptMissing bool `json:"promptMissing"`
+	// The Prompter asking for missing required flags if PromptMissing is
+	// enabled, inherited by subcommands. It is used regardless of whether
+	// the input is a terminal, defaults to reading lines from Reader.
+	Prompter Prompter `json:"-"`
+	// The prompt of the interactive shell started by RunShell
+	// applicable to root command only
+	ShellPrompt string `json:"shellPrompt"`
+	// The LineReader used by RunShell, defaults to reading lines from Reader
+	ShellLineReader LineReader `json:"-"`
+	// Tracer to start a span for every command invocation
+	// applicable to root command only
+	Tracer Tracer `json:"-"`
+	// Middleware wrapping the Action of the command and all its
+	// subcommands, see Use
+	Middleware []MiddlewareFunc `json:"-"`
+	// UpdateCheck enables the first run notice and the periodic check for
+	// newer releases
+	// applicable to root command only
+	UpdateCheck *UpdateCheck `json:"-"`
 
 	// categories contains the categorized commands and is populated on app startup
 	categories CommandCategories
@@ -143,14 +321,86 @@ type Command struct {
 	parent *Command
 	// the flag.FlagSet for this command
 	flagSet *flag.FlagSet
+	// flags keyed by all their names and the number of flags indexed, see
+	// flagByName
+	flagIndex    map[string]Flag
+	flagIndexLen int
+	// names of the flags set on the flag set they were collected from, see
+	// flagWasSet
+	setFlagNames      map[string]struct{}
+	setFlagNamesOf    *flag.FlagSet
+	setFlagNamesCount int
+	// indexes of the subcommands keyed by all their names, the trie of
+	// their names and the subcommands indexed, see indexCommands
+	commandIndex    map[string]int
+	commandTrie     *commandTrie
+	indexedCommands []indexedCommand
 	// parsed args
 	parsedArgs Args
+	// the positional args before and the args after the terminator "--",
+	// nil if there is none
+	positionalArgs  []string
+	passthroughArgs []string
 	// track state of error handling
 	isInError bool
 	// track state of defaults
 	didSetupDefaults bool
+	// whether the ErrWriter has been set up as os.Stderr by default, which
+	// the package ErrWriter takes precedence over unless it is replaced
+	defaultErrWriter bool
 	// whether in shell completion mode
 	shellCompletion bool
+	// whether this is the built-in help command
+	isHelpCommand bool
+	// whether this is another built-in command not using the flags, e.g.
+	// the completion command
+	isBuiltin bool
+	// whether the run only parses the arguments, see Parse
+	parseOnly bool
+	// logger built from the logging flags, see Logger
+	logger any
+	// invalid flag values collected while parsing
+	valueErrors []error
+	// the command invoked last during the run, the leaf of the graph
+	invokedCommand *Command
+	// reader used to prompt for missing flags
+	promptReader *bufio.Reader
+	// lines entered in the interactive shell
+	shellHistory []string
+	// handlers registered via Subscribe
+	eventHandlers []EventHandlerFunc
+	// dependencies registered via Provide
+	dependencies map[reflect.Type]*dependency
+	// dependencies constructed during the current run, tracked on the root
+	constructedDependencies []*dependency
+	dependencyMu            sync.Mutex
+	// warnings recorded during the current run, tracked on the root
+	warnings     []string
+	warningsFail bool
+	warningsMu   sync.Mutex
+	// parsed templates keyed by their text, tracked on the root
+	templates   map[string]*template.Template
+	templatesMu sync.Mutex
+	// functions registered via AddTemplateFunc
+	templateFuncs template.FuncMap
+	// state of the update check of the current run, tracked on the root
+	firstRun      bool
+	latestVersion string
+	// result of the update check running concurrently with the action
+	updateCheckDone chan updateState
+	// After functions of the current run which have not been run yet,
+	// tracked on the root to run them on a forced shutdown
+	pendingAfter   []pendingAfter
+	shutdownForced bool
+	shutdownMu     sync.Mutex
+	// error of the CommandLoader when looking up a subcommand
+	commandLoadErr error
+	// whether the command is a placeholder built by the CommandLoader of
+	// its parent
+	lazy bool
+	// whether the external commands have been discovered and described, see
+	// ensureExternalCommands
+	externalCommandsLoaded bool
 }
 
 // FullName returns the full name of the command.
@@ -166,10 +416,30 @@ func (cmd *Command) FullName() string {
 	return strings.Join(append(namePath, cmd.Name), " ")
 }
 
+// Command returns the subcommand with the given name or alias. The
+// subcommand is linked to the command as its parent, so its FullName and
+// documentation include its ancestors before the tree is run.
 func (cmd *Command) Command(name string) *Command {
-	for _, subCmd := range cmd.Commands {
-		if subCmd.HasName(name) {
-			return subCmd
+	if sub := cmd.definedCommand(name); sub != nil {
+		if sub.parent == nil {
+			sub.parent = cmd
+		}
+		return sub
+	}
+	if sub := cmd.lookupExternalCommand(name); sub != nil {
+		return sub
+	}
+
+	return cmd.loadUnknownCommand(name)
+}
+
+// definedCommand looks the subcommand up in the Commands without asking
+// the CommandLoader
+func (cmd *Command) definedCommand(name string) *Command {
+	if len(cmd.Commands) > 0 {
+		cmd.indexCommands()
+		if i, ok := cmd.commandIndex[name]; ok {
+			return cmd.loadCommand(i)
 		}
 	}
 
@@ -192,7 +462,8 @@ func (cmd *Command) setupDefaults(osArgs []string) {
 		cmd.ShellComplete = DefaultCompleteWithFlags(cmd)
 	}
 
-	if cmd.Name == "" && isRoot {
+	// runtimes like browser terminals may not provide a program name
+	if cmd.Name == "" && isRoot && len(osArgs) > 0 {
 		name := filepath.Base(osArgs[0])
 		tracef("setting cmd.Name from first arg basename (cmd=%[1]q)", name)
 		cmd.Name = name
@@ -223,9 +494,10 @@ func (cmd *Command) setupDefaults(osArgs []string) {
 		cmd.Writer = os.Stdout
 	}
 
-	if cmd.ErrWriter == nil {
+	if cmd.ErrWriter == nil && isRoot {
 		tracef("setting default ErrWriter as os.Stderr (cmd=%[1]q)", cmd.Name)
 		cmd.ErrWriter = os.Stderr
+		cmd.defaultErrWriter = true
 	}
 
 	if cmd.AllowExtFlags {
@@ -245,10 +517,15 @@ func (cmd *Command) setupDefaults(osArgs []string) {
 	}
 
 	cmd.ensureHelp()
+	cmd.ensureDryRun()
+	cmd.ensureLogging()
+	cmd.ensureErrorFormat()
+	cmd.ensureStrict()
 
 	if !cmd.HideVersion && isRoot {
 		tracef("appending version flag (cmd=%[1]q)", cmd.Name)
 		cmd.appendFlag(VersionFlag)
+		cmd.ensureVersionCommand()
 	}
 
 	if cmd.PrefixMatchCommands && cmd.SuggestCommandFunc == nil {
@@ -273,22 +550,18 @@ func (cmd *Command) setupDefaults(osArgs []string) {
 	}
 
 	tracef("setting command categories (cmd=%[1]q)", cmd.Name)
-	cmd.categories = newCommandCategories()
-
-	for _, subCmd := range cmd.Commands {
-		cmd.categories.AddCommand(subCmd.Category, subCmd)
-	}
-
-	tracef("sorting command categories (cmd=%[1]q)", cmd.Name)
-	sort.Sort(cmd.categories.(*commandCategories))
+	cmd.categories = categorize(cmd.Lineage(), cmd.Commands)
 
 	tracef("setting category on mutually exclusive flags (cmd=%[1]q)", cmd.Name)
 	for _, grp := range cmd.MutuallyExclusiveFlags {
 		grp.propagateCategory()
 	}
 
-	tracef("setting flag categories (cmd=%[1]q)", cmd.Name)
-	cmd.flagCategories = newFlagCategoriesFromFlags(cmd.allFlags())
+	cmd.setupFlagGroups()
+
+	// flag categories are only needed for help output, so they are built
+	// by VisibleFlagCategories on first use
+	cmd.flagCategories = nil
 
 	if cmd.Metadata == nil {
 		tracef("setting default Metadata (cmd=%[1]q)", cmd.Name)
@@ -302,6 +575,11 @@ func (cmd *Command) setupDefaults(osArgs []string) {
 
 	tracef("setting disableSliceFlagSeparator from cmd.DisableSliceFlagSeparator (cmd=%[1]q)", cmd.Name)
 	disableSliceFlagSeparator = cmd.DisableSliceFlagSeparator
+
+	if len(cmd.MapFlagKeyValueSeparator) != 0 {
+		tracef("setting defaultMapFlagKeyValueSeparator from cmd.MapFlagKeyValueSeparator (cmd=%[1]q)", cmd.Name)
+		defaultMapFlagKeyValueSeparator = cmd.MapFlagKeyValueSeparator
+	}
 }
 
 func (cmd *Command) setupCommandGraph() {
@@ -318,24 +596,24 @@ func (cmd *Command) setupSubcommand() {
 	tracef("setting up self as sub-command (cmd=%[1]q)", cmd.Name)
 
 	cmd.ensureHelp()
+	cmd.ensureDryRun()
+	cmd.ensureLogging()
+	cmd.ensureErrorFormat()
+	cmd.ensureStrict()
 
 	tracef("setting command categories (cmd=%[1]q)", cmd.Name)
-	cmd.categories = newCommandCategories()
-
-	for _, subCmd := range cmd.Commands {
-		cmd.categories.AddCommand(subCmd.Category, subCmd)
-	}
-
-	tracef("sorting command categories (cmd=%[1]q)", cmd.Name)
-	sort.Sort(cmd.categories.(*commandCategories))
+	cmd.categories = categorize(cmd.Lineage(), cmd.Commands)
 
 	tracef("setting category on mutually exclusive flags (cmd=%[1]q)", cmd.Name)
 	for _, grp := range cmd.MutuallyExclusiveFlags {
 		grp.propagateCategory()
 	}
 
-	tracef("setting flag categories (cmd=%[1]q)", cmd.Name)
-	cmd.flagCategories = newFlagCategoriesFromFlags(cmd.allFlags())
+	cmd.setupFlagGroups()
+
+	// flag categories are only needed for help output, so they are built
+	// by VisibleFlagCategories on first use
+	cmd.flagCategories = nil
 }
 
 func (cmd *Command) ensureHelp() {
@@ -343,7 +621,7 @@ func (cmd *Command) ensureHelp() {
 
 	helpCommand := buildHelpCommand(true)
 
-	if cmd.Command(helpCommand.Name) == nil && !cmd.HideHelp {
+	if cmd.definedCommand(helpCommand.Name) == nil && !cmd.HideHelp {
 		if !cmd.HideHelpCommand {
 			tracef("appending helpCommand (cmd=%[1]q)", cmd.Name)
 			cmd.appendCommand(helpCommand)
@@ -445,8 +723,51 @@ func (cmd *Command) Run(ctx context.Context, osArgs []string) (deferErr error) {
 		cmd.parent = v
 	}
 
+	cmd.Root().invokedCommand = cmd
+
 	if cmd.parent == nil {
-		if cmd.ReadArgsFromStdin {
+		defer func() {
+			if deferErr != nil && !cmd.parseOnly && cmd.invokedCommand.jsonErrors() {
+				cmd.invokedCommand.writeJSONError(deferErr)
+			}
+		}()
+
+		defer func() {
+			if err := cmd.closeDependencies(); err != nil {
+				if deferErr != nil {
+					deferErr = newMultiError(deferErr, err)
+				} else {
+					deferErr = err
+				}
+			}
+		}()
+
+		cmd.resetWarnings()
+		cmd.resetShutdown()
+
+		defer cmd.waitForUpdateCheck()
+
+		// the Reader may have been replaced since the last run
+		cmd.promptReader = nil
+
+		// fail on broken .env files before their variables are looked up
+		if env, ok := cmd.environment().(fallibleEnvironment); ok {
+			if err := env.Err(); err != nil {
+				return err
+			}
+		}
+
+		defer func() {
+			if deferErr != nil {
+				return
+			}
+
+			if err := cmd.warningError(); err != nil {
+				deferErr = cmd.invokedCommand.handleExitCoder(ctx, err)
+			}
+		}()
+
+		if cmd.ReadArgsFromStdin && !cmd.parseOnly {
 			if args, err := cmd.parseArgsFromStdin(); err != nil {
 				return err
 			} else {
@@ -463,7 +784,13 @@ func (cmd *Command) Run(ctx context.Context, osArgs []string) (deferErr error) {
 		cmd.shellCompletion, osArgs = checkShellCompleteFlag(cmd, osArgs)
 
 		tracef("setting cmd.shellCompletion=%[1]v from checkShellCompleteFlag (cmd=%[2]q)", cmd.shellCompletion && cmd.EnableShellCompletion, cmd.Name)
-		cmd.shellCompletion = cmd.EnableShellCompletion && cmd.shellCompletion
+		cmd.shellCompletion = cmd.EnableShellCompletion && cmd.shellCompletion && !cmd.parseOnly
+
+		if len(cmd.HandleSignals) > 0 && !cmd.shellCompletion && !cmd.parseOnly {
+			var stop func()
+			ctx, stop = cmd.handleSignals(ctx)
+			defer stop()
+		}
 	}
 
 	tracef("using post-checkShellCompleteFlag arguments %[1]q (cmd=%[2]q)", osArgs, cmd.Name)
@@ -475,6 +802,8 @@ func (cmd *Command) Run(ctx context.Context, osArgs []string) (deferErr error) {
 		cmd.setupCommandGraph()
 	}
 
+	cmd.emit(ctx, Event{Kind: EventParseStarted, Command: cmd, Args: osArgs})
+
 	args, err := cmd.parseFlags(&stringSliceArgs{v: osArgs})
 
 	tracef("using post-parse arguments %[1]q (cmd=%[2]q)", args, cmd.Name)
@@ -488,18 +817,38 @@ func (cmd *Command) Run(ctx context.Context, osArgs []string) (deferErr error) {
 		deferErr = err
 
 		cmd.isInError = true
-		if cmd.OnUsageError != nil {
+		if cmd.OnUsageError != nil && !cmd.Root().parseOnly {
 			err = cmd.OnUsageError(ctx, cmd, err, cmd.parent != nil)
 			err = cmd.handleExitCoder(ctx, err)
 			return err
 		}
-		fmt.Fprintf(cmd.Root().ErrWriter, "Incorrect Usage: %s\n\n", err.Error())
-		if cmd.Suggest {
-			if suggestion, err := cmd.suggestFlagFromError(err, ""); err == nil {
-				fmt.Fprintf(cmd.Root().ErrWriter, "%s", suggestion)
+		if cmd.jsonErrors() {
+			return cmd.handleUsageError(ctx, err)
+		}
+		// with a UsageErrorExitCode the error is printed once when exiting
+		// with the code, see handleUsageError
+		printErr := cmd.Root().UsageErrorExitCode == 0
+		if format := cmd.errorFormatter(); format != nil {
+			if printErr {
+				fmt.Fprintf(cmd.errWriter(), "%s\n\n", cmd.styleError(format(err)))
+			}
+		} else {
+			// the suggestion is printed on its own line
+			msg := err.Error()
+			if undefinedErr, ok := err.(*ErrFlagUndefined); ok {
+				msg = trf("flag provided but not defined: -%s", undefinedErr.Flag)
+			}
+			if printErr {
+				fmt.Fprintf(cmd.errWriter(), "%s\n\n", cmd.styleError(trf("Incorrect Usage: %s", msg)))
+			}
+
+			if cmd.Suggest {
+				if suggestion, err := cmd.suggestFlagFromError(err, ""); err == nil {
+					fmt.Fprintf(cmd.errWriter(), "%s", suggestion)
+				}
 			}
 		}
-		if !cmd.HideHelp {
+		if !cmd.showFlagUsage(err) && !cmd.HideHelp {
 			if cmd.parent == nil {
 				tracef("running ShowAppHelp")
 				if err := ShowAppHelp(cmd); err != nil {
@@ -513,22 +862,63 @@ func (cmd *Command) Run(ctx context.Context, osArgs []string) (deferErr error) {
 			}
 		}
 
-		return err
+		return cmd.handleUsageError(ctx, err)
+	}
+
+	cmd.emitFlagsResolved(ctx)
+
+	if cmd.parent == nil && !cmd.parseOnly {
+		cmd.checkForUpdates(ctx)
 	}
 
 	if cmd.checkHelp() {
+		if cmd.Root().parseOnly {
+			return nil
+		}
 		return helpCommandAction(ctx, cmd)
 	} else {
 		tracef("no help is wanted (cmd=%[1]q)", cmd.Name)
 	}
 
 	if cmd.parent == nil && !cmd.HideVersion && checkVersion(cmd) {
-		ShowVersion(cmd)
+		if !cmd.parseOnly {
+			ShowVersion(cmd)
+		}
 		return nil
 	}
 
-	if cmd.After != nil && !cmd.Root().shellCompletion {
+	if !cmd.isBuiltinCommand() && !cmd.hasSubcommandArg(args) {
+		if err := cmd.resolvePendingSources(ctx); err != nil {
+			return cmd.handleExitCoder(ctx, err)
+		}
+	}
+
+	if err := cmd.applyDefaultFuncs(); err != nil {
+		return cmd.handleExitCoder(ctx, err)
+	}
+
+	if cmd.Root().CollectValidationErrors {
+		cmd.promptMissingFlags(ctx, cmd.Flags, false)
+
+		if err := cmd.validationErrors(ctx); err != nil {
+			return cmd.handleRequiredFlagsError(ctx, err)
+		}
+	}
+
+	ctx = cmd.contextWithLogger(ctx)
+
+	// only the arguments are parsed without running any functions of the
+	// command, see Parse
+	parseOnly := cmd.Root().parseOnly
+
+	if cmd.After != nil && !cmd.Root().shellCompletion && !parseOnly {
+		cmd.Root().pushAfter(ctx, cmd)
 		defer func() {
+			if !cmd.Root().popAfter() {
+				// the After function has been run by the forced shutdown
+				return
+			}
+
 			if err := cmd.After(ctx, cmd); err != nil {
 				err = cmd.handleExitCoder(ctx, err)
 
@@ -541,20 +931,47 @@ func (cmd *Command) Run(ctx context.Context, osArgs []string) (deferErr error) {
 		}()
 	}
 
+	cmd.promptMissingFlags(ctx, cmd.Flags, false)
+
 	if err := cmd.checkRequiredFlags(); err != nil {
-		cmd.isInError = true
-		_ = ShowSubcommandHelp(cmd)
-		return err
+		return cmd.handleRequiredFlagsError(ctx, err)
 	}
 
 	for _, grp := range cmd.MutuallyExclusiveFlags {
 		if err := grp.check(cmd); err != nil {
+			if !cmd.jsonErrors() {
+				_ = ShowSubcommandHelp(cmd)
+			}
+			return cmd.handleUsageError(ctx, err)
+		}
+	}
+
+	if err := cmd.checkRequiresFlags(); err != nil {
+		if !cmd.jsonErrors() {
 			_ = ShowSubcommandHelp(cmd)
-			return err
 		}
+		return cmd.handleUsageError(ctx, err)
 	}
 
-	if cmd.Before != nil && !cmd.Root().shellCompletion {
+	if !cmd.Root().CollectValidationErrors {
+		if err := cmd.validateFlags(ctx); err != nil {
+			return cmd.handleRequiredFlagsError(ctx, err)
+		}
+	}
+
+	if cmd.Lock != nil && !cmd.Root().shellCompletion && !parseOnly {
+		release, err := cmd.Lock.acquire(ctx, cmd)
+		if err != nil {
+			return cmd.handleExitCoder(ctx, err)
+		}
+		defer release()
+	}
+
+	if !cmd.Root().shellCompletion && !parseOnly {
+		cmd.warnDeprecated()
+	}
+
+	if cmd.Before != nil && !cmd.Root().shellCompletion && !parseOnly {
 		if err := cmd.Before(ctx, cmd); err != nil {
 			deferErr = cmd.handleExitCoder(ctx, err)
 			return deferErr
@@ -563,11 +980,19 @@ func (cmd *Command) Run(ctx context.Context, osArgs []string) (deferErr error) {
 
 	tracef("running flag actions (cmd=%[1]q)", cmd.Name)
 
-	if err := cmd.runFlagActions(ctx); err != nil {
-		return err
+	if !parseOnly {
+		if err := cmd.runFlagActions(ctx); err != nil {
+			return err
+		}
 	}
 
 	var subCmd *Command
+	cmd.commandLoadErr = nil
+
+	if cmd.EnablePassthroughArgs {
+		// the arguments after the terminator are never subcommands
+		args = &stringSliceArgs{v: cmd.positionalArgs}
+	}
 
 	if args.Present() {
 		tracef("checking positional args %[1]q (cmd=%[2]q)", args, cmd.Name)
@@ -576,10 +1001,11 @@ func (cmd *Command) Run(ctx context.Context, osArgs []string) (deferErr error) {
 
 		tracef("using first positional argument as sub-command name=%[1]q (cmd=%[2]q)", name, cmd.Name)
 
-		if cmd.SuggestCommandFunc != nil {
+		subCmd = cmd.Command(name)
+		if subCmd == nil && cmd.SuggestCommandFunc != nil {
 			name = cmd.SuggestCommandFunc(cmd.Commands, name)
+			subCmd = cmd.Command(name)
 		}
-		subCmd = cmd.Command(name)
 		if subCmd == nil {
 			hasDefault := cmd.DefaultCommand != ""
 			isFlagName := checkStringSliceIncludes(name, cmd.FlagNames())
@@ -615,7 +1041,13 @@ func (cmd *Command) Run(ctx context.Context, osArgs []string) (deferErr error) {
 		}
 	}
 
+	if err := cmd.takeCommandLoadErr(); err != nil {
+		return cmd.handleExitCoder(ctx, err)
+	}
+
 	if subCmd != nil {
+		cmd.emit(ctx, Event{Kind: EventCommandMatched, Command: subCmd})
+
 		tracef("running sub-command %[1]q with arguments %[2]q (cmd=%[3]q)", subCmd.Name, cmd.Args(), cmd.Name)
 		return subCmd.Run(ctx, cmd.Args().Slice())
 	}
@@ -623,10 +1055,15 @@ func (cmd *Command) Run(ctx context.Context, osArgs []string) (deferErr error) {
 	if cmd.Action == nil {
 		cmd.Action = helpCommandAction
 	} else {
-		if err := cmd.checkPersistentRequiredFlags(); err != nil {
-			cmd.isInError = true
-			_ = ShowSubcommandHelp(cmd)
-			return err
+		cmd.promptMissingFlags(ctx, cmd.appliedFlags, true)
+
+		requiredErr := cmd.checkPersistentRequiredFlags()
+		if requiredErr != nil && !cmd.Root().CollectValidationErrors {
+			return cmd.handleRequiredFlagsError(ctx, requiredErr)
+		}
+
+		if cmd.EnablePassthroughArgs {
+			cmd.parsedArgs = &stringSliceArgs{v: cmd.positionalArgs}
 		}
 
 		if len(cmd.Arguments) > 0 {
@@ -637,14 +1074,42 @@ func (cmd *Command) Run(ctx context.Context, osArgs []string) (deferErr error) {
 				rargs, err = arg.Parse(rargs)
 				if err != nil {
 					tracef("calling with %[1]v (cmd=%[2]q)", err, cmd.Name)
-					return err
+					if requiredErr != nil {
+						err = newMultiError(requiredErr, err)
+					}
+					return cmd.handleUsageError(ctx, err)
+				}
+			}
+			if len(rargs) > 0 {
+				err := fmt.Errorf(tr("unexpected arguments %[1]q, expected %[2]s"), rargs, cmd.argumentsUsage())
+				if requiredErr != nil {
+					err = newMultiError(requiredErr, err)
 				}
+				return cmd.handleUsageError(ctx, err)
 			}
 			cmd.parsedArgs = &stringSliceArgs{v: rargs}
 		}
+
+		if requiredErr != nil {
+			return cmd.handleRequiredFlagsError(ctx, requiredErr)
+		}
+	}
+
+	if parseOnly {
+		tracef("skipping action of parse only run (cmd=%[1]q)", cmd.Name)
+		return nil
 	}
 
-	if err := cmd.Action(ctx, cmd); err != nil {
+	cmd.emit(ctx, Event{Kind: EventActionStarted, Command: cmd})
+
+	err = cmd.runAction(ctx)
+	if err != nil && cmd.Root().ErrorsWithCommandPath {
+		err = withCommandPath(cmd, err)
+	}
+
+	cmd.emit(ctx, Event{Kind: EventActionFinished, Command: cmd, Err: err})
+
+	if err != nil {
 		tracef("calling handleExitCoder with %[1]v (cmd=%[2]q)", err, cmd.Name)
 		deferErr = cmd.handleExitCoder(ctx, err)
 	}
@@ -674,11 +1139,31 @@ func (cmd *Command) newFlagSet() (*flag.FlagSet, error) {
 
 	cmd.appliedFlags = append(cmd.appliedFlags, allFlags...)
 
+	for _, fl := range allFlags {
+		cmd.prepareFlag(fl)
+	}
+
 	tracef("making new flag set (cmd=%[1]q)", cmd.Name)
 
 	return newFlagSet(cmd.Name, allFlags)
 }
 
+// collectValueErrors makes the flags of the flag set record invalid values
+// instead of stopping the parsing at the first one
+func (cmd *Command) collectValueErrors() {
+	cmd.flagSet.VisitAll(func(f *flag.Flag) {
+		fv, ok := f.Value.(*fnValue)
+		if !ok {
+			return
+		}
+
+		name := f.Name
+		fv.collect = func(value string, err error) {
+			cmd.valueErrors = append(cmd.valueErrors, fmt.Errorf(tr("invalid value %q for flag -%s: %w"), value, name, err))
+		}
+	})
+}
+
 func (cmd *Command) allFlags() []Flag {
 	var flags []Flag
 	flags = append(flags, cmd.Flags...)
@@ -690,10 +1175,25 @@ func (cmd *Command) allFlags() []Flag {
 	return flags
 }
 
+// flagSyntax traverses Lineage() for the FlagPrefixes and
+// FlagValueSeparators of the command and its ancestors
+func (cmd *Command) flagSyntax() (prefixes, separators []string) {
+	for pCmd := cmd; pCmd != nil; pCmd = pCmd.parent {
+		if pCmd.UseSlashFlags {
+			prefixes = append(prefixes, "/")
+			separators = append(separators, ":")
+		}
+		prefixes = append(prefixes, pCmd.FlagPrefixes...)
+		separators = append(separators, pCmd.FlagValueSeparators...)
+	}
+
+	return prefixes, separators
+}
+
 // useShortOptionHandling traverses Lineage() for *any* ancestors
 // with UseShortOptionHandling
 func (cmd *Command) useShortOptionHandling() bool {
-	for _, pCmd := range cmd.Lineage() {
+	for pCmd := cmd; pCmd != nil; pCmd = pCmd.parent {
 		if pCmd.UseShortOptionHandling {
 			return true
 		}
@@ -703,6 +1203,10 @@ func (cmd *Command) useShortOptionHandling() bool {
 }
 
 func (cmd *Command) suggestFlagFromError(err error, commandName string) (string, error) {
+	if undefinedErr, ok := err.(*ErrFlagUndefined); ok && commandName == "" && len(undefinedErr.Suggestions) > 0 {
+		return fmt.Sprintf(SuggestDidYouMeanTemplate, undefinedErr.Suggestions[0]) + "\n\n", nil
+	}
+
 	fl, parseErr := flagFromError(err)
 	if parseErr != nil {
 		return "", err
@@ -728,10 +1232,25 @@ func (cmd *Command) suggestFlagFromError(err error, commandName string) (string,
 	return fmt.Sprintf(SuggestDidYouMeanTemplate, suggestion) + "\n\n", nil
 }
 
+// undefinedFlagError returns the error for the undefined flag, suggesting
+// the most similar flags of the command and its ancestors if enabled
+func (cmd *Command) undefinedFlagError(name string) *ErrFlagUndefined {
+	err := &ErrFlagUndefined{Flag: name}
+	if !cmd.Suggest {
+		return err
+	}
+
+	err.Suggestions = cmd.undefinedFlagSuggestions(name)
+	tracef("suggesting flags %[1]q for %[2]q (cmd=%[3]q)", err.Suggestions, name, cmd.Name)
+
+	return err
+}
+
 func (cmd *Command) parseFlags(args Args) (Args, error) {
 	tracef("parsing flags from arguments %[1]q (cmd=%[2]q)", args, cmd.Name)
 
 	cmd.parsedArgs = nil
+	cmd.flagIndex = nil
 	if v, err := cmd.newFlagSet(); err != nil {
 		return args, err
 	} else {
@@ -768,14 +1287,12 @@ func (cmd *Command) parseFlags(args Args) (Args, error) {
 
 			applyPersistentFlag := true
 
-			cmd.flagSet.VisitAll(func(f *flag.Flag) {
-				for _, name := range flNames {
-					if name == f.Name {
-						applyPersistentFlag = false
-						break
-					}
+			for _, name := range flNames {
+				if cmd.flagSet.Lookup(name) != nil {
+					applyPersistentFlag = false
+					break
 				}
-			})
+			}
 
 			if !applyPersistentFlag {
 				tracef("not applying as persistent flag=%[1]q (cmd=%[2]q)", flNames, cmd.Name)
@@ -785,6 +1302,8 @@ func (cmd *Command) parseFlags(args Args) (Args, error) {
 
 			tracef("applying as persistent flag=%[1]q (cmd=%[2]q)", flNames, cmd.Name)
 
+			cmd.prepareFlag(fl)
+
 			if err := fl.Apply(cmd.flagSet); err != nil {
 				return cmd.Args(), err
 			}
@@ -796,10 +1315,29 @@ func (cmd *Command) parseFlags(args Args) (Args, error) {
 
 	tracef("parsing flags iteratively tail=%[1]q (cmd=%[2]q)", args.Tail(), cmd.Name)
 
-	if err := parseIter(cmd.flagSet, cmd, args.Tail(), cmd.Root().shellCompletion); err != nil {
+	cmd.valueErrors = nil
+	if cmd.Root().CollectValidationErrors {
+		cmd.collectValueErrors()
+	}
+
+	tail := args.Tail()
+	if prefixes, separators := cmd.flagSyntax(); len(prefixes) > 0 || len(separators) > 0 {
+		tail = normalizeFlagSyntax(cmd.flagSet, tail, prefixes, separators)
+	}
+
+	cmd.positionalArgs, cmd.passthroughArgs = nil, nil
+	if err := parseIter(cmd.flagSet, cmd, tail, cmd.Root().shellCompletion); err != nil {
+		if name, fErr := flagFromError(err); fErr == nil {
+			err = cmd.undefinedFlagError(name)
+		}
+		if len(cmd.valueErrors) > 0 {
+			err = newMultiError(append(cmd.valueErrors, err)...)
+		}
 		return cmd.Args(), err
 	}
 
+	cmd.positionalArgs, cmd.passthroughArgs = splitPassthroughArgs(tail, cmd.flagSet.Args())
+
 	tracef("normalizing flags (cmd=%[1]q)", cmd.Name)
 
 	if err := normalizeFlags(cmd.Flags, cmd.flagSet); err != nil {
@@ -868,6 +1406,38 @@ func (cmd *Command) VisibleFlags() []Flag {
 	return visibleFlags(cmd.allFlags())
 }
 
+// VisiblePersistentFlags returns the visible persistent flags of the
+// ancestors of the command which are inherited by it, i.e. not shadowed by
+// a flag of the same name of the command or a closer ancestor
+func (cmd *Command) VisiblePersistentFlags() []Flag {
+	names := map[string]bool{}
+	for _, fl := range cmd.allFlags() {
+		for _, name := range fl.Names() {
+			names[name] = true
+		}
+	}
+
+	var flags []Flag
+	for pCmd := cmd.parent; pCmd != nil; pCmd = pCmd.parent {
+		for _, fl := range pCmd.Flags {
+			if pf, ok := fl.(PersistentFlag); !ok || !pf.IsPersistent() {
+				continue
+			}
+
+			shadowed := false
+			for _, name := range fl.Names() {
+				shadowed = shadowed || names[name]
+				names[name] = true
+			}
+			if !shadowed {
+				flags = append(flags, fl)
+			}
+		}
+	}
+
+	return visibleFlags(flags)
+}
+
 func (cmd *Command) appendFlag(fl Flag) {
 	if !hasFlag(cmd.Flags, fl) {
 		cmd.Flags = append(cmd.Flags, fl)
@@ -882,19 +1452,82 @@ func (cmd *Command) appendCommand(aCmd *Command) {
 }
 
 func (cmd *Command) handleExitCoder(ctx context.Context, err error) error {
-	if cmd.parent != nil {
-		return cmd.parent.handleExitCoder(ctx, err)
+	return cmd.Root().exitWithError(ctx, cmd, cmd.decorateError(ctx, err))
+}
+
+// exitWithError handles the error of a run of the root command which
+// occurred on the given command
+func (cmd *Command) exitWithError(ctx context.Context, origin *Command, err error) error {
+	if cmd.parseOnly {
+		return err
 	}
 
+	err = cmd.applyCancelExitCode(ctx, err)
+	err = cmd.applyExitCodes(err)
+
 	if cmd.ExitErrHandler != nil {
 		cmd.ExitErrHandler(ctx, cmd, err)
 		return err
 	}
 
-	HandleExitCoder(err)
+	if cmd.invokedCommand != nil && cmd.invokedCommand.jsonErrors() {
+		// the error is written and exited with once the run has finished
+		return err
+	}
+
+	handleExitCoder(err, origin.errWriter(), origin.themedErrorFormatter(), cmd.exit)
 	return err
 }
 
+// errWriter returns the ErrWriter of the command or its nearest ancestor
+// which has one set
+func (cmd *Command) errWriter() io.Writer {
+	for _, pCmd := range cmd.Lineage() {
+		if pCmd.ErrWriter != nil && !(pCmd.defaultErrWriter && pCmd.ErrWriter == os.Stderr) {
+			return pCmd.ErrWriter
+		}
+	}
+
+	// the package ErrWriter takes precedence over the os.Stderr default of
+	// the root command
+	return ErrWriter
+}
+
+// errorFormatter returns a function formatting errors with the FormatError
+// function of the command or its nearest ancestor which has one, or nil
+func (cmd *Command) errorFormatter() func(error) string {
+	for _, pCmd := range cmd.Lineage() {
+		if pCmd.FormatError != nil {
+			format := pCmd.FormatError
+			return func(err error) string {
+				return format(cmd, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// decorateError passes the error to the DecorateError function of the root
+// command, if any, keeping the exit code of the original error
+func (cmd *Command) decorateError(ctx context.Context, err error) error {
+	decorate := cmd.Root().DecorateError
+	if decorate == nil || err == nil {
+		return err
+	}
+
+	tracef("decorating error %[1]q (cmd=%[2]q)", err, cmd.Name)
+
+	decorated := decorate(ctx, cmd, err)
+	if exitErr, ok := err.(ExitCoder); ok && decorated != nil {
+		if _, ok := decorated.(ExitCoder); !ok {
+			return Exit(decorated, exitErr.ExitCode())
+		}
+	}
+
+	return decorated
+}
+
 func (cmd *Command) argsWithDefaultCommand(oldArgs Args) Args {
 	if cmd.DefaultCommand != "" {
 		rawArgs := append([]string{cmd.DefaultCommand}, oldArgs.Slice()...)
@@ -916,30 +1549,116 @@ func (cmd *Command) Root() *Command {
 }
 
 func (cmd *Command) lookupFlag(name string) Flag {
-	for _, pCmd := range cmd.Lineage() {
-		for _, f := range pCmd.Flags {
+	for pCmd := cmd; pCmd != nil; pCmd = pCmd.parent {
+		if f := pCmd.flagByName(name); f != nil {
+			tracef("flag found for name %[1]q (cmd=%[2]q)", name, cmd.Name)
+			return f
+		}
+	}
+
+	tracef("flag NOT found for name %[1]q (cmd=%[2]q)", name, cmd.Name)
+	return nil
+}
+
+// flagByName returns the flag of the command with the given name. The
+// flags are indexed by all their names once instead of comparing the names
+// of every flag on each lookup, the index is rebuilt for every parse and
+// when flags have been appended.
+func (cmd *Command) flagByName(name string) Flag {
+	if cmd.flagIndex == nil || cmd.flagIndexLen != len(cmd.Flags) {
+		cmd.flagIndex = make(map[string]Flag, len(cmd.Flags))
+		cmd.flagIndexLen = len(cmd.Flags)
+
+		for _, f := range cmd.Flags {
 			for _, n := range f.Names() {
-				if n == name {
-					tracef("flag found for name %[1]q (cmd=%[2]q)", name, cmd.Name)
-					return f
+				// the first flag with a name wins like in a linear search
+				if _, ok := cmd.flagIndex[n]; !ok {
+					cmd.flagIndex[n] = f
 				}
 			}
 		}
 	}
 
-	tracef("flag NOT found for name %[1]q (cmd=%[2]q)", name, cmd.Name)
+	return cmd.flagIndex[name]
+}
+
+// flagWasSet reports whether the flag with the given name has been set on
+// the flag set of the command. The names of the set flags are collected
+// once instead of visiting the sorted flags of the flag set on each lookup,
+// they are collected again when the flag set has been replaced or further
+// flags have been set.
+func (cmd *Command) flagWasSet(name string) bool {
+	if cmd.flagSet == nil {
+		return false
+	}
+
+	if n := cmd.flagSet.NFlag(); cmd.setFlagNamesOf != cmd.flagSet || cmd.setFlagNamesCount != n {
+		cmd.setFlagNames = make(map[string]struct{}, n)
+		cmd.setFlagNamesOf = cmd.flagSet
+		cmd.setFlagNamesCount = n
+
+		cmd.flagSet.Visit(func(f *flag.Flag) {
+			cmd.setFlagNames[f.Name] = struct{}{}
+		})
+	}
+
+	_, ok := cmd.setFlagNames[name]
+	return ok
+}
+
+// lookupSetFlag returns the first flag matching one of the given names
+// which has been set on the command line of this command or, walking up
+// the lineage, one of its ancestors
+func (cmd *Command) lookupSetFlag(names []string) *flag.Flag {
+	for _, pCmd := range cmd.Lineage() {
+		for _, name := range names {
+			if pCmd.flagWasSet(name) {
+				tracef("set flag found for names %[1]q on %[2]q (cmd=%[3]q)", names, pCmd.Name, cmd.Name)
+				return pCmd.flagSet.Lookup(name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// lookupFlagValue returns the value of the first flag matching one of the
+// given names which has been set on the command line of this command or one
+// of its ancestors or else the value of the flag closest to this command,
+// which includes the value of its sources
+func (cmd *Command) lookupFlagValue(names []string) flag.Value {
+	if f := cmd.lookupSetFlag(names); f != nil {
+		return f.Value
+	}
+
+	for _, name := range names {
+		if fs := cmd.lookupFlagSet(name); fs != nil {
+			return fs.Lookup(name).Value
+		}
+	}
+
 	return nil
 }
 
 func (cmd *Command) lookupFlagSet(name string) *flag.FlagSet {
-	for _, pCmd := range cmd.Lineage() {
+	if pCmd := cmd.lookupFlagSetCommand(name); pCmd != nil {
+		return pCmd.flagSet
+	}
+
+	return nil
+}
+
+// lookupFlagSetCommand returns the command of the lineage whose flag set
+// defines the flag with the given name
+func (cmd *Command) lookupFlagSetCommand(name string) *Command {
+	for pCmd := cmd; pCmd != nil; pCmd = pCmd.parent {
 		if pCmd.flagSet == nil {
 			continue
 		}
 
 		if f := pCmd.flagSet.Lookup(name); f != nil {
 			tracef("matching flag set found for name %[1]q (cmd=%[2]q)", name, cmd.Name)
-			return pCmd.flagSet
+			return pCmd
 		}
 	}
 
@@ -989,7 +1708,7 @@ func (cmd *Command) checkRequiredFlags() requiredFlagsErr {
 	if len(missingFlags) != 0 {
 		tracef("found missing required flags %[1]q (cmd=%[2]q)", missingFlags, cmd.Name)
 
-		return &errRequiredFlags{missingFlags: missingFlags}
+		return &ErrMissingRequiredFlags{Names: missingFlags}
 	}
 
 	tracef("all required flags set (cmd=%[1]q)", cmd.Name)
@@ -997,6 +1716,66 @@ func (cmd *Command) checkRequiredFlags() requiredFlagsErr {
 	return nil
 }
 
+// handleRequiredFlagsError shows the help for an error caused by flags not
+// or wrongly set and handles it as usage error
+func (cmd *Command) handleRequiredFlagsError(ctx context.Context, err error) error {
+	cmd.isInError = true
+	if !cmd.jsonErrors() && !cmd.showFlagUsage(err) {
+		_ = ShowSubcommandHelp(cmd)
+	}
+	return cmd.handleUsageError(ctx, err)
+}
+
+// validateFlags runs the ValidateFlags function of the command, if any
+func (cmd *Command) validateFlags(ctx context.Context) error {
+	if cmd.ValidateFlags == nil || cmd.Root().shellCompletion {
+		return nil
+	}
+
+	tracef("validating flags (cmd=%[1]q)", cmd.Name)
+
+	return cmd.ValidateFlags(ctx, cmd)
+}
+
+// validationErrors runs all checks of the flags of the command and returns
+// a combined error of all violations found
+func (cmd *Command) validationErrors(ctx context.Context) error {
+	errs := cmd.valueErrors
+
+	if err := cmd.checkRequiredFlags(); err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, grp := range cmd.MutuallyExclusiveFlags {
+		if err := grp.check(cmd); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := cmd.checkRequiresFlags(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := cmd.validateFlags(ctx); err != nil {
+		if validateErrs, ok := unwrapMulti(err); ok {
+			errs = append(errs, validateErrs...)
+		} else {
+			errs = append(errs, err)
+		}
+	}
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+
+	tracef("found %[1]d validation errors (cmd=%[2]q)", len(errs), cmd.Name)
+
+	return newMultiError(errs...)
+}
+
 func (cmd *Command) checkPersistentRequiredFlags() requiredFlagsErr {
 	tracef("checking for required flags (cmd=%[1]q)", cmd.Name)
 
@@ -1013,7 +1792,7 @@ func (cmd *Command) checkPersistentRequiredFlags() requiredFlagsErr {
 	if len(missingFlags) != 0 {
 		tracef("found missing required flags %[1]q (cmd=%[2]q)", missingFlags, cmd.Name)
 
-		return &errRequiredFlags{missingFlags: missingFlags}
+		return &ErrMissingRequiredFlags{Names: missingFlags}
 	}
 
 	tracef("all required flags set (cmd=%[1]q)", cmd.Name)
@@ -1047,21 +1826,13 @@ func (cmd *Command) Set(name, value string) error {
 
 // IsSet determines if the flag was actually set
 func (cmd *Command) IsSet(name string) bool {
-	flSet := cmd.lookupFlagSet(name)
+	pCmd := cmd.lookupFlagSetCommand(name)
 
-	if flSet == nil {
+	if pCmd == nil {
 		return false
 	}
 
-	isSet := false
-
-	flSet.Visit(func(f *flag.Flag) {
-		if f.Name == name {
-			isSet = true
-		}
-	})
-
-	if isSet {
+	if pCmd.flagWasSet(name) {
 		tracef("flag with name %[1]q found via flag set lookup (cmd=%[2]q)", name, cmd.Name)
 		return true
 	}
@@ -1072,7 +1843,7 @@ func (cmd *Command) IsSet(name string) bool {
 		return false
 	}
 
-	isSet = fl.IsSet()
+	isSet := fl.IsSet()
 	if isSet {
 		tracef("flag with name %[1]q is set (cmd=%[2]q)", name, cmd.Name)
 	} else {
@@ -1126,10 +1897,14 @@ func (cmd *Command) FlagNames() []string {
 // Lineage returns *this* command and all of its ancestor commands
 // in order from child to parent
 func (cmd *Command) Lineage() []*Command {
-	lineage := []*Command{cmd}
+	depth := 0
+	for pCmd := cmd; pCmd != nil; pCmd = pCmd.parent {
+		depth++
+	}
 
-	if cmd.parent != nil {
-		lineage = append(lineage, cmd.parent.Lineage()...)
+	lineage := make([]*Command, 0, depth)
+	for pCmd := cmd; pCmd != nil; pCmd = pCmd.parent {
+		lineage = append(lineage, pCmd)
 	}
 
 	return lineage
@@ -1149,7 +1924,13 @@ func (cmd *Command) Count(name string) int {
 func (cmd *Command) Value(name string) interface{} {
 	if fs := cmd.lookupFlagSet(name); fs != nil {
 		tracef("value found for name %[1]q (cmd=%[2]q)", name, cmd.Name)
-		return fs.Lookup(name).Value.(flag.Getter).Get()
+
+		v := fs.Lookup(name).Value
+		if g, ok := v.(flag.Getter); ok {
+			return g.Get()
+		}
+
+		return v.String()
 	}
 
 	tracef("value NOT found for name %[1]q (cmd=%[2]q)", name, cmd.Name)
@@ -1165,6 +1946,35 @@ func (cmd *Command) Args() Args {
 	return &stringSliceArgs{v: cmd.flagSet.Args()}
 }
 
+// PassthroughArgs returns the arguments after the terminator "--" in the
+// order and with the quoting they were given in, e.g. the command line of
+// an exec command to run. Flags aren't parsed from them. It returns nil if
+// no terminator was given.
+func (cmd *Command) PassthroughArgs() []string {
+	if cmd.passthroughArgs == nil {
+		return nil
+	}
+	return append([]string{}, cmd.passthroughArgs...)
+}
+
+// splitPassthroughArgs splits the arguments left by parsing the flags of
+// the arguments into the positional arguments and the arguments after the
+// terminator "--", nil if there is none. The flag set drops the terminator
+// if it directly follows the flags.
+func splitPassthroughArgs(parsed, rest []string) ([]string, []string) {
+	if n := len(parsed) - len(rest); n > 0 && parsed[n-1] == "--" {
+		return []string{}, rest
+	}
+
+	for i, arg := range rest {
+		if arg == "--" {
+			return rest[:i], rest[i+1:]
+		}
+	}
+
+	return rest, nil
+}
+
 // NArg returns the number of the command line arguments.
 func (cmd *Command) NArg() int {
 	return cmd.Args().Len()
@@ -1180,34 +1990,58 @@ func hasCommand(commands []*Command, command *Command) bool {
 	return false
 }
 
-func (cmd *Command) runFlagActions(ctx context.Context) error {
+// resolvePendingSources looks up the sources of the flags of the command,
+// including the persistent flags it inherits, which have been left to be
+// looked up when the flags were applied. This is only done for the command
+// which is run, as the value is not needed for its help, the completion or
+// another subcommand.
+func (cmd *Command) resolvePendingSources(ctx context.Context) error {
 	for _, fl := range cmd.appliedFlags {
-		isSet := false
-
-		// check only local flagset for running local flag actions
-		for _, name := range fl.Names() {
-			cmd.flagSet.Visit(func(f *flag.Flag) {
-				if f.Name == name {
-					isSet = true
-				}
-			})
-			if isSet {
-				break
+		if pf, ok := fl.(pendingSourcesFlag); ok {
+			if err := pf.resolvePendingSources(ctx, cmd.flagSet); err != nil {
+				return err
 			}
 		}
+	}
 
-		// If the flag hasnt been set on cmd line then we need to further
-		// check if it has been set via other means. If however it has
-		// been set by other means but it is persistent(and not set via current cmd)
-		// do not run the flag action
-		if !isSet {
-			if !fl.IsSet() {
-				continue
-			}
-			if pf, ok := fl.(PersistentFlag); ok && pf.IsPersistent() {
-				continue
+	return nil
+}
+
+// hasSubcommandArg returns true if the first argument is the name of a
+// subcommand, which is run instead of the command
+func (cmd *Command) hasSubcommandArg(args Args) bool {
+	if cmd.EnablePassthroughArgs {
+		args = &stringSliceArgs{v: cmd.positionalArgs}
+	}
+
+	return args.Present() && cmd.definedCommand(args.First()) != nil
+}
+
+// isBuiltinCommand returns true for the commands added by the package which
+// don't use the flags, e.g. the help command
+func (cmd *Command) isBuiltinCommand() bool {
+	return cmd.isHelpCommand || cmd.isBuiltin
+}
+
+// applyDefaultFuncs computes the default values of the flags which haven't
+// been set
+func (cmd *Command) applyDefaultFuncs() error {
+	for _, fl := range cmd.appliedFlags {
+		if df, ok := fl.(defaultFuncFlag); ok {
+			if err := df.applyDefaultFunc(cmd.flagSet); err != nil {
+				return err
 			}
 		}
+	}
+
+	return nil
+}
+
+func (cmd *Command) runFlagActions(ctx context.Context) error {
+	for _, fl := range cmd.appliedFlags {
+		if !cmd.localFlagWasSet(fl) {
+			continue
+		}
 
 		if af, ok := fl.(ActionableFlag); ok {
 			if err := af.RunAction(ctx, cmd); err != nil {
@@ -1219,6 +2053,30 @@ func (cmd *Command) runFlagActions(ctx context.Context) error {
 	return nil
 }
 
+// localFlagWasSet reports whether the flag has been set on the flag set of
+// the command or by other means unless it is a persistent flag, which is
+// only considered set by the command it has been set on
+func (cmd *Command) localFlagWasSet(fl Flag) bool {
+	// check only local flagset for running local flag actions
+	for _, name := range fl.Names() {
+		if cmd.flagWasSet(name) {
+			return true
+		}
+	}
+
+	// If the flag hasnt been set on cmd line then we need to further
+	// check if it has been set via other means. If however it has
+	// been set by other means but it is persistent(and not set via current cmd)
+	// do not run the flag action
+	if !fl.IsSet() {
+		return false
+	}
+	if pf, ok := fl.(PersistentFlag); ok && pf.IsPersistent() {
+		return false
+	}
+	return true
+}
+
 func checkStringSliceIncludes(want string, sSlice []string) bool {
 	found := false
 	for _, s := range sSlice {
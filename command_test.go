@@ -208,8 +208,10 @@ func TestParseAndRunShortOpts(t *testing.T) {
 		{testArgs: &stringSliceArgs{v: []string{"test", "-cf"}}, expectedArgs: &stringSliceArgs{v: []string{}}},
 		{testArgs: &stringSliceArgs{v: []string{"test", "-acf"}}, expectedArgs: &stringSliceArgs{v: []string{}}},
 		{testArgs: &stringSliceArgs{v: []string{"test", "--acf"}}, expectedErr: "flag provided but not defined: -acf"},
-		{testArgs: &stringSliceArgs{v: []string{"test", "-invalid"}}, expectedErr: "flag provided but not defined: -invalid"},
-		{testArgs: &stringSliceArgs{v: []string{"test", "-acf", "-invalid"}}, expectedErr: "flag provided but not defined: -invalid"},
+		{testArgs: &stringSliceArgs{v: []string{"test", "-xyz"}}, expectedErr: "flag provided but not defined: -xyz"},
+		{testArgs: &stringSliceArgs{v: []string{"test", "-acf", "-xyz"}}, expectedErr: "flag provided but not defined: -xyz"},
+		{testArgs: &stringSliceArgs{v: []string{"test", "-invalid"}}, expectedArgs: &stringSliceArgs{v: []string{}}},
+		{testArgs: &stringSliceArgs{v: []string{"test", "-acivalue", "arg1"}}, expectedArgs: &stringSliceArgs{v: []string{"arg1"}}},
 		{testArgs: &stringSliceArgs{v: []string{"test", "--invalid"}}, expectedErr: "flag provided but not defined: -invalid"},
 		{testArgs: &stringSliceArgs{v: []string{"test", "-acf", "--invalid"}}, expectedErr: "flag provided but not defined: -invalid"},
 		{testArgs: &stringSliceArgs{v: []string{"test", "-acf", "arg1", "-invalid"}}, expectedArgs: &stringSliceArgs{v: []string{"arg1", "-invalid"}}},
@@ -609,6 +611,21 @@ func TestCommand_Run(t *testing.T) {
 	assert.Equal(t, s, "foobar")
 }
 
+func TestCommand_RunWithoutProgramName(t *testing.T) {
+	var ran bool
+
+	cmd := &Command{
+		Name: "app",
+		Action: func(context.Context, *Command) error {
+			ran = true
+			return nil
+		},
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), nil))
+	assert.True(t, ran)
+}
+
 var commandTests = []struct {
 	name     string
 	expected bool
@@ -820,14 +837,15 @@ func TestCommand_RunDefaultCommandWithFlags(t *testing.T) {
 }
 
 func TestCommand_FlagsFromExtPackage(t *testing.T) {
+	// Register the ext package flag on a fresh global flag set, restoring the
+	// parsed flags of the test binary afterwards
+	orig := flag.CommandLine
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	defer func() { flag.CommandLine = orig }()
+
 	var someint int
 	flag.IntVar(&someint, "epflag", 2, "ext package flag usage")
 
-	// Based on source code we can reset the global flag parsing this way
-	defer func() {
-		flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
-	}()
-
 	cmd := &Command{
 		AllowExtFlags: true,
 		Flags: []Flag{
@@ -1190,6 +1208,42 @@ func TestCommand_UseShortOptionAfterSliceFlag(t *testing.T) {
 	assert.Equal(t, expected, name)
 }
 
+func TestCommand_UseShortOptionHandlingValues(t *testing.T) {
+	tests := []struct {
+		args []string
+		one  bool
+		two  bool
+		name string
+	}{
+		{args: []string{"", "-otnvalue"}, one: true, two: true, name: "value"},
+		{args: []string{"", "-ot", "-n=value"}, one: true, two: true, name: "value"},
+		{args: []string{"", "-on=value"}, one: true, name: "value"},
+		{args: []string{"", "-on", "value"}, one: true, name: "value"},
+		{args: []string{"", "-nvalue"}, name: "value"},
+		{args: []string{"", "-not"}, name: "ot"},
+		{args: []string{"", "-o=false", "-t", "--name", "value"}, two: true, name: "value"},
+		{args: []string{"", "-to=false", "--name=value"}, two: true, name: "value"},
+		{args: []string{"", "-n-o"}, name: "-o"},
+	}
+
+	for _, test := range tests {
+		t.Run(strings.Join(test.args[1:], " "), func(t *testing.T) {
+			cmd := buildMinimalTestCommand()
+			cmd.UseShortOptionHandling = true
+			cmd.Flags = []Flag{
+				&BoolFlag{Name: "one", Aliases: []string{"o"}},
+				&BoolFlag{Name: "two", Aliases: []string{"t"}},
+				&StringFlag{Name: "name", Aliases: []string{"n"}},
+			}
+
+			require.NoError(t, cmd.Run(buildTestContext(t), test.args))
+			assert.Equal(t, test.one, cmd.Bool("one"))
+			assert.Equal(t, test.two, cmd.Bool("two"))
+			assert.Equal(t, test.name, cmd.String("name"))
+		})
+	}
+}
+
 func TestCommand_Float64Flag(t *testing.T) {
 	var meters float64
 
@@ -2105,6 +2159,72 @@ func TestCommand_Run_Categories(t *testing.T) {
 	assert.Contains(t, output, "1:\n     command1", "want buffer to include category %q, did not: \n%q", "1:\n     command1", output)
 }
 
+func buildCategoryTestCommand() *Command {
+	return &Command{
+		Name:     "kube",
+		HideHelp: true,
+		Categories: []Category{
+			{Name: "Cluster Management", Description: "create and remove clusters"},
+			{Name: "Basic Commands"},
+		},
+		Commands: []*Command{
+			{Name: "apply", Usage: "apply a configuration", Category: "Advanced Commands"},
+			{Name: "get", Usage: "display resources", Category: "Basic Commands"},
+			{Name: "version", Usage: "print the version"},
+			{Name: "delete", Usage: "delete a cluster", Category: "Cluster Management"},
+			{Name: "create", Usage: "create a cluster", Category: "Cluster Management"},
+			{
+				Name:     "config",
+				Usage:    "modify kubeconfig files",
+				Category: "Advanced Commands",
+				Commands: []*Command{
+					{Name: "view", Usage: "display the config", Category: "Basic Commands"},
+					{Name: "unset", Usage: "unset a property"},
+				},
+			},
+		},
+	}
+}
+
+func TestCommand_Run_CategoryOrder(t *testing.T) {
+	buf := new(bytes.Buffer)
+	cmd := buildCategoryTestCommand()
+	cmd.Writer = buf
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"kube"}))
+
+	var names []string
+	for _, category := range cmd.VisibleCategories() {
+		names = append(names, category.Name())
+	}
+	assert.Equal(t, []string{"", "Cluster Management", "Basic Commands", "Advanced Commands"}, names)
+	assert.Equal(t, "create and remove clusters", cmd.VisibleCategories()[1].Description())
+	assert.Empty(t, cmd.VisibleCategories()[3].Description())
+
+	assert.Contains(t, buf.String(), `COMMANDS:
+   version  print the version
+
+   Cluster Management:
+     create and remove clusters
+
+     delete  delete a cluster
+     create  create a cluster
+
+   Basic Commands:
+     get  display resources
+
+   Advanced Commands:
+     apply   apply a configuration
+     config  modify kubeconfig files
+`)
+
+	var subNames []string
+	for _, category := range cmd.Commands[5].VisibleCategories() {
+		subNames = append(subNames, category.Name())
+	}
+	assert.Equal(t, []string{"", "Basic Commands"}, subNames, "categories are inherited")
+}
+
 func TestCommand_VisibleCategories(t *testing.T) {
 	cmd := &Command{
 		Name:     "visible-categories",
@@ -3025,65 +3145,6 @@ func TestFlagDuplicates(t *testing.T) {
 	}
 }
 
-func TestShorthandCommand(t *testing.T) {
-	af := func(p *int) ActionFunc {
-		return func(context.Context, *Command) error {
-			*p = *p + 1
-			return nil
-		}
-	}
-
-	var cmd1, cmd2 int
-
-	cmd := &Command{
-		PrefixMatchCommands: true,
-		Commands: []*Command{
-			{
-				Name:    "cthdisd",
-				Aliases: []string{"cth"},
-				Action:  af(&cmd1),
-			},
-			{
-				Name:    "cthertoop",
-				Aliases: []string{"cer"},
-				Action:  af(&cmd2),
-			},
-		},
-	}
-
-	err := cmd.Run(buildTestContext(t), []string{"foo", "cth"})
-	assert.NoError(t, err)
-	assert.True(t, cmd1 == 1 && cmd2 == 0, "Expected command1 to be trigerred once")
-
-	cmd1 = 0
-	cmd2 = 0
-
-	err = cmd.Run(buildTestContext(t), []string{"foo", "cthd"})
-	assert.NoError(t, err)
-	assert.True(t, cmd1 == 1 && cmd2 == 0, "Expected command1 to be trigerred once")
-
-	cmd1 = 0
-	cmd2 = 0
-
-	err = cmd.Run(buildTestContext(t), []string{"foo", "cthe"})
-	assert.NoError(t, err)
-	assert.True(t, cmd1 == 1 && cmd2 == 0, "Expected command1 to be trigerred once")
-
-	cmd1 = 0
-	cmd2 = 0
-
-	err = cmd.Run(buildTestContext(t), []string{"foo", "cthert"})
-	assert.NoError(t, err)
-	assert.True(t, cmd1 == 0 && cmd2 == 1, "Expected command1 to be trigerred once")
-
-	cmd1 = 0
-	cmd2 = 0
-
-	err = cmd.Run(buildTestContext(t), []string{"foo", "cthet"})
-	assert.NoError(t, err)
-	assert.True(t, cmd1 == 0 && cmd2 == 1, "Expected command1 to be trigerred once")
-}
-
 func TestCommand_Int(t *testing.T) {
 	set := flag.NewFlagSet("test", 0)
 	set.Int64("myflag", 12, "doc")
@@ -3918,6 +3979,7 @@ func TestJSONExportCommand(t *testing.T) {
 					"defaultText": "",
 					"usage": "",
 					"required": false,
+					"sensitive": false,
 					"hidden": false,
 					"persistent": false,
 					"defaultValue": "",
@@ -3926,10 +3988,16 @@ func TestJSONExportCommand(t *testing.T) {
 					  "s"
 					],
 					"takesFileArg": false,
+					"deprecated": "",
+					"replacedBy": "",
+					"examples": null,
+					"since": "",
+					"seeAlso": null,
 					"config": {
 					  "TrimSpace": false
 					},
-					"onlyOnce": false
+					"onlyOnce": false,
+					"requires": null
 				  },
 				  {
 					"name": "sub-command-flag",
@@ -3937,6 +4005,7 @@ func TestJSONExportCommand(t *testing.T) {
 					"defaultText": "",
 					"usage": "some usage text",
 					"required": false,
+					"sensitive": false,
 					"hidden": false,
 					"persistent": false,
 					"defaultValue": false,
@@ -3944,27 +4013,62 @@ func TestJSONExportCommand(t *testing.T) {
 					  "s"
 					],
 					"takesFileArg": false,
+					"deprecated": "",
+					"replacedBy": "",
+					"examples": null,
+					"since": "",
+					"seeAlso": null,
 					"config": {
-					  "Count": null
+					  "Count": null,
+					  "Negatable": false
 					},
-					"onlyOnce": false
+					"onlyOnce": false,
+					"requires": null
 				  }
 				],
 				"hideHelp": false,
+				"categories": null,
+				"deprecated": "",
+				"examples": null,
+				"since": "",
+				"seeAlso": null,
 				"hideHelpCommand": false,
 				"hideVersion": false,
+				"externalCommandDirs": null,
+				"enablePager": false,
+				"enableExternalCommands": false,
+				"errorsWithCommandPath": false,
+				"warningPolicy": 0,
+				"enableStrict": false,
+				"envPrefix": "",
+				"shutdownTimeout": 0,
+				"collectValidationErrors": false,
+				"flagUsageOnError": false,
+				"usageErrorExitCode": 0,
+				"enableErrorFormat": false,
+				"defaultExitCode": 0,
+				"promptMissing": false,
+				"shellPrompt": "",
+				"enableLogging": false,
+				"enableDryRun": false,
 				"hidden": false,
 				"authors": null,
 				"copyright": "",
 				"metadata": null,
 				"sliceFlagSeparator": "",
 				"disableSliceFlagSeparator": false,
+				"mapFlagKeyValueSeparator": "",
 				"useShortOptionHandling": false,
+				"useSlashFlags": false,
+				"flagPrefixes": null,
+				"flagValueSeparators": null,
 				"suggest": false,
 				"allowExtFlags": false,
 				"skipFlagParsing": false,
+				"enablePassthroughArgs": false,
 				"prefixMatchCommands": false,
 				"mutuallyExclusiveFlags": null,
+				"requiredTogetherFlags": null,
 				"arguments": null,
 				"readArgsFromStdin": false
 			  }
@@ -3976,6 +4080,7 @@ func TestJSONExportCommand(t *testing.T) {
 				"defaultText": "",
 				"usage": "",
 				"required": false,
+				"sensitive": false,
 				"hidden": false,
 				"persistent": false,
 				"defaultValue": "",
@@ -3984,10 +4089,16 @@ func TestJSONExportCommand(t *testing.T) {
 				  "f"
 				],
 				"takesFileArg": true,
+				"deprecated": "",
+				"replacedBy": "",
+				"examples": null,
+				"since": "",
+				"seeAlso": null,
 				"config": {
 				  "TrimSpace": false
 				},
-				"onlyOnce": false
+				"onlyOnce": false,
+				"requires": null
 			  },
 			  {
 				"name": "another-flag",
@@ -3995,6 +4106,7 @@ func TestJSONExportCommand(t *testing.T) {
 				"defaultText": "",
 				"usage": "another usage text",
 				"required": false,
+				"sensitive": false,
 				"hidden": false,
 				"persistent": false,
 				"defaultValue": false,
@@ -4002,27 +4114,62 @@ func TestJSONExportCommand(t *testing.T) {
 				  "b"
 				],
 				"takesFileArg": false,
+				"deprecated": "",
+				"replacedBy": "",
+				"examples": null,
+				"since": "",
+				"seeAlso": null,
 				"config": {
-				  "Count": null
+				  "Count": null,
+				  "Negatable": false
 				},
-				"onlyOnce": false
+				"onlyOnce": false,
+				"requires": null
 			  }
 			],
 			"hideHelp": false,
+			"categories": null,
+			"deprecated": "",
+			"examples": null,
+			"since": "",
+			"seeAlso": null,
 			"hideHelpCommand": false,
 			"hideVersion": false,
+			"externalCommandDirs": null,
+			"enablePager": false,
+			"enableExternalCommands": false,
+			"errorsWithCommandPath": false,
+			"warningPolicy": 0,
+			"enableStrict": false,
+			"envPrefix": "",
+			"shutdownTimeout": 0,
+			"collectValidationErrors": false,
+			"flagUsageOnError": false,
+			"usageErrorExitCode": 0,
+			"enableErrorFormat": false,
+			"defaultExitCode": 0,
+			"promptMissing": false,
+			"shellPrompt": "",
+			"enableLogging": false,
+			"enableDryRun": false,
 			"hidden": false,
 			"authors": null,
 			"copyright": "",
 			"metadata": null,
 			"sliceFlagSeparator": "",
 			"disableSliceFlagSeparator": false,
+			"mapFlagKeyValueSeparator": "",
 			"useShortOptionHandling": false,
+			"useSlashFlags": false,
+			"flagPrefixes": null,
+			"flagValueSeparators": null,
 			"suggest": false,
 			"allowExtFlags": false,
 			"skipFlagParsing": false,
+			"enablePassthroughArgs": false,
 			"prefixMatchCommands": false,
 			"mutuallyExclusiveFlags": null,
+			"requiredTogetherFlags": null,
 			"arguments": null,
 			"readArgsFromStdin": false
 		  },
@@ -4042,20 +4189,48 @@ func TestJSONExportCommand(t *testing.T) {
 			"commands": null,
 			"flags": null,
 			"hideHelp": false,
+			"categories": null,
+			"deprecated": "",
+			"examples": null,
+			"since": "",
+			"seeAlso": null,
 			"hideHelpCommand": false,
 			"hideVersion": false,
+			"externalCommandDirs": null,
+			"enablePager": false,
+			"enableExternalCommands": false,
+			"errorsWithCommandPath": false,
+			"warningPolicy": 0,
+			"enableStrict": false,
+			"envPrefix": "",
+			"shutdownTimeout": 0,
+			"collectValidationErrors": false,
+			"flagUsageOnError": false,
+			"usageErrorExitCode": 0,
+			"enableErrorFormat": false,
+			"defaultExitCode": 0,
+			"promptMissing": false,
+			"shellPrompt": "",
+			"enableLogging": false,
+			"enableDryRun": false,
 			"hidden": false,
 			"authors": null,
 			"copyright": "",
 			"metadata": null,
 			"sliceFlagSeparator": "",
 			"disableSliceFlagSeparator": false,
+			"mapFlagKeyValueSeparator": "",
 			"useShortOptionHandling": false,
+			"useSlashFlags": false,
+			"flagPrefixes": null,
+			"flagValueSeparators": null,
 			"suggest": false,
 			"allowExtFlags": false,
 			"skipFlagParsing": false,
+			"enablePassthroughArgs": false,
 			"prefixMatchCommands": false,
 			"mutuallyExclusiveFlags": null,
+			"requiredTogetherFlags": null,
 			"arguments": null,
 			"readArgsFromStdin": false
 		  },
@@ -4072,20 +4247,48 @@ func TestJSONExportCommand(t *testing.T) {
 			"commands": null,
 			"flags": null,
 			"hideHelp": false,
+			"categories": null,
+			"deprecated": "",
+			"examples": null,
+			"since": "",
+			"seeAlso": null,
 			"hideHelpCommand": false,
 			"hideVersion": false,
+			"externalCommandDirs": null,
+			"enablePager": false,
+			"enableExternalCommands": false,
+			"errorsWithCommandPath": false,
+			"warningPolicy": 0,
+			"enableStrict": false,
+			"envPrefix": "",
+			"shutdownTimeout": 0,
+			"collectValidationErrors": false,
+			"flagUsageOnError": false,
+			"usageErrorExitCode": 0,
+			"enableErrorFormat": false,
+			"defaultExitCode": 0,
+			"promptMissing": false,
+			"shellPrompt": "",
+			"enableLogging": false,
+			"enableDryRun": false,
 			"hidden": false,
 			"authors": null,
 			"copyright": "",
 			"metadata": null,
 			"sliceFlagSeparator": "",
 			"disableSliceFlagSeparator": false,
+			"mapFlagKeyValueSeparator": "",
 			"useShortOptionHandling": false,
+			"useSlashFlags": false,
+			"flagPrefixes": null,
+			"flagValueSeparators": null,
 			"suggest": false,
 			"allowExtFlags": false,
 			"skipFlagParsing": false,
+			"enablePassthroughArgs": false,
 			"prefixMatchCommands": false,
 			"mutuallyExclusiveFlags": null,
+			"requiredTogetherFlags": null,
 			"arguments": null,
 			"readArgsFromStdin": false
 		  },
@@ -4102,20 +4305,48 @@ func TestJSONExportCommand(t *testing.T) {
 			"commands": null,
 			"flags": null,
 			"hideHelp": false,
+			"categories": null,
+			"deprecated": "",
+			"examples": null,
+			"since": "",
+			"seeAlso": null,
 			"hideHelpCommand": false,
 			"hideVersion": false,
+			"externalCommandDirs": null,
+			"enablePager": false,
+			"enableExternalCommands": false,
+			"errorsWithCommandPath": false,
+			"warningPolicy": 0,
+			"enableStrict": false,
+			"envPrefix": "",
+			"shutdownTimeout": 0,
+			"collectValidationErrors": false,
+			"flagUsageOnError": false,
+			"usageErrorExitCode": 0,
+			"enableErrorFormat": false,
+			"defaultExitCode": 0,
+			"promptMissing": false,
+			"shellPrompt": "",
+			"enableLogging": false,
+			"enableDryRun": false,
 			"hidden": true,
 			"authors": null,
 			"copyright": "",
 			"metadata": null,
 			"sliceFlagSeparator": "",
 			"disableSliceFlagSeparator": false,
+			"mapFlagKeyValueSeparator": "",
 			"useShortOptionHandling": false,
+			"useSlashFlags": false,
+			"flagPrefixes": null,
+			"flagValueSeparators": null,
 			"suggest": false,
 			"allowExtFlags": false,
 			"skipFlagParsing": false,
+			"enablePassthroughArgs": false,
 			"prefixMatchCommands": false,
 			"mutuallyExclusiveFlags": null,
+			"requiredTogetherFlags": null,
 			"arguments": null,
 			"readArgsFromStdin": false
 		  },
@@ -4152,6 +4383,7 @@ func TestJSONExportCommand(t *testing.T) {
 					"defaultText": "",
 					"usage": "some usage text",
 					"required": false,
+					"sensitive": false,
 					"hidden": false,
 					"persistent": false,
 					"defaultValue": false,
@@ -4159,27 +4391,62 @@ func TestJSONExportCommand(t *testing.T) {
 					  "s"
 					],
 					"takesFileArg": false,
+					"deprecated": "",
+					"replacedBy": "",
+					"examples": null,
+					"since": "",
+					"seeAlso": null,
 					"config": {
-					  "Count": null
+					  "Count": null,
+					  "Negatable": false
 					},
-					"onlyOnce": false
+					"onlyOnce": false,
+					"requires": null
 				  }
 				],
 				"hideHelp": false,
+				"categories": null,
+				"deprecated": "",
+				"examples": null,
+				"since": "",
+				"seeAlso": null,
 				"hideHelpCommand": false,
 				"hideVersion": false,
+				"externalCommandDirs": null,
+				"enablePager": false,
+				"enableExternalCommands": false,
+				"errorsWithCommandPath": false,
+				"warningPolicy": 0,
+				"enableStrict": false,
+				"envPrefix": "",
+				"shutdownTimeout": 0,
+				"collectValidationErrors": false,
+				"flagUsageOnError": false,
+				"usageErrorExitCode": 0,
+				"enableErrorFormat": false,
+				"defaultExitCode": 0,
+				"promptMissing": false,
+				"shellPrompt": "",
+				"enableLogging": false,
+				"enableDryRun": false,
 				"hidden": false,
 				"authors": null,
 				"copyright": "",
 				"metadata": null,
 				"sliceFlagSeparator": "",
 				"disableSliceFlagSeparator": false,
+				"mapFlagKeyValueSeparator": "",
 				"useShortOptionHandling": false,
+				"useSlashFlags": false,
+				"flagPrefixes": null,
+				"flagValueSeparators": null,
 				"suggest": false,
 				"allowExtFlags": false,
 				"skipFlagParsing": false,
+				"enablePassthroughArgs": false,
 				"prefixMatchCommands": false,
 				"mutuallyExclusiveFlags": null,
+				"requiredTogetherFlags": null,
 				"arguments": null,
 				"readArgsFromStdin": false
 			  }
@@ -4191,6 +4458,7 @@ func TestJSONExportCommand(t *testing.T) {
 				"defaultText": "",
 				"usage": "",
 				"required": false,
+				"sensitive": false,
 				"hidden": false,
 				"persistent": false,
 				"defaultValue": "",
@@ -4199,10 +4467,16 @@ func TestJSONExportCommand(t *testing.T) {
 				  "f"
 				],
 				"takesFileArg": true,
+				"deprecated": "",
+				"replacedBy": "",
+				"examples": null,
+				"since": "",
+				"seeAlso": null,
 				"config": {
 				  "TrimSpace": false
 				},
-				"onlyOnce": false
+				"onlyOnce": false,
+				"requires": null
 			  },
 			  {
 				"name": "another-flag",
@@ -4210,6 +4484,7 @@ func TestJSONExportCommand(t *testing.T) {
 				"defaultText": "",
 				"usage": "another usage text",
 				"required": false,
+				"sensitive": false,
 				"hidden": false,
 				"persistent": false,
 				"defaultValue": false,
@@ -4217,27 +4492,62 @@ func TestJSONExportCommand(t *testing.T) {
 				  "b"
 				],
 				"takesFileArg": false,
+				"deprecated": "",
+				"replacedBy": "",
+				"examples": null,
+				"since": "",
+				"seeAlso": null,
 				"config": {
-				  "Count": null
+				  "Count": null,
+				  "Negatable": false
 				},
-				"onlyOnce": false
+				"onlyOnce": false,
+				"requires": null
 			  }
 			],
 			"hideHelp": false,
+			"categories": null,
+			"deprecated": "",
+			"examples": null,
+			"since": "",
+			"seeAlso": null,
 			"hideHelpCommand": false,
 			"hideVersion": false,
+			"externalCommandDirs": null,
+			"enablePager": false,
+			"enableExternalCommands": false,
+			"errorsWithCommandPath": false,
+			"warningPolicy": 0,
+			"enableStrict": false,
+			"envPrefix": "",
+			"shutdownTimeout": 0,
+			"collectValidationErrors": false,
+			"flagUsageOnError": false,
+			"usageErrorExitCode": 0,
+			"enableErrorFormat": false,
+			"defaultExitCode": 0,
+			"promptMissing": false,
+			"shellPrompt": "",
+			"enableLogging": false,
+			"enableDryRun": false,
 			"hidden": false,
 			"authors": null,
 			"copyright": "",
 			"metadata": null,
 			"sliceFlagSeparator": "",
 			"disableSliceFlagSeparator": false,
+			"mapFlagKeyValueSeparator": "",
 			"useShortOptionHandling": false,
+			"useSlashFlags": false,
+			"flagPrefixes": null,
+			"flagValueSeparators": null,
 			"suggest": false,
 			"allowExtFlags": false,
 			"skipFlagParsing": false,
+			"enablePassthroughArgs": false,
 			"prefixMatchCommands": false,
 			"mutuallyExclusiveFlags": null,
+			"requiredTogetherFlags": null,
 			"arguments": null,
 			"readArgsFromStdin": false
 		  }
@@ -4249,6 +4559,7 @@ func TestJSONExportCommand(t *testing.T) {
 			"defaultText": "",
 			"usage": "some 'usage' text",
 			"required": false,
+			"sensitive": false,
 			"hidden": false,
 			"persistent": false,
 			"defaultValue": "value",
@@ -4256,10 +4567,16 @@ func TestJSONExportCommand(t *testing.T) {
 			  "s"
 			],
 			"takesFileArg": true,
+			"deprecated": "",
+			"replacedBy": "",
+			"examples": null,
+			"since": "",
+			"seeAlso": null,
 			"config": {
 			  "TrimSpace": false
 			},
-			"onlyOnce": false
+			"onlyOnce": false,
+			"requires": null
 		  },
 		  {
 			"name": "flag",
@@ -4267,6 +4584,7 @@ func TestJSONExportCommand(t *testing.T) {
 			"defaultText": "",
 			"usage": "",
 			"required": false,
+			"sensitive": false,
 			"hidden": false,
 			"persistent": false,
 			"defaultValue": "",
@@ -4275,10 +4593,16 @@ func TestJSONExportCommand(t *testing.T) {
 			  "f"
 			],
 			"takesFileArg": false,
+			"deprecated": "",
+			"replacedBy": "",
+			"examples": null,
+			"since": "",
+			"seeAlso": null,
 			"config": {
 			  "TrimSpace": false
 			},
-			"onlyOnce": false
+			"onlyOnce": false,
+			"requires": null
 		  },
 		  {
 			"name": "another-flag",
@@ -4286,6 +4610,7 @@ func TestJSONExportCommand(t *testing.T) {
 			"defaultText": "",
 			"usage": "another usage text",
 			"required": false,
+			"sensitive": false,
 			"hidden": false,
 			"persistent": false,
 			"defaultValue": false,
@@ -4293,10 +4618,17 @@ func TestJSONExportCommand(t *testing.T) {
 			  "b"
 			],
 			"takesFileArg": false,
+			"deprecated": "",
+			"replacedBy": "",
+			"examples": null,
+			"since": "",
+			"seeAlso": null,
 			"config": {
-			  "Count": null
+			  "Count": null,
+			  "Negatable": false
 			},
-			"onlyOnce": false
+			"onlyOnce": false,
+			"requires": null
 		  },
 		  {
 			"name": "hidden-flag",
@@ -4304,20 +4636,50 @@ func TestJSONExportCommand(t *testing.T) {
 			"defaultText": "",
 			"usage": "",
 			"required": false,
+			"sensitive": false,
 			"hidden": true,
 			"persistent": false,
 			"defaultValue": false,
 			"aliases": null,
 			"takesFileArg": false,
+			"deprecated": "",
+			"replacedBy": "",
+			"examples": null,
+			"since": "",
+			"seeAlso": null,
 			"config": {
-			  "Count": null
+			  "Count": null,
+			  "Negatable": false
 			},
-			"onlyOnce": false
+			"onlyOnce": false,
+			"requires": null
 		  }
 		],
 		"hideHelp": false,
+		"categories": null,
+		"deprecated": "",
+		"examples": null,
+		"since": "",
+		"seeAlso": null,
 		"hideHelpCommand": false,
 		"hideVersion": false,
+		"externalCommandDirs": null,
+		"enablePager": false,
+		"enableExternalCommands": false,
+		"errorsWithCommandPath": false,
+		"warningPolicy": 0,
+		"enableStrict": false,
+		"envPrefix": "",
+		"shutdownTimeout": 0,
+		"collectValidationErrors": false,
+		"flagUsageOnError": false,
+		"usageErrorExitCode": 0,
+		"enableErrorFormat": false,
+		"defaultExitCode": 0,
+		"promptMissing": false,
+		"shellPrompt": "",
+		"enableLogging": false,
+		"enableDryRun": false,
 		"hidden": false,
 		"authors": [
 		  "Harrison <harrison@lolwut.example.com>",
@@ -4330,17 +4692,24 @@ func TestJSONExportCommand(t *testing.T) {
 		"metadata": null,
 		"sliceFlagSeparator": "",
 		"disableSliceFlagSeparator": false,
+		"mapFlagKeyValueSeparator": "",
 		"useShortOptionHandling": false,
+		"useSlashFlags": false,
+		"flagPrefixes": null,
+		"flagValueSeparators": null,
 		"suggest": false,
 		"allowExtFlags": false,
 		"skipFlagParsing": false,
+		"enablePassthroughArgs": false,
 		"prefixMatchCommands": false,
 		"mutuallyExclusiveFlags": null,
+		"requiredTogetherFlags": null,
 		"arguments": [
 		  {
 			"name": "fooi",
 			"value": 0,
 			"usageText": "",
+			"description": "",
 			"minTimes": 0,
 			"maxTimes": 0,
 			"config": {
@@ -4353,3 +4722,35 @@ func TestJSONExportCommand(t *testing.T) {
 `
 	assert.JSONEq(t, expected, string(out))
 }
+
+func TestCommand_FlagLookupIndex(t *testing.T) {
+	cmd := &Command{
+		Name: "app",
+		Flags: []Flag{
+			&StringFlag{Name: "name", Aliases: []string{"n"}},
+			&StringFlag{Name: "other", Aliases: []string{"o"}},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	r := require.New(t)
+	r.Same(cmd.Flags[0], cmd.lookupFlag("n"))
+	r.Same(cmd.Flags[1], cmd.lookupFlag("other"))
+	r.Nil(cmd.lookupFlag("missing"))
+
+	added := &IntFlag{Name: "count"}
+	cmd.Flags = append(cmd.Flags, added)
+	r.Same(added, cmd.lookupFlag("count"))
+
+	r.NoError(cmd.Run(buildTestContext(t), []string{"app", "--name", "x"}))
+	r.True(cmd.IsSet("name"))
+	r.False(cmd.IsSet("count"))
+
+	// flags set after parsing are picked up
+	r.NoError(cmd.Set("count", "3"))
+	r.True(cmd.IsSet("count"))
+
+	// a new run doesn't see the flags set in the last one
+	r.NoError(cmd.Run(buildTestContext(t), []string{"app"}))
+	r.False(cmd.IsSet("name"))
+}
@@ -0,0 +1,165 @@
+package cli
+
+// commandTrie is a prefix tree of the names and aliases of subcommands,
+// resolving abbreviated command names in time proportional to the length
+// of the abbreviation instead of the number of commands.
+type commandTrie struct {
+	children map[byte]*commandTrie
+	// index of the command with the name ending at this node, or -1
+	exact int
+	// index of the only command with a name below this node, or -1 if
+	// several commands share the prefix
+	only int
+}
+
+func newCommandTrie(commands []*Command) *commandTrie {
+	root := &commandTrie{exact: -1, only: -1}
+	for i, command := range commands {
+		for _, name := range command.Names() {
+			root.insert(name, i)
+		}
+	}
+	return root
+}
+
+func (t *commandTrie) insert(name string, index int) {
+	node := t
+	for i := 0; i < len(name); i++ {
+		child, ok := node.children[name[i]]
+		if !ok {
+			if node.children == nil {
+				node.children = map[byte]*commandTrie{}
+			}
+			child = &commandTrie{exact: -1, only: index}
+			node.children[name[i]] = child
+		} else if child.only != index {
+			child.only = -1
+		}
+		node = child
+	}
+
+	// the first command with a name wins like in a linear search
+	if node.exact == -1 {
+		node.exact = index
+	}
+}
+
+// lookup returns the index of the command named like the prefix or else of
+// the only command with a name starting with it, and -1 if there is none
+// or the prefix is ambiguous
+func (t *commandTrie) lookup(prefix string) int {
+	if prefix == "" {
+		return -1
+	}
+
+	node := t
+	for i := 0; i < len(prefix); i++ {
+		child, ok := node.children[prefix[i]]
+		if !ok {
+			return -1
+		}
+		node = child
+	}
+
+	if node.exact != -1 {
+		return node.exact
+	}
+	return node.only
+}
+
+// indexedCommand is a subcommand as it was indexed, see indexCommands
+type indexedCommand struct {
+	cmd   *Command
+	names []string
+}
+
+// indexCommands indexes the subcommands by all their names once instead of
+// comparing the names of every subcommand on each lookup. Appended
+// subcommands are added to the indexes, which are rebuilt from scratch
+// when subcommands were removed, replaced or renamed or a lazy command was
+// built.
+func (cmd *Command) indexCommands() {
+	if cmd.commandIndex == nil || !cmd.indexedCommandsUnchanged() {
+		cmd.commandIndex = make(map[string]int, len(cmd.Commands))
+		cmd.indexedCommands = make([]indexedCommand, 0, len(cmd.Commands))
+		cmd.commandTrie = nil
+	}
+
+	for i := len(cmd.indexedCommands); i < len(cmd.Commands); i++ {
+		names := cmd.Commands[i].Names()
+		for _, name := range names {
+			// the first command with a name wins like in a linear search
+			if _, ok := cmd.commandIndex[name]; !ok {
+				cmd.commandIndex[name] = i
+			}
+			if cmd.commandTrie != nil {
+				cmd.commandTrie.insert(name, i)
+			}
+		}
+		cmd.indexedCommands = append(cmd.indexedCommands, indexedCommand{cmd: cmd.Commands[i], names: names})
+	}
+}
+
+// indexedCommandsUnchanged reports whether the indexed subcommands are still
+// the first subcommands, named as they were when they were indexed
+func (cmd *Command) indexedCommandsUnchanged() bool {
+	if len(cmd.indexedCommands) > len(cmd.Commands) {
+		return false
+	}
+
+	for i, indexed := range cmd.indexedCommands {
+		if cmd.Commands[i] != indexed.cmd {
+			return false
+		}
+
+		names := indexed.cmd.Names()
+		if len(names) != len(indexed.names) {
+			return false
+		}
+		for j, name := range names {
+			if name != indexed.names[j] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// commandByPrefix returns the subcommand named like the prefix or else the
+// only subcommand with a name or alias starting with it, and nil if there is
+// none or the prefix is ambiguous
+func (cmd *Command) commandByPrefix(prefix string) *Command {
+	cmd.indexCommands()
+	if cmd.commandTrie == nil {
+		cmd.commandTrie = newCommandTrie(cmd.Commands)
+	}
+
+	if i := cmd.commandTrie.lookup(prefix); i != -1 {
+		return cmd.loadCommand(i)
+	}
+	return nil
+}
+
+// MatchCommandPrefix is a SuggestCommandFunc resolving unambiguous
+// abbreviations of command names and aliases, e.g. "st" to "status" if no
+// other command starts with "st". Use it with PrefixMatchCommands to match
+// abbreviations instead of the most similar command name. The prefix tree of
+// the commands is cached on their parent command.
+func MatchCommandPrefix(commands []*Command, provided string) string {
+	if len(commands) == 0 {
+		return ""
+	}
+
+	if parent := commands[0].parent; parent != nil && len(parent.Commands) == len(commands) && &parent.Commands[0] == &commands[0] {
+		if subCmd := parent.commandByPrefix(provided); subCmd != nil {
+			return subCmd.Name
+		}
+		return ""
+	}
+
+	if i := newCommandTrie(commands).lookup(provided); i != -1 {
+		return commands[i].Name
+	}
+	return ""
+}
@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandTrie(t *testing.T) {
+	trie := newCommandTrie([]*Command{
+		{Name: "status", Aliases: []string{"st"}},
+		{Name: "stash"},
+		{Name: "get"},
+		{Name: "getall"},
+		{Name: "remote", Aliases: []string{"rm-remote"}},
+	})
+
+	for _, testCase := range []struct {
+		prefix   string
+		expected int
+	}{
+		{"status", 0},
+		{"stat", 0},
+		{"st", 0},
+		{"sta", -1},
+		{"stas", 1},
+		{"get", 2},
+		{"geta", 3},
+		{"r", 4},
+		{"rm", 4},
+		{"x", -1},
+		{"statusx", -1},
+		{"", -1},
+	} {
+		assert.Equal(t, testCase.expected, trie.lookup(testCase.prefix), "prefix %q", testCase.prefix)
+	}
+}
+
+func TestCommand_CommandIndex(t *testing.T) {
+	cmd := &Command{
+		Commands: []*Command{
+			{Name: "first", Aliases: []string{"f"}},
+			{Name: "second", Aliases: []string{"f"}},
+		},
+	}
+
+	assert.Equal(t, "first", cmd.Command("f").Name)
+	assert.Equal(t, "second", cmd.Command("second").Name)
+	assert.Nil(t, cmd.Command("third"))
+
+	cmd.Commands = append(cmd.Commands, &Command{Name: "third"})
+	assert.Equal(t, "third", cmd.Command("third").Name)
+
+	cmd.Commands = append(cmd.Commands, Lazy("fourth", "", func() *Command {
+		return &Command{Name: "renamed"}
+	}))
+	assert.Equal(t, "renamed", cmd.Command("fourth").Name)
+	assert.Equal(t, "renamed", cmd.Command("renamed").Name)
+	assert.Nil(t, cmd.Command("fourth"))
+}
+
+func TestCommand_CommandIndexReassignedCommands(t *testing.T) {
+	var ran []string
+	action := func(_ context.Context, cmd *Command) error {
+		ran = append(ran, cmd.Name)
+		return nil
+	}
+
+	cmd := &Command{
+		Name:      "app",
+		Writer:    &bytes.Buffer{},
+		ErrWriter: &bytes.Buffer{},
+		Commands:  []*Command{{Name: "a", Action: action}},
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "a"}))
+
+	cmd.Commands = []*Command{{Name: "b", Action: action}}
+	assert.Nil(t, cmd.Command("a"))
+	require.NotNil(t, cmd.Command("b"))
+	assert.Equal(t, "b", cmd.Command("b").Name)
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "b"}))
+	assert.Equal(t, []string{"a", "b"}, ran)
+
+	cmd.Commands[0].Name = "c"
+	assert.Nil(t, cmd.Command("b"))
+	assert.Equal(t, "c", cmd.Command("c").Name)
+}
+
+func TestMatchCommandPrefix(t *testing.T) {
+	var ran []string
+	action := func(_ context.Context, cmd *Command) error {
+		ran = append(ran, cmd.Name)
+		return nil
+	}
+
+	cmd := &Command{
+		Name:                "app",
+		PrefixMatchCommands: true,
+		SuggestCommandFunc:  MatchCommandPrefix,
+		Writer:              &bytes.Buffer{},
+		ErrWriter:           &bytes.Buffer{},
+		Commands: []*Command{
+			{Name: "status", Action: action},
+			{Name: "stash", Action: action},
+			{Name: "remote", Aliases: []string{"rem"}, Action: action},
+		},
+	}
+
+	for _, args := range [][]string{
+		{"app", "stat"},
+		{"app", "stas"},
+		{"app", "re"},
+		{"app", "rem"},
+	} {
+		require.NoError(t, cmd.Run(buildTestContext(t), args))
+	}
+	assert.Equal(t, []string{"status", "stash", "remote", "remote"}, ran)
+
+	err := cmd.Run(buildTestContext(t), []string{"app", "sta"})
+	assert.ErrorContains(t, err, "No help topic for 'sta'")
+
+	assert.Equal(t, "stash", MatchCommandPrefix([]*Command{{Name: "status"}, {Name: "stash"}}, "stas"))
+	assert.Equal(t, "", MatchCommandPrefix(nil, "stas"))
+}
+
+// buildDeepTestCommand returns a command tree with width subcommands on
+// every level, of which the last has subcommands again down to depth, and
+// the arguments to run the deepest command
+func buildDeepTestCommand(width, depth int) (*Command, []string) {
+	args := []string{"app"}
+	root := &Command{Name: "app"}
+
+	parent := root
+	for level := 0; level < depth; level++ {
+		for i := 0; i < width; i++ {
+			parent.Commands = append(parent.Commands, &Command{
+				Name:    fmt.Sprintf("command-%d-%d", level, i),
+				Aliases: []string{fmt.Sprintf("c%d-%d", level, i)},
+			})
+		}
+		parent = parent.Commands[width-1]
+		args = append(args, parent.Name)
+	}
+	parent.Action = func(context.Context, *Command) error { return nil }
+
+	return root, args
+}
+
+func BenchmarkCommandResolution(b *testing.B) {
+	for _, width := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprint(width), func(b *testing.B) {
+			cmd, args := buildDeepTestCommand(width, 4)
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				subCmd := cmd
+				for _, name := range args[1:] {
+					subCmd = subCmd.Command(name)
+				}
+				if subCmd.Action == nil {
+					b.Fatal("deepest command not resolved")
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkRunDeepCommand(b *testing.B) {
+	for _, width := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprint(width), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				cmd, args := buildDeepTestCommand(width, 4)
+				b.StartTimer()
+
+				if err := cmd.Run(context.Background(), args); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -1,3 +1,5 @@
+//go:build !urfave_cli_no_completion
+
 package cli
 
 import (
@@ -25,6 +27,9 @@ var (
 	}
 )
 
+// completionShells are the shells supported by ToShellCompletion
+var completionShells = []string{"bash", "fish", "pwsh", "zsh"}
+
 type renderCompletion func(*Command) (string, error)
 
 func getCompletion(s string) renderCompletion {
@@ -36,9 +41,42 @@ func getCompletion(s string) renderCompletion {
 
 func buildCompletionCommand() *Command {
 	return &Command{
-		Name:   completionCommandName,
-		Hidden: true,
-		Action: completionCommandAction,
+		Name:      completionCommandName,
+		Hidden:    true,
+		Action:    completionCommandAction,
+		isBuiltin: true,
+	}
+}
+
+// NewCompletionCommand returns a command printing the completion script of
+// the shell given as argument, generated by ToShellCompletion from the tree
+// of the root command, to be mounted like
+//
+//	cmd.Commands = append(cmd.Commands, cli.NewCompletionCommand())
+//
+// so `app completion zsh > _app` installs the completions for zsh.
+func NewCompletionCommand() *Command {
+	return &Command{
+		Name:      "completion",
+		Usage:     "Output the shell completion script",
+		ArgsUsage: "<shell>",
+		ShellComplete: func(_ context.Context, cmd *Command) {
+			for _, shell := range completionShells {
+				_, _ = fmt.Fprintln(cmd.Root().Writer, shell)
+			}
+		},
+		Action: func(_ context.Context, cmd *Command) error {
+			if cmd.Args().Len() == 0 {
+				return Exit(fmt.Sprintf("no shell provided for completion command. available shells are %+v", completionShells), 1)
+			}
+
+			script, err := cmd.Root().ToShellCompletion(cmd.Args().First())
+			if err != nil {
+				return Exit(err, 1)
+			}
+			_, err = fmt.Fprint(cmd.Root().Writer, script)
+			return err
+		},
 	}
 }
 
@@ -0,0 +1,48 @@
+//go:build urfave_cli_no_completion
+
+package cli
+
+import (
+	"context"
+	"errors"
+)
+
+const (
+	completionCommandName = "generate-completion"
+)
+
+var errCompletionUnavailable = errors.New("shell completion scripts are not included in this build")
+
+func buildCompletionCommand() *Command {
+	return &Command{
+		Name:      completionCommandName,
+		Hidden:    true,
+		isBuiltin: true,
+		Action: func(context.Context, *Command) error {
+			return Exit(errCompletionUnavailable, 1)
+		},
+	}
+}
+
+// NewCompletionCommand returns a command which always fails in builds
+// tagged urfave_cli_no_completion
+func NewCompletionCommand() *Command {
+	return &Command{
+		Name:      "completion",
+		Usage:     "Output the shell completion script",
+		ArgsUsage: "<shell>",
+		Action: func(context.Context, *Command) error {
+			return Exit(errCompletionUnavailable, 1)
+		},
+	}
+}
+
+// ToFishCompletion always fails in builds tagged urfave_cli_no_completion
+func (cmd *Command) ToFishCompletion() (string, error) {
+	return "", errCompletionUnavailable
+}
+
+// ToShellCompletion always fails in builds tagged urfave_cli_no_completion
+func (cmd *Command) ToShellCompletion(shell string) (string, error) {
+	return "", errCompletionUnavailable
+}
@@ -0,0 +1,429 @@
+//go:build !urfave_cli_no_completion
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToShellCompletion renders a completion script for the shell, one of
+// "bash", "zsh", "fish" and "pwsh" (or "powershell"), from the commands and
+// flags of the tree. Unlike the scripts of the completion command, which
+// ask the program for completions on every key press, the generated scripts
+// contain all subcommands, flags and their descriptions, and complete file
+// names for flags taking a file and the choices of flags accepting only
+// some values.
+func (cmd *Command) ToShellCompletion(shell string) (string, error) {
+	cmd.loadCommands()
+
+	switch shell {
+	case "bash":
+		return newCompletionNode(cmd, nil, nil).bash(cmd.Name), nil
+	case "zsh":
+		return newCompletionNode(cmd, nil, nil).zsh(cmd.Name), nil
+	case "fish":
+		return cmd.ToFishCompletion()
+	case "pwsh", "powershell", "ps":
+		return newCompletionNode(cmd, nil, nil).pwsh(cmd.Name), nil
+	}
+
+	return "", fmt.Errorf("unknown shell %s, available shells are %+v", shell, completionShells)
+}
+
+// completionNode is a visible command of the tree with the words which can
+// be completed after it
+type completionNode struct {
+	// names of the command and its ancestors separated by spaces
+	path     string
+	names    []string
+	usage    string
+	flags    []completionFlag
+	children []*completionNode
+}
+
+type completionFlag struct {
+	// names including the dashes
+	names      []string
+	usage      string
+	takesValue bool
+	takesFile  bool
+	choices    []string
+}
+
+func newCompletionNode(cmd *Command, parent *completionNode, inherited []Flag) *completionNode {
+	n := &completionNode{
+		path:  cmd.Name,
+		names: cmd.Names(),
+		usage: cmd.Usage,
+	}
+	if parent != nil {
+		n.path = parent.path + " " + cmd.Name
+	}
+
+	flags := append(append([]Flag{}, inherited...), cmd.VisibleFlags()...)
+	if !cmd.HideHelp && HelpFlag != nil {
+		flags = append(flags, HelpFlag)
+	}
+	if parent == nil && !cmd.HideVersion && cmd.Version != "" && VersionFlag != nil {
+		flags = append(flags, VersionFlag)
+	}
+
+	seen := map[string]bool{}
+	for _, fl := range flags {
+		names := fl.Names()
+		if len(names) == 0 || seen[names[0]] {
+			continue
+		}
+		seen[names[0]] = true
+
+		cf := completionFlag{}
+		for _, name := range flagDocNames(fl) {
+			cf.names = append(cf.names, prefixFor(name)+name)
+		}
+		if df, ok := fl.(DocGenerationFlag); ok {
+			_, cf.usage = unquoteUsage(df.GetUsage())
+			cf.takesValue = df.TakesValue()
+		}
+		if ff, ok := fl.(fileFlag); ok {
+			cf.takesFile = cf.takesValue && ff.takesFile()
+		}
+		if chf, ok := fl.(ChoicesFlag); ok && cf.takesValue {
+			cf.choices = chf.GetChoices()
+		}
+		n.flags = append(n.flags, cf)
+	}
+
+	var persistent []Flag
+	persistent = append(persistent, inherited...)
+	for _, fl := range cmd.VisibleFlags() {
+		if pfl, ok := fl.(PersistentFlag); ok && pfl.IsPersistent() {
+			persistent = append(persistent, fl)
+		}
+	}
+
+	for _, sub := range cmd.VisibleCommands() {
+		n.children = append(n.children, newCompletionNode(sub, n, persistent))
+	}
+
+	return n
+}
+
+// walk calls fn for the node and all its descendants
+func (n *completionNode) walk(fn func(*completionNode)) {
+	fn(n)
+	for _, child := range n.children {
+		child.walk(fn)
+	}
+}
+
+// completionFuncName returns the name of the shell function completing the
+// program
+func completionFuncName(name string) string {
+	return "_" + strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, name) + "_completions"
+}
+
+// shellQuote quotes the string for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// pwshQuote quotes the string for PowerShell
+func pwshQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// transitions returns the patterns of the "<path>:<word>" case statements
+// walking the command line: words moving to a subcommand and flags whose
+// value is skipped
+func (n *completionNode) transitions(quote func(string) string) (moves [][2]string, values []string) {
+	n.walk(func(node *completionNode) {
+		for _, fl := range node.flags {
+			if !fl.takesValue {
+				continue
+			}
+			for _, name := range fl.names {
+				values = append(values, quote(node.path+":"+name))
+			}
+		}
+		for _, child := range node.children {
+			var patterns []string
+			for _, name := range child.names {
+				patterns = append(patterns, quote(node.path+":"+name))
+			}
+			moves = append(moves, [2]string{strings.Join(patterns, "|"), child.path})
+		}
+	})
+	return moves, values
+}
+
+// valueFlags returns the patterns of the "<path>:<flag>" case statements
+// matching the flags taking a value without choices, split by whether they
+// take a file
+func (n *completionNode) valueFlags(quote func(string) string) (files, others []string) {
+	n.walk(func(node *completionNode) {
+		for _, fl := range node.flags {
+			if !fl.takesValue || len(fl.choices) > 0 {
+				continue
+			}
+			for _, name := range fl.names {
+				if fl.takesFile {
+					files = append(files, quote(node.path+":"+name))
+				} else {
+					others = append(others, quote(node.path+":"+name))
+				}
+			}
+		}
+	})
+	return files, others
+}
+
+// choiceFlags returns the patterns of the "<path>:<flag>" case statements
+// matching the flags with choices along with their choices
+func (n *completionNode) choiceFlags(quote func(string) string) (choices [][2][]string) {
+	n.walk(func(node *completionNode) {
+		for _, fl := range node.flags {
+			if len(fl.choices) == 0 {
+				continue
+			}
+			var patterns []string
+			for _, name := range fl.names {
+				patterns = append(patterns, quote(node.path+":"+name))
+			}
+			choices = append(choices, [2][]string{patterns, fl.choices})
+		}
+	})
+	return choices
+}
+
+func (n *completionNode) bash(program string) string {
+	var b strings.Builder
+	fn := completionFuncName(program)
+
+	fmt.Fprintf(&b, "# bash completion for %s\n\n", program)
+	fmt.Fprintf(&b, "%s() {\n", fn)
+	b.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	b.WriteString("    local prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	fmt.Fprintf(&b, "    local cmdpath=%s\n", shellQuote(n.path))
+	b.WriteString("    local i\n\n")
+
+	moves, values := n.transitions(shellQuote)
+	b.WriteString("    for ((i = 1; i < COMP_CWORD; i++)); do\n")
+	b.WriteString("        case \"$cmdpath:${COMP_WORDS[i]}\" in\n")
+	if len(values) > 0 {
+		fmt.Fprintf(&b, "            %s) ((i++)) ;;\n", strings.Join(values, "|"))
+	}
+	for _, move := range moves {
+		fmt.Fprintf(&b, "            %s) cmdpath=%s ;;\n", move[0], shellQuote(move[1]))
+	}
+	b.WriteString("        esac\n")
+	b.WriteString("    done\n\n")
+
+	files, others := n.valueFlags(shellQuote)
+	choices := n.choiceFlags(shellQuote)
+	if len(files)+len(others)+len(choices) > 0 {
+		b.WriteString("    case \"$cmdpath:$prev\" in\n")
+		for _, choice := range choices {
+			fmt.Fprintf(&b, "        %s)\n", strings.Join(choice[0], "|"))
+			fmt.Fprintf(&b, "            COMPREPLY=($(compgen -W %s -- \"$cur\"))\n", shellQuote(strings.Join(choice[1], " ")))
+			b.WriteString("            return\n")
+			b.WriteString("            ;;\n")
+		}
+		if len(files) > 0 {
+			fmt.Fprintf(&b, "        %s)\n", strings.Join(files, "|"))
+			b.WriteString("            COMPREPLY=($(compgen -f -- \"$cur\"))\n")
+			b.WriteString("            return\n")
+			b.WriteString("            ;;\n")
+		}
+		if len(others) > 0 {
+			fmt.Fprintf(&b, "        %s)\n", strings.Join(others, "|"))
+			b.WriteString("            COMPREPLY=()\n")
+			b.WriteString("            return\n")
+			b.WriteString("            ;;\n")
+		}
+		b.WriteString("    esac\n\n")
+	}
+
+	b.WriteString("    local words=\"\"\n")
+	b.WriteString("    case \"$cmdpath\" in\n")
+	n.walk(func(node *completionNode) {
+		var words []string
+		for _, child := range node.children {
+			words = append(words, child.names...)
+		}
+		for _, fl := range node.flags {
+			words = append(words, fl.names...)
+		}
+		fmt.Fprintf(&b, "        %s) words=%s ;;\n", shellQuote(node.path), shellQuote(strings.Join(words, " ")))
+	})
+	b.WriteString("    esac\n\n")
+
+	b.WriteString("    COMPREPLY=($(compgen -W \"$words\" -- \"$cur\"))\n")
+	b.WriteString("}\n\n")
+	fmt.Fprintf(&b, "complete -F %s %s\n", fn, program)
+
+	return b.String()
+}
+
+// zshDescribe formats the item for _describe, escaping colons of the name
+func zshDescribe(name, usage string) string {
+	item := strings.ReplaceAll(name, ":", `\:`)
+	if usage != "" {
+		item += ":" + usage
+	}
+	return shellQuote(item)
+}
+
+func (n *completionNode) zsh(program string) string {
+	var b strings.Builder
+	fn := completionFuncName(program)
+
+	fmt.Fprintf(&b, "#compdef %s\n\n", program)
+	fmt.Fprintf(&b, "%s() {\n", fn)
+	fmt.Fprintf(&b, "    local cmdpath=%s\n", shellQuote(n.path))
+	b.WriteString("    local i\n\n")
+
+	moves, values := n.transitions(shellQuote)
+	b.WriteString("    for ((i = 2; i < CURRENT; i++)); do\n")
+	b.WriteString("        case \"$cmdpath:${words[i]}\" in\n")
+	if len(values) > 0 {
+		fmt.Fprintf(&b, "            %s) ((i++)) ;;\n", strings.Join(values, "|"))
+	}
+	for _, move := range moves {
+		fmt.Fprintf(&b, "            %s) cmdpath=%s ;;\n", move[0], shellQuote(move[1]))
+	}
+	b.WriteString("        esac\n")
+	b.WriteString("    done\n\n")
+
+	files, others := n.valueFlags(shellQuote)
+	choices := n.choiceFlags(shellQuote)
+	if len(files)+len(others)+len(choices) > 0 {
+		b.WriteString("    case \"$cmdpath:${words[CURRENT-1]}\" in\n")
+		for _, choice := range choices {
+			var values []string
+			for _, value := range choice[1] {
+				values = append(values, shellQuote(value))
+			}
+			fmt.Fprintf(&b, "        %s) compadd -- %s; return ;;\n", strings.Join(choice[0], "|"), strings.Join(values, " "))
+		}
+		if len(files) > 0 {
+			fmt.Fprintf(&b, "        %s) _files; return ;;\n", strings.Join(files, "|"))
+		}
+		if len(others) > 0 {
+			fmt.Fprintf(&b, "        %s) return ;;\n", strings.Join(others, "|"))
+		}
+		b.WriteString("    esac\n\n")
+	}
+
+	b.WriteString("    local -a commands flags\n")
+	b.WriteString("    case \"$cmdpath\" in\n")
+	n.walk(func(node *completionNode) {
+		fmt.Fprintf(&b, "        %s)\n", shellQuote(node.path))
+		var commands, flags []string
+		for _, child := range node.children {
+			for _, name := range child.names {
+				commands = append(commands, zshDescribe(name, child.usage))
+			}
+		}
+		for _, fl := range node.flags {
+			for _, name := range fl.names {
+				flags = append(flags, zshDescribe(name, fl.usage))
+			}
+		}
+		fmt.Fprintf(&b, "            commands=(%s)\n", strings.Join(commands, " "))
+		fmt.Fprintf(&b, "            flags=(%s)\n", strings.Join(flags, " "))
+		b.WriteString("            ;;\n")
+	})
+	b.WriteString("    esac\n\n")
+
+	b.WriteString("    if [[ \"${words[CURRENT]}\" == -* ]]; then\n")
+	b.WriteString("        _describe -t flags 'flags' flags\n")
+	b.WriteString("    else\n")
+	b.WriteString("        _describe -t commands 'commands' commands\n")
+	b.WriteString("    fi\n")
+	b.WriteString("}\n\n")
+	fmt.Fprintf(&b, "compdef %s %s\n", fn, program)
+
+	return b.String()
+}
+
+func (n *completionNode) pwsh(program string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# PowerShell completion for %s\n\n", program)
+	fmt.Fprintf(&b, "Register-ArgumentCompleter -Native -CommandName %s -ScriptBlock {\n", pwshQuote(program))
+	b.WriteString("    param($wordToComplete, $commandAst, $cursorPosition)\n\n")
+	b.WriteString("    $words = @($commandAst.CommandElements | Where-Object { $_.Extent.EndOffset -lt $cursorPosition } | ForEach-Object { $_.ToString() })\n")
+	fmt.Fprintf(&b, "    $cmdPath = %s\n\n", pwshQuote(n.path))
+
+	moves, values := n.transitions(pwshQuote)
+	b.WriteString("    for ($i = 1; $i -lt $words.Count; $i++) {\n")
+	b.WriteString("        switch -CaseSensitive (\"${cmdPath}:$($words[$i])\") {\n")
+	for _, value := range values {
+		fmt.Fprintf(&b, "            %s { $i++ }\n", value)
+	}
+	for _, move := range moves {
+		for _, pattern := range strings.Split(move[0], "|") {
+			fmt.Fprintf(&b, "            %s { $cmdPath = %s }\n", pattern, pwshQuote(move[1]))
+		}
+	}
+	b.WriteString("        }\n")
+	b.WriteString("    }\n\n")
+
+	files, others := n.valueFlags(pwshQuote)
+	choices := n.choiceFlags(pwshQuote)
+	if len(files)+len(others)+len(choices) > 0 {
+		b.WriteString("    if ($words.Count -gt 1) {\n")
+		b.WriteString("        switch -CaseSensitive (\"${cmdPath}:$($words[-1])\") {\n")
+		for _, choice := range choices {
+			var values []string
+			for _, value := range choice[1] {
+				values = append(values, pwshQuote(value))
+			}
+			for _, pattern := range choice[0] {
+				fmt.Fprintf(&b, "            %s {\n", pattern)
+				fmt.Fprintf(&b, "                @(%s) | Where-Object { $_ -like \"$wordToComplete*\" } | ForEach-Object {\n", strings.Join(values, ", "))
+				b.WriteString("                    [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)\n")
+				b.WriteString("                }\n")
+				b.WriteString("                return\n")
+				b.WriteString("            }\n")
+			}
+		}
+		for _, value := range append(files, others...) {
+			// returning nothing falls back to completing file names
+			fmt.Fprintf(&b, "            %s { return }\n", value)
+		}
+		b.WriteString("        }\n")
+		b.WriteString("    }\n\n")
+	}
+
+	b.WriteString("    $candidates = switch -CaseSensitive ($cmdPath) {\n")
+	n.walk(func(node *completionNode) {
+		fmt.Fprintf(&b, "        %s {\n", pwshQuote(node.path))
+		for _, child := range node.children {
+			for _, name := range child.names {
+				fmt.Fprintf(&b, "            ,@(%s, %s, 'Command')\n", pwshQuote(name), pwshQuote(child.usage))
+			}
+		}
+		for _, fl := range node.flags {
+			for _, name := range fl.names {
+				fmt.Fprintf(&b, "            ,@(%s, %s, 'ParameterName')\n", pwshQuote(name), pwshQuote(fl.usage))
+			}
+		}
+		b.WriteString("        }\n")
+	})
+	b.WriteString("    }\n\n")
+
+	b.WriteString("    $candidates | Where-Object { $_[0] -like \"$wordToComplete*\" } | ForEach-Object {\n")
+	b.WriteString("        $description = if ($_[1]) { $_[1] } else { $_[0] }\n")
+	b.WriteString("        [System.Management.Automation.CompletionResult]::new($_[0], $_[0], $_[2], $description)\n")
+	b.WriteString("    }\n")
+	b.WriteString("}\n")
+
+	return b.String()
+}
@@ -0,0 +1,124 @@
+//go:build !urfave_cli_no_completion
+
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToShellCompletion(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "pwsh"} {
+		t.Run(shell, func(t *testing.T) {
+			cmd := buildExtendedTestCommand()
+
+			res, err := cmd.ToShellCompletion(shell)
+
+			require.NoError(t, err)
+			expectFileContent(t, "testdata/expected-completion-"+shell, res)
+		})
+	}
+}
+
+func TestToShellCompletionFish(t *testing.T) {
+	cmd := buildExtendedTestCommand()
+
+	res, err := cmd.ToShellCompletion("fish")
+	require.NoError(t, err)
+
+	expected, err := buildExtendedTestCommand().ToFishCompletion()
+	require.NoError(t, err)
+	assert.Equal(t, expected, res)
+}
+
+func TestToShellCompletionUnknownShell(t *testing.T) {
+	_, err := buildExtendedTestCommand().ToShellCompletion("tcsh")
+	assert.EqualError(t, err, "unknown shell tcsh, available shells are [bash fish pwsh zsh]")
+}
+
+func TestToShellCompletionBash(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash is not available")
+	}
+
+	cmd := buildExtendedTestCommand()
+	cmd.Flags = append(cmd.Flags,
+		&StringFlag{Name: "verbosity", Persistent: true},
+		&ChoiceFlag{Name: "color", Config: ChoiceConfig{Choices: []string{"auto", "always", "never"}}},
+	)
+	script, err := cmd.ToShellCompletion("bash")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "greet.bash")
+	require.NoError(t, os.WriteFile(file, []byte(script), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "greet.conf"), nil, 0o644))
+
+	complete := func(words ...string) []string {
+		shellWords := make([]string, len(words))
+		for i, word := range words {
+			shellWords[i] = shellQuote(word)
+		}
+
+		c := exec.Command(bash, "--norc", "--noprofile", "-c",
+			`source "$1" && COMP_WORDS=(`+strings.Join(shellWords, " ")+`) && COMP_CWORD=$((${#COMP_WORDS[@]} - 1)) && `+
+				completionFuncName("greet")+`; printf '%s\n' "${COMPREPLY[@]}"`,
+			"bash", file)
+		c.Dir = dir
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+
+		return strings.Fields(string(out))
+	}
+
+	assert.Equal(t, []string{"config", "c"}, complete("greet", "c"))
+	assert.Equal(t, []string{"--another-flag"}, complete("greet", "--a"))
+	assert.Equal(t, []string{"sub-config"}, complete("greet", "config", "sub"))
+	assert.Equal(t, []string{"sub-config"}, complete("greet", "c", "--flag", "x", "sub"))
+	assert.Equal(t, []string{"--sub-flag", "--sub-fl", "--sub-command-flag"}, complete("greet", "c", "s", "--sub"))
+	assert.Equal(t, []string{"--verbosity"}, complete("greet", "info", "--v"), "persistent flags are inherited")
+	assert.Equal(t, []string{"greet.bash", "greet.conf"}, complete("greet", "--socket", "greet."), "file names are completed")
+	assert.Empty(t, complete("greet", "--flag", ""), "no completions for values")
+	assert.Equal(t, []string{"auto", "always"}, complete("greet", "--color", "a"), "choices are completed")
+	assert.Equal(t, []string{"config", "c"}, complete("greet", "--color", "never", "c"))
+	assert.Empty(t, complete("greet", "hidden"), "hidden commands are skipped")
+}
+
+func TestToShellCompletionChoices(t *testing.T) {
+	cmd := buildExtendedTestCommand()
+	cmd.Flags = append(cmd.Flags, &ChoiceFlag{Name: "color", Config: ChoiceConfig{Choices: []string{"auto", "never"}}})
+
+	for shell, expected := range map[string]string{
+		"bash": `COMPREPLY=($(compgen -W 'auto never' -- "$cur"))`,
+		"zsh":  `'greet:--color') compadd -- 'auto' 'never'; return ;;`,
+		"fish": `complete -c greet -n '__fish_greet_no_subcommand' -f -l color -r -a 'auto never'`,
+		"pwsh": `@('auto', 'never') | Where-Object { $_ -like "$wordToComplete*" }`,
+	} {
+		res, err := cmd.ToShellCompletion(shell)
+		require.NoError(t, err)
+		assert.Contains(t, res, expected, shell)
+	}
+}
+
+func TestToShellCompletionNegatable(t *testing.T) {
+	cmd := buildExtendedTestCommand()
+	cmd.Flags = append(cmd.Flags, &BoolFlag{Name: "color", Aliases: []string{"c"}, Config: BoolConfig{Negatable: true}})
+
+	for shell, expected := range map[string]string{
+		"bash": `--color -c --no-color`,
+		"zsh":  `'--color' '-c' '--no-color'`,
+		"fish": `-f -l color -s c -l no-color`,
+		"pwsh": `@('--no-color', '', 'ParameterName')`,
+	} {
+		res, err := cmd.ToShellCompletion(shell)
+		require.NoError(t, err)
+		assert.Contains(t, res, expected, shell)
+	}
+}
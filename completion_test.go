@@ -1,3 +1,5 @@
+//go:build !urfave_cli_no_completion
+
 package cli
 
 import (
@@ -90,3 +92,24 @@ func TestCompletionInvalidShell(t *testing.T) {
 	err := cmd.Run(buildTestContext(t), []string{"foo", completionCommandName, "junky-sheell"})
 	assert.ErrorContains(t, err, "unknown shell junky-sheell")
 }
+
+func TestNewCompletionCommand(t *testing.T) {
+	out := &bytes.Buffer{}
+	cmd := &Command{
+		Name:     "app",
+		Writer:   out,
+		Commands: []*Command{{Name: "serve", Usage: "serve the app"}, NewCompletionCommand()},
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "completion", "zsh"}))
+	script, err := cmd.ToShellCompletion("zsh")
+	require.NoError(t, err)
+	assert.Equal(t, script, out.String())
+	assert.Contains(t, out.String(), "serve")
+
+	err = cmd.Run(buildTestContext(t), []string{"app", "completion"})
+	assert.EqualError(t, err, "no shell provided for completion command. available shells are [bash fish pwsh zsh]")
+
+	err = cmd.Run(buildTestContext(t), []string{"app", "completion", "tcsh"})
+	assert.EqualError(t, err, "unknown shell tcsh, available shells are [bash fish pwsh zsh]")
+}
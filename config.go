@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConfigDecoder decodes the content of a configuration file into nested
+// maps, e.g. json.Unmarshal into a map[string]any
+type ConfigDecoder func(data []byte) (map[string]any, error)
+
+// ConfigFile is a configuration file flag values are read from by adding
+// the value sources returned by Key to the Sources of the flags. Value
+// sources are looked up in order and values given on the command line
+// override them, so adding the config file after the environment variables
+//
+//	cfg := cli.JSONConfigFile("config.json", "/etc/app/config.json")
+//
+//	&cli.IntFlag{
+//		Name:    "port",
+//		Sources: cli.NewValueSourceChain(cli.EnvVar("APP_PORT"), cfg.Key("server.port")),
+//	}
+//
+// gives the precedence: command line > environment variable > config file >
+// default. The file is read on the first lookup. Decoders for YAML and TOML
+// files are provided by the cliconfig package.
+type ConfigFile struct {
+	// Paths the file is looked up at, the first existing one is read. No
+	// values are found if none exists.
+	Paths []string
+	// Decode decodes the content of the file, defaults to JSON
+	Decode ConfigDecoder
+
+	once sync.Once
+	path string
+	data map[string]any
+	err  error
+}
+
+// JSONConfigFile returns a config file decoded as JSON, read from the first
+// of the paths that exists
+func JSONConfigFile(paths ...string) *ConfigFile {
+	return &ConfigFile{Paths: paths, Decode: DecodeJSONConfig}
+}
+
+// DecodeJSONConfig is the ConfigDecoder of JSON files
+func DecodeJSONConfig(data []byte) (map[string]any, error) {
+	var m map[string]any
+	err := json.Unmarshal(data, &m)
+	return m, err
+}
+
+// Key returns a value source looking up the value at the dot separated
+// path of keys in the config file, e.g. "server.port" for the "port" key of
+// the "server" table. Lists are joined by commas for slice flags, maps are
+// joined to key=value pairs for map flags.
+func (c *ConfigFile) Key(key string) ValueSource {
+	return &configValueSource{file: c, key: key}
+}
+
+// Path returns the path of the file which was read, empty if none exists
+// or the file wasn't read yet
+func (c *ConfigFile) Path() string {
+	c.load()
+	return c.path
+}
+
+// Err returns the error reading or decoding the file, if any. Flags looking
+// up values in the file fail to be applied with this error, so running the
+// command fails instead of silently ignoring a broken configuration.
+func (c *ConfigFile) Err() error {
+	c.load()
+	return c.err
+}
+
+func (c *ConfigFile) load() {
+	c.once.Do(func() {
+		for _, path := range c.Paths {
+			data, err := os.ReadFile(path)
+			if os.IsNotExist(err) {
+				continue
+			}
+			c.path = path
+			if err != nil {
+				c.err = err
+				return
+			}
+
+			decode := c.Decode
+			if decode == nil {
+				decode = DecodeJSONConfig
+			}
+
+			if c.data, err = decode(data); err != nil {
+				c.err = fmt.Errorf(tr("could not decode config file %[1]q: %[2]v"), path, err)
+			}
+			return
+		}
+
+		tracef("no config file found at %[1]q", c.Paths)
+	})
+}
+
+// lookup returns the value at the dot separated path of keys. Keys
+// containing dots are matched before descending into nested maps.
+func (c *ConfigFile) lookup(key string) (any, bool) {
+	c.load()
+
+	var cur any = c.data
+	for key != "" {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		if v, ok := m[key]; ok {
+			return v, true
+		}
+
+		first, rest, ok := strings.Cut(key, ".")
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[first]; !ok {
+			return nil, false
+		}
+		key = rest
+	}
+	return nil, false
+}
+
+// configValueSource encapsulates a ValueSource from a key of a config file
+type configValueSource struct {
+	file *ConfigFile
+	key  string
+}
+
+func (s *configValueSource) Lookup() (string, bool) {
+	v, ok := s.file.lookup(s.key)
+	if !ok || v == nil {
+		return "", false
+	}
+	return formatConfigValue(v), true
+}
+
+func (s *configValueSource) LookupWithError() (string, bool, error) {
+	if err := s.file.Err(); err != nil {
+		return "", false, err
+	}
+
+	v, ok := s.Lookup()
+	return v, ok, nil
+}
+
+func (s *configValueSource) String() string {
+	return fmt.Sprintf("key %[1]q of config file %[2]q", s.key, s.file.Path())
+}
+
+func (s *configValueSource) GoString() string {
+	return fmt.Sprintf("&configValueSource{Paths:%[1]q,Key:%[2]q}", s.file.Paths, s.key)
+}
+
+// formatConfigValue formats a decoded value or the value of a flag the way
+// it would be given on the command line
+func formatConfigValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatConfigValue(item)
+		}
+		return strings.Join(items, defaultSliceFlagSeparator)
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		items := make([]string, len(keys))
+		for i, key := range keys {
+			items[i] = key + defaultMapFlagKeyValueSeparator + formatConfigValue(v[key])
+		}
+		return strings.Join(items, defaultSliceFlagSeparator)
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	// typed slices and maps, e.g. the values of slice and map flags
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice:
+		items := make([]string, rv.Len())
+		for i := range items {
+			items[i] = formatConfigValue(rv.Index(i).Interface())
+		}
+		return strings.Join(items, defaultSliceFlagSeparator)
+	case reflect.Map:
+		keys := make([]string, 0, rv.Len())
+		values := map[string]string{}
+		for _, key := range rv.MapKeys() {
+			k := formatConfigValue(key.Interface())
+			keys = append(keys, k)
+			values[k] = formatConfigValue(rv.MapIndex(key).Interface())
+		}
+		sort.Strings(keys)
+
+		items := make([]string, len(keys))
+		for i, key := range keys {
+			items[i] = key + defaultMapFlagKeyValueSeparator + values[key]
+		}
+		return strings.Join(items, defaultSliceFlagSeparator)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestConfigFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestConfigFilePrecedence(t *testing.T) {
+	path := writeTestConfigFile(t, "config.json", `{"server": {"port": 8080, "host": "example.com"}, "debug": true}`)
+	cfg := JSONConfigFile(filepath.Join(t.TempDir(), "missing.json"), path)
+
+	var port int64
+	var host string
+	var debug bool
+	cmd := &Command{
+		Name: "app",
+		Env:  MapEnv{"APP_HOST": "env.example.com"},
+		Flags: []Flag{
+			&IntFlag{Name: "port", Value: 80, Sources: NewValueSourceChain(EnvVar("APP_PORT"), cfg.Key("server.port")), Destination: &port},
+			&StringFlag{Name: "host", Sources: NewValueSourceChain(EnvVar("APP_HOST"), cfg.Key("server.host")), Destination: &host},
+			&BoolFlag{Name: "debug", Sources: NewValueSourceChain(cfg.Key("debug")), Destination: &debug},
+			&StringFlag{Name: "region", Value: "eu", Sources: NewValueSourceChain(cfg.Key("region"))},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--debug=false"}))
+
+	assert.Equal(t, int64(8080), port, "config file over default")
+	assert.Equal(t, "env.example.com", host, "env var over config file")
+	assert.False(t, debug, "command line over config file")
+	assert.Equal(t, "eu", cmd.String("region"), "default without value in config file")
+	assert.Equal(t, path, cfg.Path())
+	assert.Equal(t, `key "server.port" of config file "`+path+`"`, cmd.Flags[0].(*IntFlag).valueSource().String())
+}
+
+func TestConfigFileValues(t *testing.T) {
+	cfg := JSONConfigFile(writeTestConfigFile(t, "config.json", `{
+		"tags": ["a", "b"],
+		"labels": {"team": "core", "tier": 1},
+		"ratio": 0.25,
+		"nested": {"dotted.key": "x"},
+		"empty": null
+	}`))
+
+	for key, expected := range map[string]string{
+		"tags":              "a,b",
+		"labels":            "team=core,tier=1",
+		"ratio":             "0.25",
+		"nested.dotted.key": "x",
+	} {
+		v, ok := cfg.Key(key).Lookup()
+		assert.True(t, ok, key)
+		assert.Equal(t, expected, v, key)
+	}
+
+	for _, key := range []string{"empty", "missing", "tags.missing", "nested.missing"} {
+		_, ok := cfg.Key(key).Lookup()
+		assert.False(t, ok, key)
+	}
+}
+
+func TestConfigFileErrors(t *testing.T) {
+	t.Run("missing", func(t *testing.T) {
+		cfg := JSONConfigFile(filepath.Join(t.TempDir(), "missing.json"))
+		_, ok := cfg.Key("port").Lookup()
+		assert.False(t, ok)
+		assert.NoError(t, cfg.Err())
+		assert.Empty(t, cfg.Path())
+	})
+
+	t.Run("broken", func(t *testing.T) {
+		path := writeTestConfigFile(t, "config.json", `{"port": `)
+		cfg := JSONConfigFile(path)
+
+		cmd := &Command{
+			Name: "app",
+			Flags: []Flag{
+				&IntFlag{Name: "port", Sources: NewValueSourceChain(cfg.Key("port"))},
+			},
+			Action: func(context.Context, *Command) error { return nil },
+		}
+
+		err := cmd.Run(buildTestContext(t), []string{"app"})
+		assert.EqualError(t, err, `could not look up value for flag port: could not decode config file "`+path+`": unexpected end of JSON input`)
+
+		// the config file is not needed for flags set on the command line
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--port", "1"}))
+		assert.Equal(t, int64(1), cmd.Int("port"))
+	})
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// dependency holds the constructor and the lazily constructed instance of a
+// value registered via Provide
+type dependency struct {
+	mu          sync.Mutex
+	constructor func(*Command) (any, error)
+	instance    any
+	constructed bool
+}
+
+// Provide registers a constructor for a dependency of type T on the given
+// command. The constructor is only called the first time the dependency is
+// requested via Get from this command or any of its subcommands. Any
+// constructed dependency implementing io.Closer is closed once the root
+// command has finished running.
+func Provide[T any](cmd *Command, constructor func(*Command) (T, error)) {
+	if cmd.dependencies == nil {
+		cmd.dependencies = map[reflect.Type]*dependency{}
+	}
+
+	cmd.dependencies[reflect.TypeOf((*T)(nil)).Elem()] = &dependency{
+		constructor: func(cmd *Command) (any, error) {
+			return constructor(cmd)
+		},
+	}
+}
+
+// Get returns the dependency of type T registered via Provide on the command
+// or one of its ancestors, constructing it on first use.
+func Get[T any](cmd *Command) (T, error) {
+	var t T
+
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+
+	for _, pCmd := range cmd.Lineage() {
+		dep, ok := pCmd.dependencies[typ]
+		if !ok {
+			continue
+		}
+
+		v, err := dep.get(cmd)
+		if err != nil {
+			return t, err
+		}
+
+		if v == nil {
+			return t, nil
+		}
+
+		return v.(T), nil
+	}
+
+	tracef("no dependency provided for type %[1]v (cmd=%[2]q)", typ, cmd.Name)
+
+	return t, fmt.Errorf("no dependency provided for type %v", typ)
+}
+
+func (d *dependency) get(cmd *Command) (any, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.constructed {
+		return d.instance, nil
+	}
+
+	v, err := d.constructor(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	d.instance = v
+	d.constructed = true
+
+	root := cmd.Root()
+	root.dependencyMu.Lock()
+	root.constructedDependencies = append(root.constructedDependencies, d)
+	root.dependencyMu.Unlock()
+
+	return v, nil
+}
+
+// closeDependencies closes all dependencies constructed during the run in
+// the reverse order of their construction and resets them so that they are
+// constructed anew on the next run.
+func (cmd *Command) closeDependencies() error {
+	cmd.dependencyMu.Lock()
+	deps := cmd.constructedDependencies
+	cmd.constructedDependencies = nil
+	cmd.dependencyMu.Unlock()
+
+	var errs []error
+
+	for i := len(deps) - 1; i >= 0; i-- {
+		d := deps[i]
+
+		d.mu.Lock()
+		if c, ok := d.instance.(io.Closer); ok {
+			tracef("closing dependency of type %[1]T (cmd=%[2]q)", d.instance, cmd.Name)
+
+			if err := c.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		d.instance = nil
+		d.constructed = false
+		d.mu.Unlock()
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	if len(errs) == 1 {
+		return errs[0]
+	}
+
+	return newMultiError(errs...)
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testDB struct {
+	dsn    string
+	closed bool
+}
+
+func (db *testDB) Close() error {
+	db.closed = true
+	return nil
+}
+
+func TestProvideAndGet(t *testing.T) {
+	var (
+		constructed int
+		db          *testDB
+	)
+
+	cmd := &Command{
+		Name: "app",
+		Flags: []Flag{
+			&StringFlag{Name: "dsn", Value: "sqlite://"},
+		},
+		Commands: []*Command{
+			{
+				Name: "migrate",
+				Action: func(_ context.Context, cmd *Command) error {
+					var err error
+					db, err = Get[*testDB](cmd)
+					if err != nil {
+						return err
+					}
+
+					again, err := Get[*testDB](cmd)
+					if err != nil {
+						return err
+					}
+
+					assert.Same(t, db, again)
+					assert.False(t, db.closed)
+
+					return nil
+				},
+			},
+		},
+	}
+
+	Provide(cmd, func(cmd *Command) (*testDB, error) {
+		constructed++
+		return &testDB{dsn: cmd.String("dsn")}, nil
+	})
+
+	r := require.New(t)
+	r.NoError(cmd.Run(buildTestContext(t), []string{"app", "--dsn", "pg://", "migrate"}))
+	r.Equal(1, constructed)
+	r.Equal("pg://", db.dsn)
+	r.True(db.closed)
+
+	r.NoError(cmd.Run(buildTestContext(t), []string{"app", "migrate"}))
+	r.Equal(2, constructed)
+}
+
+func TestProvideLazy(t *testing.T) {
+	constructed := false
+
+	cmd := &Command{
+		Name:   "app",
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	Provide(cmd, func(*Command) (*testDB, error) {
+		constructed = true
+		return &testDB{}, nil
+	})
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+	require.False(t, constructed)
+}
+
+func TestGetNotProvided(t *testing.T) {
+	cmd := &Command{
+		Name: "app",
+		Action: func(_ context.Context, cmd *Command) error {
+			_, err := Get[*testDB](cmd)
+			return err
+		},
+	}
+
+	err := cmd.Run(buildTestContext(t), []string{"app"})
+	require.ErrorContains(t, err, "no dependency provided for type *cli.testDB")
+}
+
+func TestGetConstructorError(t *testing.T) {
+	cmd := &Command{
+		Name: "app",
+		Action: func(_ context.Context, cmd *Command) error {
+			_, err := Get[*testDB](cmd)
+			return err
+		},
+	}
+
+	Provide(cmd, func(*Command) (*testDB, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	err := cmd.Run(buildTestContext(t), []string{"app"})
+	require.EqualError(t, err, "connection refused")
+}
@@ -0,0 +1,118 @@
+//go:build !urfave_cli_no_docs
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// docsFormats are the formats written by the docs command, the site
+// formats are written to a directory
+var docsFormats = []string{"man", "docusaurus", "mkdocs", "markdown", "tabular", "rst", "asciidoc", "html", "json", "yaml"}
+
+// NewDocsCommand returns a command writing the documentation of the tree of
+// the root command, to be mounted like
+//
+//	cmd.Commands = append(cmd.Commands, cli.NewDocsCommand())
+//
+// so `app docs --format man --output ./man` writes the man pages. The
+// docusaurus and mkdocs formats are written to the --output directory, the
+// man pages to the --output directory or, without one, the man page of the
+// root command to the writer of the root command. The others are written to
+// the --output file or the writer of the root command.
+func NewDocsCommand() *Command {
+	return &Command{
+		Name:  "docs",
+		Usage: "Generate the documentation",
+		Flags: []Flag{
+			&ChoiceFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Usage:   "format of the documentation",
+				Value:   "man",
+				Config:  ChoiceConfig{Choices: docsFormats},
+			},
+			&StringFlag{
+				Name:      "output",
+				Aliases:   []string{"o"},
+				Usage:     "directory or file to write the documentation to",
+				TakesFile: true,
+			},
+			&IntFlag{
+				Name:  "section",
+				Usage: "section of the man pages",
+				Value: 1,
+			},
+		},
+		Action: docsCommandAction,
+	}
+}
+
+func docsCommandAction(_ context.Context, cmd *Command) error {
+	root := cmd.Root()
+	format := cmd.String("format")
+	output := cmd.String("output")
+
+	switch format {
+	case "docusaurus", "mkdocs":
+		if output == "" {
+			return Exit(fmt.Sprintf("the %s format requires an --output directory", format), 1)
+		}
+	}
+
+	var site *DocsSite
+	var err error
+	switch format {
+	case "man":
+		if output != "" {
+			return root.ToManPages(output, int(cmd.Int("section")))
+		}
+	case "docusaurus":
+		site, err = root.ToDocusaurus()
+	case "mkdocs":
+		site, err = root.ToMkDocs()
+	}
+	if err != nil {
+		return err
+	}
+	if site != nil {
+		return site.Save(output)
+	}
+
+	// the other formats are streamed to the file or the writer
+	write := map[string]func(io.Writer) error{
+		"man": func(w io.Writer) error {
+			return root.ToManToWriter(w, int(cmd.Int("section")))
+		},
+		"markdown": root.ToMarkdownToWriter,
+		"tabular": func(w io.Writer) error {
+			return root.ToTabularToWriter("", w)
+		},
+		"rst":      root.ToReStructuredTextToWriter,
+		"asciidoc": root.ToAsciiDocToWriter,
+		"html":     root.ToHTMLToWriter,
+		"json":     root.ToJSONToWriter,
+		"yaml":     root.ToYAMLToWriter,
+	}[format]
+
+	if output == "" {
+		return write(root.Writer)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,96 @@
+//go:build !urfave_cli_no_docs
+
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildDocsCommandTestCommand(out *bytes.Buffer) *Command {
+	return &Command{
+		Name:     "app",
+		Version:  "v1.0.0",
+		Writer:   out,
+		Commands: []*Command{{Name: "serve", Usage: "serve the app"}, NewDocsCommand()},
+	}
+}
+
+func TestNewDocsCommand(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("man", func(t *testing.T) {
+		cmd := buildDocsCommandTestCommand(&bytes.Buffer{})
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "docs", "--format", "man", "--output", filepath.Join(dir, "man"), "--section", "8"}))
+		assert.FileExists(t, filepath.Join(dir, "man", "app.8.gz"))
+		assert.FileExists(t, filepath.Join(dir, "man", "app-serve.8.gz"))
+	})
+
+	t.Run("site", func(t *testing.T) {
+		cmd := buildDocsCommandTestCommand(&bytes.Buffer{})
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "docs", "-f", "mkdocs", "-o", filepath.Join(dir, "mkdocs")}))
+		assert.FileExists(t, filepath.Join(dir, "mkdocs", "nav.yml"))
+		assert.FileExists(t, filepath.Join(dir, "mkdocs", "app", "serve.md"))
+	})
+
+	t.Run("writer", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		cmd := buildDocsCommandTestCommand(out)
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "docs", "--format", "json"}))
+		expected, err := cmd.ToJSON()
+		require.NoError(t, err)
+		assert.Equal(t, expected, out.String())
+	})
+
+	t.Run("file", func(t *testing.T) {
+		cmd := buildDocsCommandTestCommand(&bytes.Buffer{})
+		file := filepath.Join(dir, "docs", "app.rst")
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "docs", "--format", "rst", "--output", file}))
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "serve the app")
+	})
+
+	t.Run("man writer", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		cmd := buildDocsCommandTestCommand(out)
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "docs", "--section", "8"}))
+		expected, err := cmd.ToMan(8)
+		require.NoError(t, err)
+		assert.Equal(t, expected, out.String())
+	})
+
+	for format, render := range map[string]func(cmd *Command) (string, error){
+		"markdown": (*Command).ToMarkdown,
+		"tabular": func(cmd *Command) (string, error) {
+			return cmd.ToTabularMarkdown("")
+		},
+	} {
+		t.Run(format+" writer", func(t *testing.T) {
+			out := &bytes.Buffer{}
+			cmd := buildDocsCommandTestCommand(out)
+			require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "docs", "--format", format}))
+			expected, err := render(cmd)
+			require.NoError(t, err)
+			assert.Equal(t, expected, out.String())
+			assert.Contains(t, out.String(), "serve the app")
+		})
+	}
+
+	t.Run("missing output", func(t *testing.T) {
+		cmd := buildDocsCommandTestCommand(&bytes.Buffer{})
+		err := cmd.Run(buildTestContext(t), []string{"app", "docs", "--format", "mkdocs"})
+		assert.EqualError(t, err, "the mkdocs format requires an --output directory")
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		cmd := buildDocsCommandTestCommand(&bytes.Buffer{})
+		err := cmd.Run(buildTestContext(t), []string{"app", "docs", "--format", "pdf"})
+		assert.ErrorContains(t, err, "must be one of man, docusaurus, mkdocs, markdown, tabular, rst, asciidoc, html, json, yaml")
+	})
+}
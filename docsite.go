@@ -0,0 +1,698 @@
+//go:build !urfave_cli_no_docs
+
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// DocsSite is the documentation of a command tree rendered for a static
+// site generator, consisting of a markdown page per visible command and the
+// navigation metadata of the generator.
+type DocsSite struct {
+	// Files of the site keyed by their slash separated path relative to
+	// the docs directory of the site
+	Files map[string]string
+}
+
+// Paths returns the sorted paths of the files of the site
+func (s *DocsSite) Paths() []string {
+	paths := make([]string, 0, len(s.Files))
+	for p := range s.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Save writes the files of the site below the given directory, creating
+// missing directories on the way.
+func (s *DocsSite) Save(dir string) error {
+	for _, p := range s.Paths() {
+		name := filepath.Join(dir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(name, []byte(s.Files[p]), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// docsPage is a visible command of the tree together with its position
+type docsPage struct {
+	cmd      *Command
+	parent   *docsPage
+	names    []string
+	path     string
+	position int
+	children []*docsPage
+}
+
+// docsCategory is a category of the subcommands of a page
+type docsCategory struct {
+	name        string
+	description string
+	pages       []*docsPage
+}
+
+// categories returns the pages of the subcommands grouped by their
+// categories in the order of the help output
+func (p *docsPage) categories() []docsCategory {
+	var lineage []*Command
+	for q := p; q != nil; q = q.parent {
+		lineage = append(lineage, q.cmd)
+	}
+
+	pages := map[*Command]*docsPage{}
+	cmds := make([]*Command, 0, len(p.children))
+	for _, child := range p.children {
+		pages[child.cmd] = child
+		cmds = append(cmds, child.cmd)
+	}
+
+	var ret []docsCategory
+	for _, category := range *categorize(lineage, cmds) {
+		dc := docsCategory{name: category.name, description: category.description}
+		for _, cmd := range category.commands {
+			dc.pages = append(dc.pages, pages[cmd])
+		}
+		ret = append(ret, dc)
+	}
+	return ret
+}
+
+// ToDocusaurus renders the command tree as Docusaurus docs. Every visible
+// command becomes a markdown page with front matter, commands with
+// subcommands become a directory with an index page and a _category_.json
+// so the autogenerated sidebar mirrors the command tree. The site is rooted
+// in a directory named after the command, ready to be copied into the docs
+// (or versioned_docs) directory of the site.
+func (cmd *Command) ToDocusaurus() (*DocsSite, error) {
+	cmd.loadCommands()
+
+	root := newDocsPage(cmd, nil, 1)
+	site := &DocsSite{Files: renderDocsPages(root, runtime.GOMAXPROCS(0), func(p *docsPage) string {
+		return docusaurusFrontMatter(p) + p.markdown()
+	})}
+
+	var add func(p *docsPage) error
+	add = func(p *docsPage) error {
+
+		if len(p.children) > 0 {
+			category, err := json.MarshalIndent(map[string]any{
+				"label":    p.cmd.Name,
+				"position": p.position,
+				"link": map[string]string{
+					"type": "doc",
+					"id":   strings.TrimSuffix(p.path, ".md"),
+				},
+			}, "", "  ")
+			if err != nil {
+				return err
+			}
+			site.Files[path.Join(path.Dir(p.path), "_category_.json")] = string(category) + "\n"
+		}
+
+		for _, child := range p.children {
+			if err := add(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := add(root); err != nil {
+		return nil, err
+	}
+	return site, nil
+}
+
+// ToMkDocs renders the command tree as MkDocs docs. Every visible command
+// becomes a markdown page, the navigation is written to nav.yml which can be
+// pasted into (or included from) mkdocs.yml.
+func (cmd *Command) ToMkDocs() (*DocsSite, error) {
+	cmd.loadCommands()
+
+	root := newDocsPage(cmd, nil, 1)
+	site := &DocsSite{Files: renderDocsPages(root, runtime.GOMAXPROCS(0), func(p *docsPage) string {
+		return mkdocsFrontMatter(p) + p.markdown()
+	})}
+
+	var nav strings.Builder
+	nav.WriteString("nav:\n")
+
+	var add func(p *docsPage, depth int)
+	add = func(p *docsPage, depth int) {
+		indent := strings.Repeat("  ", depth)
+		if len(p.children) == 0 {
+			fmt.Fprintf(&nav, "%s- %s: %s\n", indent, yamlString(p.cmd.Name), p.path)
+			return
+		}
+
+		fmt.Fprintf(&nav, "%s- %s:\n", indent, yamlString(p.cmd.Name))
+		fmt.Fprintf(&nav, "%s  - %s\n", indent, p.path)
+		for _, child := range p.children {
+			add(child, depth+1)
+		}
+	}
+
+	add(root, 1)
+	site.Files["nav.yml"] = nav.String()
+
+	return site, nil
+}
+
+// ToMarkdown renders the page of the command alone like the pages of
+// ToMkDocs without front matter, e.g. for sites with a page per command.
+// The page documents the persistent flags inherited from the ancestors as
+// global options, which are known for subcommands looked up with Command,
+// e.g. root.Command("config").Command("set"), or returned by Parse.
+func (cmd *Command) ToMarkdown() (string, error) {
+	var b strings.Builder
+	if err := cmd.ToMarkdownToWriter(&b); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// ToMarkdownToWriter writes the page of ToMarkdown to the writer while
+// rendering it
+func (cmd *Command) ToMarkdownToWriter(w io.Writer) error {
+	cmd.loadCommands()
+
+	return newLineageDocsPage(cmd).writeMarkdown(w)
+}
+
+// newLineageDocsPage returns the page of the command and its subcommands
+// below the pages of its ancestors, which lack the pages of their other
+// subcommands
+func newLineageDocsPage(cmd *Command) *docsPage {
+	var parent *docsPage
+	lineage := cmd.Lineage()
+	for i := len(lineage) - 1; i > 0; i-- {
+		p := &docsPage{cmd: lineage[i], parent: parent, names: []string{lineage[i].Name}}
+		if parent != nil {
+			p.names = append(append([]string{}, parent.names...), lineage[i].Name)
+		}
+		parent = p
+	}
+
+	return newDocsPage(cmd, parent, 1)
+}
+
+func newDocsPage(cmd *Command, parent *docsPage, position int) *docsPage {
+	p := &docsPage{cmd: cmd, parent: parent, position: position}
+	// the relations of grouped flags and the env vars derived from the env
+	// prefix of the root are documented without a run
+	cmd.setupFlagGroups()
+	root := p
+	for root.parent != nil {
+		root = root.parent
+	}
+	cmd.setupEnvPrefix(root.cmd.EnvPrefix)
+
+	if parent == nil {
+		p.names = []string{cmd.Name}
+	} else {
+		p.names = append(append([]string{}, parent.names...), cmd.Name)
+	}
+
+	dir := path.Join(p.names[:len(p.names)-1]...)
+	children := docsCommands(cmd)
+	if parent == nil || len(children) > 0 {
+		p.path = path.Join(dir, cmd.Name, "index.md")
+	} else {
+		p.path = path.Join(dir, cmd.Name+".md")
+	}
+
+	for i, child := range children {
+		p.children = append(p.children, newDocsPage(child, p, i+1))
+	}
+
+	return p
+}
+
+// renderDocsPages renders the pages of the tree concurrently by the given
+// number of workers, since large trees take long to render page by page.
+// The pages are keyed by their path, so the result doesn't depend on the
+// order in which the workers finish.
+func renderDocsPages(root *docsPage, workers int, render func(*docsPage) string) map[string]string {
+	var pages []*docsPage
+	var collect func(p *docsPage)
+	collect = func(p *docsPage) {
+		pages = append(pages, p)
+		for _, child := range p.children {
+			collect(child)
+		}
+	}
+	collect(root)
+
+	rendered := make([]string, len(pages))
+	if workers > len(pages) {
+		workers = len(pages)
+	}
+
+	if workers <= 1 {
+		for i, p := range pages {
+			rendered[i] = render(p)
+		}
+	} else {
+		indexes := make(chan int)
+		var wg sync.WaitGroup
+		wg.Add(workers)
+		for w := 0; w < workers; w++ {
+			go func() {
+				defer wg.Done()
+				for i := range indexes {
+					rendered[i] = render(pages[i])
+				}
+			}()
+		}
+		for i := range pages {
+			indexes <- i
+		}
+		close(indexes)
+		wg.Wait()
+	}
+
+	files := make(map[string]string, len(pages))
+	for i, p := range pages {
+		files[p.path] = rendered[i]
+	}
+	return files
+}
+
+// docsCommands returns the subcommands of the command to be documented
+func docsCommands(cmd *Command) []*Command {
+	var ret []*Command
+	for _, sub := range cmd.VisibleCommands() {
+		if !sub.isHelpCommand {
+			ret = append(ret, sub)
+		}
+	}
+	return ret
+}
+
+func (p *docsPage) title() string {
+	return strings.Join(p.names, " ")
+}
+
+// usageText returns the usage text of the command or the synopsis of its
+// flags, subcommands and arguments if it has none
+func (p *docsPage) usageText() string {
+	if usage := strings.TrimSpace(p.cmd.UsageText); usage != "" {
+		return usage
+	}
+
+	return strings.Join(append([]string{p.title()}, p.synopsis()...), " ")
+}
+
+// synopsis returns the parts of the synopsis following the title. The
+// single character flags without values which can be combined with short
+// option handling are listed POSIX style, e.g. [-lv].
+func (p *docsPage) synopsis() []string {
+	cmd := p.cmd
+
+	var synopsis []string
+	if p.useShortOptionHandling() {
+		var cluster []string
+		for _, fl := range cmd.VisibleFlags() {
+			if df, ok := fl.(DocGenerationFlag); !ok || df.TakesValue() {
+				continue
+			}
+			for _, name := range fl.Names() {
+				if utf8.RuneCountInString(name) == 1 {
+					cluster = append(cluster, name)
+				}
+			}
+		}
+		if len(cluster) > 1 {
+			sort.Strings(cluster)
+			synopsis = append(synopsis, "[-"+strings.Join(cluster, "")+"]")
+		}
+	}
+	if len(cmd.VisibleFlags()) > 0 {
+		synopsis = append(synopsis, "[options]")
+	}
+	if len(p.children) > 0 {
+		synopsis = append(synopsis, "[command [command options]]")
+	}
+	if cmd.ArgsUsage != "" {
+		synopsis = append(synopsis, cmd.ArgsUsage)
+	} else if len(cmd.Arguments) > 0 {
+		synopsis = append(synopsis, cmd.argumentsUsage())
+	}
+	if cmd.EnablePassthroughArgs {
+		synopsis = append(synopsis, "[-- ARGS...]")
+	}
+	return synopsis
+}
+
+// docsArgument is the data of a documented argument of a command
+type docsArgument struct {
+	Name        string
+	Usage       string
+	Description string
+	Type        string
+	Min         int
+	Max         int
+}
+
+// docsArguments returns the documented arguments of the command
+func docsArguments(cmd *Command) []docsArgument {
+	var args []docsArgument
+	for _, arg := range cmd.Arguments {
+		da, ok := arg.(DocGenerationArgument)
+		if !ok {
+			continue
+		}
+		a := docsArgument{
+			Name:        da.GetName(),
+			Usage:       da.Usage(),
+			Description: da.GetDescription(),
+			Type:        da.GetTypeName(),
+		}
+		a.Min, a.Max = da.GetOccurrences()
+		args = append(args, a)
+	}
+	return args
+}
+
+// useShortOptionHandling is like Command.useShortOptionHandling for the
+// commands of the pages, which have no parents without a run
+func (p *docsPage) useShortOptionHandling() bool {
+	for page := p; page != nil; page = page.parent {
+		if page.cmd.UseShortOptionHandling {
+			return true
+		}
+	}
+
+	return false
+}
+
+// markdown renders the page of the command in Markdown
+func (p *docsPage) markdown() string {
+	var b strings.Builder
+	_ = p.writeMarkdown(&b)
+	return b.String()
+}
+
+// writeMarkdown writes the page of the command in Markdown to the writer
+func (p *docsPage) writeMarkdown(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	cmd := p.cmd
+
+	fmt.Fprintf(b, "# %s\n\n", p.title())
+
+	if cmd.Usage != "" {
+		fmt.Fprintf(b, "%s\n\n", cmd.Usage)
+	}
+
+	if len(p.names) == 1 && cmd.Version != "" {
+		fmt.Fprintf(b, "Version: %s\n\n", cmd.Version)
+	}
+
+	if cmd.Description != "" {
+		fmt.Fprintf(b, "%s\n\n", strings.TrimSpace(cmd.Description))
+	}
+
+	if len(cmd.Aliases) > 0 {
+		fmt.Fprintf(b, "Aliases: `%s`\n\n", strings.Join(cmd.Aliases, "`, `"))
+	}
+
+	if cmd.Since != "" {
+		fmt.Fprintf(b, "Since: %s\n\n", cmd.Since)
+	}
+
+	b.WriteString("## Usage\n\n```\n")
+	b.WriteString(p.usageText())
+	b.WriteString("\n```\n")
+
+	if args := docsArguments(cmd); len(args) > 0 {
+		b.WriteString("\n## Arguments\n\n")
+		for _, arg := range args {
+			fmt.Fprintf(b, "- `%s`", arg.Usage)
+			if arg.Description != "" {
+				b.WriteString(": " + arg.Description)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if len(cmd.Examples) > 0 {
+		b.WriteString("\n## Examples\n")
+		for _, ex := range cmd.Examples {
+			if ex.Description != "" {
+				fmt.Fprintf(b, "\n%s\n", ex.Description)
+			}
+			fmt.Fprintf(b, "\n```\n$ %s\n", ex.Command)
+			if output := strings.TrimSpace(ex.Output); output != "" {
+				b.WriteString(output + "\n")
+			}
+			b.WriteString("```\n")
+		}
+	}
+
+	if flags := cmd.VisibleFlags(); len(flags) > 0 {
+		b.WriteString("\n## Options\n\n")
+		for _, fl := range flags {
+			b.WriteString(docsFlag(fl))
+		}
+	}
+
+	if flags := cmd.VisiblePersistentFlags(); len(flags) > 0 {
+		b.WriteString("\n## Global Options\n\n")
+		for _, fl := range flags {
+			b.WriteString(docsFlag(fl))
+		}
+	}
+
+	if len(p.children) > 0 {
+		b.WriteString("\n## Commands\n")
+		dir := path.Dir(p.path)
+		for i, category := range p.categories() {
+			if category.name != "" {
+				fmt.Fprintf(b, "\n### %s\n", category.name)
+				if category.description != "" {
+					fmt.Fprintf(b, "\n%s\n", category.description)
+				}
+			}
+			if category.name != "" || i == 0 {
+				b.WriteString("\n")
+			}
+			for _, child := range category.pages {
+				link := strings.TrimPrefix(child.path, dir+"/")
+				fmt.Fprintf(b, "- [%s](%s)", child.cmd.Name, link)
+				if child.cmd.Usage != "" {
+					fmt.Fprintf(b, ": %s", child.cmd.Usage)
+				}
+				b.WriteString("\n")
+			}
+		}
+	}
+
+	if len(cmd.SeeAlso) > 0 {
+		b.WriteString("\n## See Also\n\n")
+		for _, ref := range cmd.SeeAlso {
+			fmt.Fprintf(b, "- %s\n", ref)
+		}
+	}
+
+	return b.Flush()
+}
+
+// docsTemplateCommand is the data of a command in the templates of the
+// HTML and AsciiDoc docs
+type docsTemplateCommand struct {
+	ID          string
+	Name        string
+	Title       string
+	Level       int
+	Usage       string
+	UsageText   string
+	Description string
+	Version     string
+	Since       string
+	Aliases     []string
+	Examples    []Example
+	SeeAlso     []string
+	Arguments   []docsArgument
+	Flags       []docsTemplateFlag
+	Commands    []docsTemplateCommand
+}
+
+// docsTemplateFlag is the data of a flag in the templates of the HTML and
+// AsciiDoc docs
+type docsTemplateFlag struct {
+	ID        string
+	Names     string
+	Usage     string
+	Choices   []string
+	Default   string
+	EnvVars   []string
+	Requires  []string
+	Conflicts []string
+	Examples  []string
+	Since     string
+	SeeAlso   []string
+}
+
+func newDocsTemplateCommand(p *docsPage) docsTemplateCommand {
+	cmd := p.cmd
+	c := docsTemplateCommand{
+		ID:          strings.Join(p.names, "-"),
+		Name:        cmd.Name,
+		Title:       p.title(),
+		Level:       len(p.names) - 1,
+		Usage:       cmd.Usage,
+		UsageText:   p.usageText(),
+		Description: strings.TrimSpace(cmd.Description),
+		Since:       cmd.Since,
+		Aliases:     cmd.Aliases,
+		SeeAlso:     cmd.SeeAlso,
+		Arguments:   docsArguments(cmd),
+	}
+
+	for _, ex := range cmd.Examples {
+		ex.Output = strings.TrimSpace(ex.Output)
+		c.Examples = append(c.Examples, ex)
+	}
+
+	if len(p.names) == 1 {
+		c.Version = cmd.Version
+	}
+
+	for _, fl := range cmd.VisibleFlags() {
+		f := newDocsTemplateFlag(fl)
+		f.ID = c.ID + "-" + fl.Names()[0]
+		c.Flags = append(c.Flags, f)
+	}
+
+	for _, child := range p.children {
+		c.Commands = append(c.Commands, newDocsTemplateCommand(child))
+	}
+
+	return c
+}
+
+func newDocsTemplateFlag(fl Flag) docsTemplateFlag {
+	r := newRSTFlag(fl)
+	f := docsTemplateFlag{
+		Names:     r.Names,
+		Usage:     r.Usage,
+		Choices:   r.Choices,
+		Default:   r.Default,
+		EnvVars:   r.EnvVars,
+		Requires:  r.Requires,
+		Conflicts: r.Conflicts,
+	}
+	if mf, ok := fl.(DocMetadataFlag); ok {
+		f.Examples = mf.GetExamples()
+		f.Since = mf.GetSince()
+		f.SeeAlso = mf.GetSeeAlso()
+	}
+	return f
+}
+
+// docsFlag renders a flag as markdown list item
+func docsFlag(fl Flag) string {
+	df, ok := fl.(DocGenerationFlag)
+	if !ok {
+		return fmt.Sprintf("- `%s`\n", prefixedNames(fl.Names(), ""))
+	}
+
+	placeholder, usage := unquoteUsage(df.GetUsage())
+	if df.TakesValue() && placeholder == "" {
+		placeholder = defaultPlaceholder
+	}
+	if !df.TakesValue() {
+		placeholder = ""
+	}
+
+	item := fmt.Sprintf("- `%s`", prefixedNames(flagDocNames(fl), placeholder))
+	if usage != "" {
+		item += ": " + usage
+	}
+	if cf, ok := fl.(ChoicesFlag); ok {
+		if choices := cf.GetChoices(); len(choices) > 0 {
+			item += fmt.Sprintf(" (one of: `%s`)", strings.Join(choices, "`, `"))
+		}
+	}
+	if lf, ok := fl.(LayoutsFlag); ok {
+		if layouts := lf.GetLayouts(); len(layouts) == 1 {
+			item += fmt.Sprintf(" (format: `%s`)", layouts[0])
+		} else if len(layouts) > 1 {
+			item += fmt.Sprintf(" (formats: `%s`)", strings.Join(layouts, "`, `"))
+		}
+	}
+	if s := df.GetDefaultText(); s != "" {
+		item += fmt.Sprintf(" (default: `%s`)", s)
+	}
+	if envVars := df.GetEnvVars(); len(envVars) > 0 {
+		item += fmt.Sprintf(" [env: `%s`]", strings.Join(envVars, "`, `"))
+	}
+	if rf, ok := fl.(RelatedFlag); ok {
+		if names := rf.RequiresFlags(); len(names) > 0 {
+			item += fmt.Sprintf(" (requires `%s`)", prefixedNames(names, ""))
+		}
+		if names := rf.ConflictingFlags(); len(names) > 0 {
+			item += fmt.Sprintf(" (conflicts with `%s`)", prefixedNames(names, ""))
+		}
+	}
+	if mf, ok := fl.(DocMetadataFlag); ok {
+		if examples := mf.GetExamples(); len(examples) > 0 {
+			item += fmt.Sprintf(" (e.g. `%s`)", strings.Join(examples, "`, `"))
+		}
+		if since := mf.GetSince(); since != "" {
+			item += " (since " + since + ")"
+		}
+		if refs := mf.GetSeeAlso(); len(refs) > 0 {
+			item += " (see also: " + strings.Join(refs, ", ") + ")"
+		}
+	}
+
+	return item + "\n"
+}
+
+func docusaurusFrontMatter(p *docsPage) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "title: %s\n", yamlString(p.title()))
+	fmt.Fprintf(&b, "sidebar_label: %s\n", yamlString(p.cmd.Name))
+	fmt.Fprintf(&b, "sidebar_position: %d\n", p.position)
+	if p.cmd.Usage != "" {
+		fmt.Fprintf(&b, "description: %s\n", yamlString(p.cmd.Usage))
+	}
+	b.WriteString("---\n\n")
+	return b.String()
+}
+
+func mkdocsFrontMatter(p *docsPage) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "title: %s\n", yamlString(p.title()))
+	if p.cmd.Usage != "" {
+		fmt.Fprintf(&b, "description: %s\n", yamlString(p.cmd.Usage))
+	}
+	b.WriteString("---\n\n")
+	return b.String()
+}
+
+// yamlString quotes the string as YAML scalar, JSON strings are valid YAML
+func yamlString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,230 @@
+//go:build !urfave_cli_no_docs
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToDocusaurus(t *testing.T) {
+	cmd := buildExtendedTestCommand()
+
+	site, err := cmd.ToDocusaurus()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"greet/_category_.json",
+		"greet/config/_category_.json",
+		"greet/config/index.md",
+		"greet/config/sub-config.md",
+		"greet/index.md",
+		"greet/info.md",
+		"greet/some-command.md",
+		"greet/usage/_category_.json",
+		"greet/usage/index.md",
+		"greet/usage/sub-usage.md",
+	}, site.Paths())
+
+	expectFileContent(t, "testdata/expected-docusaurus-index.md", site.Files["greet/index.md"])
+	expectFileContent(t, "testdata/expected-docusaurus-category.json", site.Files["greet/config/_category_.json"])
+}
+
+func TestToMkDocs(t *testing.T) {
+	cmd := buildExtendedTestCommand()
+
+	site, err := cmd.ToMkDocs()
+	require.NoError(t, err)
+
+	require.Contains(t, site.Files, "greet/config/sub-config.md")
+	expectFileContent(t, "testdata/expected-mkdocs-nav.yml", site.Files["nav.yml"])
+	expectFileContent(t, "testdata/expected-mkdocs-sub-config.md", site.Files["greet/config/sub-config.md"])
+}
+
+func TestDocsSiteSave(t *testing.T) {
+	site, err := buildExtendedTestCommand().ToMkDocs()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, site.Save(dir))
+
+	data, err := os.ReadFile(filepath.Join(dir, "greet", "usage", "sub-usage.md"))
+	require.NoError(t, err)
+	assert.Equal(t, site.Files["greet/usage/sub-usage.md"], string(data))
+}
+
+func TestLazyCommandDocs(t *testing.T) {
+	var built []string
+	cmd := buildLazyTestCommand(&built, &bytes.Buffer{})
+
+	site, err := cmd.ToMkDocs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"users", "orders", "invoices"}, built)
+	assert.Contains(t, site.Files, "api/invoices/get.md")
+}
+
+func TestRenderDocsPagesConcurrently(t *testing.T) {
+	root := newDocsPage(buildGeneratedTestCommand(200), nil, 1)
+	render := func(p *docsPage) string {
+		return mkdocsFrontMatter(p) + p.markdown()
+	}
+
+	sequential := renderDocsPages(root, 1, render)
+	require.Len(t, sequential, 201)
+	for _, workers := range []int{2, 8, 1000} {
+		assert.Equal(t, sequential, renderDocsPages(root, workers, render), "workers: %d", workers)
+	}
+}
+
+func BenchmarkRenderDocsPages(b *testing.B) {
+	root := newDocsPage(buildGeneratedTestCommand(1000), nil, 1)
+	render := func(p *docsPage) string {
+		return docusaurusFrontMatter(p) + p.markdown()
+	}
+
+	for _, workers := range []int{1, 4, 8} {
+		b.Run(fmt.Sprint(workers), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				renderDocsPages(root, workers, render)
+			}
+		})
+	}
+}
+
+func TestDocsCategories(t *testing.T) {
+	root := newDocsPage(buildCategoryTestCommand(), nil, 1)
+
+	assert.Contains(t, root.markdown(), `## Commands
+
+- [version](version.md): print the version
+
+### Cluster Management
+
+create and remove clusters
+
+- [delete](delete.md): delete a cluster
+- [create](create.md): create a cluster
+
+### Basic Commands
+
+- [get](get.md): display resources
+
+### Advanced Commands
+
+- [apply](apply.md): apply a configuration
+- [config](config/index.md): modify kubeconfig files
+`)
+
+	assert.Contains(t, root.man(1, ""), `.SH COMMANDS
+.TP
+.B version
+print the version
+.SS Cluster Management
+create and remove clusters
+.TP
+.B delete
+`)
+}
+
+func buildGlobalOptionsTestCommand() *Command {
+	return &Command{
+		Name:    "app",
+		Version: "v1.0.0",
+		Flags: []Flag{
+			&StringFlag{Name: "profile", Usage: "profile to use", Persistent: true},
+			&BoolFlag{Name: "verbose", Usage: "print more"},
+		},
+		Commands: []*Command{{
+			Name:  "config",
+			Usage: "manage the config",
+			Commands: []*Command{{
+				Name:   "set",
+				Usage:  "set a value",
+				Flags:  []Flag{&BoolFlag{Name: "force", Usage: "overwrite the value"}},
+				Action: func(context.Context, *Command) error { return nil },
+			}},
+		}},
+	}
+}
+
+func TestToMarkdown(t *testing.T) {
+	cmd := buildGlobalOptionsTestCommand()
+	sub, err := cmd.Parse(buildTestContext(t), []string{"app", "config", "set"})
+	require.NoError(t, err)
+
+	md, err := sub.ToMarkdown()
+	require.NoError(t, err)
+	assert.Equal(t, `# app config set
+
+set a value
+
+## Usage
+
+`+"```"+`
+app config set [options]
+`+"```"+`
+
+## Options
+
+- `+"`--force`"+`: overwrite the value (default: `+"`false`"+`)
+- `+"`--help, -h`"+`: show help (default: `+"`false`"+`)
+
+## Global Options
+
+- `+"`--profile value`"+`: profile to use
+`, md)
+
+	md, err = cmd.Commands[0].ToMarkdown()
+	require.NoError(t, err)
+	assert.Contains(t, md, "# app config\n")
+	assert.Contains(t, md, "- [set](set.md): set a value\n")
+
+	md, err = buildGlobalOptionsTestCommand().Command("config").Command("set").ToMarkdown()
+	require.NoError(t, err)
+	assert.Contains(t, md, "# app config set\n", "the ancestors are linked without a run")
+	assert.Contains(t, md, "app config set [options]\n")
+	assert.Contains(t, md, "## Global Options\n\n- `--profile value`: profile to use\n")
+}
+
+func TestDocsShortOptionSynopsis(t *testing.T) {
+	cmd := &Command{
+		Name:                   "app",
+		UseShortOptionHandling: true,
+		ArgsUsage:              "[file...]",
+		Flags: []Flag{
+			&BoolFlag{Name: "verbose", Aliases: []string{"v"}},
+			&BoolFlag{Name: "all", Aliases: []string{"a"}},
+			&BoolFlag{Name: "hidden", Aliases: []string{"x"}, Hidden: true},
+			&StringFlag{Name: "output", Aliases: []string{"o"}},
+		},
+		Commands: []*Command{{
+			Name: "list",
+			Flags: []Flag{
+				&BoolFlag{Name: "long", Aliases: []string{"l"}},
+				&CountFlag{Name: "recursive", Aliases: []string{"R"}},
+			},
+		}},
+	}
+
+	site, err := cmd.ToMkDocs()
+	require.NoError(t, err)
+	assert.Contains(t, site.Files["app/index.md"], "app [-av] [options] [command [command options]] [file...]\n")
+	assert.Contains(t, site.Files["app/list.md"], "app list [-Rl] [options]\n", "the setting is inherited")
+
+	man, err := cmd.ToMan(1)
+	require.NoError(t, err)
+	assert.Contains(t, man, ".SH SYNOPSIS\n.B app\n[\\-av] [options] [command [command options]] [file...]\n")
+
+	cmd.UseShortOptionHandling = false
+	site, err = cmd.ToMkDocs()
+	require.NoError(t, err)
+	assert.Contains(t, site.Files["app/list.md"], "app list [options]\n")
+}
@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DotEnv is an Environment reading variables from .env files, so local
+// development and containers configure the flags the same way:
+//
+//	cmd := &cli.Command{
+//		Env: cli.DotEnvFiles(".env.local", ".env"),
+//		Flags: []cli.Flag{
+//			&cli.IntFlag{Name: "port", Sources: cli.EnvVars("APP_PORT")},
+//		},
+//	}
+//
+// reads APP_PORT from the environment of the process, then from .env.local
+// and then from .env. The files are read before the flags are parsed, Key
+// returns a value source reading a variable from the files only.
+type DotEnv struct {
+	// Paths of the files, the variables of earlier files take precedence
+	// over the ones of later files. Missing files are skipped.
+	Paths []string
+	// Env the variables are looked up in before the files, defaults to the
+	// environment of the process
+	Env Environment
+
+	once  sync.Once
+	vars  map[string]string
+	paths map[string]string
+	err   error
+}
+
+// DotEnvFiles returns an environment reading the .env files in order of
+// precedence after the environment of the process
+func DotEnvFiles(paths ...string) *DotEnv {
+	return &DotEnv{Paths: paths}
+}
+
+// LookupEnv returns the value of the variable in Env or, if it isn't set
+// there, in the first file defining it
+func (d *DotEnv) LookupEnv(key string) (string, bool) {
+	env := d.Env
+	if env == nil {
+		env = osEnvironment{}
+	}
+	if v, ok := env.LookupEnv(key); ok {
+		return v, true
+	}
+
+	return d.lookupFiles(key)
+}
+
+// Environ returns the variables of Env followed by the ones of the files
+// which aren't set there, sorted by key
+func (d *DotEnv) Environ() []string {
+	env := d.Env
+	if env == nil {
+		env = osEnvironment{}
+	}
+	vars := environ(env)
+
+	d.load()
+	keys := make([]string, 0, len(d.vars))
+	for key := range d.vars {
+		if _, ok := env.LookupEnv(key); !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		vars = append(vars, key+"="+d.vars[key])
+	}
+	return vars
+}
+
+// Err returns the error reading or parsing the files, if any. Running a
+// command with the environment fails with this error before the flags are
+// parsed.
+func (d *DotEnv) Err() error {
+	d.load()
+	return d.err
+}
+
+// Key returns a value source looking up the variable in the files only,
+// ignoring the environment of the process
+func (d *DotEnv) Key(key string) ValueSource {
+	return &dotEnvValueSource{file: d, key: key}
+}
+
+func (d *DotEnv) lookupFiles(key string) (string, bool) {
+	d.load()
+	v, ok := d.vars[key]
+	return v, ok
+}
+
+func (d *DotEnv) load() {
+	d.once.Do(func() {
+		d.vars = map[string]string{}
+		d.paths = map[string]string{}
+
+		for _, path := range d.Paths {
+			data, err := os.ReadFile(path)
+			if os.IsNotExist(err) {
+				tracef("no .env file found at %[1]q", path)
+				continue
+			}
+			if err != nil {
+				d.err = err
+				return
+			}
+
+			vars, err := parseDotEnv(data)
+			if err != nil {
+				d.err = fmt.Errorf(tr("could not parse .env file %[1]q: %[2]v"), path, err)
+				return
+			}
+
+			for key, v := range vars {
+				if _, ok := d.vars[key]; !ok {
+					d.vars[key] = v
+					d.paths[key] = path
+				}
+			}
+		}
+	})
+}
+
+// parseDotEnv parses the KEY=value lines of a .env file. Lines may start
+// with export, blank lines and lines starting with # are ignored. Values
+// may be quoted: double quoted values are unquoted like Go strings, single
+// quoted ones are taken literally and unquoted ones end at a " #" comment.
+func parseDotEnv(data []byte) (map[string]string, error) {
+	vars := map[string]string{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if rest := strings.TrimPrefix(line, "export "); rest != line {
+			line = strings.TrimSpace(rest)
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %d: expected KEY=value", n)
+		}
+
+		value, err := parseDotEnvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		vars[key] = value
+	}
+
+	return vars, scanner.Err()
+}
+
+func parseDotEnvValue(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+
+	switch quote := s[0]; quote {
+	case '"', '\'':
+		end := strings.LastIndexByte(s, quote)
+		if end == 0 {
+			return "", fmt.Errorf("missing closing quote")
+		}
+		if rest := strings.TrimSpace(s[end+1:]); rest != "" && rest[0] != '#' {
+			return "", fmt.Errorf("unexpected %q after quoted value", rest)
+		}
+		if quote == '\'' {
+			return s[1:end], nil
+		}
+		return strconv.Unquote(s[:end+1])
+	}
+
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s), nil
+}
+
+// fallibleEnvironment is implemented by environments failing to be loaded,
+// like DotEnv with a broken file
+type fallibleEnvironment interface {
+	Environment
+	Err() error
+}
+
+// dotEnvValueSource encapsulates a ValueSource from a variable of .env files
+type dotEnvValueSource struct {
+	file *DotEnv
+	key  string
+}
+
+func (s *dotEnvValueSource) Lookup() (string, bool) {
+	return s.file.lookupFiles(s.key)
+}
+
+func (s *dotEnvValueSource) LookupWithError() (string, bool, error) {
+	if err := s.file.Err(); err != nil {
+		return "", false, err
+	}
+
+	v, ok := s.Lookup()
+	return v, ok, nil
+}
+
+func (s *dotEnvValueSource) String() string {
+	s.file.load()
+	return fmt.Sprintf("variable %[1]q of .env file %[2]q", s.key, s.file.paths[s.key])
+}
+
+func (s *dotEnvValueSource) GoString() string {
+	return fmt.Sprintf("&dotEnvValueSource{Paths:%[1]q,Key:%[2]q}", s.file.Paths, s.key)
+}
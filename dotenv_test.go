@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDotEnvPrecedence(t *testing.T) {
+	local := writeTestConfigFile(t, ".env.local", "APP_PORT=9090\n")
+	shared := writeTestConfigFile(t, ".env", "# shared defaults\nexport APP_PORT=8080\nAPP_HOST=example.com\nAPP_REGION=eu\nAPP_TOKEN=file-token\n")
+
+	env := DotEnvFiles(filepath.Join(t.TempDir(), "missing.env"), local, shared)
+	env.Env = MapEnv{"APP_HOST": "env.example.com"}
+
+	cmd := &Command{
+		Name: "app",
+		Env:  env,
+		Flags: []Flag{
+			&IntFlag{Name: "port", Sources: EnvVars("APP_PORT")},
+			&StringFlag{Name: "host", Sources: EnvVars("APP_HOST")},
+			&StringFlag{Name: "region", Sources: EnvVars("APP_REGION")},
+			&StringFlag{Name: "token", Sources: NewValueSourceChain(env.Key("APP_TOKEN"))},
+			&StringFlag{Name: "user", Value: "nobody", Sources: EnvVars("APP_USER")},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--region", "us"}))
+
+	assert.Equal(t, int64(9090), cmd.Int("port"), "earlier file over later file")
+	assert.Equal(t, "env.example.com", cmd.String("host"), "environment over files")
+	assert.Equal(t, "us", cmd.String("region"), "command line over files")
+	assert.Equal(t, "file-token", cmd.String("token"))
+	assert.Equal(t, "nobody", cmd.String("user"), "default without variable")
+	assert.Equal(t, `variable "APP_TOKEN" of .env file "`+shared+`"`, cmd.Flags[3].(*StringFlag).valueSource().String())
+
+	v, ok := cmd.LookupEnv("APP_REGION")
+	assert.True(t, ok)
+	assert.Equal(t, "eu", v, "actions look up the files too")
+}
+
+func TestDotEnvValues(t *testing.T) {
+	vars, err := parseDotEnv([]byte(`
+# comment
+PLAIN=value
+SPACED = spaced value  # trailing comment
+HASH=a#b
+EMPTY=
+DOUBLE="line\nbreak \"quoted\"" # comment
+SINGLE='literal \n $HOME'
+export EXPORTED=1
+`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"PLAIN":    "value",
+		"SPACED":   "spaced value",
+		"HASH":     "a#b",
+		"EMPTY":    "",
+		"DOUBLE":   "line\nbreak \"quoted\"",
+		"SINGLE":   `literal \n $HOME`,
+		"EXPORTED": "1",
+	}, vars)
+
+	for _, data := range []string{"NOVALUE", "=value", "BAD KEY=1", `OPEN="value`, `TRAILING="a" b`} {
+		_, err := parseDotEnv([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestDotEnvError(t *testing.T) {
+	path := writeTestConfigFile(t, ".env", "APP_PORT=8080\nbroken\n")
+
+	cmd := &Command{
+		Name:   "app",
+		Env:    DotEnvFiles(path),
+		Flags:  []Flag{&IntFlag{Name: "port", Sources: EnvVars("APP_PORT")}},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	err := cmd.Run(buildTestContext(t), []string{"app", "--port", "1"})
+	assert.EqualError(t, err, `could not parse .env file "`+path+`": line 2: expected KEY=value`)
+}
@@ -0,0 +1,32 @@
+package cli
+
+// DryRunFlag is the flag appended to every command of the graph when
+// EnableDryRun is set on the root command. Set to nil to disable the flag.
+var DryRunFlag Flag = &BoolFlag{
+	Name:  "dry-run",
+	Usage: "show what would be done without making any changes",
+}
+
+func (cmd *Command) ensureDryRun() {
+	if DryRunFlag == nil || !cmd.Root().EnableDryRun {
+		return
+	}
+
+	tracef("appending DryRunFlag (cmd=%[1]q)", cmd.Name)
+	cmd.appendFlag(DryRunFlag)
+}
+
+// DryRun returns true if the dry-run flag has been set on this command
+// or any of its ancestors or via the sources of the flag
+func (cmd *Command) DryRun() bool {
+	if DryRunFlag == nil {
+		return false
+	}
+
+	if v := cmd.lookupFlagValue(DryRunFlag.Names()); v != nil {
+		b, _ := valueAs[bool](v)
+		return b
+	}
+
+	return false
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		env      MapEnv
+		expected bool
+	}{
+		{
+			name: "not set",
+			args: []string{"app", "sub"},
+		},
+		{
+			name:     "set on root",
+			args:     []string{"app", "--dry-run", "sub"},
+			expected: true,
+		},
+		{
+			name:     "set on subcommand",
+			args:     []string{"app", "sub", "--dry-run"},
+			expected: true,
+		},
+		{
+			name:     "set via env",
+			args:     []string{"app", "sub"},
+			env:      MapEnv{"APP_DRY_RUN": "true"},
+			expected: true,
+		},
+		{
+			name: "env overridden on root",
+			args: []string{"app", "--dry-run=false", "sub"},
+			env:  MapEnv{"APP_DRY_RUN": "true"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var dryRun bool
+
+			cmd := &Command{
+				Name:         "app",
+				EnableDryRun: true,
+				EnvPrefix:    "APP",
+				Env:          test.env,
+				Commands: []*Command{
+					{
+						Name: "sub",
+						Action: func(_ context.Context, cmd *Command) error {
+							dryRun = cmd.DryRun()
+							return nil
+						},
+					},
+				},
+			}
+
+			require.NoError(t, cmd.Run(buildTestContext(t), test.args))
+			require.Equal(t, test.expected, dryRun)
+		})
+	}
+}
+
+func TestDryRunDisabled(t *testing.T) {
+	cmd := &Command{
+		Name:      "app",
+		Writer:    &bytes.Buffer{},
+		ErrWriter: &bytes.Buffer{},
+		Action: func(_ context.Context, cmd *Command) error {
+			require.False(t, cmd.DryRun())
+			return nil
+		},
+	}
+
+	require.Error(t, cmd.Run(buildTestContext(t), []string{"app", "--dry-run"}))
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+}
+
+func TestDryRunHelp(t *testing.T) {
+	out := &bytes.Buffer{}
+	cmd := &Command{
+		Name:         "app",
+		EnableDryRun: true,
+		Writer:       out,
+		Commands: []*Command{
+			{
+				Name:   "sub",
+				Action: func(context.Context, *Command) error { return nil },
+			},
+		},
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "sub", "--help"}))
+	require.Contains(t, out.String(), "--dry-run   show what would be done without making any changes")
+}
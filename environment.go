@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Clock provides the current time. It allows to pin the time in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts an ordinary function to the Clock interface
+type ClockFunc func() time.Time
+
+// Now calls f()
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// Environment looks up environment variables. It allows to fake the
+// environment in tests without modifying the environment of the process.
+//
+// Child processes like external commands and pagers get the variables
+// listed by an Environ() []string method of the environment. Environments
+// without one pass the variables of the process they define.
+type Environment interface {
+	LookupEnv(key string) (string, bool)
+}
+
+// MapEnv is an Environment holding the variables in a map
+type MapEnv map[string]string
+
+// LookupEnv returns the value of the variable and whether it is set
+func (m MapEnv) LookupEnv(key string) (string, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+// Environ returns the variables as key=value pairs sorted by key
+func (m MapEnv) Environ() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	vars := make([]string, 0, len(keys))
+	for _, key := range keys {
+		vars = append(vars, key+"="+m[key])
+	}
+	return vars
+}
+
+type osEnvironment struct{}
+
+func (osEnvironment) LookupEnv(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+func (osEnvironment) Environ() []string {
+	return os.Environ()
+}
+
+// listableEnvironment is implemented by environments listing their
+// variables for child processes
+type listableEnvironment interface {
+	Environ() []string
+}
+
+// environ returns the variables of the environment as key=value pairs for
+// child processes
+func environ(env Environment) []string {
+	if le, ok := env.(listableEnvironment); ok {
+		return le.Environ()
+	}
+
+	var vars []string
+	for _, kv := range os.Environ() {
+		key, _, _ := strings.Cut(kv, "=")
+		if v, ok := env.LookupEnv(key); ok {
+			vars = append(vars, key+"="+v)
+		}
+	}
+	return vars
+}
+
+// runtimeFlag is implemented by flags using the clock or environment of
+// the command they are applied to
+type runtimeFlag interface {
+	setRuntime(env Environment, clock Clock)
+}
+
+func (f *FlagBase[T, C, V]) setRuntime(env Environment, clock Clock) {
+	f.env = env
+	f.clock = clock
+}
+
+// envPrefixFlag is implemented by flags deriving the environment variable
+// they are read from from the EnvPrefix of the root command if they have
+// no sources
+type envPrefixFlag interface {
+	setEnvPrefix(prefix string)
+}
+
+func (f *FlagBase[T, C, V]) setEnvPrefix(prefix string) {
+	f.envPrefix = prefix
+}
+
+// sources returns the Sources of the flag or, if it has none, the
+// environment variable derived from the env prefix
+func (f *FlagBase[T, C, V]) sources() *ValueSourceChain {
+	if len(f.Sources.Chain) > 0 || f.envPrefix == "" {
+		return &f.Sources
+	}
+	vsc := EnvVars(prefixedEnvVar(f.envPrefix, f.Name))
+	return &vsc
+}
+
+// prefixedEnvVar returns the name of the environment variable of the flag
+// derived from the prefix, e.g. MYAPP_LOG_LEVEL for log-level
+func prefixedEnvVar(prefix, name string) string {
+	name = strings.NewReplacer("-", "_", ".", "_").Replace(name)
+	return strings.ToUpper(strings.TrimSuffix(prefix, "_") + "_" + name)
+}
+
+// setupEnvPrefix passes the env prefix to the flags of the command, except
+// for the help and version flags which aren't read from the environment
+func (cmd *Command) setupEnvPrefix(prefix string) {
+	for _, fl := range cmd.Flags {
+		cmd.prepareFlagEnvPrefix(fl, prefix)
+	}
+}
+
+func (cmd *Command) prepareFlagEnvPrefix(fl Flag, prefix string) {
+	if fl == HelpFlag || fl == VersionFlag {
+		return
+	}
+	if pf, ok := fl.(envPrefixFlag); ok {
+		pf.setEnvPrefix(prefix)
+	}
+}
+
+// Now returns the current time according to the Clock of the root command
+func (cmd *Command) Now() time.Time {
+	return cmd.clock().Now()
+}
+
+// LookupEnv looks up the environment variable in the Env of the root
+// command
+func (cmd *Command) LookupEnv(key string) (string, bool) {
+	return cmd.environment().LookupEnv(key)
+}
+
+func (cmd *Command) clock() Clock {
+	if clock := cmd.Root().Clock; clock != nil {
+		return clock
+	}
+
+	return ClockFunc(time.Now)
+}
+
+func (cmd *Command) environment() Environment {
+	if env := cmd.Root().Env; env != nil {
+		return env
+	}
+
+	return osEnvironment{}
+}
+
+// prepareFlag passes the clock and environment of the command to the flag
+// before it is applied
+func (cmd *Command) prepareFlag(fl Flag) {
+	if rf, ok := fl.(runtimeFlag); ok {
+		rf.setRuntime(cmd.environment(), cmd.clock())
+	}
+	cmd.prepareFlagEnvPrefix(fl, cmd.Root().EnvPrefix)
+}
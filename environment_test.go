@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandEnv(t *testing.T) {
+	t.Parallel()
+
+	for port, env := range map[int64]string{80: "80", 8080: "8080"} {
+		port, env := port, env
+
+		t.Run(env, func(t *testing.T) {
+			t.Parallel()
+
+			var got int64
+			var verbose, debug bool
+
+			cmd := &Command{
+				Name: "app",
+				Env:  MapEnv{"PORT": env, "VERBOSE": "true", "DEBUG": "1"},
+				Flags: []Flag{
+					&IntFlag{Name: "port", Sources: EnvVars("PORT"), Persistent: true},
+					&BoolWithInverseFlag{BoolFlag: &BoolFlag{Name: "verbose", Sources: EnvVars("VERBOSE")}},
+				},
+				Commands: []*Command{
+					{
+						Name: "serve",
+						Action: func(_ context.Context, cmd *Command) error {
+							got = cmd.Int("port")
+							verbose = cmd.Bool("verbose")
+							_, debug = cmd.LookupEnv("DEBUG")
+							return nil
+						},
+					},
+				},
+			}
+
+			require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "serve"}))
+			assert.Equal(t, port, got)
+			assert.True(t, verbose)
+			assert.True(t, debug)
+		})
+	}
+}
+
+func TestCommandEnvNotInProcess(t *testing.T) {
+	t.Setenv("NAME", "process")
+
+	cmd := &Command{
+		Name:  "app",
+		Env:   MapEnv{},
+		Flags: []Flag{&StringFlag{Name: "name", Sources: EnvVars("NAME"), Value: "default"}},
+	}
+
+	res, err := cmd.Resolve(buildTestContext(t), []string{"app"})
+	require.NoError(t, err)
+	assert.Equal(t, "default", res.Flags["name"].Value)
+}
+
+type lookupOnlyEnv MapEnv
+
+func (e lookupOnlyEnv) LookupEnv(key string) (string, bool) {
+	return MapEnv(e).LookupEnv(key)
+}
+
+func TestEnviron(t *testing.T) {
+	t.Setenv("CLI_TEST_SHARED", "process")
+	t.Setenv("CLI_TEST_PROCESS", "process")
+
+	assert.Equal(t, []string{"A=1", "B=2"}, environ(MapEnv{"B": "2", "A": "1"}))
+	assert.Contains(t, environ(osEnvironment{}), "CLI_TEST_PROCESS=process")
+
+	vars := environ(lookupOnlyEnv{"CLI_TEST_SHARED": "env", "CLI_TEST_ONLY": "env"})
+	assert.Equal(t, []string{"CLI_TEST_SHARED=env"}, vars, "only the variables of the process are looked up")
+
+	dotEnv := DotEnvFiles(writeTestConfigFile(t, ".env", "B=file\nC=file\n"))
+	dotEnv.Env = MapEnv{"A": "env", "B": "env"}
+	assert.Equal(t, []string{"A=env", "B=env", "C=file"}, environ(dotEnv))
+}
+
+func TestCommandClock(t *testing.T) {
+	t.Parallel()
+
+	pinned := time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)
+
+	var now, stamp, fromEnv time.Time
+
+	cmd := &Command{
+		Name:  "app",
+		Clock: ClockFunc(func() time.Time { return pinned }),
+		Env:   MapEnv{"SINCE": "now"},
+		Flags: []Flag{
+			&TimestampFlag{Name: "at", Config: TimestampConfig{Layout: time.RFC3339}},
+			&TimestampFlag{Name: "since", Sources: EnvVars("SINCE"), Config: TimestampConfig{Layout: time.RFC3339}},
+		},
+		Action: func(_ context.Context, cmd *Command) error {
+			now = cmd.Now()
+			stamp = cmd.Timestamp("at")
+			fromEnv = cmd.Timestamp("since")
+			return nil
+		},
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--at", "now"}))
+	assert.Equal(t, pinned, now)
+	assert.Equal(t, pinned, stamp)
+	assert.Equal(t, pinned, fromEnv)
+}
+
+func TestCommandEnvTerminal(t *testing.T) {
+	cmd := &Command{Env: MapEnv{"COLUMNS": "42", "NO_COLOR": ""}}
+
+	assert.Equal(t, 42, cmd.TerminalWidth())
+	assert.False(t, cmd.ColorSupported())
+}
+
+func TestCommandEnvPrefix(t *testing.T) {
+	buildCmd := func(out *bytes.Buffer) *Command {
+		return &Command{
+			Name:      "app",
+			EnvPrefix: "MYAPP",
+			Writer:    out,
+			Env: MapEnv{
+				"MYAPP_LOG_LEVEL": "debug",
+				"MYAPP_TOKEN":     "ignored",
+				"TOKEN":           "secret",
+				"MYAPP_PORT":      "8080",
+				"MYAPP_HELP":      "true",
+			},
+			Flags: []Flag{
+				&StringFlag{Name: "log-level", Value: "info"},
+				&StringFlag{Name: "token", Sources: EnvVars("TOKEN")},
+			},
+			Commands: []*Command{{
+				Name:   "serve",
+				Flags:  []Flag{&IntFlag{Name: "port"}},
+				Action: func(context.Context, *Command) error { return nil },
+			}},
+		}
+	}
+
+	cmd := buildCmd(&bytes.Buffer{})
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "serve"}))
+	assert.Equal(t, "debug", cmd.String("log-level"))
+	assert.Equal(t, "secret", cmd.String("token"), "explicit sources are kept")
+	assert.Equal(t, int64(8080), cmd.Commands[0].Int("port"))
+
+	out := &bytes.Buffer{}
+	require.NoError(t, buildCmd(out).Run(buildTestContext(t), []string{"app", "serve", "--help"}))
+	assert.Contains(t, out.String(), "--port value  (default: 0) [$MYAPP_PORT]")
+	assert.Contains(t, out.String(), "--help, -h    show help (default: false)\n", "the help flag isn't read from the environment")
+
+	assert.Equal(t, "MYAPP_LOG_LEVEL", prefixedEnvVar("myapp_", "log.level"))
+}
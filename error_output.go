@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrorFormatFlag is the flag appended to every command of the graph when
+// EnableErrorFormat is set on the root command. With "json" errors are
+// written to ErrWriter as a single JSON object per error instead of text.
+var ErrorFormatFlag Flag = &StringFlag{
+	Name:    "error-format",
+	Usage:   "set the error output `format` (text, json)",
+	Value:   "text",
+	Sources: EnvVars("CLI_ERROR_FORMAT"),
+	Validator: func(s string) error {
+		if s != "text" && s != "json" {
+			return fmt.Errorf("unknown error format %q", s)
+		}
+		return nil
+	},
+}
+
+// jsonError is the representation of an error written in json error format
+type jsonError struct {
+	Error      string `json:"error"`
+	Suggestion string `json:"suggestion,omitempty"`
+	Code       int    `json:"code"`
+	Command    string `json:"command"`
+}
+
+func (cmd *Command) ensureErrorFormat() {
+	if ErrorFormatFlag == nil || !cmd.Root().EnableErrorFormat {
+		return
+	}
+
+	tracef("appending ErrorFormatFlag (cmd=%[1]q)", cmd.Name)
+	cmd.appendFlag(ErrorFormatFlag)
+}
+
+// jsonErrors returns true if errors are to be written as json, either
+// requested by the flag on this command or one of its ancestors or via the
+// sources of the flag
+func (cmd *Command) jsonErrors() bool {
+	if ErrorFormatFlag == nil || !cmd.Root().EnableErrorFormat {
+		return false
+	}
+
+	if v := cmd.lookupFlagValue(ErrorFormatFlag.Names()); v != nil {
+		return v.String() == "json"
+	}
+
+	return false
+}
+
+// writeJSONError writes the final error of a run as json and exits with its
+// exit code unless the root command has an ExitErrHandler
+func (cmd *Command) writeJSONError(err error) {
+	root := cmd.Root()
+	code := exitCodeFromError(root.applyExitCodes(err))
+
+	jErr := jsonError{
+		Error:   err.Error(),
+		Code:    code,
+		Command: cmd.FullName(),
+	}
+	var cliErr *Error
+	if errors.As(err, &cliErr) {
+		jErr.Suggestion = cliErr.Suggestion
+	}
+
+	b, mErr := json.Marshal(jErr)
+	if mErr != nil {
+		tracef("unable to marshal error %[1]q: %[2]v (cmd=%[3]q)", err, mErr, cmd.Name)
+		return
+	}
+
+	_, _ = fmt.Fprintln(cmd.errWriter(), string(b))
+
+	if root.ExitErrHandler == nil {
+		root.exit(code)
+	}
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildErrorFormatTestCommand(errOut, out *bytes.Buffer) *Command {
+	return &Command{
+		Name:              "app",
+		EnableErrorFormat: true,
+		ErrWriter:         errOut,
+		Writer:            out,
+		Commands: []*Command{
+			{
+				Name: "deploy",
+				Flags: []Flag{
+					&StringFlag{Name: "env", Required: true},
+				},
+				Action: func(context.Context, *Command) error {
+					return Exit("deployment failed", 4)
+				},
+			},
+			{
+				Name: "check",
+				Action: func(context.Context, *Command) error {
+					return errors.New("check failed")
+				},
+			},
+			{
+				Name: "login",
+				Action: func(context.Context, *Command) error {
+					return &Error{Message: "token expired", Suggestion: "run 'app login --renew'", Code: 6}
+				},
+			},
+		},
+	}
+}
+
+func TestErrorFormatJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+		code     int
+	}{
+		{
+			name:     "exit coder",
+			args:     []string{"app", "--error-format", "json", "deploy", "--env", "prod"},
+			expected: `{"error":"deployment failed","code":4,"command":"app deploy"}`,
+			code:     4,
+		},
+		{
+			name:     "plain error",
+			args:     []string{"app", "check", "--error-format=json"},
+			expected: `{"error":"check failed","code":1,"command":"app check"}`,
+			code:     1,
+		},
+		{
+			name:     "error with suggestion",
+			args:     []string{"app", "--error-format", "json", "login"},
+			expected: `{"error":"token expired","suggestion":"run 'app login --renew'","code":6,"command":"app login"}`,
+			code:     6,
+		},
+		{
+			name:     "usage error",
+			args:     []string{"app", "--error-format", "json", "deploy", "--nope"},
+			expected: `{"error":"flag provided but not defined: -nope","code":1,"command":"app deploy"}`,
+			code:     1,
+		},
+		{
+			name:     "missing required flag",
+			args:     []string{"app", "--error-format", "json", "deploy"},
+			expected: `{"error":"Required flag \"env\" not set","code":1,"command":"app deploy"}`,
+			code:     1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			exitCode := -1
+			OsExiter = func(code int) { exitCode = code }
+			defer func() { OsExiter = fakeOsExiter }()
+
+			errOut, out := &bytes.Buffer{}, &bytes.Buffer{}
+			cmd := buildErrorFormatTestCommand(errOut, out)
+
+			require.Error(t, cmd.Run(buildTestContext(t), test.args))
+			assert.Equal(t, test.expected+"\n", errOut.String())
+			assert.Empty(t, out.String())
+			assert.Equal(t, test.code, exitCode)
+		})
+	}
+}
+
+func TestErrorFormatJSONSubcommandErrWriter(t *testing.T) {
+	OsExiter = func(int) {}
+	defer func() { OsExiter = fakeOsExiter }()
+
+	errOut, subErrOut := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := buildErrorFormatTestCommand(errOut, &bytes.Buffer{})
+	cmd.Commands[1].ErrWriter = subErrOut
+
+	require.Error(t, cmd.Run(buildTestContext(t), []string{"app", "--error-format", "json", "check"}))
+	assert.Equal(t, `{"error":"check failed","code":1,"command":"app check"}`+"\n", subErrOut.String())
+	assert.Empty(t, errOut.String())
+}
+
+func TestErrorFormatEnv(t *testing.T) {
+	t.Setenv("CLI_ERROR_FORMAT", "json")
+
+	errOut, out := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := buildErrorFormatTestCommand(errOut, out)
+	cmd.ExitErrHandler = func(context.Context, *Command, error) {}
+
+	require.Error(t, cmd.Run(buildTestContext(t), []string{"app", "check"}))
+	assert.Equal(t, `{"error":"check failed","code":1,"command":"app check"}`+"\n", errOut.String())
+}
+
+func TestErrorFormatText(t *testing.T) {
+	errOut, out := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := buildErrorFormatTestCommand(errOut, out)
+
+	require.EqualError(t, cmd.Run(buildTestContext(t), []string{"app", "check"}), "check failed")
+	assert.Empty(t, errOut.String())
+}
@@ -1,13 +1,17 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"reflect"
 	"strings"
+	"text/template"
 )
 
-// OsExiter is the function used when the app exits. If not set defaults to os.Exit.
+// OsExiter is the function used when the app exits and the root command has
+// no Exiter. If not set defaults to os.Exit.
 var OsExiter = os.Exit
 
 // ErrWriter is used to write errors to the user. This can be anything
@@ -45,25 +49,132 @@ func (m *multiError) Errors() []error {
 	return errs
 }
 
+// Unwrap returns the wrapped errors for errors.Is and errors.As
+func (m *multiError) Unwrap() []error {
+	return m.Errors()
+}
+
+// unwrapMulti returns the errors wrapped by a MultiError or joined by
+// errors.Join. Other errors wrapping multiple errors, like the ones of
+// fmt.Errorf with several %w verbs, are single errors with a message of
+// their own.
+func unwrapMulti(err error) ([]error, bool) {
+	if e, ok := err.(MultiError); ok {
+		return e.Errors(), true
+	}
+
+	if joinErrorType != nil && reflect.TypeOf(err) == joinErrorType {
+		return err.(interface{ Unwrap() []error }).Unwrap(), true
+	}
+
+	return nil, false
+}
+
 type requiredFlagsErr interface {
 	error
 	getMissingFlags() []string
 }
 
-type errRequiredFlags struct {
-	missingFlags []string
+// ErrMissingRequiredFlags is returned when required flags have not been set
+type ErrMissingRequiredFlags struct {
+	// Names are the primary names of the missing flags
+	Names []string
+}
+
+func (e *ErrMissingRequiredFlags) Error() string {
+	if len(e.Names) == 1 {
+		return trf("Required flag %q not set", e.Names[0])
+	}
+	joinedMissingFlags := strings.Join(e.Names, ", ")
+	return trf("Required flags %q not set", joinedMissingFlags)
+}
+
+func (e *ErrMissingRequiredFlags) getMissingFlags() []string {
+	return e.Names
+}
+
+// ErrFlagUndefined is returned when a flag is passed which is not defined
+// for the command
+type ErrFlagUndefined struct {
+	// Flag is the name of the flag as passed, without dashes
+	Flag string
+	// Suggestions are similar flags of the command and its ancestors, if
+	// suggestions are enabled
+	Suggestions []string
+}
+
+func (e *ErrFlagUndefined) Error() string {
+	msg := trf("flag provided but not defined: -%s", e.Flag)
+	if len(e.Suggestions) > 0 {
+		msg += ". " + fmt.Sprintf(SuggestDidYouMeanTemplate, e.Suggestions[0])
+	}
+	return msg
+}
+
+// ErrInvalidFlag reports an invalid value of a flag found by a
+// ValidateFlags function
+type ErrInvalidFlag struct {
+	// Flag is the name of the flag
+	Flag string
+	// Err is the problem with the value
+	Err error
+}
+
+func (e *ErrInvalidFlag) Error() string {
+	return trf("invalid value for flag -%s: %v", e.Flag, e.Err)
+}
+
+// Unwrap returns the problem with the value
+func (e *ErrInvalidFlag) Unwrap() error {
+	return e.Err
+}
+
+// ErrCommandNotFound is returned wrapped in an ExitCoder with exit code 3
+// when help is requested for a command which does not exist
+type ErrCommandNotFound struct {
+	// Name of the command as passed
+	Name string
+	// Suggestions are names of similar commands, if suggestions are enabled
+	Suggestions []string
 }
 
-func (e *errRequiredFlags) Error() string {
-	if len(e.missingFlags) == 1 {
-		return fmt.Sprintf("Required flag %q not set", e.missingFlags[0])
+func (e *ErrCommandNotFound) Error() string {
+	msg := trf("No help topic for '%v'", e.Name)
+	if len(e.Suggestions) > 0 {
+		msg += ". " + fmt.Sprintf(SuggestDidYouMeanTemplate, e.Suggestions[0])
 	}
-	joinedMissingFlags := strings.Join(e.missingFlags, ", ")
-	return fmt.Sprintf("Required flags %q not set", joinedMissingFlags)
+	return msg
+}
+
+// commandPathError prefixes an error with the full path of the command it
+// occurred on
+type commandPathError struct {
+	path string
+	err  error
+}
+
+func (e *commandPathError) Error() string {
+	return e.path + ": " + e.err.Error()
 }
 
-func (e *errRequiredFlags) getMissingFlags() []string {
-	return e.missingFlags
+func (e *commandPathError) Unwrap() error {
+	return e.err
+}
+
+// withCommandPath wraps the error with the full path of the command, keeping
+// the exit code of errors which would be exited with
+func withCommandPath(cmd *Command, err error) error {
+	wrapped := &commandPathError{path: cmd.FullName(), err: err}
+
+	if _, ok := err.(ExitCoder); ok {
+		return Exit(wrapped, exitCodeFromError(err))
+	}
+
+	if _, ok := unwrapMulti(err); ok {
+		return Exit(wrapped, exitCodeFromError(err))
+	}
+
+	return wrapped
 }
 
 type mutuallyExclusiveGroup struct {
@@ -72,7 +183,19 @@ type mutuallyExclusiveGroup struct {
 }
 
 func (e *mutuallyExclusiveGroup) Error() string {
-	return fmt.Sprintf("option %s cannot be set along with option %s", e.flag1Name, e.flag2Name)
+	return trf("option %s cannot be set along with option %s", e.flag1Name, e.flag2Name)
+}
+
+type flagRequiresFlags struct {
+	flagName string
+	missing  []string
+}
+
+func (e *flagRequiresFlags) Error() string {
+	if len(e.missing) == 1 {
+		return trf("option %s requires option %s to be set", e.flagName, e.missing[0])
+	}
+	return trf("option %s requires options %s to be set", e.flagName, strings.Join(e.missing, ", "))
 }
 
 type mutuallyExclusiveGroupRequiredFlag struct {
@@ -87,15 +210,15 @@ func (e *mutuallyExclusiveGroupRequiredFlag) Error() string {
 			grpString = append(grpString, f.Names()...)
 		}
 		if len(e.flags.Flags) == 1 {
-			err := errRequiredFlags{
-				missingFlags: grpString,
+			err := ErrMissingRequiredFlags{
+				Names: grpString,
 			}
 			return err.Error()
 		}
 		missingFlags = append(missingFlags, strings.Join(grpString, " "))
 	}
 
-	return fmt.Sprintf("one of these flags needs to be provided: %s", strings.Join(missingFlags, ", "))
+	return trf("one of these flags needs to be provided: %s", strings.Join(missingFlags, ", "))
 }
 
 // ErrorFormatter is the interface that will suitably format the error output
@@ -120,8 +243,8 @@ type exitError struct {
 //
 // This is the simplest way to trigger a non-zero exit code for an App without
 // having to call os.Exit manually. During testing, this behavior can be avoided
-// by overriding the ExitErrHandler function on an App or the package-global
-// OsExiter function.
+// by overriding the ExitErrHandler function or the Exiter of the root command,
+// or by running it with RunForTest.
 func Exit(message interface{}, exitCode int) ExitCoder {
 	var err error
 
@@ -152,51 +275,196 @@ func (ee *exitError) Unwrap() error {
 	return ee.err
 }
 
+// Error is an error for the user of the application, carrying the exit code
+// to exit with and a suggestion how to resolve it besides the message, e.g.
+//
+//	return &cli.Error{
+//		Message:    "not logged in",
+//		Suggestion: "run 'app login' first",
+//		Code:       4,
+//		Cause:      err,
+//	}
+//
+// Errors of this type, including the ones wrapped by others, are written
+// using ErrorTemplate during default error handling.
+type Error struct {
+	// Message for the user, the message of Cause if empty
+	Message string
+	// Suggestion how to resolve the error, if any
+	Suggestion string
+	// Code is the exit code, 1 if not set
+	Code int
+	// Cause is the wrapped error, if any
+	Cause error
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" && e.Cause != nil {
+		return e.Cause.Error()
+	}
+	return e.Message
+}
+
+// ExitCode returns the exit code of the error
+func (e *Error) ExitCode() int {
+	if e.Code == 0 {
+		return 1
+	}
+	return e.Code
+}
+
+// Unwrap returns the cause of the error
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// ErrorTemplate is the template errors of type Error are written with by
+// HandleExitCoder and the default error handling of commands, unless a
+// FormatError function is set. It is executed with the Message, the
+// Suggestion and the ExitCode of the error.
+var ErrorTemplate = `{{.Message}}{{if .Suggestion}}
+{{.Suggestion}}{{end}}`
+
+// errorTemplateData is the data ErrorTemplate is executed with
+type errorTemplateData struct {
+	Message    string
+	Suggestion string
+	ExitCode   int
+}
+
+// errorText returns the text the error is written with, rendered by
+// ErrorTemplate if it is or wraps an Error
+func errorText(err error) string {
+	var e *Error
+	if !errors.As(err, &e) {
+		return fmt.Sprint(err)
+	}
+
+	t, tErr := template.New("error").Parse(ErrorTemplate)
+	if tErr != nil {
+		tracef("unable to parse error template: %[1]v", tErr)
+		return err.Error()
+	}
+
+	var b strings.Builder
+	data := errorTemplateData{
+		Message:    err.Error(),
+		Suggestion: e.Suggestion,
+		ExitCode:   exitCodeFromError(err),
+	}
+	if tErr := t.Execute(&b, data); tErr != nil {
+		tracef("unable to execute error template: %[1]v", tErr)
+		return err.Error()
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
 // HandleExitCoder handles errors implementing ExitCoder by printing their
 // message and calling OsExiter with the given exit code.
 //
-// If the given error instead implements MultiError, each error will be checked
-// for the ExitCoder interface, and OsExiter will be called with the last exit
-// code found, or exit code 1 if no ExitCoder is found.
+// If the given error instead implements MultiError or has been returned by
+// errors.Join, the message of each error is printed and OsExiter is called
+// with the highest exit code of the errors implementing ExitCoder, nested
+// ones included, or exit code 1 if no ExitCoder is found.
 //
 // This function is the default error-handling behavior for an App.
 func HandleExitCoder(err error) {
+	handleExitCoder(err, ErrWriter, nil, OsExiter)
+}
+
+// handleExitCoder implements HandleExitCoder writing to the given writer,
+// formatting the errors with format if not nil and exiting with exit
+func handleExitCoder(err error, w io.Writer, format func(error) string, exit func(int)) {
 	if err == nil {
 		return
 	}
 
 	if exitErr, ok := err.(ExitCoder); ok {
 		if err.Error() != "" {
-			if _, ok := exitErr.(ErrorFormatter); ok {
-				_, _ = fmt.Fprintf(ErrWriter, "%+v\n", err)
+			if format != nil {
+				_, _ = fmt.Fprintln(w, format(err))
+			} else if _, ok := exitErr.(ErrorFormatter); ok {
+				_, _ = fmt.Fprintf(w, "%+v\n", err)
 			} else {
-				_, _ = fmt.Fprintln(ErrWriter, err)
+				_, _ = fmt.Fprintln(w, errorText(err))
 			}
 		}
-		OsExiter(exitErr.ExitCode())
+		exit(exitErr.ExitCode())
 		return
 	}
 
-	if multiErr, ok := err.(MultiError); ok {
-		code := handleMultiError(multiErr)
-		OsExiter(code)
+	if errs, ok := unwrapMulti(err); ok {
+		code := handleMultiError(errs, w, format)
+		exit(code)
 		return
 	}
 }
 
-func handleMultiError(multiErr MultiError) int {
-	code := 1
-	for _, merr := range multiErr.Errors() {
-		if multiErr2, ok := merr.(MultiError); ok {
-			code = handleMultiError(multiErr2)
+// exitCodeFromError returns the exit code which corresponds to the given
+// error following the same rules as HandleExitCoder, or 0 if err is nil
+func exitCodeFromError(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	if exitErr, ok := err.(ExitCoder); ok {
+		return exitErr.ExitCode()
+	}
+
+	if errs, ok := unwrapMulti(err); ok {
+		return multiExitCode(errs)
+	}
+
+	return 1
+}
+
+// multiExitCode returns the highest exit code of the errors implementing
+// ExitCoder, nested ones included, or 1 if there is none
+func multiExitCode(errs []error) int {
+	code, found := highestExitCode(errs)
+	if !found {
+		return 1
+	}
+	return code
+}
+
+func highestExitCode(errs []error) (int, bool) {
+	code, found := 0, false
+	for _, merr := range errs {
+		c, ok := 0, false
+		if errs2, isMulti := unwrapMulti(merr); isMulti {
+			c, ok = highestExitCode(errs2)
+		} else if exitErr, isExitCoder := merr.(ExitCoder); isExitCoder {
+			c, ok = exitErr.ExitCode(), true
+		}
+
+		if ok && (!found || c > code) {
+			code, found = c, true
+		}
+	}
+	return code, found
+}
+
+// handleMultiError prints the message of each error, nested ones included,
+// and returns the exit code for them
+func handleMultiError(errs []error, w io.Writer, format func(error) string) int {
+	printMultiError(errs, w, format)
+	return multiExitCode(errs)
+}
+
+func printMultiError(errs []error, w io.Writer, format func(error) string) {
+	for _, merr := range errs {
+		if errs2, ok := unwrapMulti(merr); ok {
+			printMultiError(errs2, w, format)
 		} else if merr != nil {
-			fmt.Fprintln(ErrWriter, merr)
-			if exitErr, ok := merr.(ExitCoder); ok {
-				code = exitErr.ExitCode()
+			if format != nil {
+				fmt.Fprintln(w, format(merr))
+			} else {
+				fmt.Fprintln(w, errorText(merr))
 			}
 		}
 	}
-	return code
 }
 
 type typeError[T any] struct {
@@ -0,0 +1,11 @@
+//go:build go1.20
+
+package cli
+
+import (
+	"errors"
+	"reflect"
+)
+
+// joinErrorType is the type of the errors returned by errors.Join
+var joinErrorType = reflect.TypeOf(errors.Join(errors.New("")))
@@ -0,0 +1,8 @@
+//go:build !go1.20
+
+package cli
+
+import "reflect"
+
+// joinErrorType is nil as errors.Join is only available from go1.20 on
+var joinErrorType reflect.Type
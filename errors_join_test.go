@@ -0,0 +1,51 @@
+//go:build go1.20
+
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleExitCoder_JoinedErrors(t *testing.T) {
+	exitCode := 0
+	called := false
+
+	OsExiter = func(rc int) {
+		if !called {
+			exitCode = rc
+			called = true
+		}
+	}
+	ErrWriter = &bytes.Buffer{}
+
+	defer func() {
+		OsExiter = fakeOsExiter
+		ErrWriter = fakeErrWriter
+	}()
+
+	err := errors.Join(
+		errors.New("wowsa"),
+		Exit("highest ExitCoder", 11),
+		newMultiError(errors.New("egad"), Exit("galactic perimeter breach", 9)),
+	)
+	HandleExitCoder(err)
+
+	assert.Equal(t, 11, exitCode)
+	assert.True(t, called)
+	assert.Equal(t, "wowsa\nhighest ExitCoder\negad\ngalactic perimeter breach\n", ErrWriter.(*bytes.Buffer).String())
+	assert.Equal(t, 11, exitCodeFromError(err))
+}
+
+func TestHandleExitCoder_WrappedErrorsNotSplit(t *testing.T) {
+	err := fmt.Errorf("deploy failed: %w, %w", Exit("timeout", 3), errors.New("rollback failed"))
+
+	_, ok := unwrapMulti(err)
+	assert.False(t, ok)
+	assert.Equal(t, 1, exitCodeFromError(err))
+	assert.Equal(t, "deploy failed: timeout, rollback failed", errorText(err))
+}
@@ -2,11 +2,16 @@ package cli
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHandleExitCoder_nil(t *testing.T) {
@@ -174,12 +179,367 @@ func TestMultiErrorErrorsCopy(t *testing.T) {
 
 func TestErrRequiredFlags_Error(t *testing.T) {
 	missingFlags := []string{"flag1", "flag2"}
-	err := &errRequiredFlags{missingFlags: missingFlags}
+	err := &ErrMissingRequiredFlags{Names: missingFlags}
 	expectedMsg := "Required flags \"flag1, flag2\" not set"
 	assert.Equal(t, expectedMsg, err.Error())
 
 	missingFlags = []string{"flag1"}
-	err = &errRequiredFlags{missingFlags: missingFlags}
+	err = &ErrMissingRequiredFlags{Names: missingFlags}
 	expectedMsg = "Required flag \"flag1\" not set"
 	assert.Equal(t, expectedMsg, err.Error())
 }
+
+// testMultiError is a MultiError implemented outside of the package
+type testMultiError []error
+
+func (e testMultiError) Error() string   { return fmt.Sprint([]error(e)) }
+func (e testMultiError) Errors() []error { return e }
+
+func TestHandleExitCoder_HighestExitCode(t *testing.T) {
+	exitCode := 0
+	called := false
+
+	OsExiter = func(rc int) {
+		if !called {
+			exitCode = rc
+			called = true
+		}
+	}
+	ErrWriter = &bytes.Buffer{}
+
+	defer func() {
+		OsExiter = fakeOsExiter
+		ErrWriter = fakeErrWriter
+	}()
+
+	err := testMultiError{
+		errors.New("wowsa"),
+		newMultiError(errors.New("egad"), Exit("highest ExitCoder", 11)),
+		Exit("galactic perimeter breach", 9),
+	}
+	HandleExitCoder(err)
+
+	assert.Equal(t, 11, exitCode)
+	assert.True(t, called)
+	assert.Equal(t, "wowsa\negad\nhighest ExitCoder\ngalactic perimeter breach\n", ErrWriter.(*bytes.Buffer).String())
+	assert.Equal(t, 11, exitCodeFromError(err))
+	assert.Equal(t, 1, exitCodeFromError(testMultiError{errors.New("wowsa")}))
+	assert.Equal(t, 0, exitCodeFromError(testMultiError{errors.New("wowsa"), Exit("", 0)}))
+}
+
+func TestHandleExitCoder_AfterErrorCombination(t *testing.T) {
+	var handled []int
+
+	cmd := &Command{
+		Name: "app",
+		ExitErrHandler: func(_ context.Context, _ *Command, err error) {
+			handled = append(handled, exitCodeFromError(err))
+		},
+		Action: func(context.Context, *Command) error {
+			return Exit("action failed", 4)
+		},
+		After: func(context.Context, *Command) error {
+			return Exit("cleanup failed", 3)
+		},
+	}
+
+	err := cmd.Run(buildTestContext(t), []string{"app"})
+	assert.Equal(t, []int{4, 3}, handled)
+
+	multiErr, ok := err.(MultiError)
+	assert.True(t, ok)
+	assert.Len(t, multiErr.Errors(), 2)
+	assert.Equal(t, 4, exitCodeFromError(err), "the highest exit code")
+	assert.Equal(t, "action failed\ncleanup failed", err.Error())
+}
+
+func TestDecorateError(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		before   error
+		action   error
+		after    error
+		expected string
+	}{
+		{name: "before", args: []string{"app", "sub"}, before: errors.New("before"), expected: "app sub: before (see --help)"},
+		{name: "action", args: []string{"app", "sub"}, action: Exit("action", 3), expected: "app sub: action (see --help)"},
+		{name: "after", args: []string{"app", "sub"}, after: errors.New("after"), expected: "app sub: after (see --help)"},
+		{name: "usage", args: []string{"app", "sub", "--nope"}, expected: "app sub: flag provided but not defined: -nope (see --help)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				handled []string
+				codes   []int
+			)
+
+			cmd := &Command{
+				Name:      "app",
+				Writer:    &bytes.Buffer{},
+				ErrWriter: &bytes.Buffer{},
+				DecorateError: func(_ context.Context, cmd *Command, err error) error {
+					return fmt.Errorf("%s: %w (see --help)", cmd.FullName(), err)
+				},
+				ExitErrHandler: func(_ context.Context, _ *Command, err error) {
+					handled = append(handled, err.Error())
+					codes = append(codes, exitCodeFromError(err))
+				},
+				Commands: []*Command{
+					{
+						Name: "sub",
+						Before: func(context.Context, *Command) error {
+							return test.before
+						},
+						Action: func(context.Context, *Command) error {
+							return test.action
+						},
+						After: func(context.Context, *Command) error {
+							return test.after
+						},
+					},
+				},
+			}
+
+			err := cmd.Run(buildTestContext(t), test.args)
+			assert.EqualError(t, err, test.expected)
+
+			if test.name != "usage" {
+				assert.Equal(t, []string{test.expected}, handled)
+			}
+
+			if test.action != nil {
+				assert.Equal(t, []int{3}, codes)
+			}
+		})
+	}
+}
+
+func TestExportedErrorTypes(t *testing.T) {
+	cmd := &Command{
+		Name:      "app",
+		Suggest:   true,
+		Writer:    &bytes.Buffer{},
+		ErrWriter: &bytes.Buffer{},
+		Commands: []*Command{
+			{
+				Name: "deploy",
+				Flags: []Flag{
+					&StringFlag{Name: "env", Required: true},
+					&StringFlag{Name: "region", Required: true},
+				},
+				Action: func(context.Context, *Command) error { return nil },
+			},
+		},
+	}
+
+	t.Run("flag undefined", func(t *testing.T) {
+		err := cmd.Run(buildTestContext(t), []string{"app", "deploy", "--nope"})
+
+		var target *ErrFlagUndefined
+		assert.True(t, errors.As(err, &target))
+		assert.Equal(t, "nope", target.Flag)
+		assert.EqualError(t, err, "flag provided but not defined: -nope")
+	})
+
+	t.Run("missing required flags", func(t *testing.T) {
+		err := cmd.Run(buildTestContext(t), []string{"app", "deploy"})
+
+		var target *ErrMissingRequiredFlags
+		assert.True(t, errors.As(err, &target))
+		assert.Equal(t, []string{"env", "region"}, target.Names)
+	})
+
+	t.Run("command not found", func(t *testing.T) {
+		err := cmd.Run(buildTestContext(t), []string{"app", "help", "dep"})
+
+		var target *ErrCommandNotFound
+		assert.True(t, errors.As(err, &target))
+		assert.Equal(t, "dep", target.Name)
+		assert.Equal(t, []string{"deploy"}, target.Suggestions)
+		assert.Equal(t, 3, exitCodeFromError(err))
+	})
+}
+
+func TestCommandErrWriterAndFormatError(t *testing.T) {
+	exitCode := 0
+	OsExiter = func(rc int) { exitCode = rc }
+	defer func() { OsExiter = fakeOsExiter }()
+
+	rootErr, subErr := &bytes.Buffer{}, &bytes.Buffer{}
+
+	cmd := &Command{
+		Name:      "app",
+		Writer:    io.Discard,
+		ErrWriter: rootErr,
+		FormatError: func(cmd *Command, err error) string {
+			return fmt.Sprintf("error: %s: %v", cmd.FullName(), err)
+		},
+		Commands: []*Command{
+			{
+				Name:      "sub",
+				ErrWriter: subErr,
+				Action: func(context.Context, *Command) error {
+					return newMultiError(errors.New("first"), Exit("second", 4))
+				},
+			},
+			{
+				Name: "other",
+				Action: func(context.Context, *Command) error {
+					return Exit("failed", 5)
+				},
+			},
+		},
+	}
+
+	r := require.New(t)
+
+	r.Error(cmd.Run(buildTestContext(t), []string{"app", "sub"}))
+	r.Equal(4, exitCode)
+	r.Equal("error: app sub: first\nerror: app sub: second\n", subErr.String())
+	r.Empty(rootErr.String())
+
+	subErr.Reset()
+
+	r.Error(cmd.Run(buildTestContext(t), []string{"app", "other"}))
+	r.Equal(5, exitCode)
+	r.Equal("error: app other: failed\n", rootErr.String())
+
+	rootErr.Reset()
+
+	r.Error(cmd.Run(buildTestContext(t), []string{"app", "sub", "--nope"}))
+	r.True(strings.HasPrefix(subErr.String(), "error: app sub: flag provided but not defined: -nope\n\n"))
+	r.Empty(rootErr.String())
+}
+
+func TestCommandErrWriterStderr(t *testing.T) {
+	pkgErr := &bytes.Buffer{}
+	ErrWriter = pkgErr
+	defer func() { ErrWriter = fakeErrWriter }()
+
+	cmd := &Command{Name: "app", Commands: []*Command{{Name: "sub"}}}
+	cmd.setupDefaults([]string{"app"})
+	cmd.Commands[0].parent = cmd
+
+	assert.Equal(t, os.Stderr, cmd.ErrWriter)
+	assert.Same(t, pkgErr, cmd.Commands[0].errWriter(), "the package ErrWriter over the default")
+
+	cmd = &Command{Name: "app", ErrWriter: os.Stderr, Commands: []*Command{{Name: "sub"}}}
+	cmd.setupDefaults([]string{"app"})
+	cmd.Commands[0].parent = cmd
+
+	assert.Equal(t, os.Stderr, cmd.Commands[0].errWriter(), "os.Stderr set explicitly")
+}
+
+func TestErrorsWithCommandPath(t *testing.T) {
+	errNoDB := errors.New("no database")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+		code     int
+	}{
+		{
+			name:     "plain error",
+			err:      errNoDB,
+			expected: "app db migrate: no database",
+		},
+		{
+			name:     "exit error",
+			err:      Exit("locked", 3),
+			expected: "app db migrate: locked",
+			code:     3,
+		},
+		{
+			name:     "multi error",
+			err:      newMultiError(errNoDB, Exit("locked", 4)),
+			expected: "app db migrate: no database\nlocked",
+			code:     4,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := &Command{
+				Name:                  "app",
+				ErrWriter:             io.Discard,
+				ErrorsWithCommandPath: true,
+				Commands: []*Command{
+					{
+						Name: "db",
+						Commands: []*Command{
+							{
+								Name: "migrate",
+								Action: func(context.Context, *Command) error {
+									return test.err
+								},
+							},
+						},
+					},
+				},
+			}
+
+			code, err := cmd.RunForTest(buildTestContext(t), []string{"app", "db", "migrate"})
+			require.EqualError(t, err, test.expected)
+			if test.code != 0 {
+				assert.Equal(t, test.code, code)
+			} else {
+				assert.ErrorIs(t, err, errNoDB)
+			}
+		})
+	}
+}
+
+func TestError(t *testing.T) {
+	cause := errors.New("connection refused")
+
+	err := &Error{Message: "cannot reach the server", Suggestion: "check your network", Code: 3, Cause: cause}
+	assert.Equal(t, "cannot reach the server", err.Error())
+	assert.Equal(t, 3, err.ExitCode())
+	assert.ErrorIs(t, err, cause)
+
+	err = &Error{Cause: cause}
+	assert.Equal(t, "connection refused", err.Error())
+	assert.Equal(t, 1, err.ExitCode())
+}
+
+func TestHandleExitCoder_ErrorTemplate(t *testing.T) {
+	exitCode := 0
+	OsExiter = func(rc int) { exitCode = rc }
+	defer func() { OsExiter = fakeOsExiter }()
+
+	var errOut bytes.Buffer
+	cmd := &Command{
+		Name:      "app",
+		ErrWriter: &errOut,
+		Commands: []*Command{
+			{
+				Name: "deploy",
+				Action: func(context.Context, *Command) error {
+					return &Error{Message: "not logged in", Suggestion: "run 'app login' first", Code: 4}
+				},
+			},
+		},
+		ErrorsWithCommandPath: true,
+	}
+
+	require.Error(t, cmd.Run(buildTestContext(t), []string{"app", "deploy"}))
+	assert.Equal(t, 4, exitCode)
+	assert.Equal(t, "app deploy: not logged in\nrun 'app login' first\n", errOut.String())
+
+	defer func(tmpl string) { ErrorTemplate = tmpl }(ErrorTemplate)
+	ErrorTemplate = `error: {{.Message}} (exit code {{.ExitCode}}){{if .Suggestion}}
+hint: {{.Suggestion}}{{end}}`
+
+	errOut.Reset()
+	require.Error(t, cmd.Run(buildTestContext(t), []string{"app", "deploy"}))
+	assert.Equal(t, "error: app deploy: not logged in (exit code 4)\nhint: run 'app login' first\n", errOut.String())
+
+	errOut.Reset()
+	cmd.ErrorsWithCommandPath = false
+	cmd.FormatError = func(_ *Command, err error) string { return "formatted: " + err.Error() }
+	require.Error(t, cmd.Run(buildTestContext(t), []string{"app", "deploy"}))
+	assert.Equal(t, "formatted: not logged in\n", errOut.String())
+}
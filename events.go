@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"flag"
+)
+
+// EventKind identifies a step in the lifecycle of a command run
+type EventKind int
+
+const (
+	// EventParseStarted is emitted before the arguments of a command are parsed
+	EventParseStarted EventKind = iota
+	// EventFlagResolved is emitted for every flag which has been set after
+	// the arguments of a command have been parsed successfully
+	EventFlagResolved
+	// EventCommandMatched is emitted when a subcommand has been matched
+	EventCommandMatched
+	// EventActionStarted is emitted right before the Action of a command is run
+	EventActionStarted
+	// EventActionFinished is emitted right after the Action of a command has
+	// returned
+	EventActionFinished
+)
+
+// String returns the name of the event kind
+func (k EventKind) String() string {
+	switch k {
+	case EventParseStarted:
+		return "ParseStarted"
+	case EventFlagResolved:
+		return "FlagResolved"
+	case EventCommandMatched:
+		return "CommandMatched"
+	case EventActionStarted:
+		return "ActionStarted"
+	case EventActionFinished:
+		return "ActionFinished"
+	}
+	return "Unknown"
+}
+
+// Event describes a step in the lifecycle of a command run
+type Event struct {
+	// Kind of the event
+	Kind EventKind
+	// Command the event relates to
+	Command *Command
+	// Args being parsed, only set for EventParseStarted
+	Args []string
+	// Flag which has been resolved, only set for EventFlagResolved
+	Flag Flag
+	// Err returned by the Action, only set for EventActionFinished
+	Err error
+}
+
+// EventHandlerFunc is called for every emitted lifecycle event
+type EventHandlerFunc func(context.Context, Event)
+
+// Subscribe registers a handler which is called for every lifecycle event
+// of this command and all of its subcommands. Handlers are called in the
+// order they have been registered, handlers of ancestors before handlers
+// of descendants.
+func (cmd *Command) Subscribe(handler EventHandlerFunc) {
+	cmd.eventHandlers = append(cmd.eventHandlers, handler)
+}
+
+func (cmd *Command) emit(ctx context.Context, ev Event) {
+	if !cmd.hasEventHandlers() {
+		return
+	}
+
+	lineage := cmd.Lineage()
+
+	for i := len(lineage) - 1; i >= 0; i-- {
+		for _, handler := range lineage[i].eventHandlers {
+			handler(ctx, ev)
+		}
+	}
+}
+
+func (cmd *Command) emitFlagsResolved(ctx context.Context) {
+	if !cmd.hasEventHandlers() {
+		return
+	}
+
+	visited := map[string]bool{}
+	cmd.flagSet.Visit(func(f *flag.Flag) {
+		visited[f.Name] = true
+	})
+
+	for _, fl := range cmd.appliedFlags {
+		isSet := fl.IsSet()
+		for _, name := range fl.Names() {
+			if visited[name] {
+				isSet = true
+				break
+			}
+		}
+
+		if isSet {
+			cmd.emit(ctx, Event{Kind: EventFlagResolved, Command: cmd, Flag: fl})
+		}
+	}
+}
+
+func (cmd *Command) hasEventHandlers() bool {
+	for pCmd := cmd; pCmd != nil; pCmd = pCmd.parent {
+		if len(pCmd.eventHandlers) > 0 {
+			return true
+		}
+	}
+	return false
+}
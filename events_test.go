@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribe(t *testing.T) {
+	var events []string
+
+	cmd := &Command{
+		Name: "app",
+		Flags: []Flag{
+			&BoolFlag{Name: "verbose"},
+			&StringFlag{Name: "unused"},
+		},
+		Commands: []*Command{
+			{
+				Name: "deploy",
+				Flags: []Flag{
+					&StringFlag{Name: "env", Sources: EnvVars("APP_DEPLOY_ENV")},
+				},
+				Action: func(context.Context, *Command) error {
+					return errors.New("deploy failed")
+				},
+			},
+		},
+	}
+
+	t.Setenv("APP_DEPLOY_ENV", "prod")
+
+	cmd.Subscribe(func(_ context.Context, ev Event) {
+		s := fmt.Sprintf("%s:%s", ev.Kind, ev.Command.Name)
+		switch ev.Kind {
+		case EventParseStarted:
+			s += fmt.Sprintf(":%q", ev.Args)
+		case EventFlagResolved:
+			s += ":" + ev.Flag.Names()[0]
+		case EventActionFinished:
+			s += ":" + ev.Err.Error()
+		}
+		events = append(events, s)
+	})
+
+	err := cmd.Run(buildTestContext(t), []string{"app", "--verbose", "deploy"})
+	require.EqualError(t, err, "deploy failed")
+	require.Equal(t, []string{
+		`ParseStarted:app:["app" "--verbose" "deploy"]`,
+		"FlagResolved:app:verbose",
+		"CommandMatched:deploy",
+		`ParseStarted:deploy:["deploy"]`,
+		"FlagResolved:deploy:env",
+		"ActionStarted:deploy",
+		"ActionFinished:deploy:deploy failed",
+	}, events)
+}
+
+func TestSubscribeOrder(t *testing.T) {
+	var order []string
+
+	sub := &Command{
+		Name:   "sub",
+		Action: func(context.Context, *Command) error { return nil },
+	}
+	sub.Subscribe(func(_ context.Context, ev Event) {
+		if ev.Kind == EventActionStarted {
+			order = append(order, "sub")
+		}
+	})
+
+	cmd := &Command{
+		Name:     "app",
+		Commands: []*Command{sub},
+	}
+	cmd.Subscribe(func(_ context.Context, ev Event) {
+		if ev.Kind == EventActionStarted {
+			order = append(order, "root")
+		}
+	})
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "sub"}))
+	require.Equal(t, []string{"root", "sub"}, order)
+}
+
+func TestEventKindString(t *testing.T) {
+	require.Equal(t, "ActionFinished", EventActionFinished.String())
+	require.Equal(t, "Unknown", EventKind(-1).String())
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Example is an invocation of a command documented in the help output and
+// the docs, e.g.
+//
+//	cli.Example{
+//		Description: "Deploy the latest tag",
+//		Command:     "app deploy --tag latest",
+//		Output:      "deployed v1.2.0",
+//	}
+//
+// CheckExamples runs the examples to keep them in line with the program.
+type Example struct {
+	// Description of what the example does, if any
+	Description string `json:"description,omitempty"`
+	// Command line of the example starting with the name of the program
+	Command string `json:"command"`
+	// Output printed by the command line, if any. Leading and trailing
+	// white space is ignored.
+	Output string `json:"output,omitempty"`
+}
+
+// CheckExamples runs the command lines of the examples of the command and
+// its subcommands against the command, which has to be the root command,
+// and returns the errors of the failed ones and of the ones printing other
+// than their Output, if any. It is meant to be called in the tests of the
+// program:
+//
+//	func TestExamples(t *testing.T) {
+//		if err := newApp().CheckExamples(context.Background()); err != nil {
+//			t.Fatal(err)
+//		}
+//	}
+//
+// The examples are run with Execute, the output written to the standard
+// output is compared and the program is never exited.
+func (cmd *Command) CheckExamples(ctx context.Context) error {
+	var examples []Example
+	var collect func(c *Command)
+	collect = func(c *Command) {
+		c.loadCommands()
+		examples = append(examples, c.Examples...)
+		for _, sub := range c.Commands {
+			collect(sub)
+		}
+	}
+	collect(cmd)
+
+	var errs []error
+	for _, ex := range examples {
+		if err := cmd.checkExample(ctx, ex); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return newMultiError(errs...)
+	}
+	return nil
+}
+
+// checkExample runs the command line of the example with the standard
+// output written to a buffer
+func (cmd *Command) checkExample(ctx context.Context, ex Example) error {
+	args, err := splitShellWords(ex.Command)
+	if err != nil {
+		return fmt.Errorf("example %q: %w", ex.Command, err)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("example %q: missing command line", ex.Command)
+	}
+
+	tracef("checking example %[1]q (cmd=%[2]q)", ex.Command, cmd.Name)
+
+	var out bytes.Buffer
+	if _, err := cmd.Execute(ctx, args, nil, &out, nil); err != nil {
+		return fmt.Errorf("example %q failed: %w", ex.Command, err)
+	}
+	if got, expected := strings.TrimSpace(out.String()), strings.TrimSpace(ex.Output); ex.Output != "" && got != expected {
+		return fmt.Errorf("example %q printed %q instead of %q", ex.Command, got, expected)
+	}
+	return nil
+}
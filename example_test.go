@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildExampleTestCommand(examples ...Example) *Command {
+	return &Command{
+		Name: "app",
+		Commands: []*Command{{
+			Name:     "deploy",
+			Flags:    []Flag{&StringFlag{Name: "tag", Value: "latest"}},
+			Examples: examples,
+			Action: func(_ context.Context, cmd *Command) error {
+				if cmd.String("tag") == "broken" {
+					return Exit("cannot deploy", 3)
+				}
+				_, _ = fmt.Fprintf(cmd.Writer, "deployed %s\n", cmd.String("tag"))
+				return nil
+			},
+		}},
+	}
+}
+
+func TestCheckExamples(t *testing.T) {
+	cmd := buildExampleTestCommand(
+		Example{Description: "Deploy a tag", Command: "app deploy --tag v1", Output: "deployed v1\n"},
+		Example{Command: "app deploy --tag 'v1 rc'", Output: "deployed v1 rc"},
+		Example{Command: "app deploy"},
+	)
+	cmd.Writer = os.Stdout
+
+	require.NoError(t, cmd.CheckExamples(buildTestContext(t)))
+	assert.Equal(t, os.Stdout, cmd.Writer, "the writers are restored")
+	assert.Equal(t, os.Stdout, cmd.Commands[0].Writer, "like after a run")
+}
+
+func TestCheckExamplesErrors(t *testing.T) {
+	cmd := buildExampleTestCommand(
+		Example{Command: "app deploy --tag v2", Output: "deployed v1"},
+		Example{Command: "app deploy --tag broken"},
+		Example{Command: "app deploy --tag 'v1"},
+		Example{Command: "app deploy --tag v1", Output: "deployed v1"},
+	)
+	cmd.Exiter = ExiterFunc(func(code int) { t.Errorf("exited with %d", code) })
+
+	err := cmd.CheckExamples(buildTestContext(t))
+	require.Error(t, err)
+	assert.Equal(t, `example "app deploy --tag v2" printed "deployed v2" instead of "deployed v1"
+example "app deploy --tag broken" failed: cannot deploy
+example "app deploy --tag 'v1": unterminated quote '\''`, err.Error())
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+)
+
+// Execute runs the command like RunForTest with the given input and output
+// instead of the standard streams of the process, e.g. to embed the command
+// in SSH servers or chat bots. The program is never exited and the standard
+// streams are never used by the command graph, nil streams are empty or
+// discard the output. Readers and writers explicitly set on commands other
+// than the standard streams are kept.
+//
+// Execute must not be called concurrently for the same command.
+func (cmd *Command) Execute(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	if stdin == nil {
+		stdin = strings.NewReader("")
+	}
+
+	if stdout == nil {
+		stdout = io.Discard
+	}
+
+	if stderr == nil {
+		stderr = io.Discard
+	}
+
+	tracef("executing with redirected streams (cmd=%[1]q)", cmd.Name)
+
+	restore := cmd.redirectStreams(stdin, stdout, stderr)
+	defer restore()
+
+	return cmd.RunForTest(ctx, args)
+}
+
+// redirectStreams replaces the standard streams in the command graph with
+// the given ones, returning a function restoring them
+func (cmd *Command) redirectStreams(stdin io.Reader, stdout, stderr io.Writer) func() {
+	reader, writer, errWriter := cmd.Reader, cmd.Writer, cmd.ErrWriter
+
+	if reader == nil || reader == os.Stdin {
+		cmd.Reader = stdin
+	}
+
+	if writer == nil || writer == os.Stdout {
+		cmd.Writer = stdout
+	}
+
+	// subcommands without ErrWriter use the one of their parent
+	if (errWriter == nil && cmd.parent == nil) || errWriter == os.Stderr {
+		cmd.ErrWriter = stderr
+	}
+
+	var restores []func()
+	for _, sub := range cmd.Commands {
+		restores = append(restores, sub.redirectStreams(stdin, stdout, stderr))
+	}
+
+	return func() {
+		for _, restore := range restores {
+			restore()
+		}
+
+		cmd.Reader, cmd.Writer, cmd.ErrWriter = reader, writer, errWriter
+
+		// the defaults are not set up again by later runs
+		if cmd.didSetupDefaults {
+			if cmd.Reader == nil {
+				cmd.Reader = os.Stdin
+			}
+
+			if cmd.Writer == nil {
+				cmd.Writer = os.Stdout
+			}
+
+			if cmd.ErrWriter == nil && cmd.parent == nil {
+				cmd.ErrWriter = os.Stderr
+				cmd.defaultErrWriter = true
+			}
+		}
+	}
+}
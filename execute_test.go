@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandExecute(t *testing.T) {
+	defer func() { OsExiter = fakeOsExiter }()
+	OsExiter = func(int) { t.Fatal("Execute must not exit") }
+
+	custom := &bytes.Buffer{}
+
+	cmd := &Command{
+		Name: "bot",
+		Commands: []*Command{
+			{
+				Name: "echo",
+				Action: func(_ context.Context, cmd *Command) error {
+					line, _ := bufio.NewReader(cmd.Reader).ReadString('\n')
+					fmt.Fprintf(cmd.Writer, "echo: %s", line)
+					return nil
+				},
+			},
+			{
+				Name: "fail",
+				Action: func(_ context.Context, cmd *Command) error {
+					fmt.Fprintln(cmd.Writer, "failing")
+					return Exit("it failed", 3)
+				},
+			},
+			{
+				Name:   "custom",
+				Writer: custom,
+				Action: func(_ context.Context, cmd *Command) error {
+					fmt.Fprintln(cmd.Writer, "custom")
+					return nil
+				},
+			},
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		var stdout, stderr bytes.Buffer
+
+		code, err := cmd.Execute(buildTestContext(t), []string{"bot", "echo"}, strings.NewReader("hello\n"), &stdout, &stderr)
+		require.NoError(t, err)
+		assert.Equal(t, 0, code)
+		assert.Equal(t, "echo: hello\n", stdout.String())
+		assert.Empty(t, stderr.String())
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	code, err := cmd.Execute(buildTestContext(t), []string{"bot", "fail"}, nil, &stdout, &stderr)
+	require.EqualError(t, err, "it failed")
+	assert.Equal(t, 3, code)
+	assert.Equal(t, "failing\n", stdout.String())
+	assert.Equal(t, "it failed\n", stderr.String())
+
+	stdout.Reset()
+	code, err = cmd.Execute(buildTestContext(t), []string{"bot", "custom"}, nil, &stdout, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stdout.String())
+	assert.Equal(t, "custom\n", custom.String())
+
+	stdout.Reset()
+	code, err = cmd.Execute(buildTestContext(t), []string{"bot", "--help"}, nil, &stdout, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout.String(), "bot [global options]")
+
+	assert.Same(t, os.Stdout, cmd.Writer)
+	assert.Same(t, os.Stdin, cmd.Command("echo").Reader)
+	assert.Same(t, os.Stdout, cmd.Command("echo").Writer)
+	assert.Same(t, os.Stderr, cmd.ErrWriter)
+	assert.Nil(t, cmd.Command("echo").ErrWriter)
+}
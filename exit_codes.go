@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"errors"
+)
+
+// ExitStatus maps the errors matched by Match to an exit code. Registered
+// via the ExitCodes of the root command they are applied centrally instead
+// of wrapping errors with Exit in every action.
+type ExitStatus struct {
+	// Code is the exit code used for matching errors
+	Code int
+	// Match reports whether the error exits with Code
+	Match func(error) bool
+	// Description of the exit status shown in the EXIT STATUS section of
+	// the help output, exit statuses without description are not listed
+	Description string
+}
+
+// ExitCodeFor returns an ExitStatus for errors matching target according to
+// errors.Is.
+func ExitCodeFor(target error, code int, description string) ExitStatus {
+	return ExitStatus{
+		Code:        code,
+		Match:       func(err error) bool { return errors.Is(err, target) },
+		Description: description,
+	}
+}
+
+// ExitCodeForType returns an ExitStatus for errors of type E according to
+// errors.As.
+func ExitCodeForType[E error](code int, description string) ExitStatus {
+	return ExitStatus{
+		Code: code,
+		Match: func(err error) bool {
+			var target E
+			return errors.As(err, &target)
+		},
+		Description: description,
+	}
+}
+
+// VisibleExitCodes returns the exit statuses of the command which have a
+// description
+func (cmd *Command) VisibleExitCodes() []ExitStatus {
+	var statuses []ExitStatus
+	for _, status := range cmd.ExitCodes {
+		if status.Description != "" {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+// applyExitCodes wraps the error with the exit code of the first matching
+// ExitStatus or the DefaultExitCode of the command. Errors which already
+// carry an exit code are returned unchanged.
+func (cmd *Command) applyExitCodes(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(ExitCoder); ok {
+		return err
+	}
+
+	for _, status := range cmd.ExitCodes {
+		if status.Match != nil && status.Match(err) {
+			tracef("mapped error %[1]q to exit code %[2]d (cmd=%[3]q)", err, status.Code, cmd.Name)
+			return Exit(err, status.Code)
+		}
+	}
+
+	if cmd.DefaultExitCode != 0 {
+		return Exit(err, cmd.DefaultExitCode)
+	}
+
+	return err
+}
+
+// handleUsageError exits with the UsageErrorExitCode of the root command if
+// one is set, printing the error once, otherwise the decorated error is
+// returned
+func (cmd *Command) handleUsageError(ctx context.Context, err error) error {
+	root := cmd.Root()
+	if root.UsageErrorExitCode == 0 {
+		return cmd.decorateError(ctx, err)
+	}
+
+	tracef("handling usage error %[1]q with exit code %[2]d (cmd=%[3]q)", err, root.UsageErrorExitCode, cmd.Name)
+
+	return cmd.handleExitCoder(ctx, Exit(err, root.UsageErrorExitCode))
+}
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errTestConflict = errors.New("conflict")
+
+func TestExitCodes(t *testing.T) {
+	tests := []struct {
+		name         string
+		actionErr    error
+		defaultCode  int
+		expectedCode int
+	}{
+		{name: "sentinel", actionErr: errTestConflict, expectedCode: 3},
+		{name: "wrapped sentinel", actionErr: fmt.Errorf("saving: %w", errTestConflict), expectedCode: 3},
+		{name: "type", actionErr: &fs.PathError{Op: "open", Path: "x", Err: fs.ErrNotExist}, expectedCode: 4},
+		{name: "exit coder", actionErr: Exit("explicit", 5), expectedCode: 5},
+		{name: "default", actionErr: errors.New("other"), defaultCode: 6, expectedCode: 6},
+		{name: "no default", actionErr: errors.New("other"), expectedCode: -1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code := -1
+
+			cmd := &Command{
+				Name: "app",
+				ExitCodes: []ExitStatus{
+					ExitCodeFor(errTestConflict, 3, "the resource was modified concurrently"),
+					ExitCodeForType[*fs.PathError](4, ""),
+				},
+				DefaultExitCode: test.defaultCode,
+				ExitErrHandler: func(_ context.Context, _ *Command, err error) {
+					if exitErr, ok := err.(ExitCoder); ok {
+						code = exitErr.ExitCode()
+					}
+				},
+				Action: func(context.Context, *Command) error {
+					return test.actionErr
+				},
+			}
+
+			err := cmd.Run(buildTestContext(t), []string{"app"})
+			require.ErrorIs(t, err, test.actionErr)
+			assert.Equal(t, test.expectedCode, code)
+		})
+	}
+}
+
+func TestExitCodesHelp(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	cmd := &Command{
+		Name:   "app",
+		Writer: out,
+		ExitCodes: []ExitStatus{
+			ExitCodeFor(errTestConflict, 3, "the resource was modified concurrently"),
+			ExitCodeForType[*fs.PathError](4, ""),
+			ExitCodeFor(fs.ErrPermission, 77, "permission denied"),
+		},
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--help"}))
+	assert.Contains(t, out.String(), `EXIT STATUS:
+   3   the resource was modified concurrently
+   77  permission denied
+`)
+}
+
+func TestUsageErrorExitCode(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		expectedCode int
+	}{
+		{name: "undefined flag", args: []string{"app", "run", "--nope"}, expectedCode: 2},
+		{name: "missing required flag", args: []string{"app", "run"}, expectedCode: 2},
+		{name: "mutually exclusive flags", args: []string{"app", "run", "--target", "x", "--a", "--b"}, expectedCode: 2},
+		{name: "action error", args: []string{"app", "run", "--target", "x"}, expectedCode: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code := -1
+
+			cmd := &Command{
+				Name:               "app",
+				Writer:             &bytes.Buffer{},
+				ErrWriter:          &bytes.Buffer{},
+				UsageErrorExitCode: 2,
+				DefaultExitCode:    1,
+				ExitErrHandler: func(_ context.Context, _ *Command, err error) {
+					code = exitCodeFromError(err)
+				},
+				Commands: []*Command{
+					{
+						Name: "run",
+						Flags: []Flag{
+							&StringFlag{Name: "target", Required: true},
+						},
+						MutuallyExclusiveFlags: []MutuallyExclusiveFlags{
+							{
+								Flags: [][]Flag{
+									{&BoolFlag{Name: "a"}},
+									{&BoolFlag{Name: "b"}},
+								},
+							},
+						},
+						Action: func(context.Context, *Command) error {
+							return errors.New("failed")
+						},
+					},
+				},
+			}
+
+			require.Error(t, cmd.Run(buildTestContext(t), test.args))
+			assert.Equal(t, test.expectedCode, code)
+		})
+	}
+}
+
+func TestUsageErrorExitCodePrintsErrorOnce(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		args []string
+		msg  string
+	}{
+		{name: "undefined flag", args: []string{"app", "--nope"}, msg: "flag provided but not defined: -nope"},
+		{name: "missing required flag", args: []string{"app"}, msg: `Required flag "target" not set`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			errOut := &bytes.Buffer{}
+			cmd := &Command{
+				Name:               "app",
+				Writer:             &bytes.Buffer{},
+				ErrWriter:          errOut,
+				UsageErrorExitCode: 2,
+				Flags:              []Flag{&StringFlag{Name: "target", Required: true}},
+				Action:             func(context.Context, *Command) error { return nil },
+			}
+
+			code, err := cmd.RunForTest(buildTestContext(t), tc.args)
+			require.Error(t, err)
+			assert.Equal(t, 2, code)
+			assert.Equal(t, 1, strings.Count(errOut.String(), tc.msg), errOut.String())
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package cli
+
+import "context"
+
+// Exiter terminates the program with the given exit code
+type Exiter interface {
+	Exit(code int)
+}
+
+// ExiterFunc adapts an ordinary function to the Exiter interface
+type ExiterFunc func(code int)
+
+// Exit calls f(code)
+func (f ExiterFunc) Exit(code int) {
+	f(code)
+}
+
+// exit terminates the program with the Exiter of the root command, or the
+// package OsExiter if none is set
+func (cmd *Command) exit(code int) {
+	if exiter := cmd.Root().Exiter; exiter != nil {
+		tracef("exiting with code %[1]d via Exiter (cmd=%[2]q)", code, cmd.Name)
+		exiter.Exit(code)
+		return
+	}
+
+	OsExiter(code)
+}
+
+// RunForTest runs the command like Run, but instead of terminating the
+// program it returns the code the program would exit with alongside the
+// error. Errors which would not have caused an exit yield the exit code
+// HandleExitCoder uses for them, a successful run yields zero.
+//
+// Since the exit is scoped to the command, commands may be run this way by
+// parallel tests without swapping the package OsExiter.
+func (cmd *Command) RunForTest(ctx context.Context, args []string) (int, error) {
+	var (
+		code   int
+		exited bool
+	)
+
+	exiter := cmd.Exiter
+	cmd.Exiter = ExiterFunc(func(c int) {
+		// the program would have stopped at the first exit
+		if !exited {
+			code, exited = c, true
+		}
+	})
+	defer func() { cmd.Exiter = exiter }()
+
+	err := cmd.Run(ctx, args)
+	if !exited {
+		code = exitCodeFromError(err)
+	}
+
+	return code, err
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunForTest(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		args     []string
+		expected int
+	}{
+		{name: "success", expected: 0},
+		{name: "exit error", err: Exit("failed", 4), expected: 4},
+		{name: "plain error", err: errors.New("failed"), expected: 1},
+		{name: "multi error", err: newMultiError(errors.New("a"), Exit("b", 6)), expected: 6},
+		{name: "usage error", args: []string{"--nope"}, expected: 1},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := &Command{
+				Name:      "app",
+				Writer:    io.Discard,
+				ErrWriter: io.Discard,
+				Action: func(context.Context, *Command) error {
+					return test.err
+				},
+			}
+
+			code, err := cmd.RunForTest(context.Background(), append([]string{"app"}, test.args...))
+			assert.Equal(t, test.expected, code)
+			assert.Equal(t, test.expected != 0, err != nil)
+			assert.Nil(t, cmd.Exiter)
+		})
+	}
+}
+
+func TestRunForTestFirstExitWins(t *testing.T) {
+	cmd := &Command{
+		Name:               "app",
+		Writer:             io.Discard,
+		ErrWriter:          io.Discard,
+		UsageErrorExitCode: 2,
+		Flags: []Flag{
+			&IntFlag{Name: "n", Required: true},
+		},
+	}
+
+	code, err := cmd.RunForTest(buildTestContext(t), []string{"app"})
+	require.Error(t, err)
+	assert.Equal(t, 2, code)
+}
+
+func TestCommandExiter(t *testing.T) {
+	var codes []int
+
+	cmd := &Command{
+		Name:      "app",
+		ErrWriter: io.Discard,
+		Exiter:    ExiterFunc(func(code int) { codes = append(codes, code) }),
+		Commands: []*Command{
+			{
+				Name: "sub",
+				Action: func(context.Context, *Command) error {
+					return Exit(fmt.Errorf("failed"), 9)
+				},
+			},
+		},
+	}
+
+	require.Error(t, cmd.Run(buildTestContext(t), []string{"app", "sub"}))
+	assert.Equal(t, []int{9}, codes)
+}
@@ -0,0 +1,308 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ExternalCommandDescribeFlag is passed as the only argument to external
+// commands to ask for their ExternalCommandDescription
+const ExternalCommandDescribeFlag = "--cli-describe"
+
+// externalDescribeTimeout is the time an external command may take to
+// describe itself
+const externalDescribeTimeout = time.Second
+
+// ExternalCommandDescription is the JSON an external command prints when
+// called with ExternalCommandDescribeFlag, used in help and completion
+type ExternalCommandDescription struct {
+	Usage       string   `json:"usage"`
+	Description string   `json:"description"`
+	ArgsUsage   string   `json:"argsUsage"`
+	Aliases     []string `json:"aliases"`
+	Category    string   `json:"category"`
+	Hidden      bool     `json:"hidden"`
+}
+
+// externalCommand is an executable found by the discovery
+type externalCommand struct {
+	name string
+	path string
+}
+
+// lookupExternalCommand returns the external command with the name, if the
+// command is the root. The executables are only run to describe themselves
+// if none is named like that, as the name may be an alias.
+func (cmd *Command) lookupExternalCommand(name string) *Command {
+	if !cmd.canRunExternalCommands() || name == "" {
+		return nil
+	}
+
+	if !cmd.externalCommandsLoaded {
+		for _, ext := range cmd.findExternalCommands() {
+			if ext.name == name {
+				tracef("appending external command %[1]q from %[2]q (cmd=%[3]q)", ext.name, ext.path, cmd.Name)
+				return cmd.appendExternalCommand(newExternalCommand(ext, ExternalCommandDescription{}))
+			}
+		}
+	}
+
+	cmd.ensureExternalCommands()
+
+	return cmd.definedCommand(name)
+}
+
+// ensureExternalCommands adds the executables named "<root>-<name>" in the
+// ExternalCommandDirs and PATH as subcommands of the root command with the
+// description they give. As all of them are run for that, this is only done
+// when they are needed, i.e. to list the subcommands in the help or the
+// completion or if a subcommand was not found.
+func (cmd *Command) ensureExternalCommands() {
+	if !cmd.canRunExternalCommands() || cmd.externalCommandsLoaded {
+		return
+	}
+	cmd.externalCommandsLoaded = true
+
+	var found []externalCommand
+
+	for _, ext := range cmd.findExternalCommands() {
+		// defined commands take precedence
+		if cmd.definedCommand(ext.name) == nil {
+			found = append(found, ext)
+		}
+	}
+
+	descriptions := make([]ExternalCommandDescription, len(found))
+
+	var wg sync.WaitGroup
+
+	for i, ext := range found {
+		wg.Add(1)
+
+		go func(i int, ext externalCommand) {
+			defer wg.Done()
+			descriptions[i] = describeExternalCommand(ext.path)
+		}(i, ext)
+	}
+
+	wg.Wait()
+
+	for i, ext := range found {
+		tracef("appending external command %[1]q from %[2]q (cmd=%[3]q)", ext.name, ext.path, cmd.Name)
+		cmd.appendExternalCommand(newExternalCommand(ext, descriptions[i]))
+	}
+}
+
+// canRunExternalCommands returns true for a root command with external
+// commands enabled if the runtime can run them
+func (cmd *Command) canRunExternalCommands() bool {
+	if cmd.parent != nil || !cmd.EnableExternalCommands {
+		return false
+	}
+
+	if !runtimeCanExec {
+		tracef("skipping external commands unsupported by the runtime (cmd=%[1]q)", cmd.Name)
+		return false
+	}
+
+	return true
+}
+
+// appendExternalCommand adds the external command as subcommand, setting
+// it up if the command graph has been set up already
+func (cmd *Command) appendExternalCommand(ext *Command) *Command {
+	cmd.appendCommand(ext)
+
+	if cmd.categories != nil {
+		ext.setupSubcommand()
+		cmd.categories = categorize(cmd.Lineage(), cmd.Commands)
+	}
+
+	return ext
+}
+
+// findExternalCommands returns the external commands sorted by name, the
+// first one found wins if a name exists in multiple directories
+func (cmd *Command) findExternalCommands() []externalCommand {
+	dirs := append([]string{}, cmd.ExternalCommandDirs...)
+	if path, ok := cmd.LookupEnv("PATH"); ok {
+		dirs = append(dirs, filepath.SplitList(path)...)
+	}
+
+	prefix := cmd.Name + "-"
+	seen := map[string]bool{}
+
+	var found []externalCommand
+
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			name, ok := externalCommandName(entry, prefix)
+			if !ok || seen[name] {
+				continue
+			}
+
+			seen[name] = true
+			found = append(found, externalCommand{name: name, path: filepath.Join(dir, entry.Name())})
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool { return found[i].name < found[j].name })
+
+	return found
+}
+
+// externalCommandName returns the name of the subcommand if the entry is an
+// executable with the given prefix
+func externalCommandName(entry os.DirEntry, prefix string) (string, bool) {
+	file := entry.Name()
+	if entry.IsDir() || !strings.HasPrefix(file, prefix) {
+		return "", false
+	}
+
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(file))
+		if ext != ".exe" && ext != ".bat" && ext != ".cmd" && ext != ".com" {
+			return "", false
+		}
+
+		file = strings.TrimSuffix(file, filepath.Ext(file))
+	} else if info, err := entry.Info(); err != nil || info.Mode()&0o111 == 0 {
+		return "", false
+	}
+
+	name := strings.TrimPrefix(file, prefix)
+
+	return name, name != ""
+}
+
+// describeExternalCommand asks the external command for its description,
+// returning an empty one if it does not answer in time
+func describeExternalCommand(path string) ExternalCommandDescription {
+	ctx, cancel := context.WithTimeout(context.Background(), externalDescribeTimeout)
+	defer cancel()
+
+	var desc ExternalCommandDescription
+
+	c := exec.CommandContext(ctx, path, ExternalCommandDescribeFlag)
+	// processes started by the command may keep its output open after it
+	// has been killed on the timeout
+	setWaitDelay(c, externalDescribeTimeout)
+
+	out, err := c.Output()
+	if err == nil {
+		err = json.Unmarshal(out, &desc)
+	}
+
+	if err != nil {
+		tracef("unable to describe external command %[1]q: %[2]v", path, err)
+		return ExternalCommandDescription{}
+	}
+
+	return desc
+}
+
+func newExternalCommand(ext externalCommand, desc ExternalCommandDescription) *Command {
+	return &Command{
+		Name:            ext.name,
+		Aliases:         desc.Aliases,
+		Usage:           desc.Usage,
+		Description:     desc.Description,
+		ArgsUsage:       desc.ArgsUsage,
+		Category:        desc.Category,
+		Hidden:          desc.Hidden,
+		HideHelp:        true,
+		SkipFlagParsing: true,
+		Action: func(ctx context.Context, cmd *Command) error {
+			return runExternalCommand(ctx, cmd, ext.path, cmd.Args().Slice())
+		},
+		ShellComplete: func(ctx context.Context, cmd *Command) {
+			args := append(cmd.Args().Slice(), "--"+GenerateShellCompletionFlag.Names()[0])
+			_ = runExternalCommand(ctx, cmd, ext.path, args)
+		},
+	}
+}
+
+// runExternalCommand runs the executable with the input and output of the
+// command, exiting with its exit code on failure
+func runExternalCommand(ctx context.Context, cmd *Command, path string, args []string) error {
+	tracef("running external command %[1]q with args %[2]q (cmd=%[3]q)", path, args, cmd.Name)
+
+	c := exec.CommandContext(ctx, path, args...)
+	c.Env = externalCommandEnv(cmd)
+	c.Stdin = cmd.Root().Reader
+	c.Stdout = cmd.Root().Writer
+	c.Stderr = cmd.errWriter()
+
+	err := c.Run()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return Exit("", exitErr.ExitCode())
+	}
+
+	return err
+}
+
+// externalCommandEnv returns the environment of the external command, the
+// Env of the root command followed by the flags set on the ancestors of the
+// command, e.g. the flag log-level of the root command app as
+// APP_LOG_LEVEL. Sensitive flags aren't exported.
+func externalCommandEnv(cmd *Command) []string {
+	env := environ(cmd.environment())
+
+	seen := map[string]bool{}
+	for _, pCmd := range cmd.Lineage()[1:] {
+		for _, fl := range pCmd.Flags {
+			names := fl.Names()
+			if len(names) == 0 || seen[names[0]] || isBuiltinFlag(fl) || !pCmd.IsSet(names[0]) {
+				continue
+			}
+			if sf, ok := fl.(SensitiveFlag); ok && sf.IsSensitive() {
+				tracef("not exporting sensitive flag %[1]q to external command (cmd=%[2]q)", names[0], cmd.Name)
+				continue
+			}
+			seen[names[0]] = true
+
+			key := externalCommandEnvName(cmd.Root().Name, names[0])
+			tracef("exporting flag %[1]q as %[2]q to external command (cmd=%[3]q)", names[0], key, cmd.Name)
+			env = append(env, key+"="+formatConfigValue(pCmd.Value(names[0])))
+		}
+	}
+
+	return env
+}
+
+// externalCommandEnvName returns the name of the environment variable the
+// flag is exported as, the names of the root command and the flag in upper
+// case joined by an underscore with other characters than letters and
+// digits replaced by underscores
+func externalCommandEnvName(root, flag string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		default:
+			return '_'
+		}
+	}, root+"_"+flag)
+}
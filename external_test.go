@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeExternalCommand(t *testing.T, dir, name, script string) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0o755))
+}
+
+func TestExternalCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("external commands are shell scripts")
+	}
+
+	pluginDir := t.TempDir()
+	pathDir := t.TempDir()
+
+	writeExternalCommand(t, pluginDir, "app-hello", `
+if [ "$1" = "--cli-describe" ]; then
+	echo '{"usage": "say hello", "aliases": ["hi"]}'
+	exit 0
+fi
+echo "hello $@"
+read line
+echo "read $line" >&2
+exit 3
+`)
+	writeExternalCommand(t, pathDir, "app-hello", `echo "shadowed"`)
+	writeExternalCommand(t, pathDir, "app-plain", `echo "plain $@"`)
+	writeExternalCommand(t, pathDir, "app-builtin", `echo "shadowed"`)
+	require.NoError(t, os.WriteFile(filepath.Join(pathDir, "app-data"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(pathDir, "other-cmd"), nil, 0o755))
+
+	var builtin bool
+
+	newApp := func(out, errOut *bytes.Buffer) *Command {
+		return &Command{
+			Name:                   "app",
+			Reader:                 bytes.NewBufferString("input\n"),
+			Writer:                 out,
+			ErrWriter:              errOut,
+			Env:                    MapEnv{"PATH": pathDir},
+			EnableExternalCommands: true,
+			ExternalCommandDirs:    []string{pluginDir},
+			Commands: []*Command{
+				{Name: "builtin", Action: func(context.Context, *Command) error {
+					builtin = true
+					return nil
+				}},
+			},
+		}
+	}
+
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	code, err := newApp(out, errOut).RunForTest(buildTestContext(t), []string{"app", "hi", "--name", "gopher", "-v"})
+	require.Error(t, err)
+	assert.Equal(t, 3, code)
+	assert.Equal(t, "hello --name gopher -v\n", out.String())
+	assert.Equal(t, "read input\n", errOut.String())
+
+	out.Reset()
+	require.NoError(t, newApp(out, errOut).Run(buildTestContext(t), []string{"app", "plain", "--help"}))
+	assert.Equal(t, "plain --help\n", out.String())
+
+	out.Reset()
+	require.NoError(t, newApp(out, errOut).Run(buildTestContext(t), []string{"app", "builtin"}))
+	assert.True(t, builtin)
+	assert.Empty(t, out.String())
+
+	out.Reset()
+	require.NoError(t, newApp(out, errOut).Run(buildTestContext(t), []string{"app", "--help"}))
+	assert.Contains(t, out.String(), "hello, hi  say hello")
+	assert.Contains(t, out.String(), "plain")
+	assert.NotContains(t, out.String(), "data")
+	assert.NotContains(t, out.String(), "other")
+}
+
+func TestExternalCommandsDiscoveredWhenNeeded(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("external commands are shell scripts")
+	}
+
+	dir := t.TempDir()
+	described := filepath.Join(dir, "described")
+	writeExternalCommand(t, dir, "app-hello", `
+if [ "$1" = "--cli-describe" ]; then
+	echo hello >> `+described+`
+	echo '{"usage": "say hello"}'
+	exit 0
+fi
+echo "hello $@"
+`)
+
+	out := &bytes.Buffer{}
+	newApp := func() *Command {
+		return &Command{
+			Name:                   "app",
+			Writer:                 out,
+			Env:                    MapEnv{"PATH": dir},
+			EnableExternalCommands: true,
+			Commands: []*Command{
+				{Name: "builtin", Action: func(context.Context, *Command) error { return nil }},
+			},
+		}
+	}
+
+	require.NoError(t, newApp().Run(buildTestContext(t), []string{"app", "builtin"}))
+	require.NoError(t, newApp().Run(buildTestContext(t), []string{"app", "hello", "world"}))
+	assert.Equal(t, "hello world\n", out.String())
+	assert.NoFileExists(t, described, "running commands does not describe the external commands")
+
+	out.Reset()
+	require.NoError(t, newApp().Run(buildTestContext(t), []string{"app", "--help"}))
+	assert.Contains(t, out.String(), "hello    say hello")
+	assert.FileExists(t, described)
+}
+
+func TestExternalCommandsEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("external commands are shell scripts")
+	}
+
+	dir := t.TempDir()
+	writeExternalCommand(t, dir, "my-app-env", `
+echo "level=$MY_APP_LOG_LEVEL tags=$MY_APP_TAG dry=$MY_APP_DRY_RUN token=$TOKEN password=${MY_APP_PASSWORD-none} unset=${MY_APP_REGION-none} $@"
+`)
+
+	out := &bytes.Buffer{}
+	cmd := &Command{
+		Name:                   "my-app",
+		Writer:                 out,
+		Env:                    MapEnv{"TOKEN": "secret", "LEVEL": "debug"},
+		EnableExternalCommands: true,
+		ExternalCommandDirs:    []string{dir},
+		Flags: []Flag{
+			&StringFlag{Name: "log-level", Sources: EnvVars("LEVEL")},
+			&StringSliceFlag{Name: "tag", Aliases: []string{"t"}},
+			&BoolFlag{Name: "dry-run"},
+			&StringFlag{Name: "region", Value: "eu"},
+			&StringFlag{Name: "password", Sensitive: true},
+		},
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"my-app", "-t", "a", "-t", "b", "--dry-run", "--password", "hunter2", "env", "--region", "us"}))
+	assert.Equal(t, "level=debug tags=a,b dry=true token=secret password=none unset=none --region us\n", out.String())
+}
+
+func TestExternalCommandEnvValues(t *testing.T) {
+	assert.Equal(t, "a,b", formatConfigValue([]string{"a", "b"}))
+	assert.Equal(t, "1.5", formatConfigValue(1.5))
+	assert.Equal(t, "1m30s", formatConfigValue(90*time.Second))
+	assert.Equal(t, "a=1,b=2", formatConfigValue(map[string]int64{"b": 2, "a": 1}))
+	assert.Equal(t, "2024-01-02T03:04:05Z", formatConfigValue(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+	assert.Equal(t, "MY_APP_LOG_LEVEL", externalCommandEnvName("my-app", "log-level"))
+}
+
+func TestExternalCommandsDisabled(t *testing.T) {
+	dir := t.TempDir()
+	writeExternalCommand(t, dir, "app-hello", `echo hello`)
+
+	cmd := &Command{
+		Name:                "app",
+		Env:                 MapEnv{"PATH": dir},
+		ExternalCommandDirs: []string{dir},
+	}
+	cmd.setupDefaults([]string{"app"})
+
+	assert.Nil(t, cmd.Command("hello"))
+}
@@ -0,0 +1,14 @@
+//go:build go1.20
+
+package cli
+
+import (
+	"os/exec"
+	"time"
+)
+
+// setWaitDelay bounds the time waiting for the output of the command after
+// its context is done
+func setWaitDelay(c *exec.Cmd, d time.Duration) {
+	c.WaitDelay = d
+}
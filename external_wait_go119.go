@@ -0,0 +1,12 @@
+//go:build !go1.20
+
+package cli
+
+import (
+	"os/exec"
+	"time"
+)
+
+// setWaitDelay is a no-op before Go 1.20, which waits for the output of
+// the command to be closed by all the processes it started
+func setWaitDelay(*exec.Cmd, time.Duration) {}
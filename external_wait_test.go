@@ -0,0 +1,36 @@
+//go:build go1.20
+
+package cli
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalCommandsDescribeTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("external commands are shell scripts")
+	}
+
+	dir := t.TempDir()
+	// the sleep keeps the output open after the script has been killed
+	writeExternalCommand(t, dir, "app-slow", `sleep 10`)
+
+	out := &bytes.Buffer{}
+	cmd := &Command{
+		Name:                   "app",
+		Writer:                 out,
+		Env:                    MapEnv{"PATH": dir},
+		EnableExternalCommands: true,
+	}
+
+	start := time.Now()
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--help"}))
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Contains(t, out.String(), "slow")
+}
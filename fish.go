@@ -1,3 +1,5 @@
+//go:build !urfave_cli_no_completion
+
 package cli
 
 import (
@@ -26,7 +28,12 @@ type fishCommandCompletionTemplate struct {
 
 func (cmd *Command) writeFishCompletionTemplate(w io.Writer) error {
 	const name = "cli"
-	t, err := template.New(name).Parse(FishCompletionTemplate)
+	cmd.loadCommands()
+
+	funcMap := cmd.lineageTemplateFuncs()
+	t, err := cmd.cachedTemplate(FishCompletionTemplate, funcMap, func() (*template.Template, error) {
+		return template.New(name).Funcs(funcMap).Parse(FishCompletionTemplate)
+	})
 	if err != nil {
 		return err
 	}
@@ -136,11 +143,24 @@ func (cmd *Command) prepareFishFlags(flags []Flag, previousCommands []string) []
 			}
 		}
 
+		if nf, ok := f.(NegatableFlag); ok {
+			for _, opt := range nf.GetNegatedNames() {
+				completion.WriteString(fmt.Sprintf(" -l %s", opt))
+			}
+		}
+
 		if flag, ok := f.(DocGenerationFlag); ok {
 			if flag.TakesValue() {
 				completion.WriteString(" -r")
 			}
 
+			if cf, ok := f.(ChoicesFlag); ok && flag.TakesValue() {
+				if choices := cf.GetChoices(); len(choices) > 0 {
+					completion.WriteString(fmt.Sprintf(" -a '%s'",
+						escapeSingleQuotes(strings.Join(choices, " "))))
+				}
+			}
+
 			if flag.GetUsage() != "" {
 				completion.WriteString(fmt.Sprintf(" -d '%s'",
 					escapeSingleQuotes(flag.GetUsage())))
@@ -163,6 +183,8 @@ func fishAddFileFlag(flag Flag, completion *strings.Builder) {
 		if f.TakesFile {
 			return
 		}
+	case *FileFlag, *DirFlag:
+		return
 	}
 	completion.WriteString(" -f")
 }
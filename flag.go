@@ -118,6 +118,13 @@ type RequiredFlag interface {
 	IsRequired() bool
 }
 
+// SensitiveFlag is an interface that allows us to mark flags as sensitive,
+// their value is not echoed when prompted for interactively
+type SensitiveFlag interface {
+	// whether the flag value is sensitive or not
+	IsSensitive() bool
+}
+
 // DocGenerationFlag is an interface that allows documentation generation for the flag
 type DocGenerationFlag interface {
 	// TakesValue returns true if the flag takes a value, otherwise false
@@ -173,6 +180,59 @@ type PersistentFlag interface {
 	IsPersistent() bool
 }
 
+// RelatedFlag is an interface for flags which can only be set along with or
+// without other flags
+type RelatedFlag interface {
+	// RequiresFlags returns the names of the flags which have to be set
+	// when the flag is set
+	RequiresFlags() []string
+
+	// ConflictingFlags returns the names of the flags which cannot be set
+	// along with the flag
+	ConflictingFlags() []string
+}
+
+// ChoicesFlag is an interface for flags which accept only one of a set of
+// values
+type ChoicesFlag interface {
+	// GetChoices returns the accepted values or nil if any value is accepted
+	GetChoices() []string
+}
+
+// LayoutsFlag is an interface for flags whose values are parsed by layouts
+// like the ones of time.Parse
+type LayoutsFlag interface {
+	// GetLayouts returns the accepted layouts or nil if the flag doesn't
+	// use layouts
+	GetLayouts() []string
+}
+
+// NegatableFlag is an interface for bool flags which are also accepted
+// with negated names setting them to false
+type NegatableFlag interface {
+	// GetNegatedNames returns the negated names or nil if the flag isn't
+	// negatable
+	GetNegatedNames() []string
+}
+
+// DeprecatedFlag is an interface for flags which may be deprecated
+type DeprecatedFlag interface {
+	// GetDeprecated returns the message of the deprecation warning or ""
+	// if the flag isn't deprecated
+	GetDeprecated() string
+}
+
+// DocMetadataFlag is an interface for flags documenting examples of their
+// use, the version they have been added in and related references
+type DocMetadataFlag interface {
+	// GetExamples returns the examples of the uses of the flag
+	GetExamples() []string
+	// GetSince returns the version the flag has been added in or ""
+	GetSince() string
+	// GetSeeAlso returns the references to related flags or documentation
+	GetSeeAlso() []string
+}
+
 func newFlagSet(name string, flags []Flag) (*flag.FlagSet, error) {
 	set := flag.NewFlagSet(name, flag.ContinueOnError)
 
@@ -211,6 +271,9 @@ func normalizeFlags(flags []Flag, set *flag.FlagSet) error {
 			name = strings.Trim(name, " ")
 			if visited[name] {
 				if ff != nil {
+					if isMultiOccurrenceFlag(f, ff) {
+						continue
+					}
 					return errors.New("Cannot use two forms of the same flag: " + name + " " + ff.Name)
 				}
 				ff = set.Lookup(name)
@@ -229,6 +292,19 @@ func normalizeFlags(flags []Flag, set *flag.FlagSet) error {
 	return nil
 }
 
+// isMultiOccurrenceFlag returns true for counters and flags taking multiple
+// values, whose names all share the value so giving several forms of the
+// flag accumulates the occurrences
+func isMultiOccurrenceFlag(f Flag, ff *flag.Flag) bool {
+	if fv, ok := ff.Value.(*fnValue); ok {
+		if _, ok := fv.v.(Countable); ok {
+			return true
+		}
+	}
+	mf, ok := f.(DocGenerationMultiValueFlag)
+	return ok && mf.IsMultiValueFlag()
+}
+
 func visibleFlags(fl []Flag) []Flag {
 	var visible []Flag
 	for _, f := range fl {
@@ -306,14 +382,18 @@ func withEnvHint(envVars []string, str string) string {
 }
 
 func FlagNames(name string, aliases []string) []string {
-	var ret []string
+	ret := make([]string, 0, len(aliases)+1)
+	ret = append(ret, name)
+	ret = append(ret, aliases...)
 
-	for _, part := range append([]string{name}, aliases...) {
+	for i, part := range ret {
 		// v1 -> v2 migration warning zone:
 		// Strip off anything after the first found comma or space, which
 		// *hopefully* makes it a tiny bit more obvious that unexpected behavior is
 		// caused by using the v1 form of stringly typed "Name".
-		ret = append(ret, commaWhitespace.ReplaceAllString(part, ""))
+		if strings.ContainsAny(part, ", ") {
+			ret[i] = commaWhitespace.ReplaceAllString(part, "")
+		}
 	}
 
 	return ret
@@ -331,6 +411,108 @@ func formatDefault(format string) string {
 	return " (default: " + format + ")"
 }
 
+// flagRelationsHint returns the flags the flag requires or conflicts with
+// for usage purposes
+func flagRelationsHint(f Flag) string {
+	rf, ok := f.(RelatedFlag)
+	if !ok {
+		return ""
+	}
+
+	var hint string
+	if names := rf.RequiresFlags(); len(names) > 0 {
+		hint += " (requires " + prefixedNames(names, "") + ")"
+	}
+	if names := rf.ConflictingFlags(); len(names) > 0 {
+		hint += " (conflicts with " + prefixedNames(names, "") + ")"
+	}
+	return hint
+}
+
+// flagDocNames returns the names of the flag followed by its negated names
+// for usage purposes
+func flagDocNames(f Flag) []string {
+	names := f.Names()
+	if nf, ok := f.(NegatableFlag); ok {
+		if negated := nf.GetNegatedNames(); len(negated) > 0 {
+			names = append(append([]string{}, names...), negated...)
+		}
+	}
+	return names
+}
+
+// flagSourceHint returns the value source which supplied the value of the
+// flag for help output, e.g. which of several environment variables has
+// been used. It is not part of the String of the flag as it depends on the
+// last run.
+func flagSourceHint(f Flag) string {
+	if sf, ok := f.(sourcedFlag); ok {
+		if src := sf.valueSource(); src != nil {
+			return " (set by " + src.String() + ")"
+		}
+	}
+	return ""
+}
+
+// flagDeprecatedHint returns the deprecation annotation of the flag for
+// usage purposes
+func flagDeprecatedHint(f Flag) string {
+	if df, ok := f.(DeprecatedFlag); ok && df.GetDeprecated() != "" {
+		return " (DEPRECATED)"
+	}
+	return ""
+}
+
+// flagMetadataHint returns the examples, the version and the references
+// of the flag for usage purposes
+func flagMetadataHint(f Flag) string {
+	mf, ok := f.(DocMetadataFlag)
+	if !ok {
+		return ""
+	}
+
+	var hint string
+	if examples := mf.GetExamples(); len(examples) > 0 {
+		hint += " (e.g. " + strings.Join(examples, ", ") + ")"
+	}
+	if since := mf.GetSince(); since != "" {
+		hint += " (since " + since + ")"
+	}
+	if refs := mf.GetSeeAlso(); len(refs) > 0 {
+		hint += " (see also: " + strings.Join(refs, ", ") + ")"
+	}
+	return hint
+}
+
+// flagChoicesHint returns the values accepted by the flag for usage
+// purposes
+func flagChoicesHint(f Flag) string {
+	if cf, ok := f.(ChoicesFlag); ok {
+		if choices := cf.GetChoices(); len(choices) > 0 {
+			return " (one of: " + strings.Join(choices, ", ") + ")"
+		}
+	}
+	return ""
+}
+
+// flagLayoutsHint returns the layouts of the values of the flag for usage
+// purposes
+func flagLayoutsHint(f Flag) string {
+	lf, ok := f.(LayoutsFlag)
+	if !ok {
+		return ""
+	}
+
+	switch layouts := lf.GetLayouts(); len(layouts) {
+	case 0:
+		return ""
+	case 1:
+		return " (format: " + layouts[0] + ")"
+	default:
+		return " (formats: " + strings.Join(layouts, ", ") + ")"
+	}
+}
+
 func stringifyFlag(f Flag) string {
 	// enforce DocGeneration interface on flags to avoid reflection
 	df, ok := f.(DocGenerationFlag)
@@ -351,9 +533,9 @@ func stringifyFlag(f Flag) string {
 		defaultValueString = fmt.Sprintf(formatDefault("%s"), s)
 	}
 
-	usageWithDefault := strings.TrimSpace(usage + defaultValueString)
+	usageWithDefault := strings.TrimSpace(usage + flagChoicesHint(f) + flagLayoutsHint(f) + defaultValueString + flagRelationsHint(f) + flagMetadataHint(f) + flagDeprecatedHint(f))
 
-	pn := prefixedNames(f.Names(), placeholder)
+	pn := prefixedNames(flagDocNames(f), placeholder)
 	sliceFlag, ok := f.(DocGenerationMultiValueFlag)
 	if ok && sliceFlag.IsMultiValueFlag() {
 		pn = pn + " [ " + pn + " ]"
@@ -10,6 +10,37 @@ type BoolFlag = FlagBase[bool, BoolConfig, boolValue]
 // BoolConfig defines the configuration for bool flags
 type BoolConfig struct {
 	Count *int
+	// Whether the flag is also accepted with its names of more than one
+	// character prefixed by DefaultInverseBoolPrefix, e.g. --no-color for
+	// --color, which sets it to false
+	Negatable bool
+}
+
+func (c BoolConfig) negatable() bool {
+	return c.Negatable
+}
+
+// negatableConfig is implemented by the configs of flags which may be
+// negated
+type negatableConfig interface {
+	negatable() bool
+}
+
+// GetNegatedNames returns the names setting the flag to false or nil if
+// the flag isn't negatable
+func (f *FlagBase[T, C, V]) GetNegatedNames() []string {
+	nc, ok := any(f.Config).(negatableConfig)
+	if !ok || !nc.negatable() {
+		return nil
+	}
+
+	var names []string
+	for _, name := range f.Names() {
+		if len(name) > 1 {
+			names = append(names, DefaultInverseBoolPrefix+name)
+		}
+	}
+	return names
 }
 
 // boolValue needs to implement the boolFlag internal interface in flag
@@ -25,7 +56,7 @@ type boolValue struct {
 }
 
 func (cmd *Command) Bool(name string) bool {
-	if v, ok := cmd.Value(name).(bool); ok {
+	if v, ok := lookupValue[bool](cmd, name); ok {
 		tracef("bool available for flag name %[1]q with value=%[2]v (cmd=%[3]q)", name, v, cmd.Name)
 		return v
 	}
@@ -70,6 +101,8 @@ func (b *boolValue) Set(s string) error {
 
 func (b *boolValue) Get() interface{} { return *b.destination }
 
+func (b *boolValue) typedGet() bool { return *b.destination }
+
 func (b *boolValue) String() string {
 	if b.destination != nil {
 		return strconv.FormatBool(*b.destination)
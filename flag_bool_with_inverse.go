@@ -139,6 +139,10 @@ func (parent *BoolWithInverseFlag) Apply(set *flag.FlagSet) error {
 		parent.initialize()
 	}
 
+	parent.positiveFlag.setRuntime(parent.BoolFlag.env, parent.BoolFlag.clock)
+	parent.negativeFlag.setRuntime(parent.BoolFlag.env, parent.BoolFlag.clock)
+	parent.negativeFlag.setEnvPrefix(parent.BoolFlag.envPrefix)
+
 	if err := parent.positiveFlag.Apply(set); err != nil {
 		return err
 	}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChoiceFlag is a string flag accepting only one of the values of its
+// Config.Choices. The choices are shown in help and generated docs and
+// completed by the shell completions, values are read with Command.String.
+//
+//	&cli.ChoiceFlag{
+//		Name:   "format",
+//		Value:  "text",
+//		Config: cli.ChoiceConfig{Choices: []string{"text", "json", "yaml"}},
+//	}
+type ChoiceFlag = FlagBase[string, ChoiceConfig, choiceValue]
+
+// ChoiceConfig defines the configuration for choice flags
+type ChoiceConfig struct {
+	// Values accepted by the flag
+	Choices []string
+}
+
+func (c ChoiceConfig) choices() []string {
+	return c.Choices
+}
+
+// choicesConfig is implemented by the configs of flags restricting their
+// values to a set of choices
+type choicesConfig interface {
+	choices() []string
+}
+
+// GetChoices returns the values accepted by the flag or nil if it accepts
+// any value
+func (f *FlagBase[T, C, V]) GetChoices() []string {
+	if cc, ok := any(f.Config).(choicesConfig); ok {
+		return cc.choices()
+	}
+	return nil
+}
+
+// -- choice Value
+type choiceValue struct {
+	destination *string
+	choices     []string
+}
+
+// Below functions are to satisfy the ValueCreator interface
+
+func (s choiceValue) Create(val string, p *string, c ChoiceConfig) Value {
+	*p = val
+	return &choiceValue{
+		destination: p,
+		choices:     c.Choices,
+	}
+}
+
+func (s choiceValue) ToString(val string) string {
+	if val == "" {
+		return val
+	}
+	return fmt.Sprintf("%q", val)
+}
+
+// Below functions are to satisfy the flag.Value interface
+
+func (s *choiceValue) Set(val string) error {
+	for _, choice := range s.choices {
+		if val == choice {
+			*s.destination = val
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(s.choices, ", "))
+}
+
+// validateDefault accepts no default or one of the choices
+func (s *choiceValue) validateDefault() error {
+	if *s.destination == "" {
+		return nil
+	}
+	return s.Set(*s.destination)
+}
+
+func (s *choiceValue) Get() any { return *s.destination }
+
+func (s *choiceValue) typedGet() string { return *s.destination }
+
+func (s *choiceValue) String() string {
+	if s.destination != nil {
+		return *s.destination
+	}
+	return ""
+}
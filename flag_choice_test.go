@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildChoiceTestCommand() *Command {
+	return &Command{
+		Name: "app",
+		Env:  MapEnv{},
+		Flags: []Flag{
+			&ChoiceFlag{
+				Name:    "format",
+				Usage:   "output format",
+				Value:   "text",
+				Sources: EnvVars("APP_FORMAT"),
+				Config:  ChoiceConfig{Choices: []string{"text", "json", "yaml"}},
+			},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+}
+
+func TestChoiceFlag(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		cmd := buildChoiceTestCommand()
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+		assert.Equal(t, "text", cmd.String("format"))
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		cmd := buildChoiceTestCommand()
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--format", "json"}))
+		assert.Equal(t, "json", cmd.String("format"))
+	})
+
+	t.Run("env var", func(t *testing.T) {
+		cmd := buildChoiceTestCommand()
+		cmd.Env = MapEnv{"APP_FORMAT": "yaml"}
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+		assert.Equal(t, "yaml", cmd.String("format"))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		cmd := buildChoiceTestCommand()
+		err := cmd.Run(buildTestContext(t), []string{"app", "--format", "xml"})
+		assert.ErrorContains(t, err, `invalid value "xml" for flag -format: must be one of text, json, yaml`)
+	})
+
+	t.Run("invalid env var", func(t *testing.T) {
+		cmd := buildChoiceTestCommand()
+		cmd.Env = MapEnv{"APP_FORMAT": "xml"}
+		err := cmd.Run(buildTestContext(t), []string{"app"})
+		assert.ErrorContains(t, err, "must be one of text, json, yaml")
+	})
+}
+
+func TestChoiceFlagDefault(t *testing.T) {
+	t.Run("invalid", func(t *testing.T) {
+		cmd := buildChoiceTestCommand()
+		cmd.Flags[0].(*ChoiceFlag).Value = "xml"
+		err := cmd.Run(buildTestContext(t), []string{"app", "--format", "json"})
+		assert.ErrorContains(t, err, `invalid default "xml" for flag format: must be one of text, json, yaml`)
+	})
+
+	t.Run("invalid with env var", func(t *testing.T) {
+		cmd := buildChoiceTestCommand()
+		cmd.Flags[0].(*ChoiceFlag).Value = "xml"
+		cmd.Env = MapEnv{"APP_FORMAT": "json"}
+		err := cmd.Run(buildTestContext(t), []string{"app"})
+		assert.ErrorContains(t, err, `invalid default "xml" for flag format`)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		cmd := buildChoiceTestCommand()
+		cmd.Flags[0].(*ChoiceFlag).Value = ""
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+		assert.Equal(t, "", cmd.String("format"))
+	})
+}
+
+func TestChoiceFlagHelp(t *testing.T) {
+	fl := buildChoiceTestCommand().Flags[0]
+	assert.Equal(t, `--format value	output format (one of: text, json, yaml) (default: "text")`+withEnvHint([]string{"APP_FORMAT"}, ""), fl.String())
+	assert.Equal(t, []string{"text", "json", "yaml"}, fl.(ChoicesFlag).GetChoices())
+	assert.Nil(t, (&StringFlag{Name: "name"}).GetChoices())
+}
+
+func TestChoiceFlagCompletion(t *testing.T) {
+	origArgv := os.Args
+	t.Cleanup(func() { os.Args = origArgv })
+
+	out := &bytes.Buffer{}
+	cmd := buildChoiceTestCommand()
+	cmd.EnableShellCompletion = true
+	cmd.Writer = out
+
+	os.Args = []string{"app", "--format", "--generate-shell-completion"}
+	require.NoError(t, cmd.Run(buildTestContext(t), os.Args))
+	assert.Equal(t, "text\njson\nyaml\n", out.String())
+}
+
+func TestChoiceFlagCompletionShortOptions(t *testing.T) {
+	origArgv := os.Args
+	t.Cleanup(func() { os.Args = origArgv })
+
+	out := &bytes.Buffer{}
+	cmd := buildChoiceTestCommand()
+	cmd.EnableShellCompletion = true
+	cmd.UseShortOptionHandling = true
+	cmd.Writer = out
+	cmd.Flags[0].(*ChoiceFlag).Aliases = []string{"f"}
+	cmd.Flags = append(cmd.Flags, &BoolFlag{Name: "verbose", Aliases: []string{"v"}})
+
+	os.Args = []string{"app", "-vf", "--generate-shell-completion"}
+	require.NoError(t, cmd.Run(buildTestContext(t), os.Args))
+	assert.Equal(t, "text\njson\nyaml\n", out.String(), "the values of the last flag of the cluster are completed")
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"strconv"
+	"strings"
+)
+
+// CountFlag is a flag without value counting how often it is given, so
+// -v -v -v yields 3 like -vvv does for single character names. Value is
+// the count the flag starts with. The count is read with Command.Count.
+// A value given like --verbose=2 or by the flag's sources sets the count,
+// true adds one and false resets it.
+//
+//	&cli.CountFlag{Name: "verbose", Aliases: []string{"v"}}
+type CountFlag = FlagBase[int, NoConfig, countValue]
+
+// -- count Value
+type countValue struct {
+	destination *int
+}
+
+// Below functions are to satisfy the ValueCreator interface
+
+func (c countValue) Create(val int, p *int, _ NoConfig) Value {
+	*p = val
+	return &countValue{destination: p}
+}
+
+func (c countValue) ToString(val int) string {
+	return strconv.Itoa(val)
+}
+
+// Below functions are to satisfy the flag.Value interface
+
+func (c *countValue) Set(s string) error {
+	if v, err := strconv.Atoi(s); err == nil && v >= 0 {
+		*c.destination = v
+		return nil
+	}
+
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return errors.New("parse error")
+	}
+	if v {
+		*c.destination++
+	} else {
+		*c.destination = 0
+	}
+	return nil
+}
+
+func (c *countValue) Get() any { return *c.destination }
+
+func (c *countValue) typedGet() int { return *c.destination }
+
+func (c *countValue) String() string {
+	if c.destination != nil {
+		return strconv.Itoa(*c.destination)
+	}
+	return "0"
+}
+
+func (c *countValue) IsBoolFlag() bool { return true }
+
+func (c *countValue) Count() int {
+	if c.destination != nil {
+		return *c.destination
+	}
+	return 0
+}
+
+// isRepeatedCountFlag reports whether the argument repeats the single
+// character name of a count flag, like -vvv, which is split into separate
+// flags even without short option handling
+func isRepeatedCountFlag(set *flag.FlagSet, arg string) bool {
+	if !isSplittable(arg) || strings.Trim(arg[1:], arg[1:2]) != "" {
+		return false
+	}
+
+	f := set.Lookup(arg[1:2])
+	if f == nil {
+		return false
+	}
+	fv, ok := f.Value.(*fnValue)
+	if !ok {
+		return false
+	}
+	_, ok = fv.v.(*countValue)
+	return ok
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountFlag(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		args     []string
+		env      MapEnv
+		short    bool
+		expected int
+	}{
+		{name: "unset", args: []string{"app"}},
+		{name: "separate", args: []string{"app", "-v", "-v", "-v"}, expected: 3},
+		{name: "repeated", args: []string{"app", "-vvv", "arg"}, expected: 3},
+		{name: "repeated with short options", args: []string{"app", "-vvq", "-v"}, short: true, expected: 3},
+		{name: "mixed forms", args: []string{"app", "-v", "-v", "--verbose"}, expected: 3},
+		{name: "value", args: []string{"app", "--verbose=2", "--verbose"}, expected: 3},
+		{name: "reset", args: []string{"app", "--verbose", "--verbose=false", "--verbose"}, expected: 1},
+		{name: "env var", args: []string{"app"}, env: MapEnv{"APP_VERBOSE": "2"}, expected: 2},
+		{name: "env var and flags", args: []string{"app", "-v"}, env: MapEnv{"APP_VERBOSE": "true"}, expected: 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var verbosity int
+			cmd := &Command{
+				Name:                   "app",
+				Env:                    tc.env,
+				UseShortOptionHandling: tc.short,
+				Flags: []Flag{
+					&CountFlag{Name: "verbose", Aliases: []string{"v"}, Sources: EnvVars("APP_VERBOSE"), Destination: &verbosity},
+					&BoolFlag{Name: "quiet", Aliases: []string{"q"}},
+				},
+				Action: func(context.Context, *Command) error { return nil },
+			}
+
+			require.NoError(t, cmd.Run(buildTestContext(t), tc.args))
+			assert.Equal(t, tc.expected, cmd.Count("verbose"))
+			assert.Equal(t, tc.expected, cmd.Count("v"))
+			assert.Equal(t, tc.expected, verbosity)
+		})
+	}
+}
+
+func TestCountFlagErrors(t *testing.T) {
+	cmd := &Command{
+		Name: "app",
+		Flags: []Flag{
+			&CountFlag{Name: "verbose", Aliases: []string{"v"}},
+			&BoolFlag{Name: "quiet", Aliases: []string{"q"}},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	err := cmd.Run(buildTestContext(t), []string{"app", "-vq"})
+	assert.ErrorContains(t, err, "flag provided but not defined: -vq", "only repeated count flags are split")
+
+	err = cmd.Run(buildTestContext(t), []string{"app", "--verbose=lots"})
+	assert.ErrorContains(t, err, `invalid boolean value "lots" for -verbose: parse error`)
+}
+
+func TestCountFlagHelp(t *testing.T) {
+	fl := &CountFlag{Name: "verbose", Aliases: []string{"v"}, Usage: "increase verbosity"}
+	assert.False(t, fl.TakesValue())
+	assert.Empty(t, fl.GetValue())
+	assert.Equal(t, "--verbose, -v\tincrease verbosity (default: 0)", fl.String())
+}
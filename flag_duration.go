@@ -34,10 +34,12 @@ func (d *durationValue) Set(s string) error {
 
 func (d *durationValue) Get() any { return time.Duration(*d) }
 
+func (d *durationValue) typedGet() time.Duration { return time.Duration(*d) }
+
 func (d *durationValue) String() string { return (*time.Duration)(d).String() }
 
 func (cmd *Command) Duration(name string) time.Duration {
-	if v, ok := cmd.Value(name).(time.Duration); ok {
+	if v, ok := lookupValue[time.Duration](cmd, name); ok {
 		tracef("duration available for flag name %[1]q with value=%[2]v (cmd=%[3]q)", name, v, cmd.Name)
 		return v
 	}
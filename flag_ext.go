@@ -2,6 +2,20 @@ package cli
 
 import "flag"
 
+// FlagsFromFlagSet wraps the flags defined in a standard library flag set,
+// e.g. by legacy code or packages like glog, to be added to the Flags of a
+// command. Usage text and defaults are preserved and values are set on the
+// original flag values, so code reading them keeps working.
+func FlagsFromFlagSet(fs *flag.FlagSet) []Flag {
+	var flags []Flag
+
+	fs.VisitAll(func(f *flag.Flag) {
+		flags = append(flags, &extFlag{f})
+	})
+
+	return flags
+}
+
 type extFlag struct {
 	f *flag.Flag
 }
@@ -28,7 +42,11 @@ func (e *extFlag) IsVisible() bool {
 }
 
 func (e *extFlag) TakesValue() bool {
-	return false
+	if b, ok := e.f.Value.(boolFlag); ok && b.IsBoolFlag() {
+		return false
+	}
+
+	return true
 }
 
 func (e *extFlag) GetUsage() string {
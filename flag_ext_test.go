@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// upperValue is a flag.Value not implementing flag.Getter
+type upperValue struct{ s string }
+
+func (u *upperValue) String() string     { return u.s }
+func (u *upperValue) Set(s string) error { u.s = strings.ToUpper(s); return nil }
+
+func TestFlagsFromFlagSet(t *testing.T) {
+	fs := flag.NewFlagSet("legacy", flag.ContinueOnError)
+	verbosity := fs.Int("v", 0, "log level for V logs")
+	logDir := fs.String("log_dir", "/tmp", "write log files in this directory")
+	toStderr := fs.Bool("logtostderr", false, "log to standard error instead of files")
+	interval := fs.Duration("interval", time.Second, "flush interval")
+	fs.Var(&upperValue{}, "mode", "mode of operation")
+
+	var got []any
+
+	out := &bytes.Buffer{}
+	cmd := &Command{
+		Name:   "app",
+		Writer: out,
+		Flags:  append(FlagsFromFlagSet(fs), &StringFlag{Name: "name"}),
+		Action: func(_ context.Context, cmd *Command) error {
+			got = []any{cmd.Value("v"), cmd.String("log_dir"), cmd.Bool("logtostderr"), cmd.Duration("interval"), cmd.Value("mode")}
+			return nil
+		},
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "-v", "2", "--logtostderr", "--mode", "fast", "--name", "x"}))
+	assert.Equal(t, 2, *verbosity)
+	assert.Equal(t, "/tmp", *logDir)
+	assert.True(t, *toStderr)
+	assert.Equal(t, time.Second, *interval)
+	assert.Equal(t, []any{2, "/tmp", true, time.Second, "FAST"}, got)
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--help"}))
+	assert.Contains(t, out.String(), "--log_dir value   write log files in this directory (default: /tmp)")
+	assert.Contains(t, out.String(), "--logtostderr     log to standard error instead of files (default: false)")
+}
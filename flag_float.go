@@ -33,12 +33,14 @@ func (f *floatValue) Set(s string) error {
 
 func (f *floatValue) Get() any { return float64(*f) }
 
+func (f *floatValue) typedGet() float64 { return float64(*f) }
+
 func (f *floatValue) String() string { return strconv.FormatFloat(float64(*f), 'g', -1, 64) }
 
 // Float looks up the value of a local FloatFlag, returns
 // 0 if not found
 func (cmd *Command) Float(name string) float64 {
-	if v, ok := cmd.Value(name).(float64); ok {
+	if v, ok := lookupValue[float64](cmd, name); ok {
 		tracef("float available for flag name %[1]q with value=%[2]v (cmd=%[3]q)", name, v, cmd.Name)
 		return v
 	}
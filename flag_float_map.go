@@ -0,0 +1,20 @@
+package cli
+
+type (
+	FloatMap     = MapBase[float64, NoConfig, floatValue]
+	FloatMapFlag = FlagBase[map[string]float64, NoConfig, FloatMap]
+)
+
+var NewFloatMap = NewMapBase[float64, NoConfig, floatValue]
+
+// FloatMap looks up the value of a local FloatMapFlag, returns
+// nil if not found
+func (cmd *Command) FloatMap(name string) map[string]float64 {
+	if v, ok := lookupValue[map[string]float64](cmd, name); ok {
+		tracef("float map available for flag name %[1]q with value=%[2]v (cmd=%[3]q)", name, v, cmd.Name)
+		return v
+	}
+
+	tracef("float map NOT available for flag name %[1]q (cmd=%[2]q)", name, cmd.Name)
+	return nil
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+)
+
+// GenericFlag adapts any flag.Value, e.g. one implemented by another
+// package for the standard library flag package, as flag of a command.
+// Values are set in place, so the Value doubles as destination, and
+// values implementing IsBoolFlag() bool don't take an argument.
+type GenericFlag = FlagBase[flag.Value, NoConfig, genericValue]
+
+// -- generic Value
+type genericValue struct {
+	val flag.Value
+}
+
+// Below functions are to satisfy the ValueCreator interface
+
+func (g genericValue) Create(val flag.Value, p *flag.Value, c NoConfig) Value {
+	if val != nil {
+		*p = val
+	}
+	return &genericValue{val: *p}
+}
+
+func (g genericValue) ToString(val flag.Value) string {
+	if val == nil {
+		return ""
+	}
+	return val.String()
+}
+
+// Below functions are to satisfy the flag.Value interface
+
+func (g *genericValue) Set(s string) error {
+	if g.val == nil {
+		return fmt.Errorf("no value to set")
+	}
+	return g.val.Set(s)
+}
+
+func (g *genericValue) Get() any { return g.val }
+
+func (g *genericValue) typedGet() flag.Value { return g.val }
+
+func (g *genericValue) String() string {
+	if g.val == nil {
+		return ""
+	}
+	return g.val.String()
+}
+
+func (g *genericValue) IsBoolFlag() bool {
+	b, ok := g.val.(boolFlag)
+	return ok && b.IsBoolFlag()
+}
+
+// Generic looks up the value of a local GenericFlag, returns
+// nil if not found
+func (cmd *Command) Generic(name string) flag.Value {
+	if v, ok := lookupValue[flag.Value](cmd, name); ok {
+		tracef("generic available for flag name %[1]q with value=%[2]v (cmd=%[3]q)", name, v, cmd.Name)
+		return v
+	}
+
+	tracef("generic NOT available for flag name %[1]q (cmd=%[2]q)", name, cmd.Name)
+	return nil
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// levelValue is a flag.Value as implemented by third party packages
+type levelValue struct {
+	level string
+}
+
+func (l *levelValue) String() string { return l.level }
+
+func (l *levelValue) Set(s string) error {
+	switch s {
+	case "debug", "info", "error":
+		l.level = s
+		return nil
+	}
+	return fmt.Errorf("unknown level %q", s)
+}
+
+// verboseValue is a boolean flag.Value
+type verboseValue struct {
+	on bool
+}
+
+func (v *verboseValue) String() string   { return fmt.Sprint(v.on) }
+func (v *verboseValue) Set(string) error { v.on = true; return nil }
+func (v *verboseValue) IsBoolFlag() bool { return true }
+
+func TestGenericFlag(t *testing.T) {
+	level := &levelValue{level: "info"}
+	verbose := &verboseValue{}
+
+	var got flag.Value
+	cmd := &Command{
+		Name: "app",
+		Flags: []Flag{
+			&GenericFlag{Name: "level", Usage: "log level", Value: level},
+			&GenericFlag{Name: "verbose", Value: verbose},
+		},
+		Action: func(_ context.Context, cmd *Command) error {
+			got = cmd.Generic("level")
+			return nil
+		},
+	}
+
+	r := require.New(t)
+	r.NoError(cmd.Run(buildTestContext(t), []string{"app", "--verbose", "--level", "debug"}))
+	r.Equal("debug", level.level)
+	r.True(verbose.on)
+	r.Same(level, got)
+	r.Nil(cmd.Generic("missing"))
+}
+
+func TestGenericFlagErrors(t *testing.T) {
+	cmd := &Command{
+		Name:   "app",
+		Writer: &strings.Builder{},
+		Flags: []Flag{
+			&GenericFlag{Name: "level", Value: &levelValue{}},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	err := cmd.Run(buildTestContext(t), []string{"app", "--level", "trace"})
+	require.ErrorContains(t, err, `unknown level "trace"`)
+}
+
+func TestGenericFlagSources(t *testing.T) {
+	level := &levelValue{level: "info"}
+
+	cmd := &Command{
+		Name: "app",
+		Env:  MapEnv{"APP_LEVEL": "error"},
+		Flags: []Flag{
+			&GenericFlag{Name: "level", Value: level, Sources: EnvVars("APP_LEVEL")},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+	assert.Equal(t, "error", level.level)
+}
+
+func TestGenericFlagDestination(t *testing.T) {
+	var dest flag.Value = &levelValue{level: "info"}
+
+	cmd := &Command{
+		Name: "app",
+		Flags: []Flag{
+			&GenericFlag{Name: "level", Destination: &dest},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--level", "debug"}))
+	assert.Equal(t, "debug", dest.String())
+}
+
+func TestGenericFlagString(t *testing.T) {
+	fl := &GenericFlag{Name: "level", Usage: "log level", Value: &levelValue{level: "info"}}
+	assert.Equal(t, "--level value\tlog level (default: info)", fl.String())
+	assert.True(t, fl.TakesValue())
+
+	bfl := &GenericFlag{Name: "verbose", Value: &verboseValue{}}
+	assert.False(t, bfl.TakesValue())
+
+	assert.True(t, (&GenericFlag{Name: "empty"}).TakesValue())
+}
+
+func TestGenericFlagRecord(t *testing.T) {
+	cmd := &Command{
+		Name: "app",
+		Flags: []Flag{
+			&GenericFlag{Name: "level", Value: &levelValue{level: "info"}},
+		},
+	}
+
+	inv, err := cmd.Record(buildTestContext(t), []string{"app", "--level", "debug"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `"debug"`, string(inv.Flags["level"].Value))
+}
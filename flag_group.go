@@ -0,0 +1,122 @@
+package cli
+
+// groupedFlag is implemented by flags which can be related to other flags
+// by the groups of a command
+type groupedFlag interface {
+	setFlagGroups(together, conflicts []string)
+}
+
+// setupFlagGroups relates the flags of the command to the others of their
+// RequiredTogetherFlags and MutuallyExclusiveFlags groups, so the relations
+// are checked and shown in help like the ones set by Requires
+func (cmd *Command) setupFlagGroups() {
+	tracef("setting up flag groups (cmd=%[1]q)", cmd.Name)
+
+	flags := cmd.allFlags()
+	together := map[groupedFlag][]string{}
+	conflicts := map[groupedFlag][]string{}
+
+	for _, grp := range cmd.RequiredTogetherFlags {
+		for _, name := range grp {
+			gf, ok := flagNamed(flags, name).(groupedFlag)
+			if !ok {
+				continue
+			}
+			for _, other := range grp {
+				if other != name {
+					together[gf] = appendUnique(together[gf], other)
+				}
+			}
+		}
+	}
+
+	for _, grp := range cmd.MutuallyExclusiveFlags {
+		for i, grpf := range grp.Flags {
+			for _, f := range grpf {
+				gf, ok := f.(groupedFlag)
+				if !ok {
+					continue
+				}
+				for j, other := range grp.Flags {
+					if i == j {
+						continue
+					}
+					for _, of := range other {
+						conflicts[gf] = appendUnique(conflicts[gf], of.Names()[0])
+					}
+				}
+			}
+		}
+	}
+
+	for _, f := range flags {
+		if gf, ok := f.(groupedFlag); ok {
+			gf.setFlagGroups(together[gf], conflicts[gf])
+		}
+	}
+}
+
+// checkRequiresFlags returns an error for the first flag of the command
+// which is set without the flags it requires
+func (cmd *Command) checkRequiresFlags() error {
+	for _, f := range cmd.allFlags() {
+		rf, ok := f.(RelatedFlag)
+		if !ok || !cmd.isFlagSet(f) {
+			continue
+		}
+
+		var missing []string
+		for _, name := range rf.RequiresFlags() {
+			if !cmd.IsSet(name) {
+				missing = append(missing, name)
+			}
+		}
+
+		if len(missing) > 0 {
+			tracef("flag %[1]q set without %[2]q (cmd=%[3]q)", f.Names()[0], missing, cmd.Name)
+
+			return &flagRequiresFlags{flagName: f.Names()[0], missing: missing}
+		}
+	}
+
+	return nil
+}
+
+// isFlagSet returns whether the flag has been set by any of its names
+func (cmd *Command) isFlagSet(f Flag) bool {
+	for _, name := range f.Names() {
+		if cmd.IsSet(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// flagNamed returns the flag having the name, nil if there is none
+func flagNamed(flags []Flag, name string) Flag {
+	for _, f := range flags {
+		for _, n := range f.Names() {
+			if n == name {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// appendUnique appends the names which are not in the slice yet
+func appendUnique(names []string, more ...string) []string {
+	for _, name := range more {
+		found := false
+		for _, n := range names {
+			if n == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			names = append(names, name)
+		}
+	}
+	return names
+}
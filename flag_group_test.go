@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildFlagGroupTestCommand() *Command {
+	return &Command{
+		Name:   "app",
+		Writer: io.Discard,
+		Flags: []Flag{
+			&StringFlag{Name: "user"},
+			&StringFlag{Name: "password"},
+			&StringFlag{Name: "tls-cert", Requires: []string{"tls-key", "tls-ca"}},
+			&StringFlag{Name: "tls-key"},
+			&StringFlag{Name: "tls-ca", Sources: EnvVars("APP_TLS_CA")},
+		},
+		RequiredTogetherFlags: [][]string{{"user", "password"}},
+		MutuallyExclusiveFlags: []MutuallyExclusiveFlags{{
+			Flags: [][]Flag{
+				{&BoolFlag{Name: "json"}},
+				{&BoolFlag{Name: "yaml"}},
+			},
+		}},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+}
+
+func TestFlagGroups(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		env         MapEnv
+		expectedErr string
+	}{
+		{name: "none", args: []string{"app"}},
+		{name: "together", args: []string{"app", "--user", "u", "--password", "p"}},
+		{name: "together missing", args: []string{"app", "--password", "p"}, expectedErr: "option password requires option user to be set"},
+		{name: "requires", args: []string{"app", "--tls-cert", "c", "--tls-key", "k", "--tls-ca", "ca"}},
+		{name: "requires from env", args: []string{"app", "--tls-cert", "c", "--tls-key", "k"}, env: MapEnv{"APP_TLS_CA": "ca"}},
+		{name: "requires missing", args: []string{"app", "--tls-cert", "c"}, expectedErr: "option tls-cert requires options tls-key, tls-ca to be set"},
+		{name: "required alone", args: []string{"app", "--tls-key", "k"}},
+		{name: "exclusive", args: []string{"app", "--json", "--yaml"}, expectedErr: "option json cannot be set along with option yaml"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := buildFlagGroupTestCommand()
+			cmd.Env = test.env
+			if cmd.Env == nil {
+				cmd.Env = MapEnv{}
+			}
+
+			err := cmd.Run(buildTestContext(t), test.args)
+			if test.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedErr)
+			}
+		})
+	}
+}
+
+func TestFlagGroupsCollectValidationErrors(t *testing.T) {
+	cmd := buildFlagGroupTestCommand()
+	cmd.CollectValidationErrors = true
+
+	err := cmd.Run(buildTestContext(t), []string{"app", "--user", "u", "--tls-cert", "c", "--tls-key", "k", "--tls-ca", "ca", "--json", "--yaml"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "option user requires option password to be set")
+	assert.Contains(t, err.Error(), "option json cannot be set along with option yaml")
+}
+
+func TestFlagGroupsHelp(t *testing.T) {
+	var out bytes.Buffer
+	cmd := buildFlagGroupTestCommand()
+	cmd.Writer = &out
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--help"}))
+	assert.Contains(t, out.String(), "--user value      (requires --password)")
+	assert.Contains(t, out.String(), "--tls-cert value  (requires --tls-key, --tls-ca)")
+	assert.Contains(t, out.String(), "--json            (default: false) (conflicts with --yaml)")
+
+}
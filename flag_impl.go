@@ -2,9 +2,11 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"reflect"
+	"strconv"
 )
 
 // Value represents a value as used by cli.
@@ -18,14 +20,28 @@ type boolFlag interface {
 	IsBoolFlag() bool
 }
 
+// defaultValidator is implemented by values checking the default of the flag
+type defaultValidator interface {
+	validateDefault() error
+}
+
 type fnValue struct {
 	fn     func(string) error
 	isBool bool
 	v      Value
+	// collect records errors instead of returning them to continue parsing
+	collect func(value string, err error)
 }
 
-func (f *fnValue) Get() any           { return f.v.Get() }
-func (f *fnValue) Set(s string) error { return f.fn(s) }
+func (f *fnValue) Get() any { return f.v.Get() }
+func (f *fnValue) Set(s string) error {
+	err := f.fn(s)
+	if err != nil && f.collect != nil {
+		f.collect(s, err)
+		return nil
+	}
+	return err
+}
 func (f *fnValue) String() string {
 	if f.v == nil {
 		return ""
@@ -75,6 +91,7 @@ type FlagBase[T any, C any, VC ValueCreator[T, C]] struct {
 	Usage       string                                   `json:"usage"`        // usage string for help output
 	Sources     ValueSourceChain                         `json:"-"`            // sources to load flag value from
 	Required    bool                                     `json:"required"`     // whether the flag is required or not
+	Sensitive   bool                                     `json:"sensitive"`    // whether the flag value must not be echoed when prompted for
 	Hidden      bool                                     `json:"hidden"`       // whether to hide the flag in help output
 	Persistent  bool                                     `json:"persistent"`   // whether the flag needs to be applied to subcommands as well
 	Value       T                                        `json:"defaultValue"` // default value for this flag if not set by from any source
@@ -85,19 +102,84 @@ type FlagBase[T any, C any, VC ValueCreator[T, C]] struct {
 	Config      C                                        `json:"config"`       // Additional/Custom configuration associated with this flag type
 	OnlyOnce    bool                                     `json:"onlyOnce"`     // whether this flag can be duplicated on the command line
 	Validator   func(T) error                            `json:"-"`            // custom function to validate this flag value
+	Requires    []string                                 `json:"requires"`     // names of the flags which have to be set along with this flag
+
+	// DefaultFunc computes the default value, e.g. the current directory,
+	// instead of Value. It is only called when the flag hasn't been set on
+	// the command line or by a source and no help or version is shown. Its
+	// errors are returned by Run. The computed value isn't shown in the help
+	// output and the docs, which show the DefaultText describing it instead.
+	DefaultFunc func() (T, error) `json:"-"`
+
+	// OnChange is called after Action when the flag has been set and
+	// additionally receives the default value that got replaced and the
+	// source the value was read from
+	OnChange func(context.Context, *Command, FlagChange[T]) error `json:"-"`
+
+	// Deprecated is the message of the warning emitted when the flag is
+	// used, e.g. "use --output instead". Deprecated flags are annotated in
+	// the help output.
+	Deprecated string `json:"deprecated"`
+	// ReplacedBy is the name of the flag the values given for this flag on
+	// the command line are forwarded to. The flag is deprecated in favor of
+	// the replacement even if Deprecated is empty.
+	ReplacedBy string `json:"replacedBy"`
+
+	// Examples are uses of the flag like "--tag v1.2.0" documented in the
+	// help output and the docs
+	Examples []string `json:"examples"`
+	// Since is the version of the program the flag has been added in
+	Since string `json:"since"`
+	// SeeAlso are references to related flags or further documentation
+	// like URLs, documented in the help output and the docs
+	SeeAlso []string `json:"seeAlso"`
 
 	// unexported fields for internal use
-	count      int   // number of times the flag has been set
-	hasBeenSet bool  // whether the flag has been set from env or file
-	applied    bool  // whether the flag has been applied to a flag set already
-	creator    VC    // value creator for this flag type
-	value      Value // value representing this flag's value
+	count           int           // number of times the flag has been set
+	defaultValue    T             // default value of the flag, the one computed by DefaultFunc if set
+	defaultComputed bool          // whether the default value has been computed by DefaultFunc
+	hasBeenSet      bool          // whether the flag has been set from env or file
+	applied         bool          // whether the flag has been applied to a flag set already
+	creator         VC            // value creator for this flag type
+	value           Value         // value representing this flag's value
+	source          ValueSource   // source the value was read from, nil if not set or set on the command line
+	pendingSources  []ValueSource // sources left to be looked up once the value is needed, see resolvePendingSources
+	env             Environment   // environment to look up env var sources in, the process environment if nil
+	clock           Clock         // clock of the command the flag is applied to, time.Now if nil
+	envPrefix       string        // EnvPrefix of the root command deriving the env var of flags without sources
+	together        []string      // flags required along with this one by a RequiredTogetherFlags group
+	conflicts       []string      // flags excluded by this one by a MutuallyExclusiveFlags group
+}
+
+// FlagChange describes a flag value which has been set along with the
+// default it replaced and where it came from
+type FlagChange[T any] struct {
+	// Value is the new value of the flag
+	Value T
+	// Previous is the default value which has been replaced
+	Previous T
+	// Source is the value source the value was read from or nil
+	// if the value was given on the command line
+	Source ValueSource
+}
+
+// FromCommandLine returns true if the value was given on the command line
+func (fc FlagChange[T]) FromCommandLine() bool {
+	return fc.Source == nil
+}
+
+// Origin returns a readable representation of where the value came from
+func (fc FlagChange[T]) Origin() string {
+	if fc.Source == nil {
+		return "command line"
+	}
+	return fc.Source.String()
 }
 
 // GetValue returns the flags value as string representation and an empty
 // string if the flag takes no value at all.
 func (f *FlagBase[T, C, V]) GetValue() string {
-	if reflect.TypeOf(f.Value).Kind() == reflect.Bool {
+	if !f.TakesValue() {
 		return ""
 	}
 	return fmt.Sprintf("%v", f.Value)
@@ -112,29 +194,42 @@ func (f *FlagBase[T, C, V]) Apply(set *flag.FlagSet) error {
 	// flag can be applied to different flag sets multiple times while still
 	// keeping the env set.
 	if !f.applied || !f.Persistent {
-		newVal := f.Value
-
-		if val, source, found := f.Sources.LookupWithSource(); found {
-			tmpVal := f.creator.Create(f.Value, new(T), f.Config)
-			if val != "" || reflect.TypeOf(f.Value).Kind() == reflect.String {
-				if err := tmpVal.Set(val); err != nil {
-					return fmt.Errorf(
-						"could not parse %[1]q as %[2]T value from %[3]s for flag %[4]s: %[5]s",
-						val, f.Value, source, f.Name, err,
-					)
-				}
-			} else if val == "" && reflect.TypeOf(f.Value).Kind() == reflect.Bool {
-				val = "false"
-				if err := tmpVal.Set(val); err != nil {
-					return fmt.Errorf(
-						"could not parse %[1]q as %[2]T value from %[3]s for flag %[4]s: %[5]s",
-						val, f.Value, source, f.Name, err,
-					)
-				}
+		f.defaultValue = f.Value
+		f.defaultComputed = false
+
+		newVal := f.defaultValue
+
+		// a flag applied again, e.g. when the command is run once more,
+		// starts over as not set, so IsSet and the count of the previous
+		// run don't leak into this one
+		f.source = nil
+		f.hasBeenSet = false
+		f.count = 0
+
+		// Values restricting what they accept, e.g. to choices, refuse a
+		// default they would not accept either
+		defVal := f.creator.Create(f.defaultValue, new(T), f.Config)
+		if dv, ok := defVal.(defaultValidator); ok {
+			if err := dv.validateDefault(); err != nil {
+				return fmt.Errorf(tr("invalid default %[1]q for flag %[2]s: %[3]w"), defVal.String(), f.Name, err)
 			}
+		}
 
-			newVal = tmpVal.Get().(T)
+		val, source, found, pending, err := f.sources().lookupWithSourceIn(nil, f.env)
+		if err != nil {
+			return fmt.Errorf(tr("could not look up value for flag %[1]s: %[2]w"), f.Name, err)
+		}
+		f.pendingSources = pending
+
+		if found {
+			v, err := f.parseSourceValue(val, source)
+			if err != nil {
+				return err
+			}
+
+			newVal = v
 			f.hasBeenSet = true
+			f.source = source
 		}
 
 		if f.Destination == nil {
@@ -143,9 +238,11 @@ func (f *FlagBase[T, C, V]) Apply(set *flag.FlagSet) error {
 			f.value = f.creator.Create(newVal, f.Destination, f.Config)
 		}
 
+		f.prepareValue(f.value)
+
 		// Validate the given default or values set from external sources as well
 		if f.Validator != nil {
-			if v, ok := f.value.Get().(T); !ok {
+			if v, ok := valueAs[T](f.value); !ok {
 				return &typeError[T]{
 					other: f.value.Get(),
 				}
@@ -160,27 +257,62 @@ func (f *FlagBase[T, C, V]) Apply(set *flag.FlagSet) error {
 		isBool = true
 	}
 
+	setValue := func(val string) error {
+		if f.count == 1 && f.OnlyOnce {
+			return fmt.Errorf("cant duplicate this flag")
+		}
+		f.count++
+		if err := f.value.Set(val); err != nil {
+			return err
+		}
+		f.hasBeenSet = true
+		f.source = nil
+		if f.Validator != nil {
+			if v, ok := valueAs[T](f.value); !ok {
+				return &typeError[T]{
+					other: f.value.Get(),
+				}
+			} else if err := f.Validator(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if f.ReplacedBy != "" {
+		setOwnValue := setValue
+		setValue = func(val string) error {
+			if err := setOwnValue(val); err != nil {
+				return err
+			}
+			// the value is set directly as the flag set would refuse
+			// two forms of the replacement being set
+			rf := set.Lookup(f.ReplacedBy)
+			if rf == nil {
+				return fmt.Errorf("no such flag -%s", f.ReplacedBy)
+			}
+			return rf.Value.Set(val)
+		}
+	}
+
 	for _, name := range f.Names() {
+		set.Var(&fnValue{
+			fn:     setValue,
+			isBool: isBool,
+			v:      f.value,
+		}, name, f.Usage)
+	}
+
+	// the negated names invert the given value, so --no-color=false sets
+	// the flag to true like --color does
+	for _, name := range f.GetNegatedNames() {
 		set.Var(&fnValue{
 			fn: func(val string) error {
-				if f.count == 1 && f.OnlyOnce {
-					return fmt.Errorf("cant duplicate this flag")
+				b, err := strconv.ParseBool(val)
+				if err != nil {
+					return errors.New("parse error")
 				}
-				f.count++
-				if err := f.value.Set(val); err != nil {
-					return err
-				}
-				f.hasBeenSet = true
-				if f.Validator != nil {
-					if v, ok := f.value.Get().(T); !ok {
-						return &typeError[T]{
-							other: f.value.Get(),
-						}
-					} else if err := f.Validator(v); err != nil {
-						return err
-					}
-				}
-				return nil
+				return setValue(strconv.FormatBool(!b))
 			},
 			isBool: isBool,
 			v:      f.value,
@@ -191,6 +323,129 @@ func (f *FlagBase[T, C, V]) Apply(set *flag.FlagSet) error {
 	return nil
 }
 
+// defaultFuncFlag is implemented by flags computing their default value
+// after the arguments have been parsed
+type defaultFuncFlag interface {
+	applyDefaultFunc(*flag.FlagSet) error
+}
+
+// applyDefaultFunc sets the flag to the default value computed by
+// DefaultFunc unless the flag has been set or may still be set by its
+// pending sources
+func (f *FlagBase[T, C, V]) applyDefaultFunc(set *flag.FlagSet) error {
+	if f.DefaultFunc == nil || f.defaultComputed || f.hasBeenSet || f.value == nil || len(f.pendingSources) > 0 {
+		return nil
+	}
+
+	v, err := f.DefaultFunc()
+	if err != nil {
+		return fmt.Errorf(tr("could not compute default value for flag %[1]s: %[2]w"), f.Name, err)
+	}
+	f.defaultValue = v
+	f.defaultComputed = true
+
+	return f.replaceValue(set, v)
+}
+
+// pendingSourcesFlag is implemented by flags whose sources are looked up
+// once the value is needed, see FlagBase.resolvePendingSources
+type pendingSourcesFlag interface {
+	resolvePendingSources(ctx context.Context, set *flag.FlagSet) error
+}
+
+// resolvePendingSources looks up the sources which have been left to be
+// looked up when the flag was applied, e.g. remote sources, unless the flag
+// has been set on the command line
+func (f *FlagBase[T, C, V]) resolvePendingSources(ctx context.Context, set *flag.FlagSet) error {
+	pending := f.pendingSources
+	f.pendingSources = nil
+	if len(pending) == 0 || f.hasBeenSet || f.value == nil {
+		return nil
+	}
+
+	val, source, found, _, err := lookupSourcesIn(ctx, f.env, pending)
+	if err != nil {
+		return fmt.Errorf(tr("could not look up value for flag %[1]s: %[2]w"), f.Name, err)
+	}
+	if !found {
+		return nil
+	}
+
+	v, err := f.parseSourceValue(val, source)
+	if err != nil {
+		return err
+	}
+	f.hasBeenSet = true
+	f.source = source
+
+	return f.replaceValue(set, v)
+}
+
+// parseSourceValue parses the value read from the source
+func (f *FlagBase[T, C, V]) parseSourceValue(val string, source ValueSource) (T, error) {
+	tmpVal := f.creator.Create(f.defaultValue, new(T), f.Config)
+	f.prepareValue(tmpVal)
+	if val != "" || kindOf(f.Value) == reflect.String {
+		if err := tmpVal.Set(val); err != nil {
+			var zero T
+			return zero, fmt.Errorf(
+				tr("could not parse %[1]q as %[2]T value from %[3]s for flag %[4]s: %[5]s"),
+				val, f.Value, source, f.Name, err,
+			)
+		}
+	} else if val == "" && kindOf(f.Value) == reflect.Bool {
+		val = "false"
+		if err := tmpVal.Set(val); err != nil {
+			var zero T
+			return zero, fmt.Errorf(
+				tr("could not parse %[1]q as %[2]T value from %[3]s for flag %[4]s: %[5]s"),
+				val, f.Value, source, f.Name, err,
+			)
+		}
+	}
+
+	v, ok := valueAs[T](tmpVal)
+	if !ok {
+		return v, &typeError[T]{other: tmpVal.Get()}
+	}
+	return v, nil
+}
+
+// replaceValue replaces the value of the flag applied to the flag set with
+// a new one holding v and validates it
+func (f *FlagBase[T, C, V]) replaceValue(set *flag.FlagSet, v T) error {
+	if f.Destination == nil {
+		f.value = f.creator.Create(v, new(T), f.Config)
+	} else {
+		f.value = f.creator.Create(v, f.Destination, f.Config)
+	}
+	f.prepareValue(f.value)
+
+	for _, name := range append(f.Names(), f.GetNegatedNames()...) {
+		if fl := set.Lookup(name); fl != nil {
+			if fv, ok := fl.Value.(*fnValue); ok {
+				fv.v = f.value
+			}
+		}
+	}
+
+	if f.Validator != nil {
+		return f.Validator(v)
+	}
+	return nil
+}
+
+// prepareValue passes the clock and environment of the command the flag is
+// applied to to the value
+func (f *FlagBase[T, C, V]) prepareValue(v Value) {
+	if cv, ok := v.(clockValue); ok && f.clock != nil {
+		cv.setClock(f.clock)
+	}
+	if ev, ok := v.(envValue); ok && f.env != nil {
+		ev.setEnv(f.env)
+	}
+}
+
 // String returns a readable representation of this value (for usage defaults)
 func (f *FlagBase[T, C, V]) String() string {
 	return FlagStringer(f)
@@ -211,6 +466,53 @@ func (f *FlagBase[T, C, V]) IsRequired() bool {
 	return f.Required
 }
 
+// RequiresFlags returns the names of the flags which have to be set when
+// the flag is set, by Requires or a RequiredTogetherFlags group of the
+// command
+func (f *FlagBase[T, C, V]) RequiresFlags() []string {
+	return appendUnique(appendUnique(nil, f.Requires...), f.together...)
+}
+
+// ConflictingFlags returns the names of the flags which cannot be set along
+// with the flag because of a MutuallyExclusiveFlags group of the command
+func (f *FlagBase[T, C, V]) ConflictingFlags() []string {
+	return f.conflicts
+}
+
+func (f *FlagBase[T, C, V]) setFlagGroups(together, conflicts []string) {
+	f.together = together
+	f.conflicts = conflicts
+}
+
+// IsSensitive returns whether or not the flag value is sensitive
+func (f *FlagBase[T, C, V]) IsSensitive() bool {
+	return f.Sensitive
+}
+
+// GetDeprecated returns the message of the deprecation warning or "" if the
+// flag isn't deprecated
+func (f *FlagBase[T, C, V]) GetDeprecated() string {
+	if f.Deprecated == "" && f.ReplacedBy != "" {
+		return "use " + prefixedNames([]string{f.ReplacedBy}, "") + " instead"
+	}
+	return f.Deprecated
+}
+
+// GetExamples returns the examples of the uses of the flag
+func (f *FlagBase[T, C, V]) GetExamples() []string {
+	return f.Examples
+}
+
+// GetSince returns the version the flag has been added in
+func (f *FlagBase[T, C, V]) GetSince() string {
+	return f.Since
+}
+
+// GetSeeAlso returns the references to related flags or documentation
+func (f *FlagBase[T, C, V]) GetSeeAlso() []string {
+	return f.SeeAlso
+}
+
 // IsVisible returns true if the flag is not hidden, otherwise false
 func (f *FlagBase[T, C, V]) IsVisible() bool {
 	return !f.Hidden
@@ -232,13 +534,19 @@ func (f *FlagBase[T, C, V]) GetUsage() string {
 
 // GetEnvVars returns the env vars for this flag
 func (f *FlagBase[T, C, V]) GetEnvVars() []string {
-	return f.Sources.EnvKeys()
+	return f.sources().EnvKeys()
 }
 
 // TakesValue returns true if the flag takes a value, otherwise false
 func (f *FlagBase[T, C, V]) TakesValue() bool {
-	var t T
-	return reflect.TypeOf(t).Kind() != reflect.Bool
+	if b, ok := any(f.Value).(boolFlag); ok && b.IsBoolFlag() {
+		return false
+	}
+	var v V
+	if b, ok := any(&v).(boolFlag); ok && b.IsBoolFlag() {
+		return false
+	}
+	return kindOf(f.Value) != reflect.Bool
 }
 
 // GetDefaultText returns the default text for this flag
@@ -246,23 +554,33 @@ func (f *FlagBase[T, C, V]) GetDefaultText() string {
 	if f.DefaultText != "" {
 		return f.DefaultText
 	}
+	if f.DefaultFunc != nil {
+		return ""
+	}
 	var v V
 	return v.ToString(f.Value)
 }
 
 // Get returns the flag’s value in the given Command.
 func (f *FlagBase[T, C, V]) Get(cmd *Command) T {
-	if v, ok := cmd.Value(f.Name).(T); ok {
-		return v
-	}
-	var t T
-	return t
+	v, _ := lookupValue[T](cmd, f.Name)
+	return v
 }
 
 // RunAction executes flag action if set
 func (f *FlagBase[T, C, V]) RunAction(ctx context.Context, cmd *Command) error {
 	if f.Action != nil {
-		return f.Action(ctx, cmd, f.Get(cmd))
+		if err := f.Action(ctx, cmd, f.Get(cmd)); err != nil {
+			return err
+		}
+	}
+
+	if f.OnChange != nil {
+		return f.OnChange(ctx, cmd, FlagChange[T]{
+			Value:    f.Get(cmd),
+			Previous: f.defaultValue,
+			Source:   f.source,
+		})
 	}
 
 	return nil
@@ -271,11 +589,20 @@ func (f *FlagBase[T, C, V]) RunAction(ctx context.Context, cmd *Command) error {
 // IsMultiValueFlag returns true if the value type T can take multiple
 // values from cmd line. This is true for slice and map type flags
 func (f *FlagBase[T, C, VC]) IsMultiValueFlag() bool {
-	// TBD how to specify
-	kind := reflect.TypeOf(f.Value).Kind()
+	var vc VC
+	if _, ok := any(vc).(multiValueCreator); ok {
+		return true
+	}
+	kind := kindOf(f.Value)
 	return kind == reflect.Slice || kind == reflect.Map
 }
 
+// multiValueCreator is implemented by the value creators of slice and map
+// flags
+type multiValueCreator interface {
+	isMultiValue()
+}
+
 // IsPersistent returns true if flag needs to be persistent across subcommands
 func (f *FlagBase[T, C, VC]) IsPersistent() bool {
 	return f.Persistent
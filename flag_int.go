@@ -44,12 +44,14 @@ func (i *intValue) Set(s string) error {
 
 func (i *intValue) Get() any { return int64(*i.val) }
 
+func (i *intValue) typedGet() int64 { return *i.val }
+
 func (i *intValue) String() string { return strconv.FormatInt(int64(*i.val), 10) }
 
 // Int looks up the value of a local Int64Flag, returns
 // 0 if not found
 func (cmd *Command) Int(name string) int64 {
-	if v, ok := cmd.Value(name).(int64); ok {
+	if v, ok := lookupValue[int64](cmd, name); ok {
 		tracef("int available for flag name %[1]q with value=%[2]v (cmd=%[3]q)", name, v, cmd.Name)
 		return v
 	}
@@ -0,0 +1,20 @@
+package cli
+
+type (
+	IntMap     = MapBase[int64, IntegerConfig, intValue]
+	IntMapFlag = FlagBase[map[string]int64, IntegerConfig, IntMap]
+)
+
+var NewIntMap = NewMapBase[int64, IntegerConfig, intValue]
+
+// IntMap looks up the value of a local IntMapFlag, returns
+// nil if not found
+func (cmd *Command) IntMap(name string) map[string]int64 {
+	if v, ok := lookupValue[map[string]int64](cmd, name); ok {
+		tracef("int map available for flag name %[1]q with value=%[2]v (cmd=%[3]q)", name, v, cmd.Name)
+		return v
+	}
+
+	tracef("int map NOT available for flag name %[1]q (cmd=%[2]q)", name, cmd.Name)
+	return nil
+}
@@ -10,7 +10,7 @@ var NewIntSlice = NewSliceBase[int64, IntegerConfig, intValue]
 // IntSlice looks up the value of a local IntSliceFlag, returns
 // nil if not found
 func (cmd *Command) IntSlice(name string) []int64 {
-	if v, ok := cmd.Value(name).([]int64); ok {
+	if v, ok := lookupValue[[]int64](cmd, name); ok {
 		tracef("int slice available for flag name %[1]q with value=%[2]v (cmd=%[3]q)", name, v, cmd.Name)
 		return v
 	}
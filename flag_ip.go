@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+)
+
+type (
+	// IPFlag is a flag taking an IPv4 or IPv6 address
+	IPFlag = FlagBase[net.IP, IPConfig, ipValue]
+	// CIDRFlag is a flag taking an IP network in CIDR notation like
+	// 192.168.0.0/16
+	CIDRFlag = FlagBase[netip.Prefix, IPConfig, cidrValue]
+)
+
+// IPConfig defines the configuration for IP and CIDR flags
+type IPConfig struct {
+	// Version of the accepted addresses, 4 or 6, any version if 0
+	Version int
+}
+
+// checkVersion returns an error if the address is not of the configured
+// version
+func (c IPConfig) checkVersion(addr netip.Addr) error {
+	switch {
+	case c.Version == 4 && !addr.Unmap().Is4():
+		return errors.New("not an IPv4 address")
+	case c.Version == 6 && (!addr.Is6() || addr.Is4In6()):
+		return errors.New("not an IPv6 address")
+	}
+	return nil
+}
+
+// -- ip Value
+type ipValue struct {
+	destination *net.IP
+	config      IPConfig
+}
+
+// Below functions are to satisfy the ValueCreator interface
+
+func (i ipValue) Create(val net.IP, p *net.IP, c IPConfig) Value {
+	*p = val
+	return &ipValue{
+		destination: p,
+		config:      c,
+	}
+}
+
+func (i ipValue) ToString(val net.IP) string {
+	if val == nil {
+		return ""
+	}
+	return val.String()
+}
+
+// Below functions are to satisfy the flag.Value interface
+
+func (i *ipValue) Set(s string) error {
+	// net.IP cannot hold the zone of IPv6 addresses like fe80::1%eth0
+	addr, err := netip.ParseAddr(s)
+	if err != nil || addr.Zone() != "" {
+		return fmt.Errorf("invalid IP address %q", s)
+	}
+	if err := i.config.checkVersion(addr); err != nil {
+		return err
+	}
+
+	*i.destination = net.IP(addr.AsSlice())
+	return nil
+}
+
+func (i *ipValue) Get() any { return *i.destination }
+
+func (i *ipValue) typedGet() net.IP { return *i.destination }
+
+func (i *ipValue) String() string {
+	if i.destination != nil {
+		return i.ToString(*i.destination)
+	}
+	return ""
+}
+
+// IP looks up the value of a local IPFlag, returns nil if not found
+func (cmd *Command) IP(name string) net.IP {
+	if v, ok := lookupValue[net.IP](cmd, name); ok {
+		tracef("ip available for flag name %[1]q with value=%[2]v (cmd=%[3]q)", name, v, cmd.Name)
+		return v
+	}
+
+	tracef("ip NOT available for flag name %[1]q (cmd=%[2]q)", name, cmd.Name)
+	return nil
+}
+
+// -- cidr Value
+type cidrValue struct {
+	destination *netip.Prefix
+	config      IPConfig
+}
+
+// Below functions are to satisfy the ValueCreator interface
+
+func (c cidrValue) Create(val netip.Prefix, p *netip.Prefix, config IPConfig) Value {
+	*p = val
+	return &cidrValue{
+		destination: p,
+		config:      config,
+	}
+}
+
+func (c cidrValue) ToString(val netip.Prefix) string {
+	if !val.IsValid() {
+		return ""
+	}
+	return val.String()
+}
+
+// Below functions are to satisfy the flag.Value interface
+
+func (c *cidrValue) Set(s string) error {
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return fmt.Errorf("invalid CIDR %q", s)
+	}
+	if err := c.config.checkVersion(prefix.Addr()); err != nil {
+		return err
+	}
+
+	*c.destination = prefix
+	return nil
+}
+
+func (c *cidrValue) Get() any { return *c.destination }
+
+func (c *cidrValue) typedGet() netip.Prefix { return *c.destination }
+
+func (c *cidrValue) String() string {
+	if c.destination != nil {
+		return c.ToString(*c.destination)
+	}
+	return ""
+}
+
+// CIDR looks up the value of a local CIDRFlag, returns the zero prefix if
+// not found
+func (cmd *Command) CIDR(name string) netip.Prefix {
+	if v, ok := lookupValue[netip.Prefix](cmd, name); ok {
+		tracef("cidr available for flag name %[1]q with value=%[2]v (cmd=%[3]q)", name, v, cmd.Name)
+		return v
+	}
+
+	tracef("cidr NOT available for flag name %[1]q (cmd=%[2]q)", name, cmd.Name)
+	return netip.Prefix{}
+}
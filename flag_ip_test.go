@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPFlag(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		config   IPConfig
+		value    string
+		expected net.IP
+		err      string
+	}{
+		{name: "v4", value: "192.168.0.1", expected: net.ParseIP("192.168.0.1")},
+		{name: "v6", value: "2001:db8::1", expected: net.ParseIP("2001:db8::1")},
+		{name: "only v4", config: IPConfig{Version: 4}, value: "10.0.0.1", expected: net.ParseIP("10.0.0.1")},
+		{name: "not v4", config: IPConfig{Version: 4}, value: "::1", err: "not an IPv4 address"},
+		{name: "not v6", config: IPConfig{Version: 6}, value: "10.0.0.1", err: "not an IPv6 address"},
+		{name: "zone", value: "fe80::1%eth0", err: `invalid IP address "fe80::1%eth0"`},
+		{name: "invalid", value: "localhost", err: `invalid IP address "localhost"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := &Command{
+				Name:   "app",
+				Flags:  []Flag{&IPFlag{Name: "listen", Config: tc.config}},
+				Action: func(context.Context, *Command) error { return nil },
+			}
+
+			err := cmd.Run(buildTestContext(t), []string{"app", "--listen", tc.value})
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tc.expected.Equal(cmd.IP("listen")), "%s != %s", tc.expected, cmd.IP("listen"))
+		})
+	}
+}
+
+func TestCIDRFlag(t *testing.T) {
+	cmd := &Command{
+		Name: "app",
+		Env:  MapEnv{"ALLOW": "10.0.0.0/8"},
+		Flags: []Flag{
+			&CIDRFlag{Name: "allow", Sources: EnvVars("ALLOW")},
+			&CIDRFlag{Name: "deny", Config: IPConfig{Version: 6}},
+			&CIDRFlag{Name: "unset"},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--deny", "2001:db8::/32"}))
+	assert.Equal(t, netip.MustParsePrefix("10.0.0.0/8"), cmd.CIDR("allow"))
+	assert.Equal(t, netip.MustParsePrefix("2001:db8::/32"), cmd.CIDR("deny"))
+	assert.False(t, cmd.CIDR("unset").IsValid())
+	assert.Equal(t, "--allow value\t(default: 10.0.0.0/8)"+withEnvHint([]string{"ALLOW"}, ""), (&CIDRFlag{Name: "allow", Value: netip.MustParsePrefix("10.0.0.0/8"), Sources: EnvVars("ALLOW")}).String())
+
+	err := cmd.Run(buildTestContext(t), []string{"app", "--deny", "10.0.0.0/8"})
+	assert.ErrorContains(t, err, "not an IPv6 address")
+
+	err = cmd.Run(buildTestContext(t), []string{"app", "--deny", "2001:db8::"})
+	assert.ErrorContains(t, err, `invalid CIDR "2001:db8::"`)
+}
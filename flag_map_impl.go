@@ -8,7 +8,10 @@ import (
 	"strings"
 )
 
-// MapBase wraps map[string]T to satisfy flag.Value
+// MapBase wraps map[string]T to satisfy flag.Value. Each item is a key and
+// a value parsed by VC joined by the separator set by the root command's
+// MapFlagKeyValueSeparator. Flags of maps of other value types are
+// declared like IntMapFlag from the ValueCreator of the value type.
 type MapBase[T any, C any, VC ValueCreator[T, C]] struct {
 	dict       *map[string]T
 	hasBeenSet bool
@@ -58,7 +61,7 @@ func (i *MapBase[T, C, VC]) Set(value string) error {
 		if err := i.value.Set(value); err != nil {
 			return err
 		}
-		tmp, ok := i.value.Get().(T)
+		tmp, ok := valueAs[T](i.value)
 		if !ok {
 			return fmt.Errorf("unable to cast %v", i.value)
 		}
@@ -72,7 +75,7 @@ func (i *MapBase[T, C, VC]) Set(value string) error {
 func (i *MapBase[T, C, VC]) String() string {
 	v := i.Value()
 	var t T
-	if reflect.TypeOf(t).Kind() == reflect.String {
+	if kindOf(t) == reflect.String {
 		return fmt.Sprintf("%v", v)
 	}
 	return fmt.Sprintf("%T{%s}", v, i.ToString(v))
@@ -97,6 +100,14 @@ func (i *MapBase[T, C, VC]) Get() interface{} {
 	return *i.dict
 }
 
+func (i *MapBase[T, C, VC]) typedGet() map[string]T {
+	return *i.dict
+}
+
+// isMultiValue marks value creators of flags which can be given multiple
+// times, see FlagBase.IsMultiValueFlag
+func (i MapBase[T, C, VC]) isMultiValue() {}
+
 func (i MapBase[T, C, VC]) ToString(t map[string]T) string {
 	var defaultVals []string
 	var vc VC
@@ -0,0 +1,220 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileFlag is a string flag taking the path of a file, e.g.
+//
+//	&cli.FileFlag{
+//		Name:   "config",
+//		Value:  "~/.app.toml",
+//		Config: cli.PathConfig{Expand: true, MustExist: true, Readable: true},
+//	}
+//
+// The shell completions complete file names for its values, which are read
+// with Command.String.
+type FileFlag = FlagBase[string, PathConfig, fileValue]
+
+// DirFlag is a string flag taking the path of a directory like FileFlag
+type DirFlag = FlagBase[string, PathConfig, dirValue]
+
+// PathConfig defines the configuration of file and directory flags. The
+// checks are run for every value, including the ones from the flag's
+// sources, but not for the default value.
+type PathConfig struct {
+	// Whether to expand a leading ~ to the home directory and environment
+	// variables like $HOME or ${HOME}
+	Expand bool
+	// Whether the path has to exist
+	MustExist bool
+	// Whether the file or directory has to be readable, implies MustExist
+	Readable bool
+	// Whether the file or directory has to be writable, implies MustExist
+	Writable bool
+	// Whether to create a missing directory or an empty file along with
+	// its parent directories
+	Create bool
+}
+
+// fileFlag is implemented by flags which may take a file name as value
+type fileFlag interface {
+	takesFile() bool
+}
+
+func (f *FlagBase[T, C, VC]) takesFile() bool {
+	if _, ok := any(f.Config).(PathConfig); ok {
+		return true
+	}
+	return f.TakesFile
+}
+
+// envValue is implemented by values using the environment of the command
+// the flag is applied to
+type envValue interface {
+	setEnv(Environment)
+}
+
+// -- path Value
+type pathValue struct {
+	destination *string
+	config      PathConfig
+	dir         bool
+	env         Environment
+}
+
+type (
+	fileValue struct{ pathValue }
+	dirValue  struct{ pathValue }
+)
+
+// Below functions are to satisfy the ValueCreator interface
+
+func (v fileValue) Create(val string, p *string, c PathConfig) Value {
+	*p = val
+	return &pathValue{destination: p, config: c}
+}
+
+func (v dirValue) Create(val string, p *string, c PathConfig) Value {
+	*p = val
+	return &pathValue{destination: p, config: c, dir: true}
+}
+
+func (v pathValue) ToString(val string) string {
+	if val == "" {
+		return val
+	}
+	return fmt.Sprintf("%q", val)
+}
+
+// Below functions are to satisfy the flag.Value interface
+
+func (v *pathValue) Set(val string) error {
+	if v.config.Expand {
+		val = v.expand(val)
+	}
+
+	if err := v.check(val); err != nil {
+		return err
+	}
+
+	*v.destination = val
+	return nil
+}
+
+func (v *pathValue) Get() any { return *v.destination }
+
+func (v *pathValue) typedGet() string { return *v.destination }
+
+func (v *pathValue) String() string {
+	if v.destination != nil {
+		return *v.destination
+	}
+	return ""
+}
+
+func (v *pathValue) setEnv(env Environment) {
+	v.env = env
+}
+
+// expand expands a leading ~ and the environment variables of the path
+func (v *pathValue) expand(path string) string {
+	env := v.env
+	if env == nil {
+		env = osEnvironment{}
+	}
+
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		home, ok := env.LookupEnv("HOME")
+		if !ok {
+			home, _ = os.UserHomeDir()
+		}
+		if home != "" {
+			path = home + path[1:]
+		}
+	}
+
+	return os.Expand(path, func(key string) string {
+		value, _ := env.LookupEnv(key)
+		return value
+	})
+}
+
+// check checks and creates the path according to the config
+func (v *pathValue) check(path string) error {
+	kind := "file"
+	if v.dir {
+		kind = "directory"
+	}
+
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) && v.config.Create {
+		if err := v.create(path); err != nil {
+			return err
+		}
+		info, err = os.Stat(path)
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		if v.config.MustExist || v.config.Readable || v.config.Writable {
+			return fmt.Errorf("%s %s does not exist", kind, path)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if v.dir && !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+	if !v.dir && info.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+
+	if v.config.Readable {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("%s %s is not readable", kind, path)
+		}
+		_ = f.Close()
+	}
+
+	if v.config.Writable {
+		if v.dir {
+			f, err := os.CreateTemp(path, ".write-check-*")
+			if err != nil {
+				return fmt.Errorf("%s %s is not writable", kind, path)
+			}
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		} else {
+			f, err := os.OpenFile(path, os.O_WRONLY, 0)
+			if err != nil {
+				return fmt.Errorf("%s %s is not writable", kind, path)
+			}
+			_ = f.Close()
+		}
+	}
+
+	return nil
+}
+
+// create creates the missing directory or the empty file
+func (v *pathValue) create(path string) error {
+	if v.dir {
+		return os.MkdirAll(path, 0o755)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathFlags(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.toml")
+	require.NoError(t, os.WriteFile(file, nil, 0o644))
+
+	for _, tc := range []struct {
+		name     string
+		flag     Flag
+		value    string
+		expected string
+		err      string
+	}{
+		{
+			name:     "file",
+			flag:     &FileFlag{Name: "path", Config: PathConfig{MustExist: true, Readable: true, Writable: true}},
+			value:    file,
+			expected: file,
+		},
+		{
+			name:     "missing file",
+			flag:     &FileFlag{Name: "path"},
+			value:    filepath.Join(dir, "missing"),
+			expected: filepath.Join(dir, "missing"),
+		},
+		{
+			name:  "missing file must exist",
+			flag:  &FileFlag{Name: "path", Config: PathConfig{MustExist: true}},
+			value: filepath.Join(dir, "missing"),
+			err:   "file " + filepath.Join(dir, "missing") + " does not exist",
+		},
+		{
+			name:  "missing file readable",
+			flag:  &FileFlag{Name: "path", Config: PathConfig{Readable: true}},
+			value: filepath.Join(dir, "missing"),
+			err:   "does not exist",
+		},
+		{
+			name:  "file is directory",
+			flag:  &FileFlag{Name: "path"},
+			value: dir,
+			err:   dir + " is a directory",
+		},
+		{
+			name:  "directory is file",
+			flag:  &DirFlag{Name: "path"},
+			value: file,
+			err:   file + " is not a directory",
+		},
+		{
+			name:     "directory",
+			flag:     &DirFlag{Name: "path", Config: PathConfig{MustExist: true, Readable: true, Writable: true}},
+			value:    dir,
+			expected: dir,
+		},
+		{
+			name:     "expanded",
+			flag:     &FileFlag{Name: "path", Config: PathConfig{Expand: true, MustExist: true}},
+			value:    "$CONFIG_DIR/config.toml",
+			expected: file,
+		},
+		{
+			name:     "expanded home",
+			flag:     &DirFlag{Name: "path", Config: PathConfig{Expand: true}},
+			value:    "~/.cache",
+			expected: "/home/gopher/.cache",
+		},
+		{
+			name:     "not expanded",
+			flag:     &FileFlag{Name: "path"},
+			value:    "$CONFIG_DIR/config.toml",
+			expected: "$CONFIG_DIR/config.toml",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := &Command{
+				Name:   "app",
+				Env:    MapEnv{"CONFIG_DIR": dir, "HOME": "/home/gopher"},
+				Flags:  []Flag{tc.flag},
+				Action: func(context.Context, *Command) error { return nil },
+			}
+
+			err := cmd.Run(buildTestContext(t), []string{"app", "--path", tc.value})
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, cmd.String("path"))
+		})
+	}
+}
+
+func TestPathFlagsCreate(t *testing.T) {
+	dir := t.TempDir()
+	cmd := &Command{
+		Name: "app",
+		Flags: []Flag{
+			&FileFlag{Name: "log", Config: PathConfig{Create: true, Writable: true}},
+			&DirFlag{Name: "cache", Sources: EnvVars("APP_CACHE"), Config: PathConfig{Create: true}},
+		},
+		Env:    MapEnv{"APP_CACHE": filepath.Join(dir, "cache", "app")},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--log", filepath.Join(dir, "logs", "app.log")}))
+	assert.FileExists(t, filepath.Join(dir, "logs", "app.log"))
+	assert.DirExists(t, filepath.Join(dir, "cache", "app"))
+}
+
+func TestPathFlagsNotWritable(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Getuid() == 0 {
+		t.Skip("permissions are not enforced")
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "readonly")
+	require.NoError(t, os.WriteFile(file, nil, 0o444))
+
+	cmd := &Command{
+		Name:   "app",
+		Flags:  []Flag{&FileFlag{Name: "out", Config: PathConfig{Writable: true}}},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	err := cmd.Run(buildTestContext(t), []string{"app", "--out", file})
+	assert.ErrorContains(t, err, "file "+file+" is not writable")
+}
+
+func TestPathFlagsCompletion(t *testing.T) {
+	origArgv := os.Args
+	t.Cleanup(func() { os.Args = origArgv })
+
+	out := &bytes.Buffer{}
+	cmd := &Command{
+		Name:                  "app",
+		EnableShellCompletion: true,
+		Writer:                out,
+		Flags: []Flag{
+			&FileFlag{Name: "config"},
+			&StringFlag{Name: "config-name"},
+		},
+	}
+
+	os.Args = []string{"app", "--config", "--generate-shell-completion"}
+	require.NoError(t, cmd.Run(buildTestContext(t), os.Args))
+	assert.Empty(t, out.String(), "nothing is printed to fall back to file names")
+	assert.True(t, cmd.Flags[0].(fileFlag).takesFile())
+}
@@ -61,7 +61,7 @@ func (i *SliceBase[T, C, VC]) Set(value string) error {
 		if err := i.value.Set(strings.TrimSpace(s)); err != nil {
 			return err
 		}
-		tmp, ok := i.value.Get().(T)
+		tmp, ok := valueAs[T](i.value)
 		if !ok {
 			return fmt.Errorf("unable to cast %v", i.value)
 		}
@@ -75,7 +75,7 @@ func (i *SliceBase[T, C, VC]) Set(value string) error {
 func (i *SliceBase[T, C, VC]) String() string {
 	v := i.Value()
 	var t T
-	if reflect.TypeOf(t).Kind() == reflect.String {
+	if kindOf(t) == reflect.String {
 		return fmt.Sprintf("%v", v)
 	}
 	return fmt.Sprintf("%T{%s}", v, i.ToString(v))
@@ -100,6 +100,14 @@ func (i *SliceBase[T, C, VC]) Get() interface{} {
 	return *i.slice
 }
 
+func (i *SliceBase[T, C, VC]) typedGet() []T {
+	return *i.slice
+}
+
+// isMultiValue marks value creators of flags which can be given multiple
+// times, see FlagBase.IsMultiValueFlag
+func (i SliceBase[T, C, VC]) isMultiValue() {}
+
 func (i SliceBase[T, C, VC]) ToString(t []T) string {
 	var defaultVals []string
 	var v VC
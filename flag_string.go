@@ -48,6 +48,8 @@ func (s *stringValue) Set(val string) error {
 
 func (s *stringValue) Get() any { return *s.destination }
 
+func (s *stringValue) typedGet() string { return *s.destination }
+
 func (s *stringValue) String() string {
 	if s.destination != nil {
 		return *s.destination
@@ -56,7 +58,7 @@ func (s *stringValue) String() string {
 }
 
 func (cmd *Command) String(name string) string {
-	if v, ok := cmd.Value(name).(string); ok {
+	if v, ok := lookupValue[string](cmd, name); ok {
 		tracef("string available for flag name %[1]q with value=%[2]v (cmd=%[3]q)", name, v, cmd.Name)
 		return v
 	}
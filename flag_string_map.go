@@ -10,7 +10,7 @@ var NewStringMap = NewMapBase[string, StringConfig, stringValue]
 // StringMap looks up the value of a local StringMapFlag, returns
 // nil if not found
 func (cmd *Command) StringMap(name string) map[string]string {
-	if v, ok := cmd.Value(name).(map[string]string); ok {
+	if v, ok := lookupValue[map[string]string](cmd, name); ok {
 		tracef("string map available for flag name %[1]q with value=%[2]v (cmd=%[3]q)", name, v, cmd.Name)
 		return v
 	}
@@ -10,7 +10,7 @@ var NewStringSlice = NewSliceBase[string, StringConfig, stringValue]
 // StringSlice looks up the value of a local StringSliceFlag, returns
 // nil if not found
 func (cmd *Command) StringSlice(name string) []string {
-	if v, ok := cmd.Value(name).([]string); ok {
+	if v, ok := lookupValue[[]string](cmd, name); ok {
 		tracef("string slice available for flag name %[1]q with value=%[2]v (cmd=%[3]q)", name, v, cmd.Name)
 		return v
 	}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"bytes"
 	"context"
 	"flag"
 	"fmt"
@@ -76,6 +77,59 @@ func TestBoolFlagApply_SetsCount(t *testing.T) {
 	assert.Equal(t, 3, count)
 }
 
+func TestBoolFlagNegatable(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		args     []string
+		env      MapEnv
+		expected bool
+		isSet    bool
+	}{
+		{name: "default", args: []string{"app"}, expected: true},
+		{name: "negated", args: []string{"app", "--no-color"}, isSet: true},
+		{name: "negated alias", args: []string{"app", "--no-colour"}, isSet: true},
+		{name: "negated false", args: []string{"app", "--no-color=false"}, expected: true, isSet: true},
+		{name: "last wins", args: []string{"app", "--no-color", "--color"}, expected: true, isSet: true},
+		{name: "negated env var", args: []string{"app", "--no-color"}, env: MapEnv{"APP_COLOR": "true"}, isSet: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := &Command{
+				Name: "app",
+				Env:  tc.env,
+				Flags: []Flag{
+					&BoolFlag{
+						Name:    "color",
+						Aliases: []string{"colour", "c"},
+						Value:   true,
+						Sources: EnvVars("APP_COLOR"),
+						Config:  BoolConfig{Negatable: true},
+					},
+				},
+				Action: func(context.Context, *Command) error { return nil },
+			}
+
+			require.NoError(t, cmd.Run(buildTestContext(t), tc.args))
+			assert.Equal(t, tc.expected, cmd.Bool("color"))
+			assert.Equal(t, tc.expected, cmd.Bool("c"))
+			assert.Equal(t, tc.isSet, cmd.IsSet("color"))
+		})
+	}
+}
+
+func TestBoolFlagNegatableHelp(t *testing.T) {
+	fl := &BoolFlag{Name: "color", Aliases: []string{"c"}, Usage: "colorize", Config: BoolConfig{Negatable: true}}
+	assert.Equal(t, []string{"no-color"}, fl.GetNegatedNames())
+	assert.Equal(t, "--color, -c, --no-color\tcolorize (default: false)", fl.String())
+	assert.Nil(t, (&BoolFlag{Name: "color"}).GetNegatedNames())
+
+	err := (&Command{
+		Name:   "app",
+		Flags:  []Flag{&BoolFlag{Name: "color", Aliases: []string{"c"}, Config: BoolConfig{Negatable: true}}},
+		Action: func(context.Context, *Command) error { return nil },
+	}).Run(buildTestContext(t), []string{"app", "--no-c"})
+	assert.ErrorContains(t, err, "flag provided but not defined: -no-c")
+}
+
 func TestBoolFlagCountFromCommand(t *testing.T) {
 	boolCountTests := []struct {
 		input         []string
@@ -309,6 +363,24 @@ func TestFlagsFromEnv(t *testing.T) {
 			output: map[string]string{"foo": "bar"},
 			fl:     &StringMapFlag{Name: "names", Sources: EnvVars("NAMES"), Config: StringConfig{TrimSpace: true}},
 		},
+		{
+			name:   "IntMapFlag valid",
+			input:  "a=1,b=-2",
+			output: map[string]int64{"a": 1, "b": -2},
+			fl:     &IntMapFlag{Name: "limits", Sources: EnvVars("LIMITS")},
+		},
+		{
+			name:   "UintMapFlag valid",
+			input:  "a=1,b=2",
+			output: map[string]uint64{"a": 1, "b": 2},
+			fl:     &UintMapFlag{Name: "limits", Sources: EnvVars("LIMITS")},
+		},
+		{
+			name:   "FloatMapFlag valid",
+			input:  "cpu=0.5,mem=2",
+			output: map[string]float64{"cpu": 0.5, "mem": 2},
+			fl:     &FloatMapFlag{Name: "limits", Sources: EnvVars("LIMITS")},
+		},
 
 		{
 			name:   "UintFlag valid",
@@ -520,12 +592,12 @@ func TestFlagStringifying(t *testing.T) {
 		{
 			name:     "timestamp-flag",
 			fl:       &TimestampFlag{Name: "eating"},
-			expected: "--eating value\t",
+			expected: "--eating value\t(format: 2006-01-02T15:04:05Z07:00)",
 		},
 		{
 			name:     "timestamp-flag-with-default-text",
 			fl:       &TimestampFlag{Name: "sleeping", DefaultText: "earlier"},
-			expected: "--sleeping value\t(default: earlier)",
+			expected: "--sleeping value\t(format: 2006-01-02T15:04:05Z07:00) (default: earlier)",
 		},
 		{
 			name:     "uint-flag",
@@ -734,6 +806,27 @@ func TestStringSliceFlagApply_SetsAllNames(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestStringSliceFlagMixedForms(t *testing.T) {
+	cmd := &Command{
+		Name:   "app",
+		Flags:  []Flag{&StringSliceFlag{Name: "goat", Aliases: []string{"G"}}},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "-G", "aaa", "--goat", "bbb", "-G", "ccc"}))
+	assert.Equal(t, []string{"aaa", "bbb", "ccc"}, cmd.StringSlice("goat"))
+	assert.Equal(t, []string{"aaa", "bbb", "ccc"}, cmd.StringSlice("G"))
+
+	cmd = &Command{
+		Name:   "app",
+		Flags:  []Flag{&StringFlag{Name: "goat", Aliases: []string{"G"}}},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	err := cmd.Run(buildTestContext(t), []string{"app", "-G", "aaa", "--goat", "bbb"})
+	assert.ErrorContains(t, err, "Cannot use two forms of the same flag")
+}
+
 func TestStringSliceFlagApply_UsesEnvValues_noDefault(t *testing.T) {
 	defer resetEnv(os.Environ())
 	os.Clearenv()
@@ -2249,20 +2342,137 @@ func TestTimestamp_set(t *testing.T) {
 	ts := timestampValue{
 		timestamp:  nil,
 		hasBeenSet: false,
-		layout:     "Jan 2, 2006 at 3:04pm (MST)",
+		layouts:    []string{"Jan 2, 2006 at 3:04pm (MST)"},
 	}
 
 	time1 := "Feb 3, 2013 at 7:54pm (PST)"
-	require.NoError(t, ts.Set(time1), "Failed to parse time %s with layout %s", time1, ts.layout)
+	require.NoError(t, ts.Set(time1), "Failed to parse time %s with layout %s", time1, ts.layouts)
 	require.True(t, ts.hasBeenSet, "hasBeenSet is not true after setting a time")
 
 	ts.hasBeenSet = false
-	ts.layout = time.RFC3339
+	ts.layouts = []string{time.RFC3339}
 	time2 := "2006-01-02T15:04:05Z"
-	require.NoError(t, ts.Set(time2), "Failed to parse time %s with layout %s", time2, ts.layout)
+	require.NoError(t, ts.Set(time2), "Failed to parse time %s with layout %s", time2, ts.layouts)
 	require.True(t, ts.hasBeenSet, "hasBeenSet is not true after setting a time")
 }
 
+func TestTimestampFlagLayouts(t *testing.T) {
+	pinned := time.Date(2024, 3, 10, 15, 30, 0, 0, time.UTC)
+	tokyo := time.FixedZone("JST", 9*60*60)
+
+	for _, tc := range []struct {
+		name     string
+		config   TimestampConfig
+		value    string
+		expected time.Time
+		err      string
+	}{
+		{
+			name:     "default layout",
+			value:    "2024-01-02T03:04:05Z",
+			expected: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		{
+			name:     "second layout",
+			config:   TimestampConfig{Layout: time.RFC3339, Layouts: []string{"2006-01-02 15:04"}},
+			value:    "2024-01-02 03:04",
+			expected: time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC),
+		},
+		{
+			name:   "no matching layout",
+			config: TimestampConfig{Layouts: []string{time.RFC3339, "2006-01-02 15:04"}},
+			value:  "yesterday",
+			err:    `"yesterday" does not match any of the layouts 2006-01-02T15:04:05Z07:00, 2006-01-02 15:04`,
+		},
+		{
+			name:     "date only",
+			config:   TimestampConfig{DateOnly: true},
+			value:    "2024-01-02",
+			expected: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "now",
+			value:    "now",
+			expected: pinned,
+		},
+		{
+			name:     "relative",
+			value:    "now-24h",
+			expected: pinned.Add(-24 * time.Hour),
+		},
+		{
+			name:     "relative date",
+			config:   TimestampConfig{DateOnly: true},
+			value:    "now+1h30m",
+			expected: time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "invalid offset",
+			value: "now24h",
+			err:   `invalid offset "24h" of now`,
+		},
+		{
+			name:     "relative days",
+			value:    "now-1d",
+			expected: pinned.Add(-24 * time.Hour),
+		},
+		{
+			name:     "relative weeks",
+			value:    "now+1w2d3h",
+			expected: pinned.Add(9*24*time.Hour + 3*time.Hour),
+		},
+		{
+			name:     "relative fraction of a day",
+			value:    "now-1.5d",
+			expected: pinned.Add(-36 * time.Hour),
+		},
+		{
+			name:  "invalid unit",
+			value: "now-1y",
+			err:   `invalid offset "-1y" of now`,
+		},
+		{
+			name:  "missing duration",
+			value: "now-",
+			err:   `invalid offset "-" of now`,
+		},
+		{
+			name:     "time zone",
+			config:   TimestampConfig{Timezone: tokyo, Layouts: []string{"2006-01-02 15:04", time.RFC3339}},
+			value:    "2024-01-02T00:00:00Z",
+			expected: time.Date(2024, 1, 2, 9, 0, 0, 0, tokyo),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := &Command{
+				Name:   "app",
+				Clock:  ClockFunc(func() time.Time { return pinned }),
+				Flags:  []Flag{&TimestampFlag{Name: "since", Config: tc.config}},
+				Action: func(context.Context, *Command) error { return nil },
+			}
+
+			err := cmd.Run(buildTestContext(t), []string{"app", "--since", tc.value})
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, cmd.Timestamp("since"))
+			assert.Equal(t, tc.expected.Location(), cmd.Timestamp("since").Location())
+		})
+	}
+}
+
+func TestTimestampFlagLayoutsHelp(t *testing.T) {
+	fl := &TimestampFlag{Name: "since", Config: TimestampConfig{DateOnly: true}}
+	assert.Equal(t, "--since value\t(format: 2006-01-02)", fl.String())
+	assert.Equal(t, []string{"2006-01-02"}, fl.GetLayouts())
+
+	fl = &TimestampFlag{Name: "since", Config: TimestampConfig{Layout: time.Kitchen, Layouts: []string{"2006-01-02 15:04:05"}}}
+	assert.Equal(t, "--since value\t(formats: 3:04PM, 2006-01-02 15:04:05)", fl.String())
+	assert.Nil(t, (&StringFlag{Name: "name"}).GetLayouts())
+}
+
 func TestTimestampFlagApply(t *testing.T) {
 	expectedResult, _ := time.Parse(time.RFC3339, "2006-01-02T15:04:05Z")
 	fl := TimestampFlag{Name: "time", Aliases: []string{"t"}, Config: TimestampConfig{Layout: time.RFC3339}}
@@ -2511,7 +2721,7 @@ func TestFlagDefaultValueWithEnv(t *testing.T) {
 			name:    "timestamp",
 			flag:    &TimestampFlag{Name: "flag", Value: ts, Config: TimestampConfig{Layout: time.RFC3339}, Sources: EnvVars("tflag")},
 			toParse: []string{"--flag", "2006-11-02T15:04:05Z"},
-			expect:  `--flag value	(default: 2005-01-02 15:04:05 +0000 UTC)` + withEnvHint([]string{"tflag"}, ""),
+			expect:  `--flag value	(format: 2006-01-02T15:04:05Z07:00) (default: 2005-01-02 15:04:05 +0000 UTC)` + withEnvHint([]string{"tflag"}, ""),
 			environ: map[string]string{
 				"tflag": "2010-01-02T15:04:05Z",
 			},
@@ -2768,3 +2978,291 @@ func TestStringMapFlagApply_Error(t *testing.T) {
 	err := set.Parse([]string{"--goat", "aaa", "bbb="})
 	assert.Error(t, err)
 }
+
+func TestMapFlags(t *testing.T) {
+	cmd := &Command{
+		Name: "app",
+		Flags: []Flag{
+			&StringMapFlag{Name: "label"},
+			&IntMapFlag{Name: "limit"},
+			&FloatMapFlag{Name: "weight"},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{
+		"app", "--label", "app=web", "--label", "tier=frontend,env=prod", "--limit", "cpu=2", "--weight", "a=0.5",
+	}))
+	assert.Equal(t, map[string]string{"app": "web", "tier": "frontend", "env": "prod"}, cmd.StringMap("label"))
+	assert.Equal(t, map[string]int64{"cpu": 2}, cmd.IntMap("limit"))
+	assert.Equal(t, map[string]float64{"a": 0.5}, cmd.FloatMap("weight"))
+	assert.Nil(t, cmd.UintMap("limit"))
+
+	err := cmd.Run(buildTestContext(t), []string{"app", "--limit", "cpu=lots"})
+	assert.ErrorContains(t, err, `invalid value "cpu=lots" for flag -limit`)
+}
+
+func TestMapFlagKeyValueSeparator(t *testing.T) {
+	t.Cleanup(func() { defaultMapFlagKeyValueSeparator = "=" })
+
+	cmd := &Command{
+		Name:                     "app",
+		MapFlagKeyValueSeparator: ":",
+		Flags: []Flag{
+			&StringMapFlag{Name: "selector", Value: map[string]string{"env": "dev"}},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--selector", "app:web,url:http://example.com"}))
+	assert.Equal(t, map[string]string{"app": "web", "url": "http://example.com"}, cmd.StringMap("selector"))
+	assert.Contains(t, cmd.Flags[0].String(), `(default: env:"dev")`)
+}
+
+func TestFlagOnChange(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		env            string
+		expectedCalled bool
+		expectedValue  string
+		expectedOrigin string
+	}{
+		{
+			name: "not set",
+			args: []string{"app"},
+		},
+		{
+			name:           "set on command line",
+			args:           []string{"app", "--insecure", "yes"},
+			expectedCalled: true,
+			expectedValue:  "yes",
+			expectedOrigin: "command line",
+		},
+		{
+			name:           "set via environment",
+			args:           []string{"app"},
+			env:            "env",
+			expectedCalled: true,
+			expectedValue:  "env",
+			expectedOrigin: `environment variable "APP_INSECURE"`,
+		},
+		{
+			name:           "command line overrides environment",
+			args:           []string{"app", "--insecure", "yes"},
+			env:            "env",
+			expectedCalled: true,
+			expectedValue:  "yes",
+			expectedOrigin: "command line",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.env != "" {
+				t.Setenv("APP_INSECURE", test.env)
+			}
+
+			var (
+				called bool
+				change FlagChange[string]
+			)
+
+			cmd := &Command{
+				Name: "app",
+				Flags: []Flag{
+					&StringFlag{
+						Name:    "insecure",
+						Value:   "no",
+						Sources: EnvVars("APP_INSECURE"),
+						OnChange: func(_ context.Context, _ *Command, fc FlagChange[string]) error {
+							called = true
+							change = fc
+							return nil
+						},
+					},
+				},
+				Action: func(context.Context, *Command) error { return nil },
+			}
+
+			r := require.New(t)
+			r.NoError(cmd.Run(buildTestContext(t), test.args))
+			r.Equal(test.expectedCalled, called)
+
+			if test.expectedCalled {
+				r.Equal(test.expectedValue, change.Value)
+				r.Equal("no", change.Previous)
+				r.Equal(test.expectedOrigin, change.Origin())
+				r.Equal(test.expectedOrigin == "command line", change.FromCommandLine())
+			}
+		})
+	}
+}
+
+func TestFlagOnChangeError(t *testing.T) {
+	cmd := &Command{
+		Name: "app",
+		Flags: []Flag{
+			&IntFlag{
+				Name: "port",
+				OnChange: func(context.Context, *Command, FlagChange[int64]) error {
+					return fmt.Errorf("port changed")
+				},
+			},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	require.EqualError(t, cmd.Run(buildTestContext(t), []string{"app", "--port", "80"}), "port changed")
+}
+
+func TestFlagEnvVarsPriority(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		env      MapEnv
+		expected string
+		hint     string
+	}{
+		{name: "new", env: MapEnv{"NEW_NAME": "new", "OLD_NAME": "old"}, expected: "new", hint: ` (set by environment variable "NEW_NAME")`},
+		{name: "old", env: MapEnv{"OLD_NAME": "old"}, expected: "old", hint: ` (set by environment variable "OLD_NAME")`},
+		{name: "unset", env: MapEnv{}, expected: "default"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			cmd := &Command{
+				Name:   "app",
+				Env:    tc.env,
+				Writer: out,
+				Flags: []Flag{
+					&StringFlag{
+						Name:    "new-name",
+						Aliases: []string{"old-name"},
+						Value:   "default",
+						Sources: EnvVars("NEW_NAME", "OLD_NAME"),
+					},
+				},
+				Action: func(context.Context, *Command) error { return nil },
+			}
+
+			require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+			assert.Equal(t, tc.expected, cmd.String("old-name"))
+			assert.Equal(t, `--new-name value, --old-name value	(default: "default")`+withEnvHint([]string{"NEW_NAME", "OLD_NAME"}, ""), cmd.Flags[0].String(), "the source is only shown in help")
+
+			require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--help"}))
+			if tc.hint == "" {
+				assert.NotContains(t, out.String(), " (set by ")
+			} else {
+				assert.Contains(t, out.String(), tc.hint+"\n")
+			}
+		})
+	}
+}
+
+func TestFlagApplyResetsSetState(t *testing.T) {
+	verbose := &BoolFlag{Name: "verbose", Aliases: []string{"v"}}
+	name := &StringFlag{Name: "name", Sources: EnvVars("NAME"), OnlyOnce: true}
+
+	cmd := &Command{
+		Name:   "app",
+		Env:    MapEnv{"NAME": "env"},
+		Flags:  []Flag{verbose, name},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "-v", "--name", "flag"}))
+	assert.True(t, verbose.IsSet())
+	assert.True(t, name.IsSet())
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--name", "again"}), "the count of OnlyOnce starts over")
+	assert.False(t, verbose.IsSet(), "the previous run doesn't leak")
+	assert.Equal(t, "again", cmd.String("name"))
+
+	cmd.Env = MapEnv{}
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+	assert.False(t, name.IsSet())
+	assert.Equal(t, "", cmd.String("name"))
+}
+
+func TestFlagDefaultFunc(t *testing.T) {
+	calls := 0
+	var previous string
+	buildCmd := func() *Command {
+		return &Command{
+			Name: "app",
+			Env:  MapEnv{"APP_USER": "env-user"},
+			Flags: []Flag{
+				&StringFlag{
+					Name:        "user",
+					DefaultText: "current user",
+					DefaultFunc: func() (string, error) {
+						calls++
+						return "gopher", nil
+					},
+					OnChange: func(_ context.Context, _ *Command, fc FlagChange[string]) error {
+						previous = fc.Previous
+						return nil
+					},
+				},
+				&StringFlag{Name: "login", Sources: EnvVars("APP_USER"), DefaultFunc: func() (string, error) { return "gopher", nil }},
+			},
+			Action: func(context.Context, *Command) error { return nil },
+		}
+	}
+
+	cmd := buildCmd()
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+	assert.Equal(t, "gopher", cmd.String("user"))
+	assert.False(t, cmd.IsSet("user"))
+	assert.Equal(t, "env-user", cmd.String("login"), "sources take precedence")
+	assert.Equal(t, 1, calls)
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--user", "admin"}))
+	assert.Equal(t, "admin", cmd.String("user"))
+	assert.Equal(t, "", previous)
+	assert.Equal(t, 1, calls, "the default is not computed for a set flag")
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+	assert.Equal(t, "gopher", cmd.String("user"))
+	assert.Equal(t, 2, calls, "the default is computed on every run")
+
+	assert.Equal(t, "--user value\t(default: current user)", cmd.Flags[0].String())
+	assert.Equal(t, "--login value\t [$APP_USER]", cmd.Flags[1].String(), "computed defaults are not shown")
+}
+
+func TestFlagDefaultFuncError(t *testing.T) {
+	var port int64
+	out := &bytes.Buffer{}
+	cmd := &Command{
+		Name:   "app",
+		Writer: out,
+		Flags: []Flag{
+			&IntFlag{Name: "port", Destination: &port, DefaultFunc: func() (int64, error) { return 0, fmt.Errorf("no free port") }},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--port", "80"}))
+	assert.Equal(t, int64(80), port)
+	assert.Equal(t, int64(80), cmd.Int("port"))
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--help"}))
+	assert.Contains(t, out.String(), "--port value")
+
+	require.EqualError(t, cmd.Run(buildTestContext(t), []string{"app"}), "could not compute default value for flag port: no free port")
+}
+
+func TestFlagDefaultFuncDestination(t *testing.T) {
+	var dir string
+	cmd := &Command{
+		Name: "app",
+		Flags: []Flag{
+			&StringFlag{Name: "dir", Destination: &dir, DefaultFunc: func() (string, error) { return "/tmp", nil }},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+	assert.Equal(t, "/tmp", dir)
+	assert.Equal(t, "/tmp", cmd.String("dir"))
+	assert.False(t, cmd.IsSet("dir"))
+}
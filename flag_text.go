@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"encoding"
+	"fmt"
+)
+
+// TextUnmarshalerPtr is the constraint of the pointer type of values of
+// flags created by NewFlag
+type TextUnmarshalerPtr[T any] interface {
+	*T
+	encoding.TextUnmarshaler
+}
+
+// NewFlag returns a flag of a custom type T whose pointer implements
+// encoding.TextUnmarshaler, e.g. netip.Addr or a type of the application.
+// Values are formatted by MarshalText if T implements
+// encoding.TextMarshaler. The fields of the returned flag are set like the
+// ones of the built-in flags.
+//
+//	addr := cli.NewFlag[netip.Addr]("listen")
+//	addr.Usage = "address to listen on"
+//	addr.Value = netip.MustParseAddr("127.0.0.1")
+func NewFlag[T any, PT TextUnmarshalerPtr[T]](name string) *FlagBase[T, NoConfig, TextCreator[T, PT]] {
+	return &FlagBase[T, NoConfig, TextCreator[T, PT]]{Name: name}
+}
+
+// TextCreator is the ValueCreator of flags created by NewFlag
+type TextCreator[T any, PT TextUnmarshalerPtr[T]] struct {
+	destination *T
+}
+
+// Below functions are to satisfy the ValueCreator interface
+
+func (t TextCreator[T, PT]) Create(val T, p *T, c NoConfig) Value {
+	*p = val
+	return &TextCreator[T, PT]{destination: p}
+}
+
+func (t TextCreator[T, PT]) ToString(val T) string {
+	if m, ok := any(val).(encoding.TextMarshaler); ok {
+		if b, err := m.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	if m, ok := any(&val).(encoding.TextMarshaler); ok {
+		if b, err := m.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprint(val)
+}
+
+// Below functions are to satisfy the flag.Value interface
+
+func (t *TextCreator[T, PT]) Set(s string) error {
+	return PT(t.destination).UnmarshalText([]byte(s))
+}
+
+func (t *TextCreator[T, PT]) Get() any { return *t.destination }
+
+func (t *TextCreator[T, PT]) typedGet() T { return *t.destination }
+
+func (t *TextCreator[T, PT]) String() string {
+	if t.destination == nil {
+		return ""
+	}
+	return t.ToString(*t.destination)
+}
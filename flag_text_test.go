@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// level is a custom type only implementing encoding.TextUnmarshaler
+type level int
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return errInvalidLevel
+	}
+	return nil
+}
+
+var errInvalidLevel = errors.New("invalid level")
+
+func TestNewFlag(t *testing.T) {
+	addr := NewFlag[netip.Addr]("listen")
+	addr.Usage = "address to listen on"
+	addr.Value = netip.MustParseAddr("127.0.0.1")
+
+	lvl := NewFlag[level]("level")
+	lvl.Sources = EnvVars("APP_LEVEL")
+
+	var got netip.Addr
+	cmd := &Command{
+		Name:  "app",
+		Env:   MapEnv{"APP_LEVEL": "high"},
+		Flags: []Flag{addr, lvl},
+		Action: func(_ context.Context, cmd *Command) error {
+			got = addr.Get(cmd)
+			return nil
+		},
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--listen", "::1"}))
+	assert.Equal(t, netip.MustParseAddr("::1"), got)
+	assert.Equal(t, "--listen value\taddress to listen on (default: 127.0.0.1)", addr.String())
+
+	l, err := FlagValue[level](cmd, "level")
+	require.NoError(t, err)
+	assert.Equal(t, level(2), l)
+
+	err = cmd.Run(buildTestContext(t), []string{"app", "--listen", "nope"})
+	assert.ErrorContains(t, err, `invalid value "nope" for flag -listen`)
+}
@@ -2,23 +2,79 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
 type TimestampFlag = FlagBase[time.Time, TimestampConfig, timestampValue]
 
+// dateOnlyLayout is the layout of dates of flags in DateOnly mode without
+// layouts
+const dateOnlyLayout = "2006-01-02"
+
 // TimestampConfig defines the config for timestamp flags
 type TimestampConfig struct {
+	// Time zone of values without one, parsed values are converted to it
 	Timezone *time.Location
-	Layout   string
+	// Layout of the values, time.RFC3339 if neither Layout nor Layouts are
+	// set
+	Layout string
+	// Layouts tried in order after Layout
+	Layouts []string
+	// Whether the values are dates, they are truncated to midnight and
+	// the layout defaults to "2006-01-02"
+	DateOnly bool
+}
+
+func (c TimestampConfig) layouts() []string {
+	var layouts []string
+	if c.Layout != "" {
+		layouts = append(layouts, c.Layout)
+	}
+	layouts = append(layouts, c.Layouts...)
+
+	if len(layouts) == 0 {
+		if c.DateOnly {
+			return []string{dateOnlyLayout}
+		}
+		return []string{time.RFC3339}
+	}
+	return layouts
+}
+
+// layoutsConfig is implemented by the configs of flags parsing their
+// values by layouts
+type layoutsConfig interface {
+	layouts() []string
+}
+
+// GetLayouts returns the layouts the values of the flag are parsed by or
+// nil if it doesn't use layouts
+func (f *FlagBase[T, C, V]) GetLayouts() []string {
+	if lc, ok := any(f.Config).(layoutsConfig); ok {
+		return lc.layouts()
+	}
+	return nil
 }
 
 // timestampValue wrap to satisfy golang's flag interface.
 type timestampValue struct {
 	timestamp  *time.Time
 	hasBeenSet bool
-	layout     string
+	layouts    []string
 	location   *time.Location
+	dateOnly   bool
+	clock      Clock
+}
+
+// clockValue is implemented by values using the clock of the command the
+// flag is applied to
+type clockValue interface {
+	setClock(Clock)
+}
+
+func (t *timestampValue) setClock(clock Clock) {
+	t.clock = clock
 }
 
 var _ ValueCreator[time.Time, TimestampConfig] = timestampValue{}
@@ -29,8 +85,9 @@ func (t timestampValue) Create(val time.Time, p *time.Time, c TimestampConfig) V
 	*p = val
 	return &timestampValue{
 		timestamp: p,
-		layout:    c.Layout,
+		layouts:   c.layouts(),
 		location:  c.Timezone,
+		dateOnly:  c.DateOnly,
 	}
 }
 
@@ -48,19 +105,19 @@ func newTimestamp(timestamp time.Time) *timestampValue {
 
 // Below functions are to satisfy the flag.Value interface
 
-// Parses the string value to timestamp
+// Parses the string value to timestamp, see parse
 func (t *timestampValue) Set(value string) error {
-	var timestamp time.Time
-	var err error
+	timestamp, err := t.parse(value)
+	if err != nil {
+		return err
+	}
 
 	if t.location != nil {
-		timestamp, err = time.ParseInLocation(t.layout, value, t.location)
-	} else {
-		timestamp, err = time.Parse(t.layout, value)
+		timestamp = timestamp.In(t.location)
 	}
-
-	if err != nil {
-		return err
+	if t.dateOnly {
+		year, month, day := timestamp.Date()
+		timestamp = time.Date(year, month, day, 0, 0, 0, 0, timestamp.Location())
 	}
 
 	if t.timestamp != nil {
@@ -70,6 +127,121 @@ func (t *timestampValue) Set(value string) error {
 	return nil
 }
 
+// relative returns the time relative to the current time according to the
+// clock of the command, "now" or "now" moved by a duration like "now-24h" or
+// "now-1d", and whether the value is such a relative time
+func (t *timestampValue) relative(value string) (time.Time, bool, error) {
+	if !strings.HasPrefix(value, "now") {
+		return time.Time{}, false, nil
+	}
+
+	timestamp, err := t.now(strings.TrimPrefix(value, "now"))
+	return timestamp, true, err
+}
+
+// now returns the current time moved by the offset, e.g. "-24h"
+func (t *timestampValue) now(offset string) (time.Time, error) {
+	var d time.Duration
+	if offset != "" {
+		if offset[0] != '+' && offset[0] != '-' {
+			return time.Time{}, fmt.Errorf("invalid offset %q of now", offset)
+		}
+
+		var err error
+		if d, err = parseOffset(offset[1:]); err != nil {
+			return time.Time{}, fmt.Errorf("invalid offset %q of now: %w", offset, err)
+		}
+		if offset[0] == '-' {
+			d = -d
+		}
+	}
+
+	clock := t.clock
+	if clock == nil {
+		clock = ClockFunc(time.Now)
+	}
+	return clock.Now().Add(d), nil
+}
+
+// offsetUnits are the units of offsets which are not supported by
+// time.ParseDuration
+var offsetUnits = map[string]time.Duration{
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// parseOffset parses an unsigned duration like time.ParseDuration which
+// additionally accepts days "d" and weeks "w", e.g. "1w2d3h"
+func parseOffset(offset string) (time.Duration, error) {
+	if offset == "" {
+		return 0, fmt.Errorf("missing duration")
+	}
+
+	var total time.Duration
+	for offset != "" {
+		i := strings.IndexFunc(offset, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if i <= 0 {
+			return 0, fmt.Errorf("invalid duration %q", offset)
+		}
+		j := strings.IndexFunc(offset[i:], func(r rune) bool { return (r >= '0' && r <= '9') || r == '.' })
+		if j < 0 {
+			j = len(offset) - i
+		}
+
+		number, unit := offset[:i], offset[i:i+j]
+		offset = offset[i+j:]
+
+		var d time.Duration
+		var err error
+		if scale, ok := offsetUnits[unit]; ok {
+			if d, err = time.ParseDuration(number + "h"); err == nil {
+				d *= scale / time.Hour
+			}
+		} else {
+			d, err = time.ParseDuration(number + unit)
+		}
+		if err != nil {
+			return 0, err
+		}
+		total += d
+	}
+	return total, nil
+}
+
+// parse parses the value as relative time or by the first matching layout
+func (t *timestampValue) parse(value string) (time.Time, error) {
+	if timestamp, ok, err := t.relative(value); ok {
+		return timestamp, err
+	}
+
+	layouts := t.layouts
+	if len(layouts) == 0 {
+		layouts = TimestampConfig{DateOnly: t.dateOnly}.layouts()
+	}
+
+	var firstErr error
+	for _, layout := range layouts {
+		var timestamp time.Time
+		var err error
+		if t.location != nil {
+			timestamp, err = time.ParseInLocation(layout, value, t.location)
+		} else {
+			timestamp, err = time.Parse(layout, value)
+		}
+		if err == nil {
+			return timestamp, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if len(layouts) == 1 {
+		return time.Time{}, firstErr
+	}
+	return time.Time{}, fmt.Errorf("%q does not match any of the layouts %s", value, strings.Join(layouts, ", "))
+}
+
 // String returns a readable representation of this value (for usage defaults)
 func (t *timestampValue) String() string {
 	return fmt.Sprintf("%#v", t.timestamp)
@@ -85,9 +257,13 @@ func (t *timestampValue) Get() any {
 	return *t.timestamp
 }
 
+func (t *timestampValue) typedGet() time.Time {
+	return *t.timestamp
+}
+
 // Timestamp gets the timestamp from a flag name
 func (cmd *Command) Timestamp(name string) time.Time {
-	if v, ok := cmd.Value(name).(time.Time); ok {
+	if v, ok := lookupValue[time.Time](cmd, name); ok {
 		tracef("time.Time available for flag name %[1]q with value=%[2]v (cmd=%[3]q)", name, v, cmd.Name)
 		return v
 	}
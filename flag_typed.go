@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+)
+
+// typedValue is implemented by the built-in values to provide their value
+// as T directly. Unlike Get, this neither allocates for boxing the value
+// into an interface nor needs a type assertion which could fail at runtime.
+type typedValue[T any] interface {
+	typedGet() T
+}
+
+// valueAs returns the value of v as T, falling back to a type assertion of
+// Get for values not implementing typedValue, e.g. of custom flag types
+func valueAs[T any](v flag.Value) (T, bool) {
+	if fv, ok := v.(*fnValue); ok {
+		v = fv.v
+	}
+
+	switch v := v.(type) {
+	case typedValue[T]:
+		return v.typedGet(), true
+	case flag.Getter:
+		t, ok := v.Get().(T)
+		return t, ok
+	}
+
+	// values of the standard library flag package are only strings
+	t, ok := any(v.String()).(T)
+	return t, ok
+}
+
+// lookupValue returns the value of the flag with the given name of the
+// command or its ancestors as T
+func lookupValue[T any](cmd *Command, name string) (T, bool) {
+	if fs := cmd.lookupFlagSet(name); fs != nil {
+		return valueAs[T](fs.Lookup(name).Value)
+	}
+
+	var t T
+	return t, false
+}
+
+// FlagValue returns the value of the flag with the given name of the
+// command or its ancestors as T, checked at compile time instead of picking
+// the matching accessor like Int or String. It fails if no such flag is
+// defined or its values are not of type T.
+//
+//	port, err := cli.FlagValue[int64](cmd, "port")
+func FlagValue[T any](cmd *Command, name string) (T, error) {
+	var t T
+
+	fs := cmd.lookupFlagSet(name)
+	if fs == nil {
+		return t, fmt.Errorf(tr("flag %[1]q is not defined"), name)
+	}
+
+	v := fs.Lookup(name).Value
+	t, ok := valueAs[T](v)
+	if !ok {
+		var actual any = v.String()
+		if fv, ok := v.(*fnValue); ok && fv.v != nil {
+			actual = fv.v.Get()
+		} else if g, ok := v.(flag.Getter); ok {
+			actual = g.Get()
+		}
+		return t, fmt.Errorf(tr("flag %[1]q has a value of type %[2]T, not %[3]T"), name, actual, t)
+	}
+
+	return t, nil
+}
+
+// kindOf returns the kind of the value, so named bool or string types are
+// handled like bool and string. For interface types the kind of the dynamic
+// value is returned, or reflect.Interface if there is none.
+func kindOf[T any](v T) reflect.Kind {
+	if t := reflect.TypeOf(v); t != nil {
+		return t.Kind()
+	}
+	return reflect.TypeOf((*T)(nil)).Elem().Kind()
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mismatchedValue is a custom value whose Get returns another type than the
+// one of its flag
+type mismatchedValue struct {
+	destination *string
+}
+
+func (m mismatchedValue) Create(val string, p *string, c NoConfig) Value {
+	*p = val
+	return &mismatchedValue{destination: p}
+}
+
+func (m mismatchedValue) ToString(val string) string { return val }
+
+func (m *mismatchedValue) Set(s string) error { *m.destination = s; return nil }
+func (m *mismatchedValue) String() string     { return *m.destination }
+func (m *mismatchedValue) Get() any           { return 42 }
+
+// namedValue is a custom value of a named type, like a bool, string or
+// slice type of a program
+type namedValue[T ~bool | ~string | ~[]string] struct {
+	destination *T
+}
+
+func (n namedValue[T]) Create(val T, p *T, c NoConfig) Value {
+	*p = val
+	return &namedValue[T]{destination: p}
+}
+
+func (n namedValue[T]) ToString(val T) string { return fmt.Sprint(val) }
+
+func (n *namedValue[T]) Set(s string) error {
+	var v any = n.destination
+	switch v := v.(type) {
+	case *namedString:
+		*v = namedString(s)
+	case *namedBool:
+		*v = namedBool(s == "true")
+	case *namedSlice:
+		*v = append(*v, s)
+	}
+	return nil
+}
+func (n *namedValue[T]) String() string { return fmt.Sprint(*n.destination) }
+func (n *namedValue[T]) Get() any       { return *n.destination }
+
+type (
+	namedBool   bool
+	namedString string
+	namedSlice  []string
+)
+
+func TestValueAs(t *testing.T) {
+	s := "text"
+	v, ok := valueAs[string](&stringValue{destination: &s})
+	assert.True(t, ok)
+	assert.Equal(t, "text", v)
+
+	d := durationValue(time.Second)
+	wrapped := &fnValue{v: &d}
+	dv, ok := valueAs[time.Duration](wrapped)
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, dv)
+
+	_, ok = valueAs[int64](&stringValue{destination: &s})
+	assert.False(t, ok)
+
+	// values without Get are strings
+	sv, ok := valueAs[string](&levelValue{level: "info"})
+	assert.True(t, ok)
+	assert.Equal(t, "info", sv)
+}
+
+func TestFlagBaseMismatchedType(t *testing.T) {
+	fl := &FlagBase[string, NoConfig, mismatchedValue]{
+		Name:    "name",
+		Sources: EnvVars("NAME"),
+		env:     MapEnv{"NAME": "value"},
+	}
+
+	err := fl.Apply(flag.NewFlagSet("test", flag.ContinueOnError))
+	require.Error(t, err)
+	assert.Equal(t, "Expected type string got instead int", err.Error())
+}
+
+func TestFlagBaseKinds(t *testing.T) {
+	assert.False(t, (&BoolFlag{}).TakesValue())
+	assert.True(t, (&StringFlag{}).TakesValue())
+	assert.Empty(t, (&BoolFlag{Value: true}).GetValue())
+	assert.Equal(t, "x", (&StringFlag{Value: "x"}).GetValue())
+
+	assert.True(t, (&StringSliceFlag{}).IsMultiValueFlag())
+	assert.True(t, (&StringMapFlag{}).IsMultiValueFlag())
+	assert.False(t, (&IntFlag{}).IsMultiValueFlag())
+
+	// custom values are told apart by the kind of their type
+	assert.False(t, (&FlagBase[namedBool, NoConfig, namedValue[namedBool]]{}).TakesValue())
+	assert.True(t, (&FlagBase[namedString, NoConfig, namedValue[namedString]]{}).TakesValue())
+	assert.True(t, (&FlagBase[namedSlice, NoConfig, namedValue[namedSlice]]{}).IsMultiValueFlag())
+	assert.False(t, (&FlagBase[namedString, NoConfig, namedValue[namedString]]{}).IsMultiValueFlag())
+}
+
+func TestFlagBaseNamedKindsFromEnv(t *testing.T) {
+	str := &FlagBase[namedString, NoConfig, namedValue[namedString]]{
+		Name:    "str",
+		Value:   "default",
+		Sources: EnvVars("STR"),
+		env:     MapEnv{"STR": ""},
+	}
+	require.NoError(t, str.Apply(flag.NewFlagSet("test", flag.ContinueOnError)))
+	assert.True(t, str.IsSet(), "empty values are set for string kinds")
+	assert.Equal(t, namedString(""), str.value.Get())
+
+	b := &FlagBase[namedBool, NoConfig, namedValue[namedBool]]{
+		Name:    "b",
+		Value:   true,
+		Sources: EnvVars("B"),
+		env:     MapEnv{"B": ""},
+	}
+	require.NoError(t, b.Apply(flag.NewFlagSet("test", flag.ContinueOnError)))
+	assert.Equal(t, namedBool(false), b.value.Get(), "empty values are false for bool kinds")
+}
+
+func TestFlagValueTyped(t *testing.T) {
+	cmd := &Command{
+		Name: "app",
+		Flags: []Flag{
+			&IntFlag{Name: "port", Persistent: true},
+			&StringSliceFlag{Name: "tag"},
+		},
+		Commands: []*Command{{Name: "serve", Action: func(context.Context, *Command) error { return nil }}},
+	}
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--port", "8080", "--tag", "a", "serve"}))
+
+	port, err := FlagValue[int64](cmd.Command("serve"), "port")
+	require.NoError(t, err)
+	assert.Equal(t, int64(8080), port)
+
+	tags, err := FlagValue[[]string](cmd, "tag")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, tags)
+
+	_, err = FlagValue[string](cmd, "port")
+	assert.EqualError(t, err, `flag "port" has a value of type int64, not string`)
+
+	_, err = FlagValue[int64](cmd, "missing")
+	assert.EqualError(t, err, `flag "missing" is not defined`)
+}
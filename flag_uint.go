@@ -39,12 +39,14 @@ func (i *uintValue) Set(s string) error {
 
 func (i *uintValue) Get() any { return uint64(*i.val) }
 
+func (i *uintValue) typedGet() uint64 { return *i.val }
+
 func (i *uintValue) String() string { return strconv.FormatUint(uint64(*i.val), 10) }
 
 // Uint looks up the value of a local Uint64Flag, returns
 // 0 if not found
 func (cmd *Command) Uint(name string) uint64 {
-	if v, ok := cmd.Value(name).(uint64); ok {
+	if v, ok := lookupValue[uint64](cmd, name); ok {
 		tracef("uint available for flag name %[1]q with value=%[2]v (cmd=%[3]q)", name, v, cmd.Name)
 		return v
 	}
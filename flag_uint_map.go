@@ -0,0 +1,20 @@
+package cli
+
+type (
+	UintMap     = MapBase[uint64, IntegerConfig, uintValue]
+	UintMapFlag = FlagBase[map[string]uint64, IntegerConfig, UintMap]
+)
+
+var NewUintMap = NewMapBase[uint64, IntegerConfig, uintValue]
+
+// UintMap looks up the value of a local UintMapFlag, returns
+// nil if not found
+func (cmd *Command) UintMap(name string) map[string]uint64 {
+	if v, ok := lookupValue[map[string]uint64](cmd, name); ok {
+		tracef("uint map available for flag name %[1]q with value=%[2]v (cmd=%[3]q)", name, v, cmd.Name)
+		return v
+	}
+
+	tracef("uint map NOT available for flag name %[1]q (cmd=%[2]q)", name, cmd.Name)
+	return nil
+}
@@ -10,7 +10,7 @@ var NewUintSlice = NewSliceBase[uint64, IntegerConfig, uintValue]
 // UintSlice looks up the value of a local UintSliceFlag, returns
 // nil if not found
 func (cmd *Command) UintSlice(name string) []uint64 {
-	if v, ok := cmd.Value(name).([]uint64); ok {
+	if v, ok := lookupValue[[]uint64](cmd, name); ok {
 		tracef("uint slice available for flag name %[1]q with value=%[2]v (cmd=%[3]q)", name, v, cmd.Name)
 		return v
 	}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// URLFlag is a flag taking an URL, e.g.
+//
+//	&cli.URLFlag{
+//		Name:   "endpoint",
+//		Config: cli.URLConfig{Schemes: []string{"http", "https"}, RequireHost: true},
+//	}
+type URLFlag = FlagBase[*url.URL, URLConfig, urlValue]
+
+// URLConfig defines the configuration for URL flags
+type URLConfig struct {
+	// Schemes accepted by the flag, any scheme if empty. They are
+	// compared case-insensitively.
+	Schemes []string
+	// Whether the URL has to be absolute with a host like
+	// https://example.com
+	RequireHost bool
+}
+
+// -- url Value
+type urlValue struct {
+	destination **url.URL
+	config      URLConfig
+}
+
+// Below functions are to satisfy the ValueCreator interface
+
+func (u urlValue) Create(val *url.URL, p **url.URL, c URLConfig) Value {
+	*p = val
+	return &urlValue{
+		destination: p,
+		config:      c,
+	}
+}
+
+func (u urlValue) ToString(val *url.URL) string {
+	if val == nil {
+		return ""
+	}
+	return val.String()
+}
+
+// Below functions are to satisfy the flag.Value interface
+
+func (u *urlValue) Set(s string) error {
+	parsed, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+
+	if len(u.config.Schemes) > 0 {
+		accepted := false
+		for _, scheme := range u.config.Schemes {
+			accepted = accepted || strings.EqualFold(parsed.Scheme, scheme)
+		}
+		if !accepted {
+			return fmt.Errorf("scheme must be one of %s", strings.Join(u.config.Schemes, ", "))
+		}
+	}
+
+	if u.config.RequireHost && parsed.Host == "" {
+		return errors.New("missing host")
+	}
+
+	*u.destination = parsed
+	return nil
+}
+
+func (u *urlValue) Get() any { return *u.destination }
+
+func (u *urlValue) typedGet() *url.URL { return *u.destination }
+
+func (u *urlValue) String() string {
+	if u.destination != nil {
+		return u.ToString(*u.destination)
+	}
+	return ""
+}
+
+// URL looks up the value of a local URLFlag, returns nil if not found
+func (cmd *Command) URL(name string) *url.URL {
+	if v, ok := lookupValue[*url.URL](cmd, name); ok {
+		tracef("url available for flag name %[1]q with value=%[2]v (cmd=%[3]q)", name, v, cmd.Name)
+		return v
+	}
+
+	tracef("url NOT available for flag name %[1]q (cmd=%[2]q)", name, cmd.Name)
+	return nil
+}
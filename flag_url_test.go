@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLFlag(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		config   URLConfig
+		value    string
+		expected string
+		err      string
+	}{
+		{name: "any", value: "/relative/path?q=1", expected: "/relative/path?q=1"},
+		{name: "scheme", config: URLConfig{Schemes: []string{"http", "https"}}, value: "HTTPS://example.com/api", expected: "https://example.com/api"},
+		{name: "wrong scheme", config: URLConfig{Schemes: []string{"http", "https"}}, value: "ftp://example.com", err: "scheme must be one of http, https"},
+		{name: "host", config: URLConfig{RequireHost: true}, value: "postgres://db:5432/app", expected: "postgres://db:5432/app"},
+		{name: "missing host", config: URLConfig{RequireHost: true}, value: "example.com", err: "missing host"},
+		{name: "invalid", value: "http://[::1", err: `invalid value "http://[::1" for flag -endpoint`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var dest *url.URL
+			cmd := &Command{
+				Name:   "app",
+				Flags:  []Flag{&URLFlag{Name: "endpoint", Config: tc.config, Destination: &dest}},
+				Action: func(context.Context, *Command) error { return nil },
+			}
+
+			err := cmd.Run(buildTestContext(t), []string{"app", "--endpoint", tc.value})
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, cmd.URL("endpoint").String())
+			assert.Equal(t, dest, cmd.URL("endpoint"))
+		})
+	}
+}
+
+func TestURLFlagDefault(t *testing.T) {
+	fl := &URLFlag{Name: "endpoint", Value: &url.URL{Scheme: "https", Host: "example.com"}}
+	assert.Equal(t, "--endpoint value\t(default: https://example.com)", fl.String())
+
+	cmd := &Command{Name: "app", Flags: []Flag{fl}, Action: func(context.Context, *Command) error { return nil }}
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+	assert.Equal(t, "https://example.com", cmd.URL("endpoint").String())
+	assert.Nil(t, cmd.URL("missing"))
+}
@@ -1,7 +1,10 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -110,3 +113,162 @@ func TestFlagValidation(t *testing.T) {
 		}
 	}
 }
+
+func TestCollectValidationErrors(t *testing.T) {
+	cmd := &Command{
+		Name:                    "app",
+		CollectValidationErrors: true,
+		Writer:                  io.Discard,
+		ErrWriter:               io.Discard,
+		Flags: []Flag{
+			&IntFlag{Name: "count"},
+			&StringFlag{
+				Name:  "level",
+				Value: "low",
+				Validator: func(s string) error {
+					if s != "low" && s != "high" {
+						return fmt.Errorf("level must be low or high")
+					}
+					return nil
+				},
+			},
+			&StringFlag{Name: "name", Required: true},
+		},
+		MutuallyExclusiveFlags: []MutuallyExclusiveFlags{
+			{
+				Flags: [][]Flag{
+					{&BoolFlag{Name: "json"}},
+					{&BoolFlag{Name: "yaml"}},
+				},
+			},
+		},
+		Action: func(context.Context, *Command) error {
+			return nil
+		},
+	}
+
+	r := require.New(t)
+
+	err := cmd.Run(buildTestContext(t), []string{"app", "--count", "abc", "--level", "medium", "--json", "--yaml"})
+
+	multiErr, ok := err.(MultiError)
+	r.True(ok, "expected MultiError but got %T", err)
+
+	errs := multiErr.Errors()
+	r.Len(errs, 4)
+	r.ErrorContains(errs[0], `invalid value "abc" for flag -count`)
+	r.EqualError(errs[1], `invalid value "medium" for flag -level: level must be low or high`)
+	r.EqualError(errs[2], `Required flag "name" not set`)
+	r.EqualError(errs[3], "option json cannot be set along with option yaml")
+
+	r.NoError(cmd.Run(buildTestContext(t), []string{"app", "--count", "1", "--level", "low", "--name", "x"}))
+	r.NoError(cmd.Run(buildTestContext(t), []string{"app", "--level", "medium", "--help"}))
+}
+
+func TestCollectValidationErrorsUndefinedFlag(t *testing.T) {
+	cmd := &Command{
+		Name:                    "app",
+		CollectValidationErrors: true,
+		Writer:                  io.Discard,
+		ErrWriter:               io.Discard,
+		Flags: []Flag{
+			&IntFlag{Name: "count"},
+		},
+	}
+
+	err := cmd.Run(buildTestContext(t), []string{"app", "--count", "abc", "--nope"})
+
+	multiErr, ok := err.(MultiError)
+	require.True(t, ok, "expected MultiError but got %T", err)
+	require.Len(t, multiErr.Errors(), 2)
+	require.EqualError(t, multiErr.Errors()[1], "flag provided but not defined: -nope")
+}
+
+func TestCollectValidationErrorsArguments(t *testing.T) {
+	cmd := &Command{
+		Name:                    "app",
+		CollectValidationErrors: true,
+		Writer:                  io.Discard,
+		ErrWriter:               io.Discard,
+		Flags: []Flag{
+			&StringFlag{Name: "token", Required: true, Persistent: true},
+		},
+		Commands: []*Command{
+			{
+				Name: "get",
+				Arguments: []Argument{
+					&StringArg{Name: "key", Min: 1, Max: 1},
+				},
+				Action: func(context.Context, *Command) error {
+					return nil
+				},
+			},
+		},
+	}
+
+	err := cmd.Run(buildTestContext(t), []string{"app", "get"})
+
+	multiErr, ok := err.(MultiError)
+	require.True(t, ok, "expected MultiError but got %T", err)
+	require.Len(t, multiErr.Errors(), 2)
+	require.EqualError(t, multiErr.Errors()[0], `Required flag "token" not set`)
+	require.EqualError(t, multiErr.Errors()[1], "sufficient count of arg key not provided, given 0 expected 1")
+}
+
+func TestValidateFlags(t *testing.T) {
+	validated := 0
+	cmd := &Command{
+		Name:      "app",
+		Writer:    io.Discard,
+		ErrWriter: io.Discard,
+		Flags: []Flag{
+			&IntFlag{Name: "min"},
+			&IntFlag{Name: "max", Value: 10},
+			&StringFlag{Name: "out", Required: true},
+		},
+		ValidateFlags: func(_ context.Context, cmd *Command) error {
+			validated++
+			var errs []error
+			if cmd.Int("min") > cmd.Int("max") {
+				errs = append(errs, &ErrInvalidFlag{Flag: "min", Err: fmt.Errorf("must not be greater than max %d", cmd.Int("max"))})
+			}
+			if cmd.String("out") == "/" {
+				errs = append(errs, &ErrInvalidFlag{Flag: "out", Err: errors.New("must not be the root directory")})
+			}
+			switch len(errs) {
+			case 0:
+				return nil
+			case 1:
+				return errs[0]
+			}
+			return testMultiError(errs)
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	r := require.New(t)
+
+	r.NoError(cmd.Run(buildTestContext(t), []string{"app", "--min", "1", "--out", "x"}))
+	r.Equal(1, validated)
+
+	err := cmd.Run(buildTestContext(t), []string{"app", "--min", "11", "--out", "x"})
+	r.EqualError(err, "invalid value for flag -min: must not be greater than max 10")
+
+	var invalidErr *ErrInvalidFlag
+	r.ErrorAs(err, &invalidErr)
+	r.Equal("min", invalidErr.Flag)
+
+	cmd.CollectValidationErrors = true
+	validated = 0
+
+	err = cmd.Run(buildTestContext(t), []string{"app", "--min", "11", "--max", "x", "--out", "/"})
+	multiErr, ok := err.(MultiError)
+	r.True(ok, "expected MultiError but got %T", err)
+	r.Len(multiErr.Errors(), 3)
+	r.ErrorContains(multiErr.Errors()[0], `invalid value "x" for flag -max`)
+	r.EqualError(multiErr.Errors()[1], "invalid value for flag -min: must not be greater than max 10")
+	r.EqualError(multiErr.Errors()[2], "invalid value for flag -out: must not be the root directory")
+
+	r.NoError(cmd.Run(buildTestContext(t), []string{"app", "--out", "x"}))
+	r.Equal(2, validated, "validated once per run")
+}
@@ -1,6 +1,9 @@
 package cli
 
-import "context"
+import (
+	"context"
+	"os"
+)
 
 // ShellCompleteFunc is an action to execute when the shell completion flag is set
 type ShellCompleteFunc func(context.Context, *Command)
@@ -17,9 +20,22 @@ type AfterFunc func(context.Context, *Command) error
 // ActionFunc is the action to execute when no subcommands are specified
 type ActionFunc func(context.Context, *Command) error
 
+// ValidateFlagsFunc validates the flags of a command after they have been
+// parsed, e.g. checking combinations of values
+type ValidateFlagsFunc func(context.Context, *Command) error
+
+// MiddlewareFunc wraps the action of a command, e.g. to log, trace or
+// recover from panics, and returns the action to run instead. The wrapping
+// action is expected to call next to run the wrapped one.
+type MiddlewareFunc func(next ActionFunc) ActionFunc
+
 // CommandNotFoundFunc is executed if the proper command cannot be found
 type CommandNotFoundFunc func(context.Context, *Command, string)
 
+// CommandLoaderFunc builds the subcommand with the given name on demand,
+// returning nil if there is no such command
+type CommandLoaderFunc func(name string) (*Command, error)
+
 // OnUsageErrorFunc is executed if a usage error occurs. This is useful for displaying
 // customized usage error messages.  This function is able to replace the
 // original error messages.  If this function is not set, the "Incorrect usage"
@@ -33,6 +49,19 @@ type InvalidFlagAccessFunc func(context.Context, *Command, string)
 // returned by Actions and Before/After functions.
 type ExitErrHandlerFunc func(context.Context, *Command, error)
 
+// ErrorDecoratorFunc is executed if provided in order to rewrap errors
+// returned by Actions, Before/After functions or caused by invalid usage
+// before they are handled. It receives the command the error occurred on.
+type ErrorDecoratorFunc func(context.Context, *Command, error) error
+
+// ErrorFormatFunc is executed if provided in order to format errors before
+// they are written to the ErrWriter of the command the error occurred on.
+type ErrorFormatFunc func(*Command, error) string
+
+// CancelExitCodeFunc is executed to determine the exit code of a run
+// cancelled by the given signal, zero leaves the error unchanged.
+type CancelExitCodeFunc func(context.Context, *Command, os.Signal) int
+
 // FlagStringFunc is used by the help generation to display a flag, which is
 // expected to be a single line.
 type FlagStringFunc func(Flag) string
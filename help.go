@@ -14,6 +14,10 @@ import (
 const (
 	helpName  = "help"
 	helpAlias = "h"
+
+	// minDescriptionWidth is the least width of the wrapped descriptions of
+	// flags, narrower terminals wrap the whole lines instead
+	minDescriptionWidth = 20
 )
 
 // Prints help for the App or Command
@@ -51,6 +55,8 @@ func buildHelpCommand(withAction bool) *Command {
 		Usage:     "Shows a list of commands or help for one command",
 		ArgsUsage: "[command]",
 		HideHelp:  true,
+
+		isHelpCommand: true,
 	}
 
 	if withAction {
@@ -122,11 +128,13 @@ func helpCommandAction(ctx context.Context, cmd *Command) error {
 // ShowAppHelpAndExit - Prints the list of subcommands for the app and exits with exit code.
 func ShowAppHelpAndExit(cmd *Command, exitCode int) {
 	_ = ShowAppHelp(cmd)
-	os.Exit(exitCode)
+	cmd.exit(exitCode)
 }
 
 // ShowAppHelp is an action that displays the help.
 func ShowAppHelp(cmd *Command) error {
+	cmd.Root().ensureExternalCommands()
+
 	tmpl := cmd.CustomRootCommandHelpTemplate
 	if tmpl == "" {
 		tracef("using RootCommandHelpTemplate")
@@ -135,6 +143,7 @@ func ShowAppHelp(cmd *Command) error {
 
 	if cmd.ExtraInfo == nil {
 		HelpPrinter(cmd.Root().Writer, tmpl, cmd.Root())
+		cmd.printUpdateNotice()
 		return nil
 	}
 
@@ -145,6 +154,7 @@ func ShowAppHelp(cmd *Command) error {
 		}
 	}
 	HelpPrinterCustom(cmd.Root().Writer, tmpl, cmd.Root(), customAppData())
+	cmd.printUpdateNotice()
 
 	return nil
 }
@@ -197,28 +207,58 @@ func printFlagSuggestions(lastArg string, flags []Flag, writer io.Writer) {
 			usage = docFlag.GetUsage()
 		}
 
-		name := strings.TrimSpace(flag.Names()[0])
-		// this will get total count utf8 letters in flag name
-		count := utf8.RuneCountInString(name)
-		if count > 2 {
-			count = 2 // reuse this count to generate single - or -- in flag completion
-		}
-		// if flag name has more than one utf8 letter and last argument in cli has -- prefix then
-		// skip flag completion for short flags example -v or -x
-		if strings.HasPrefix(lastArg, "--") && count == 1 {
-			continue
+		names := []string{strings.TrimSpace(flag.Names()[0])}
+		if nf, ok := flag.(NegatableFlag); ok {
+			names = append(names, nf.GetNegatedNames()...)
 		}
-		// match if last argument matches this flag and it is not repeated
-		if strings.HasPrefix(name, cur) && cur != name && !cliArgContains(name) {
-			flagCompletion := fmt.Sprintf("%s%s", strings.Repeat("-", count), name)
-			if usage != "" && strings.HasSuffix(os.Getenv("SHELL"), "zsh") {
-				flagCompletion = fmt.Sprintf("%s:%s", flagCompletion, usage)
+
+		for _, name := range names {
+			// this will get total count utf8 letters in flag name
+			count := utf8.RuneCountInString(name)
+			if count > 2 {
+				count = 2 // reuse this count to generate single - or -- in flag completion
+			}
+			// if flag name has more than one utf8 letter and last argument in cli has -- prefix then
+			// skip flag completion for short flags example -v or -x
+			if strings.HasPrefix(lastArg, "--") && count == 1 {
+				continue
+			}
+			// match if last argument matches this flag and it is not repeated
+			if strings.HasPrefix(name, cur) && cur != name && !cliArgContains(name) {
+				flagCompletion := fmt.Sprintf("%s%s", strings.Repeat("-", count), name)
+				if usage != "" && strings.HasSuffix(os.Getenv("SHELL"), "zsh") {
+					flagCompletion = fmt.Sprintf("%s:%s", flagCompletion, usage)
+				}
+				fmt.Fprintln(writer, flagCompletion)
 			}
-			fmt.Fprintln(writer, flagCompletion)
 		}
 	}
 }
 
+// printFlagValueSuggestions prints the choices of the flag named by the
+// argument if it accepts only some values and returns whether the argument
+// is a flag whose values are completed that way or, by printing nothing to
+// fall back to file names, a flag taking a file
+func printFlagValueSuggestions(arg string, flags []Flag, writer io.Writer) bool {
+	fl := flagNamed(flags, strings.TrimLeft(arg, "-"))
+	if fl == nil {
+		return false
+	}
+	if df, ok := fl.(DocGenerationFlag); !ok || !df.TakesValue() {
+		return false
+	}
+
+	if cf, ok := fl.(ChoicesFlag); ok && len(cf.GetChoices()) > 0 {
+		for _, choice := range cf.GetChoices() {
+			fmt.Fprintln(writer, choice)
+		}
+		return true
+	}
+
+	ff, ok := fl.(fileFlag)
+	return ok && ff.takesFile()
+}
+
 func DefaultCompleteWithFlags(cmd *Command) func(ctx context.Context, cmd *Command) {
 	return func(_ context.Context, cmd *Command) {
 		args := os.Args
@@ -233,6 +273,10 @@ func DefaultCompleteWithFlags(cmd *Command) func(ctx context.Context, cmd *Comma
 			lastArg := args[argsLen-2]
 
 			if strings.HasPrefix(lastArg, "-") {
+				if cmd != nil && printFlagValueSuggestions(cmd.lastClusteredFlag(lastArg), cmd.Flags, cmd.Root().Writer) {
+					return
+				}
+
 				if cmd != nil {
 					printFlagSuggestions(lastArg, cmd.Flags, cmd.Root().Writer)
 
@@ -247,25 +291,54 @@ func DefaultCompleteWithFlags(cmd *Command) func(ctx context.Context, cmd *Comma
 
 		if cmd != nil {
 			tracef("printing command suggestions on command %[1]q", cmd.Name)
+			cmd.ensureExternalCommands()
 			printCommandSuggestions(cmd.Commands, cmd.Root().Writer)
 			return
 		}
 	}
 }
 
+// lastClusteredFlag returns the last flag of a cluster of single character
+// flags like -vo, whose value is the next argument, if the command uses
+// short option handling. Other arguments are returned as is.
+func (cmd *Command) lastClusteredFlag(arg string) string {
+	if !cmd.useShortOptionHandling() || !isSplittable(arg) || strings.Contains(arg, "=") {
+		return arg
+	}
+
+	cluster := []rune(arg[1:])
+	for i, c := range cluster {
+		fl := flagNamed(cmd.Flags, string(c))
+		if fl == nil {
+			return arg
+		}
+		// the rest of the cluster would be the value of the flag
+		if df, ok := fl.(DocGenerationFlag); ok && df.TakesValue() && i < len(cluster)-1 {
+			return arg
+		}
+	}
+
+	return "-" + string(cluster[len(cluster)-1])
+}
+
 // ShowCommandHelpAndExit - exits with code after showing help
 func ShowCommandHelpAndExit(ctx context.Context, cmd *Command, command string, code int) {
 	_ = ShowCommandHelp(ctx, cmd, command)
-	os.Exit(code)
+	cmd.exit(code)
 }
 
 // ShowCommandHelp prints help for the given command
 func ShowCommandHelp(ctx context.Context, cmd *Command, commandName string) error {
-	for _, subCmd := range cmd.Commands {
+	for i, subCmd := range cmd.Commands {
 		if !subCmd.HasName(commandName) {
 			continue
 		}
 
+		subCmd = cmd.loadCommand(i)
+		if err := cmd.takeCommandLoadErr(); err != nil {
+			return err
+		}
+
 		tmpl := subCmd.CustomHelpTemplate
 		if tmpl == "" {
 			if len(subCmd.Commands) == 0 {
@@ -284,19 +357,24 @@ func ShowCommandHelp(ctx context.Context, cmd *Command, commandName string) erro
 		return nil
 	}
 
+	if cmd.lookupExternalCommand(commandName) != nil || cmd.loadUnknownCommand(commandName) != nil {
+		return ShowCommandHelp(ctx, cmd, commandName)
+	}
+	if err := cmd.takeCommandLoadErr(); err != nil {
+		return err
+	}
+
 	tracef("no matching command found")
 
 	if cmd.CommandNotFound == nil {
-		errMsg := fmt.Sprintf("No help topic for '%v'", commandName)
+		err := &ErrCommandNotFound{Name: commandName}
 
 		if cmd.Suggest {
-			if suggestion := SuggestCommand(cmd.Commands, commandName); suggestion != "" {
-				errMsg += ". " + suggestion
-			}
+			err.Suggestions = cmd.unknownCommandSuggestions(commandName)
 		}
 
-		tracef("exiting 3 with errMsg %[1]q", errMsg)
-		return Exit(errMsg, 3)
+		tracef("exiting 3 with errMsg %[1]q", err.Error())
+		return Exit(err, 3)
 	}
 
 	tracef("running CommandNotFound func for %[1]q", commandName)
@@ -308,7 +386,7 @@ func ShowCommandHelp(ctx context.Context, cmd *Command, commandName string) erro
 // ShowSubcommandHelpAndExit - Prints help for the given subcommand and exits with exit code.
 func ShowSubcommandHelpAndExit(cmd *Command, exitCode int) {
 	_ = ShowSubcommandHelp(cmd)
-	os.Exit(exitCode)
+	cmd.exit(exitCode)
 }
 
 // ShowSubcommandHelp prints help for the given subcommand
@@ -321,6 +399,43 @@ func ShowSubcommandHelp(cmd *Command) error {
 	return nil
 }
 
+// showFlagUsage prints the usage of the flags causing the given usage error
+// followed by a hint how to show the full help, if FlagUsageOnError is set
+// on the root command. It returns false if the usage has not been shown.
+func (cmd *Command) showFlagUsage(err error) bool {
+	if !cmd.Root().FlagUsageOnError {
+		return false
+	}
+
+	var names []string
+	if rErr, ok := err.(requiredFlagsErr); ok {
+		names = rErr.getMissingFlags()
+	} else if name, ok := invalidFlagFromError(err); ok {
+		names = []string{name}
+	}
+
+	if len(names) > 0 {
+		w := tabwriter.NewWriter(cmd.errWriter(), 1, 8, 2, ' ', 0)
+		for _, name := range names {
+			for _, fl := range cmd.appliedFlags {
+				if checkStringSliceIncludes(name, fl.Names()) {
+					_, _ = fmt.Fprintf(w, "   %s\n", fl.String())
+					break
+				}
+			}
+		}
+		_ = w.Flush()
+
+		_, _ = fmt.Fprintln(cmd.errWriter())
+	}
+
+	if !cmd.HideHelp && HelpFlag != nil {
+		_, _ = fmt.Fprintln(cmd.errWriter(), trf("Run '%s --%s' for usage.", cmd.FullName(), HelpFlag.Names()[0]))
+	}
+
+	return true
+}
+
 // ShowVersion prints the version number of the App
 func ShowVersion(cmd *Command) {
 	tracef("showing version via VersionPrinter (cmd=%[1]q)", cmd.Name)
@@ -360,6 +475,25 @@ func printHelpCustom(out io.Writer, templ string, data interface{}, customFuncs
 		"wrap":           func(input string, offset int) string { return wrap(input, offset, maxLineLength) },
 		"offset":         offset,
 		"offsetCommands": offsetCommands,
+		// the categorized flags are only wrapped at the terminal width
+		"wrapFlag": func(input string) string { return input },
+		// the styles of the Theme, if any
+		"heading":      func(input string) string { return input },
+		"styleCommand": func(input string) string { return input },
+		"styleFlag":    func(f Flag) string { return f.String() + flagSourceHint(f) },
+	}
+
+	// continuation lines of flag descriptions start with an empty name
+	cell := ""
+	if cmd, ok := data.(*Command); ok {
+		if theme := cmd.theme(); theme != nil {
+			tracef("styling help with theme (cmd=%[1]q)", cmd.Name)
+
+			funcMap["heading"] = func(input string) string { return theme.style(theme.Heading, input) }
+			funcMap["styleCommand"] = func(input string) string { return theme.style(theme.Command, input) }
+			funcMap["styleFlag"] = theme.styleFlag
+			cell = theme.style(theme.Flag, "")
+		}
 	}
 
 	if wa, ok := customFuncs["wrapAt"]; ok {
@@ -369,13 +503,61 @@ func printHelpCustom(out io.Writer, templ string, data interface{}, customFuncs
 				return wrap(input, offset, wrapAt)
 			}
 		}
+	} else if cmd, ok := data.(*Command); ok {
+		if width := cmd.TerminalWidth(); width > 0 {
+			tracef("wrapping help at terminal width %[1]d (cmd=%[2]q)", width, cmd.Name)
+
+			column := helpFlagColumn(cmd)
+			funcMap["wrap"] = func(input string, offset int) string {
+				return wrapColumns(input, offset, width, column, cell)
+			}
+			funcMap["wrapFlag"] = func(input string) string {
+				return wrapColumns(input, 6, width, column, cell)
+			}
+		}
+	}
+
+	if cmd, ok := data.(*Command); ok {
+		for key, value := range cmd.lineageTemplateFuncs() {
+			funcMap[key] = value
+		}
 	}
 
 	for key, value := range customFuncs {
 		funcMap[key] = value
 	}
 
+	if cmd, ok := data.(*Command); ok {
+		var wait func()
+		out, wait = cmd.pagedWriter(out)
+		defer wait()
+	}
+
 	w := tabwriter.NewWriter(out, 1, 8, 2, ' ', 0)
+
+	var t *template.Template
+	if cmd, ok := data.(*Command); ok {
+		var err error
+		t, err = cmd.cachedTemplate(templ, funcMap, func() (*template.Template, error) {
+			return parseHelpTemplate(templ, funcMap), nil
+		})
+		if err != nil {
+			handleTemplateError(err)
+			return
+		}
+	} else {
+		t = parseHelpTemplate(templ, funcMap)
+	}
+
+	tracef("executing template")
+	handleTemplateError(t.Execute(w, data))
+
+	_ = w.Flush()
+}
+
+// parseHelpTemplate parses the help template along with the templates it
+// may include
+func parseHelpTemplate(templ string, funcMap template.FuncMap) *template.Template {
 	t := template.Must(template.New("help").Funcs(funcMap).Parse(templ))
 	if _, err := t.New("helpNameTemplate").Parse(helpNameTemplate); err != nil {
 		handleTemplateError(err)
@@ -401,6 +583,22 @@ func printHelpCustom(out io.Writer, templ string, data interface{}, customFuncs
 		handleTemplateError(err)
 	}
 
+	if _, err := t.New("exitStatusTemplate").Parse(exitStatusTemplate); err != nil {
+		handleTemplateError(err)
+	}
+
+	if _, err := t.New("examplesTemplate").Parse(examplesTemplate); err != nil {
+		handleTemplateError(err)
+	}
+
+	if _, err := t.New("seeAlsoTemplate").Parse(seeAlsoTemplate); err != nil {
+		handleTemplateError(err)
+	}
+
+	if _, err := t.New("argumentsTemplate").Parse(argumentsTemplate); err != nil {
+		handleTemplateError(err)
+	}
+
 	if _, err := t.New("versionTemplate").Parse(versionTemplate); err != nil {
 		handleTemplateError(err)
 	}
@@ -413,6 +611,10 @@ func printHelpCustom(out io.Writer, templ string, data interface{}, customFuncs
 		handleTemplateError(err)
 	}
 
+	if _, err := t.New("visiblePersistentFlagTemplate").Parse(visiblePersistentFlagTemplate); err != nil {
+		handleTemplateError(err)
+	}
+
 	if _, err := t.New("visibleGlobalFlagCategoryTemplate").Parse(strings.Replace(visibleFlagCategoryTemplate, "OPTIONS", "GLOBAL OPTIONS", -1)); err != nil {
 		handleTemplateError(err)
 	}
@@ -425,10 +627,7 @@ func printHelpCustom(out io.Writer, templ string, data interface{}, customFuncs
 		handleTemplateError(err)
 	}
 
-	tracef("executing template")
-	handleTemplateError(t.Execute(w, data))
-
-	_ = w.Flush()
+	return t
 }
 
 func printHelp(out io.Writer, templ string, data interface{}) {
@@ -520,8 +719,41 @@ func wrap(input string, offset int, wrapAt int) string {
 	return strings.Join(ss, "\n")
 }
 
+// wrapColumns wraps like wrap, but of a line consisting of a name and a
+// description separated by a tab, like the one of a flag, only the
+// description is wrapped at the given column. The continuation lines start
+// with the given cell and a tab so the tabwriter aligns them below the
+// description.
+func wrapColumns(input string, offset int, wrapAt int, column int, cell string) string {
+	name, description, found := strings.Cut(input, "\t")
+	if !found || wrapAt-column < minDescriptionWidth {
+		return wrap(input, offset, wrapAt)
+	}
+
+	var ss []string
+	for _, line := range strings.Split(description, "\n") {
+		ss = append(ss, wrapLine(strings.TrimSpace(line), column, wrapAt, cell+"\t"))
+	}
+
+	return name + "\t" + strings.Join(ss, "\n"+cell+"\t")
+}
+
+// helpFlagColumn returns the column the descriptions of the flags start at
+// in the help of the command, behind the indented and padded names
+func helpFlagColumn(cmd *Command) int {
+	width := 0
+	for _, fl := range append(cmd.VisibleFlags(), cmd.VisiblePersistentFlags()...) {
+		name, _, _ := strings.Cut(fl.String(), "\t")
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+
+	return 3 + width + 2
+}
+
 func wrapLine(input string, offset int, wrapAt int, padding string) string {
-	if wrapAt <= offset || len(input) <= wrapAt-offset {
+	if wrapAt <= offset || displayWidth(input) <= wrapAt-offset {
 		return input
 	}
 
@@ -532,14 +764,14 @@ func wrapLine(input string, offset int, wrapAt int, padding string) string {
 	}
 
 	wrapped := words[0]
-	spaceLeft := lineWidth - len(wrapped)
+	spaceLeft := lineWidth - displayWidth(wrapped)
 	for _, word := range words[1:] {
-		if len(word)+1 > spaceLeft {
+		if displayWidth(word)+1 > spaceLeft {
 			wrapped += "\n" + padding + word
-			spaceLeft = lineWidth - len(word)
+			spaceLeft = lineWidth - displayWidth(word)
 		} else {
 			wrapped += " " + word
-			spaceLeft -= 1 + len(word)
+			spaceLeft -= 1 + displayWidth(word)
 		}
 	}
 
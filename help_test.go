@@ -1635,3 +1635,317 @@ GLOBAL OPTIONS:
 
 `, output.String())
 }
+
+func TestFlagUsageOnError(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name: "invalid value",
+			args: []string{"app", "sub", "--count", "abc"},
+			expected: "Incorrect Usage: invalid value \"abc\" for flag -count: parse error\n\n" +
+				"   --count value, -c value  number of items (default: 0)\n\n" +
+				"Run 'app sub --help' for usage.\n",
+		},
+		{
+			name: "missing value",
+			args: []string{"app", "sub", "--name"},
+			expected: "Incorrect Usage: flag needs an argument: -name\n\n" +
+				"   --name value  your name\n\n" +
+				"Run 'app sub --help' for usage.\n",
+		},
+		{
+			name: "undefined flag",
+			args: []string{"app", "sub", "--nope"},
+			expected: "Incorrect Usage: flag provided but not defined: -nope\n\n" +
+				"Run 'app sub --help' for usage.\n",
+		},
+		{
+			name: "missing required flag",
+			args: []string{"app", "sub"},
+			expected: "   --name value  your name\n\n" +
+				"Run 'app sub --help' for usage.\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+
+			cmd := &Command{
+				Name:             "app",
+				Writer:           out,
+				ErrWriter:        errOut,
+				FlagUsageOnError: true,
+				Commands: []*Command{
+					{
+						Name: "sub",
+						Flags: []Flag{
+							&IntFlag{Name: "count", Aliases: []string{"c"}, Usage: "number of items"},
+							&StringFlag{Name: "name", Usage: "your name", Required: true},
+						},
+						Action: func(context.Context, *Command) error { return nil },
+					},
+				},
+			}
+
+			require.Error(t, cmd.Run(buildTestContext(t), test.args))
+			assert.Equal(t, test.expected, strings.Replace(errOut.String(), `strconv.ParseInt: parsing "abc": invalid syntax`, "parse error", 1))
+			assert.Empty(t, out.String())
+		})
+	}
+}
+
+func TestHelpTemplateCache(t *testing.T) {
+	out := &bytes.Buffer{}
+	cmd := &Command{
+		Name:   "app",
+		Usage:  "does things",
+		Writer: out,
+		Commands: []*Command{
+			{Name: "sub", Usage: "does sub things"},
+		},
+	}
+
+	r := require.New(t)
+	r.NoError(cmd.Run(buildTestContext(t), []string{"app", "--help"}))
+	first := out.String()
+
+	out.Reset()
+	r.NoError(cmd.Run(buildTestContext(t), []string{"app", "--help"}))
+	r.Equal(first, out.String())
+	r.Len(cmd.templates, 1)
+
+	// templates of subcommands are cached on the root as well
+	out.Reset()
+	r.NoError(cmd.Run(buildTestContext(t), []string{"app", "sub", "--help"}))
+	r.Contains(out.String(), "does sub things")
+	r.Len(cmd.templates, 2)
+	r.Nil(cmd.Commands[0].templates)
+
+	// overriding the template is picked up
+	cmd.CustomRootCommandHelpTemplate = "custom {{.Name}}\n"
+	out.Reset()
+	r.NoError(cmd.Run(buildTestContext(t), []string{"app", "--help"}))
+	r.Equal("custom app\n", out.String())
+}
+
+func TestHelpTemplateCacheCustomFuncs(t *testing.T) {
+	cmd := &Command{Name: "app"}
+	tmpl := "{{greeting}} {{.Name}}"
+
+	for _, greeting := range []string{"hello", "bye"} {
+		out := &bytes.Buffer{}
+		greeting := greeting
+		HelpPrinterCustom(out, tmpl, cmd, map[string]any{
+			"greeting": func() string { return greeting },
+		})
+		assert.Equal(t, greeting+" app", out.String())
+	}
+
+	assert.Len(t, cmd.templates, 1)
+}
+
+func BenchmarkShowAppHelp(b *testing.B) {
+	cmd := buildExtendedTestCommand()
+	cmd.Writer = io.Discard
+	cmd.setupDefaults([]string{"greet"})
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_ = ShowAppHelp(cmd)
+	}
+}
+
+func TestShowCommandHelp_PersistentFlags(t *testing.T) {
+	out := &bytes.Buffer{}
+	cmd := &Command{
+		Name:   "app",
+		Writer: out,
+		Flags: []Flag{
+			&StringFlag{Name: "region", Usage: "region to use", Persistent: true},
+			&StringFlag{Name: "token", Persistent: true, Hidden: true},
+			&BoolFlag{Name: "verbose", Usage: "log more", Persistent: true},
+			&BoolFlag{Name: "local", Usage: "root only"},
+		},
+		Commands: []*Command{
+			{
+				Name: "db",
+				Commands: []*Command{
+					{
+						Name:   "migrate",
+						Flags:  []Flag{&BoolFlag{Name: "verbose", Usage: "show migrations"}},
+						Action: func(context.Context, *Command) error { return nil },
+					},
+				},
+			},
+		},
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "db", "migrate", "--help"}))
+	assert.Equal(t, `NAME:
+   app db migrate
+
+USAGE:
+   app db migrate [command [command options]] 
+
+OPTIONS:
+   --verbose   show migrations (default: false)
+   --help, -h  show help (default: false)
+
+GLOBAL OPTIONS:
+   --region value  region to use
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "db", "--help"}))
+	assert.Contains(t, out.String(), `GLOBAL OPTIONS:
+   --region value  region to use
+   --verbose       log more (default: false)
+`)
+
+	assert.Empty(t, cmd.VisiblePersistentFlags())
+}
+
+func TestHelpTerminalWidth(t *testing.T) {
+	out := &bytes.Buffer{}
+	cmd := &Command{
+		Name:   "app",
+		Usage:  "does things",
+		Env:    MapEnv{"COLUMNS": "60"},
+		Writer: out,
+		Flags: []Flag{
+			&StringFlag{Name: "config", Aliases: []string{"c"}, Usage: "load the configuration from the given file instead of the default location"},
+			&BoolFlag{Name: "verbose", Category: "output", Usage: "print the requests and responses to the standard error"},
+		},
+		Commands: []*Command{{
+			Name:  "serve",
+			Usage: "serve the app",
+			Flags: []Flag{
+				&IntFlag{Name: "port", Usage: "the port to listen on for incoming connections of clients"},
+			},
+		}},
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--help"}))
+	assert.Contains(t, out.String(), `GLOBAL OPTIONS:
+   --config value, -c value  load the configuration from the
+                             given file instead of the
+                             default location
+   --help, -h                show help (default: false)
+
+   output
+
+   --verbose  print the requests and
+              responses to the standard error
+              (default: false)
+`, "the categories are aligned separately")
+
+	out.Reset()
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "serve", "--help"}))
+	assert.Contains(t, out.String(), `OPTIONS:
+   --port value  the port to listen on for incoming
+                 connections of clients (default: 0)
+   --help, -h    show help (default: false)
+`)
+}
+
+func TestHelpPager(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sed is not available")
+	}
+	t.Setenv("CLI_TEST_PROCESS", "process")
+
+	out := &bytes.Buffer{}
+	cmd := &Command{
+		Name:        "app",
+		Usage:       "does things",
+		HideHelp:    true,
+		EnablePager: true,
+		Terminal:    testTerminal{tty: true},
+		Env:         MapEnv{"PAGER": "sed s/^/>/"},
+		Writer:      out,
+		Action:      func(context.Context, *Command) error { return nil },
+	}
+
+	require.NoError(t, ShowAppHelp(cmd))
+	assert.Equal(t, ">NAME:\n>   app - does things\n>\n>USAGE:\n>   app  [arguments...]\n", out.String())
+
+	out.Reset()
+	cmd.Env = MapEnv{"PAGER": "cat"}
+	require.NoError(t, ShowAppHelp(cmd))
+	assert.Equal(t, "NAME:\n   app - does things\n\nUSAGE:\n   app  [arguments...]\n", out.String())
+
+	out.Reset()
+	cmd.Env = MapEnv{"PAGER": "sh -c env;cat", "NAME": "env"}
+	require.NoError(t, ShowAppHelp(cmd))
+	assert.Contains(t, out.String(), "NAME=env\n", "the pager gets the Env")
+	assert.Contains(t, out.String(), "LESS=FRX\n")
+	assert.NotContains(t, out.String(), "CLI_TEST_PROCESS=")
+	assert.Contains(t, out.String(), "USAGE:\n")
+
+	out.Reset()
+	cmd.Env = MapEnv{"PAGER": "sed s/^/>/"}
+	cmd.Terminal = testTerminal{tty: false}
+	require.NoError(t, ShowAppHelp(cmd))
+	assert.Equal(t, "NAME:\n   app - does things\n\nUSAGE:\n   app  [arguments...]\n", out.String(), "not paged without an interactive terminal")
+}
+
+func TestShowCommandHelp_DocMetadata(t *testing.T) {
+	out := &bytes.Buffer{}
+	cmd := &Command{
+		Name:   "app",
+		Writer: out,
+		Commands: []*Command{
+			{
+				Name:  "deploy",
+				Usage: "deploy the app",
+				Since: "v1.2.0",
+				Examples: []Example{
+					{Description: "Deploy a tag", Command: "app deploy --tag v1", Output: "deployed v1\n"},
+					{Command: "app deploy --dry-run"},
+				},
+				SeeAlso: []string{"app rollback", "https://example.com/deploy"},
+				Flags: []Flag{
+					&StringFlag{
+						Name:     "tag",
+						Usage:    "tag to deploy",
+						Examples: []string{"--tag v1", "--tag latest"},
+						Since:    "v1.3.0",
+						SeeAlso:  []string{"--dry-run"},
+					},
+				},
+				Action: func(context.Context, *Command) error { return nil },
+			},
+		},
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "deploy", "--help"}))
+	assert.Equal(t, `NAME:
+   app deploy - deploy the app
+
+USAGE:
+   app deploy [command [command options]] 
+
+SINCE:
+   v1.2.0
+
+OPTIONS:
+   --tag value  tag to deploy (e.g. --tag v1, --tag latest) (since v1.3.0) (see also: --dry-run)
+   --help, -h   show help (default: false)
+
+EXAMPLES:
+   Deploy a tag
+   $ app deploy --tag v1
+   deployed v1
+
+   $ app deploy --dry-run
+
+SEE ALSO:
+   app rollback
+   https://example.com/deploy
+`, out.String())
+}
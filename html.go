@@ -0,0 +1,179 @@
+//go:build !urfave_cli_no_docs
+
+package cli
+
+import (
+	"html/template"
+	"io"
+	"strings"
+)
+
+// HTMLDocTemplate is the template used by ToHTML. The template is executed
+// for the root command, the "nav" template renders the sidebar entry and
+// the "command" template the section of every visible command in turn.
+// The commands provide ID, Name, Title, Level, Usage, UsageText,
+// Description, Version, Since, Aliases, Examples, SeeAlso, Arguments,
+// Flags and Commands, the examples Description, Command and Output, the
+// arguments Name, Usage, Description, Type, Min and Max and the flags ID,
+// Names, Usage, Choices, Default, EnvVars, Requires, Conflicts, Examples,
+// Since and SeeAlso. The usage texts and the command lines of the examples
+// are highlighted by the highlight function.
+var HTMLDocTemplate = `{{define "nav"}}<li><a href="#{{.ID}}">{{.Name}}</a>{{if .Commands}}
+<ul>
+{{range .Commands}}{{template "nav" .}}
+{{end}}</ul>
+{{end}}</li>{{end}}{{define "command"}}<section id="{{.ID}}">
+{{if eq .Level 0}}<h1>{{.Title}}</h1>{{else}}<h2>{{.Title}}</h2>{{end}}
+{{if .Usage}}<p>{{.Usage}}</p>
+{{end}}{{if .Version}}<p>Version: {{.Version}}</p>
+{{end}}{{if .Since}}<p>Since: {{.Since}}</p>
+{{end}}{{if .Aliases}}<p>Aliases: {{codes .Aliases}}</p>
+{{end}}{{if .Description}}{{paragraphs .Description}}
+{{end}}<pre class="usage"><code>{{highlight .UsageText .Title}}</code></pre>
+{{if .Arguments}}<h3>Arguments</h3>
+<dl>
+{{range .Arguments}}<dt><code>{{.Usage}}</code></dt>
+<dd>{{.Description}}</dd>
+{{end}}</dl>
+{{end}}{{if .Flags}}<h3>Options</h3>
+<dl>
+{{range .Flags}}<dt id="{{.ID}}"><a href="#{{.ID}}"><code>{{.Names}}</code></a></dt>
+<dd>{{.Usage}}{{if .Choices}}<br>One of: {{codes .Choices}}{{end}}{{if .Default}}<br>Default: <code>{{.Default}}</code>{{end}}{{if .EnvVars}}<br>Environment: {{codes .EnvVars}}{{end}}{{if .Requires}}<br>Requires: {{codes .Requires}}{{end}}{{if .Conflicts}}<br>Conflicts with: {{codes .Conflicts}}{{end}}{{if .Examples}}<br>Examples: {{codes .Examples}}{{end}}{{if .Since}}<br>Since: {{.Since}}{{end}}{{if .SeeAlso}}<br>See also: {{range $i, $ref := .SeeAlso}}{{if $i}}, {{end}}{{link $ref}}{{end}}{{end}}</dd>
+{{end}}</dl>
+{{end}}{{if .Examples}}<h3>Examples</h3>
+{{range .Examples}}{{if .Description}}<p>{{.Description}}</p>
+{{end}}<pre class="usage"><code>$ {{highlight .Command $.Title}}{{if .Output}}
+<samp>{{.Output}}</samp>{{end}}</code></pre>
+{{end}}{{end}}{{if .SeeAlso}}<h3>See Also</h3>
+<ul>
+{{range .SeeAlso}}<li>{{link .}}</li>
+{{end}}</ul>
+{{end}}</section>
+{{range .Commands}}{{template "command" .}}{{end}}{{end}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { display: flex; margin: 0; font-family: system-ui, sans-serif; line-height: 1.5; }
+nav { position: sticky; top: 0; flex-shrink: 0; box-sizing: border-box; width: 16rem; height: 100vh; padding: 1rem; overflow-y: auto; background: #f6f8fa; border-right: 1px solid #d0d7de; }
+nav ul { margin: 0; padding-left: 1rem; list-style: none; }
+nav > ul { padding-left: 0; }
+main { max-width: 60rem; padding: 1rem 2rem; }
+pre { padding: .75rem; overflow-x: auto; background: #f6f8fa; }
+dd { margin-bottom: .75rem; }
+.usage .cmd { color: #0550ae; font-weight: bold; }
+.usage .flag { color: #116329; }
+.usage .arg { color: #8250df; }
+</style>
+</head>
+<body>
+<nav>
+<ul>
+{{template "nav" .}}
+</ul>
+</nav>
+<main>
+{{template "command" .}}</main>
+</body>
+</html>
+`
+
+// ToHTML renders the command tree as a standalone HTML page using
+// HTMLDocTemplate, e.g. to be published on an internal portal. The page
+// has a sidebar navigating the sections of the visible commands, every
+// flag can be linked to by its anchor and the usage texts are highlighted.
+func (cmd *Command) ToHTML() (string, error) {
+	var b strings.Builder
+	if err := cmd.ToHTMLToWriter(&b); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// ToHTMLToWriter writes the page of ToHTML to the writer while rendering it
+func (cmd *Command) ToHTMLToWriter(w io.Writer) error {
+	cmd.loadCommands()
+
+	funcMap := template.FuncMap{
+		"highlight":  htmlHighlight,
+		"codes":      htmlCodes,
+		"paragraphs": htmlParagraphs,
+		"link":       htmlLink,
+	}
+	for name, fn := range cmd.lineageTemplateFuncs() {
+		funcMap[name] = fn
+	}
+
+	t, err := template.New("html").Funcs(funcMap).Parse(HTMLDocTemplate)
+	if err != nil {
+		return err
+	}
+
+	return t.Execute(w, newDocsTemplateCommand(newDocsPage(cmd, nil, 1)))
+}
+
+// htmlHighlight marks up the words of the usage text for highlighting: the
+// leading words of the title as command, the words starting with a dash as
+// flags and the placeholders in brackets as arguments
+func htmlHighlight(usage, title string) template.HTML {
+	titleWords := map[string]bool{}
+	for _, word := range strings.Fields(title) {
+		titleWords[word] = true
+	}
+
+	lines := strings.Split(usage, "\n")
+	for i, line := range lines {
+		words := strings.Split(line, " ")
+		leading := true
+		for j, word := range words {
+			class := ""
+			switch {
+			case word == "":
+			case leading && titleWords[word]:
+				class = "cmd"
+			case len(word) > 1 && word[0] == '-':
+				class = "flag"
+			case strings.ContainsAny(word[:1], "[<") || strings.ContainsAny(word[len(word)-1:], "]>"):
+				class = "arg"
+			}
+			leading = leading && (word == "" || class == "cmd")
+
+			words[j] = template.HTMLEscapeString(word)
+			if class != "" {
+				words[j] = `<span class="` + class + `">` + words[j] + "</span>"
+			}
+		}
+		lines[i] = strings.Join(words, " ")
+	}
+	return template.HTML(strings.Join(lines, "\n"))
+}
+
+// htmlCodes renders the strings as comma separated code elements
+func htmlCodes(items []string) template.HTML {
+	codes := make([]string, len(items))
+	for i, item := range items {
+		codes[i] = "<code>" + template.HTMLEscapeString(item) + "</code>"
+	}
+	return template.HTML(strings.Join(codes, ", "))
+}
+
+// htmlParagraphs renders the paragraphs of the text separated by blank
+// lines as paragraph elements
+func htmlParagraphs(s string) template.HTML {
+	paragraphs := strings.Split(s, "\n\n")
+	for i, paragraph := range paragraphs {
+		paragraphs[i] = "<p>" + template.HTMLEscapeString(strings.TrimSpace(paragraph)) + "</p>"
+	}
+	return template.HTML(strings.Join(paragraphs, "\n"))
+}
+
+// htmlLink renders the reference as link if it is an URL, as text otherwise
+func htmlLink(ref string) template.HTML {
+	escaped := template.HTMLEscapeString(ref)
+	if strings.HasPrefix(ref, "https://") || strings.HasPrefix(ref, "http://") {
+		return template.HTML(`<a href="` + escaped + `">` + escaped + "</a>")
+	}
+	return template.HTML(escaped)
+}
@@ -0,0 +1,61 @@
+//go:build !urfave_cli_no_docs
+
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToHTML(t *testing.T) {
+	cmd := buildExtendedTestCommand()
+
+	res, err := cmd.ToHTML()
+
+	require.NoError(t, err)
+	expectFileContent(t, "testdata/expected-doc-full.html", res)
+}
+
+func TestToHTMLEscaping(t *testing.T) {
+	cmd := &Command{
+		Name:        "app",
+		Usage:       "manage <all> the things",
+		Description: "First paragraph & more.\n\nSecond paragraph.",
+		Commands: []*Command{{
+			Name:     "deploy",
+			Since:    "v1.2.0",
+			Examples: []Example{{Description: "Deploy a <target>", Command: "app deploy --tag v1 <target>", Output: "deployed & done"}},
+			SeeAlso:  []string{"app rollback", "https://example.com/deploy?a=1&b=2"},
+			Flags: []Flag{
+				&StringFlag{Name: "tag", Usage: "deploy the `TAG`", Sources: EnvVars("APP_TAG"), Examples: []string{"--tag v1"}},
+			},
+		}},
+	}
+
+	res, err := cmd.ToHTML()
+	require.NoError(t, err)
+
+	assert.Contains(t, res, "<p>manage &lt;all&gt; the things</p>")
+	assert.Contains(t, res, "<p>First paragraph &amp; more.</p>\n<p>Second paragraph.</p>")
+	assert.Contains(t, res, `<li><a href="#app-deploy">deploy</a></li>`)
+	assert.Contains(t, res, `<section id="app-deploy">`)
+	assert.Contains(t, res, `<pre class="usage"><code><span class="cmd">app</span> <span class="cmd">deploy</span> <span class="arg">[options]</span></code></pre>`)
+	assert.Contains(t, res, `<dt id="app-deploy-tag"><a href="#app-deploy-tag"><code>--tag TAG</code></a></dt>
+<dd>deploy the TAG<br>Environment: <code>APP_TAG</code><br>Examples: <code>--tag v1</code></dd>`)
+	assert.Contains(t, res, `<p>Deploy a &lt;target&gt;</p>
+<pre class="usage"><code>$ <span class="cmd">app</span> <span class="cmd">deploy</span> <span class="flag">--tag</span> v1 <span class="arg">&lt;target&gt;</span>
+<samp>deployed &amp; done</samp></code></pre>`)
+	assert.Contains(t, res, `<li>app rollback</li>
+<li><a href="https://example.com/deploy?a=1&amp;b=2">https://example.com/deploy?a=1&amp;b=2</a></li>`)
+}
+
+func TestToHTMLTemplate(t *testing.T) {
+	defer func(old string) { HTMLDocTemplate = old }(HTMLDocTemplate)
+	HTMLDocTemplate = `{{define "command"}}<b>{{.ID}}</b>{{range .Commands}} {{template "command" .}}{{end}}{{end}}{{template "command" .}}`
+
+	res, err := buildExtendedTestCommand().ToHTML()
+	require.NoError(t, err)
+	assert.Equal(t, "<b>greet</b> <b>greet-config</b> <b>greet-config-sub-config</b> <b>greet-info</b> <b>greet-some-command</b> <b>greet-usage</b> <b>greet-usage-sub-usage</b>", res)
+}
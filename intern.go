@@ -0,0 +1,191 @@
+package cli
+
+import "sync"
+
+// TreeBuilder deduplicates the metadata of command trees generated from
+// specs, e.g. the usage texts and categories repeated by thousands of
+// commands, so equal strings share a single copy in memory. A builder may
+// be used for several trees to share strings between them.
+type TreeBuilder struct {
+	mu      sync.Mutex
+	strings map[string]string
+	stats   InternStats
+}
+
+// InternStats reports the work of a TreeBuilder
+type InternStats struct {
+	// Strings is the number of metadata strings seen
+	Strings int
+	// Unique is the number of distinct strings kept
+	Unique int
+	// SavedBytes is the number of string bytes no longer held in memory
+	// because the strings have been replaced by an equal shared one
+	SavedBytes int
+}
+
+// TreeStats reports the size of a command tree and its metadata
+type TreeStats struct {
+	// Commands is the number of commands including the root, lazy commands
+	// which have not been built yet count as one command
+	Commands int
+	// Flags is the number of flags of all commands
+	Flags int
+	// Strings is the number of non-empty metadata strings
+	Strings int
+	// StringBytes is the length of all metadata strings
+	StringBytes int
+	// UniqueStringBytes is the length of the distinct metadata strings,
+	// which is all a tree built with a TreeBuilder holds in memory
+	UniqueStringBytes int
+}
+
+// metadataFlag is implemented by flags exposing their metadata strings
+type metadataFlag interface {
+	visitMetadata(fn func(*string))
+}
+
+// NewTreeBuilder returns an empty TreeBuilder
+func NewTreeBuilder() *TreeBuilder {
+	return &TreeBuilder{strings: map[string]string{}}
+}
+
+// String returns the shared copy of the string
+func (b *TreeBuilder) String(s string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.intern(s)
+}
+
+func (b *TreeBuilder) intern(s string) string {
+	if s == "" {
+		return s
+	}
+
+	b.stats.Strings++
+	if shared, ok := b.strings[s]; ok {
+		b.stats.SavedBytes += len(s)
+		return shared
+	}
+
+	b.strings[s] = s
+	b.stats.Unique++
+	return s
+}
+
+// Build replaces the metadata strings of the command, its flags and all its
+// subcommands by shared copies and returns the command. Subcommands built
+// lazily by a Factory are deduplicated once they are built.
+func (b *TreeBuilder) Build(cmd *Command) *Command {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.build(cmd, map[metadataFlag]struct{}{})
+	return cmd
+}
+
+func (b *TreeBuilder) build(cmd *Command, seen map[metadataFlag]struct{}) {
+	cmd.visitMetadata(seen, func(s *string) {
+		*s = b.intern(*s)
+	})
+
+	for _, subCmd := range cmd.Commands {
+		if factory := subCmd.Factory; factory != nil {
+			subCmd.Factory = func() *Command {
+				built := factory()
+				if built != nil {
+					b.Build(built)
+				}
+				return built
+			}
+			continue
+		}
+
+		b.build(subCmd, seen)
+	}
+}
+
+// Stats returns the statistics of the strings deduplicated so far
+func (b *TreeBuilder) Stats() InternStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.stats
+}
+
+// TreeStats reports the number of commands and flags of the tree below the
+// command and the memory held by their metadata strings, without building
+// lazy commands.
+func (cmd *Command) TreeStats() TreeStats {
+	var stats TreeStats
+	unique := map[string]struct{}{}
+	seen := map[metadataFlag]struct{}{}
+
+	var walk func(cmd *Command)
+	walk = func(cmd *Command) {
+		stats.Commands++
+		stats.Flags += len(cmd.Flags)
+
+		cmd.visitMetadata(seen, func(s *string) {
+			if *s == "" {
+				return
+			}
+
+			stats.Strings++
+			stats.StringBytes += len(*s)
+			if _, ok := unique[*s]; !ok {
+				unique[*s] = struct{}{}
+				stats.UniqueStringBytes += len(*s)
+			}
+		})
+
+		for _, subCmd := range cmd.Commands {
+			walk(subCmd)
+		}
+	}
+
+	walk(cmd)
+	return stats
+}
+
+// visitMetadata calls fn with pointers to the metadata strings of the
+// command and its flags, skipping flags seen on other commands already
+func (cmd *Command) visitMetadata(seen map[metadataFlag]struct{}, fn func(*string)) {
+	for _, s := range []*string{
+		&cmd.Name,
+		&cmd.Usage,
+		&cmd.UsageText,
+		&cmd.ArgsUsage,
+		&cmd.Version,
+		&cmd.Description,
+		&cmd.DefaultCommand,
+		&cmd.Category,
+	} {
+		fn(s)
+	}
+	for i := range cmd.Aliases {
+		fn(&cmd.Aliases[i])
+	}
+
+	for _, fl := range cmd.Flags {
+		mf, ok := fl.(metadataFlag)
+		if !ok {
+			continue
+		}
+		if _, ok := seen[mf]; ok {
+			continue
+		}
+		seen[mf] = struct{}{}
+		mf.visitMetadata(fn)
+	}
+}
+
+func (f *FlagBase[T, C, VC]) visitMetadata(fn func(*string)) {
+	fn(&f.Name)
+	fn(&f.Category)
+	fn(&f.DefaultText)
+	fn(&f.Usage)
+	for i := range f.Aliases {
+		fn(&f.Aliases[i])
+	}
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildGeneratedTestCommand returns a command with n subcommands whose
+// metadata is constructed at runtime like the one of commands generated
+// from specs, so equal strings don't share memory
+func buildGeneratedTestCommand(n int) *Command {
+	cmd := &Command{Name: "api"}
+	for i := 0; i < n; i++ {
+		cmd.Commands = append(cmd.Commands, &Command{
+			Name:     fmt.Sprintf("operation-%d", i),
+			Usage:    strings.Repeat("x", 10) + " operation",
+			Category: fmt.Sprint("resource"),
+			Flags: []Flag{
+				&StringFlag{Name: fmt.Sprint("output"), Usage: fmt.Sprint("output format")},
+			},
+		})
+	}
+	return cmd
+}
+
+func TestTreeBuilder(t *testing.T) {
+	cmd := buildGeneratedTestCommand(100)
+
+	before := cmd.TreeStats()
+	assert.Equal(t, TreeStats{
+		Commands:          101,
+		Flags:             100,
+		Strings:           1 + 100*5,
+		StringBytes:       3 + 100*(len("xxxxxxxxxx operation")+len("resource")+len("output")+len("output format")) + 10*len("operation-0") + 90*len("operation-00"),
+		UniqueStringBytes: 3 + len("xxxxxxxxxx operation") + len("resource") + len("output") + len("output format") + 10*len("operation-0") + 90*len("operation-00"),
+	}, before)
+
+	b := NewTreeBuilder()
+	assert.Same(t, cmd, b.Build(cmd))
+
+	stats := b.Stats()
+	assert.Equal(t, before.Strings, stats.Strings)
+	assert.Equal(t, 1+100+4, stats.Unique)
+	assert.Equal(t, before.StringBytes-before.UniqueStringBytes, stats.SavedBytes)
+
+	// the tree itself is unchanged
+	assert.Equal(t, before, cmd.TreeStats())
+	assert.Equal(t, "operation-42", cmd.Commands[42].Name)
+	assert.Equal(t, "output format", cmd.Commands[42].Flags[0].(*StringFlag).Usage)
+}
+
+func TestTreeBuilderSharedFlag(t *testing.T) {
+	shared := &BoolFlag{Name: "verbose", Usage: "verbose output"}
+	cmd := &Command{
+		Name: "app",
+		Commands: []*Command{
+			{Name: "a", Flags: []Flag{shared}},
+			{Name: "b", Flags: []Flag{shared}},
+		},
+	}
+
+	b := NewTreeBuilder()
+	b.Build(cmd)
+	assert.Equal(t, InternStats{Strings: 5, Unique: 5}, b.Stats())
+
+	stats := cmd.TreeStats()
+	assert.Equal(t, 2, stats.Flags)
+	assert.Equal(t, 5, stats.Strings)
+}
+
+func TestTreeBuilderLazy(t *testing.T) {
+	cmd := &Command{
+		Name:  "api",
+		Usage: fmt.Sprint("manage things"),
+		Commands: []*Command{
+			Lazy("things", "", func() *Command {
+				return &Command{Usage: fmt.Sprint("manage things")}
+			}),
+		},
+	}
+
+	b := NewTreeBuilder()
+	b.Build(cmd)
+	assert.Equal(t, 0, b.Stats().SavedBytes)
+	assert.Equal(t, 2, cmd.TreeStats().Commands)
+
+	things := cmd.Command("things")
+	require.NotNil(t, things)
+	assert.Equal(t, "manage things", things.Usage)
+	assert.Equal(t, len("manage things"), b.Stats().SavedBytes)
+}
+
+func TestTreeBuilderString(t *testing.T) {
+	b := NewTreeBuilder()
+	assert.Equal(t, "", b.String(""))
+	assert.Equal(t, "usage", b.String("usage"))
+	assert.Equal(t, "usage", b.String(fmt.Sprint("usage")))
+	assert.Equal(t, InternStats{Strings: 2, Unique: 1, SavedBytes: 5}, b.Stats())
+}
+
+func BenchmarkTreeBuilder(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		cmd := buildGeneratedTestCommand(1000)
+		b.StartTimer()
+
+		NewTreeBuilder().Build(cmd)
+	}
+}
@@ -0,0 +1,126 @@
+package cli
+
+// Lazy returns a placeholder for a subcommand whose tree is only built by
+// the factory when the command is looked up, e.g. to run it or show its
+// help. The name and usage are enough to list the command in the help of
+// its parent, so large command trees generated from API specs don't need
+// to be constructed on every start of the program. Without a factory the
+// command is built by the CommandLoader of its parent.
+func Lazy(name, usage string, factory func() *Command) *Command {
+	return &Command{
+		Name:    name,
+		Usage:   usage,
+		Factory: factory,
+		lazy:    factory == nil,
+	}
+}
+
+// loadCommand replaces the subcommand at the given index with the command
+// built by its Factory or the CommandLoader, if any, and returns it
+func (cmd *Command) loadCommand(i int) *Command {
+	placeholder := cmd.Commands[i]
+	if placeholder.Factory == nil && (!placeholder.lazy || cmd.CommandLoader == nil) {
+		return placeholder
+	}
+
+	tracef("building lazy command %[1]q (cmd=%[2]q)", placeholder.Name, cmd.Name)
+
+	var built *Command
+	if placeholder.Factory != nil {
+		built = placeholder.Factory()
+	} else {
+		var err error
+		if built, err = cmd.CommandLoader(placeholder.Name); err != nil {
+			tracef("unable to load command %[1]q: %[2]v (cmd=%[3]q)", placeholder.Name, err, cmd.Name)
+			cmd.commandLoadErr = err
+			return placeholder
+		}
+	}
+	placeholder.Factory = nil
+	placeholder.lazy = false
+	if built == nil {
+		return placeholder
+	}
+
+	if built.Name == "" {
+		built.Name = placeholder.Name
+	}
+	if built.Aliases == nil {
+		built.Aliases = placeholder.Aliases
+	}
+	if built.Usage == "" {
+		built.Usage = placeholder.Usage
+	}
+	if built.Category == "" {
+		built.Category = placeholder.Category
+	}
+	if built.Reader == nil {
+		built.Reader = placeholder.Reader
+	}
+	if built.Writer == nil {
+		built.Writer = placeholder.Writer
+	}
+	built.Hidden = built.Hidden || placeholder.Hidden
+
+	cmd.Commands[i] = built
+	cmd.commandIndex = nil
+
+	// the placeholder has been set up as part of the command graph already
+	if placeholder.parent != nil {
+		built.parent = cmd
+		built.setupSubcommand()
+		built.setupCommandGraph()
+	}
+
+	if cmd.categories != nil {
+		cmd.categories = categorize(cmd.Lineage(), cmd.Commands)
+	}
+
+	return built
+}
+
+// loadUnknownCommand builds the subcommand which is none of the Commands
+// by the CommandLoader and adds it to the Commands
+func (cmd *Command) loadUnknownCommand(name string) *Command {
+	if cmd.CommandLoader == nil || name == "" {
+		return nil
+	}
+
+	tracef("loading unknown command %[1]q (cmd=%[2]q)", name, cmd.Name)
+
+	built, err := cmd.CommandLoader(name)
+	if err != nil {
+		tracef("unable to load command %[1]q: %[2]v (cmd=%[3]q)", name, err, cmd.Name)
+		cmd.commandLoadErr = err
+		return nil
+	}
+	if built == nil {
+		return nil
+	}
+
+	placeholder := Lazy(name, "", func() *Command { return built })
+	// the command is set up as part of the command graph if the graph has
+	// been set up already
+	if cmd.categories != nil {
+		placeholder.parent = cmd
+	}
+	cmd.Commands = append(cmd.Commands, placeholder)
+
+	return cmd.loadCommand(len(cmd.Commands) - 1)
+}
+
+// loadCommands builds all lazy subcommands in the tree, e.g. to generate
+// documentation of the whole tree
+func (cmd *Command) loadCommands() {
+	for i := range cmd.Commands {
+		cmd.loadCommand(i).loadCommands()
+	}
+}
+
+// takeCommandLoadErr returns and clears the error of the CommandLoader
+// when looking up a subcommand
+func (cmd *Command) takeCommandLoadErr() error {
+	err := cmd.commandLoadErr
+	cmd.commandLoadErr = nil
+	return err
+}
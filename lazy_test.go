@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildLazyTestCommand(built *[]string, out *bytes.Buffer) *Command {
+	lazy := func(name string) *Command {
+		return Lazy(name, "manage "+name, func() *Command {
+			*built = append(*built, name)
+			return &Command{
+				Flags: []Flag{&StringFlag{Name: "id"}},
+				Commands: []*Command{
+					{
+						Name: "get",
+						Action: func(_ context.Context, cmd *Command) error {
+							_, err := out.WriteString(cmd.FullName() + " " + cmd.String("id"))
+							return err
+						},
+					},
+				},
+			}
+		})
+	}
+
+	return &Command{
+		Name:     "api",
+		Writer:   out,
+		Commands: []*Command{lazy("users"), lazy("orders"), lazy("invoices")},
+	}
+}
+
+func TestLazyCommand(t *testing.T) {
+	var built []string
+	out := &bytes.Buffer{}
+	cmd := buildLazyTestCommand(&built, out)
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"api", "orders", "--id", "7", "get"}))
+	assert.Equal(t, []string{"orders"}, built)
+	assert.Equal(t, "api orders get 7", out.String())
+
+	orders := cmd.Command("orders")
+	assert.Nil(t, orders.Factory)
+	assert.Equal(t, "manage orders", orders.Usage)
+	assert.Equal(t, []string{"orders"}, built)
+}
+
+func TestLazyCommandHelp(t *testing.T) {
+	var built []string
+	out := &bytes.Buffer{}
+	cmd := buildLazyTestCommand(&built, out)
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"api", "--help"}))
+	assert.Empty(t, built)
+	assert.Contains(t, out.String(), "manage invoices")
+
+	out.Reset()
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"api", "help", "users"}))
+	assert.Equal(t, []string{"users"}, built)
+	assert.Contains(t, out.String(), "--id value")
+	assert.Contains(t, out.String(), "get")
+}
+
+func TestLazyCommandInherits(t *testing.T) {
+	cmd := &Command{
+		Name: "app",
+		Commands: []*Command{{
+			Name:     "db",
+			Aliases:  []string{"d"},
+			Category: "storage",
+			Hidden:   true,
+			Factory: func() *Command {
+				return &Command{Usage: "database tools"}
+			},
+		}},
+	}
+
+	db := cmd.Command("d")
+	require.NotNil(t, db)
+	assert.Equal(t, "db", db.Name)
+	assert.Equal(t, []string{"d"}, db.Aliases)
+	assert.Equal(t, "database tools", db.Usage)
+	assert.Equal(t, "storage", db.Category)
+	assert.True(t, db.Hidden)
+	assert.Same(t, db, cmd.Commands[0])
+}
+
+func buildLoaderTestCommand(loaded *[]string, out *bytes.Buffer) *Command {
+	return &Command{
+		Name:     "cloud",
+		Writer:   out,
+		Commands: []*Command{Lazy("compute", "manage instances", nil), Lazy("storage", "manage buckets", nil)},
+		CommandLoader: func(name string) (*Command, error) {
+			*loaded = append(*loaded, name)
+			switch name {
+			case "compute", "storage", "dns":
+				return &Command{
+					Flags: []Flag{&StringFlag{Name: "region"}},
+					Action: func(_ context.Context, cmd *Command) error {
+						_, err := out.WriteString(cmd.FullName() + " " + cmd.String("region"))
+						return err
+					},
+				}, nil
+			case "broken":
+				return nil, errors.New("unable to load broken")
+			}
+			return nil, nil
+		},
+	}
+}
+
+func TestCommandLoader(t *testing.T) {
+	var loaded []string
+	out := &bytes.Buffer{}
+	cmd := buildLoaderTestCommand(&loaded, out)
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"cloud", "storage", "--region", "eu"}))
+	assert.Equal(t, []string{"storage"}, loaded)
+	assert.Equal(t, "cloud storage eu", out.String())
+	assert.Equal(t, "manage buckets", cmd.Command("storage").Usage)
+
+	out.Reset()
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"cloud", "dns", "--region", "us"}))
+	assert.Equal(t, []string{"storage", "dns"}, loaded)
+	assert.Equal(t, "cloud dns us", out.String())
+	assert.Same(t, cmd.Command("dns"), cmd.Commands[len(cmd.Commands)-1], "loaded commands are added")
+
+	err := cmd.Run(buildTestContext(t), []string{"cloud", "broken"})
+	assert.EqualError(t, err, "unable to load broken")
+
+	assert.Nil(t, cmd.Command("unknown"))
+}
+
+func TestCommandLoaderHelp(t *testing.T) {
+	var loaded []string
+	out := &bytes.Buffer{}
+	cmd := buildLoaderTestCommand(&loaded, out)
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"cloud", "--help"}))
+	assert.Empty(t, loaded)
+	assert.Contains(t, out.String(), "compute  manage instances")
+
+	out.Reset()
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"cloud", "help", "dns"}))
+	assert.Equal(t, []string{"dns"}, loaded)
+	assert.Contains(t, out.String(), "--region value")
+
+	err := cmd.Run(buildTestContext(t), []string{"cloud", "help", "broken"})
+	assert.EqualError(t, err, "unable to load broken")
+}
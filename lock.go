@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultLockPollInterval = 100 * time.Millisecond
+
+// InstanceLock makes sure a command is not run concurrently, e.g. for
+// commands mutating state like migrations. The lock is taken before the
+// Before function of the command runs and released once the command and its
+// subcommands have finished.
+type InstanceLock struct {
+	// Path of the lock file, defaults to a file named after the full name
+	// of the command in the temporary directory
+	Path string
+	// Whether to wait for a running instance to finish instead of failing
+	Wait bool
+	// Maximum time to wait for the lock if Wait is set, waits until the
+	// context is done if zero
+	Timeout time.Duration
+}
+
+type alreadyRunningError struct {
+	name string
+	pid  int
+}
+
+func (e *alreadyRunningError) Error() string {
+	if e.pid == 0 {
+		return trf("%s is already running", e.name)
+	}
+
+	return trf("%s is already running (pid %d)", e.name, e.pid)
+}
+
+func (l *InstanceLock) path(cmd *Command) string {
+	if l.Path != "" {
+		return l.Path
+	}
+
+	return filepath.Join(os.TempDir(), strings.ReplaceAll(cmd.FullName(), " ", "-")+".lock")
+}
+
+// acquire takes the lock for the given command, returning a function which
+// releases it again
+func (l *InstanceLock) acquire(ctx context.Context, cmd *Command) (func(), error) {
+	path := l.path(cmd)
+
+	tracef("acquiring instance lock %[1]q (cmd=%[2]q)", path, cmd.Name)
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.Wait && l.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.Timeout)
+		defer cancel()
+	}
+
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+
+		if locked {
+			break
+		}
+
+		if !l.Wait {
+			_ = f.Close()
+			return nil, &alreadyRunningError{name: cmd.FullName(), pid: readLockPID(path)}
+		}
+
+		select {
+		case <-ctx.Done():
+			_ = f.Close()
+			return nil, &alreadyRunningError{name: cmd.FullName(), pid: readLockPID(path)}
+		case <-time.After(defaultLockPollInterval):
+		}
+	}
+
+	if err := f.Truncate(0); err == nil {
+		_, _ = f.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+	}
+
+	return func() {
+		tracef("releasing instance lock %[1]q (cmd=%[2]q)", path, cmd.Name)
+
+		_ = f.Truncate(0)
+		_ = unlockFile(f)
+		_ = f.Close()
+	}, nil
+}
+
+// readLockPID returns the pid written to the lock file by the instance
+// holding the lock, or 0 if unknown
+func readLockPID(path string) int {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(b)))
+	return pid
+}
@@ -0,0 +1,16 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd || windows)
+
+package cli
+
+import (
+	"errors"
+	"os"
+)
+
+func tryLockFile(*os.File) (bool, error) {
+	return false, errors.New("instance locking is not supported on this platform")
+}
+
+func unlockFile(*os.File) error {
+	return nil
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.lock")
+
+	var nestedErr error
+
+	inner := &Command{
+		Name: "migrate",
+		Lock: &InstanceLock{Path: path},
+		Action: func(context.Context, *Command) error {
+			return nil
+		},
+	}
+
+	cmd := &Command{
+		Name: "app",
+		Commands: []*Command{
+			{
+				Name: "migrate",
+				Lock: &InstanceLock{Path: path},
+				Action: func(context.Context, *Command) error {
+					b, err := os.ReadFile(path)
+					if err != nil {
+						return err
+					}
+					if string(b) != fmt.Sprint(os.Getpid()) {
+						return fmt.Errorf("unexpected lock file content %q", b)
+					}
+
+					nestedErr = inner.Run(buildTestContext(t), []string{"migrate"})
+					return nil
+				},
+			},
+		},
+	}
+
+	r := require.New(t)
+	r.NoError(cmd.Run(buildTestContext(t), []string{"app", "migrate"}))
+	r.EqualError(nestedErr, fmt.Sprintf("migrate is already running (pid %d)", os.Getpid()))
+
+	// the lock has been released
+	r.NoError(inner.Run(buildTestContext(t), []string{"migrate"}))
+}
+
+func TestInstanceLockWait(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.lock")
+
+	holder := &InstanceLock{Path: path}
+	release, err := holder.acquire(buildTestContext(t), &Command{Name: "app"})
+	require.NoError(t, err)
+
+	cmd := &Command{
+		Name: "app",
+		Lock: &InstanceLock{Path: path, Wait: true, Timeout: 50 * time.Millisecond},
+		Action: func(context.Context, *Command) error {
+			return nil
+		},
+	}
+
+	require.ErrorContains(t, cmd.Run(buildTestContext(t), []string{"app"}), "app is already running")
+
+	cmd.Lock.Timeout = 0
+	time.AfterFunc(30*time.Millisecond, release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, cmd.Run(ctx, []string{"app"}))
+}
+
+func TestInstanceLockDefaultPath(t *testing.T) {
+	cmd := &Command{Name: "app"}
+	sub := &Command{Name: "db migrate", parent: cmd}
+
+	require.Equal(t, filepath.Join(os.TempDir(), "app-db-migrate.lock"), (&InstanceLock{}).path(sub))
+}
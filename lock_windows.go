@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	lockfileFailImmediately = 0x1
+	lockfileExclusiveLock   = 0x2
+	errorLockViolation      = syscall.Errno(33)
+)
+
+var (
+	kernel32         = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = kernel32.NewProc("LockFileEx")
+	procUnlockFileEx = kernel32.NewProc("UnlockFileEx")
+)
+
+// lockOverlapped locks a single byte at the largest offset so that the pid
+// written to the start of the file can still be read by other processes
+func lockOverlapped() *syscall.Overlapped {
+	return &syscall.Overlapped{Offset: 0xffffffff, OffsetHigh: 0x7fffffff}
+}
+
+func tryLockFile(f *os.File) (bool, error) {
+	r, _, err := procLockFileEx.Call(f.Fd(), lockfileExclusiveLock|lockfileFailImmediately, 0, 1, 0, uintptr(unsafe.Pointer(lockOverlapped())))
+	if r != 0 {
+		return true, nil
+	}
+
+	if err == errorLockViolation {
+		return false, nil
+	}
+
+	return false, err
+}
+
+func unlockFile(f *os.File) error {
+	r, _, err := procUnlockFileEx.Call(f.Fd(), 0, 1, 0, uintptr(unsafe.Pointer(lockOverlapped())))
+	if r == 0 {
+		return err
+	}
+
+	return nil
+}
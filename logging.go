@@ -0,0 +1,126 @@
+//go:build go1.21
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+const loggerContextKey = contextKey("cli.logger")
+
+// LogLevelFlag is the flag appended to every command of the graph when
+// EnableLogging is set on the root command. It accepts any level
+// understood by slog.Level, e.g. "debug", "info", "warn" or "error".
+var LogLevelFlag Flag = &StringFlag{
+	Name:  "log-level",
+	Usage: "set the log `level` (debug, info, warn, error)",
+	Value: "info",
+	Validator: func(s string) error {
+		var level slog.Level
+		return level.UnmarshalText([]byte(s))
+	},
+}
+
+// LogFormatFlag is the flag appended to every command of the graph when
+// EnableLogging is set on the root command. It accepts "text" or "json".
+var LogFormatFlag Flag = &StringFlag{
+	Name:  "log-format",
+	Usage: "set the log `format` (text, json)",
+	Value: "text",
+	Validator: func(s string) error {
+		if s != "text" && s != "json" {
+			return fmt.Errorf("unknown log format %q", s)
+		}
+		return nil
+	},
+}
+
+func loggingFlags() []Flag {
+	return []Flag{LogLevelFlag, LogFormatFlag}
+}
+
+func (cmd *Command) ensureLogging() {
+	if !cmd.Root().EnableLogging {
+		return
+	}
+
+	for _, fl := range []Flag{LogLevelFlag, LogFormatFlag} {
+		if fl != nil {
+			tracef("appending logging flag %[1]q (cmd=%[2]q)", fl.Names(), cmd.Name)
+			cmd.appendFlag(fl)
+		}
+	}
+}
+
+// Logger returns a logger configured from the log-level and log-format
+// flags of this command or its ancestors, writing to the ErrWriter of the
+// command or its nearest ancestor that sets one. Every record carries the full command path in the
+// "command" attribute.
+func (cmd *Command) Logger() *slog.Logger {
+	if l, ok := cmd.logger.(*slog.Logger); ok {
+		return l
+	}
+
+	opts := &slog.HandlerOptions{}
+
+	if level := cmd.loggingFlagValue(LogLevelFlag); level != "" {
+		var l slog.Level
+		if err := l.UnmarshalText([]byte(level)); err == nil {
+			opts.Level = l
+		}
+	}
+
+	w := cmd.errWriter()
+
+	var h slog.Handler
+	if cmd.loggingFlagValue(LogFormatFlag) == "json" {
+		h = slog.NewJSONHandler(w, opts)
+	} else {
+		h = slog.NewTextHandler(w, opts)
+	}
+
+	l := slog.New(h).With(slog.String("command", cmd.FullName()))
+	cmd.logger = l
+
+	return l
+}
+
+// LoggerFromContext returns the logger injected into the context by the
+// command being run, or slog.Default() if there is none
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerContextKey).(*slog.Logger); ok {
+		return l
+	}
+
+	return slog.Default()
+}
+
+func (cmd *Command) contextWithLogger(ctx context.Context) context.Context {
+	cmd.logger = nil
+
+	if !cmd.Root().EnableLogging {
+		return ctx
+	}
+
+	tracef("injecting logger into context (cmd=%[1]q)", cmd.Name)
+
+	return context.WithValue(ctx, loggerContextKey, cmd.Logger())
+}
+
+func (cmd *Command) loggingFlagValue(fl Flag) string {
+	if fl == nil {
+		return ""
+	}
+
+	if v := cmd.lookupFlagValue(fl.Names()); v != nil {
+		return v.String()
+	}
+
+	if sf, ok := fl.(*StringFlag); ok {
+		return sf.Value
+	}
+
+	return ""
+}
@@ -0,0 +1,15 @@
+//go:build !go1.21
+
+package cli
+
+import "context"
+
+func loggingFlags() []Flag {
+	return nil
+}
+
+func (cmd *Command) ensureLogging() {}
+
+func (cmd *Command) contextWithLogger(ctx context.Context) context.Context {
+	return ctx
+}
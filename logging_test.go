@@ -0,0 +1,114 @@
+//go:build go1.21
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		env         MapEnv
+		expectDebug bool
+		expectJSON  bool
+	}{
+		{
+			name: "defaults",
+			args: []string{"app", "db", "migrate"},
+		},
+		{
+			name:        "level on root",
+			args:        []string{"app", "--log-level", "debug", "db", "migrate"},
+			expectDebug: true,
+		},
+		{
+			name:        "level and format on subcommand",
+			args:        []string{"app", "db", "migrate", "--log-level", "DEBUG", "--log-format", "json"},
+			expectDebug: true,
+			expectJSON:  true,
+		},
+		{
+			name:        "level and format via env",
+			args:        []string{"app", "db", "migrate"},
+			env:         MapEnv{"APP_LOG_LEVEL": "debug", "APP_LOG_FORMAT": "json"},
+			expectDebug: true,
+			expectJSON:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			errBuf := &bytes.Buffer{}
+
+			cmd := &Command{
+				Name:          "app",
+				EnableLogging: true,
+				EnvPrefix:     "APP",
+				Env:           test.env,
+				ErrWriter:     errBuf,
+				Commands: []*Command{
+					{
+						Name: "db",
+						Commands: []*Command{
+							{
+								Name: "migrate",
+								Action: func(ctx context.Context, cmd *Command) error {
+									require.Same(t, cmd.Logger(), LoggerFromContext(ctx))
+
+									LoggerFromContext(ctx).Debug("debug message")
+									LoggerFromContext(ctx).Info("info message")
+									return nil
+								},
+							},
+						},
+					},
+				},
+			}
+
+			r := require.New(t)
+			r.NoError(cmd.Run(buildTestContext(t), test.args))
+
+			out := errBuf.String()
+			r.Contains(out, "info message")
+			r.Equal(test.expectDebug, bytes.Contains(errBuf.Bytes(), []byte("debug message")))
+
+			if test.expectJSON {
+				line, _, _ := bytes.Cut(errBuf.Bytes(), []byte("\n"))
+
+				var record map[string]any
+				r.NoError(json.Unmarshal(line, &record))
+				r.Equal("app db migrate", record["command"])
+			} else {
+				r.Contains(out, `command="app db migrate"`)
+			}
+		})
+	}
+}
+
+func TestLoggerInvalidFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"app", "--log-level", "verbose"},
+		{"app", "--log-format", "xml"},
+	} {
+		cmd := &Command{
+			Name:          "app",
+			EnableLogging: true,
+			Writer:        &bytes.Buffer{},
+			ErrWriter:     &bytes.Buffer{},
+			Action:        func(context.Context, *Command) error { return nil },
+		}
+
+		require.Error(t, cmd.Run(buildTestContext(t), args))
+	}
+}
+
+func TestLoggerFromContextDefault(t *testing.T) {
+	require.NotNil(t, LoggerFromContext(context.Background()))
+}
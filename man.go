@@ -0,0 +1,268 @@
+//go:build !urfave_cli_no_docs
+
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ToManPages writes a gzip compressed man page per visible command of the
+// tree to the directory, named after the command and its ancestors joined
+// by dashes, e.g. app-config-set.1.gz for section 1. The SEE ALSO section
+// of every page references the pages of its parent and subcommands, so the
+// directory can be installed as a complete man tree.
+func (cmd *Command) ToManPages(dir string, section int) error {
+	cmd.loadCommands()
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	var write func(p *docsPage) error
+	write = func(p *docsPage) error {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Name = manPageName(p) + "." + fmt.Sprint(section)
+		if err := p.writeMan(zw, section, cmd.Version); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, zw.Name+".gz"), buf.Bytes(), 0o644); err != nil {
+			return err
+		}
+
+		for _, child := range p.children {
+			if err := write(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return write(newDocsPage(cmd, nil, 1))
+}
+
+// ToMan renders the man page of the command alone in the section, like the
+// pages of ToManPages but uncompressed. The page documents the persistent
+// flags inherited from the ancestors as global options, which are known for
+// subcommands looked up with Command or returned by Parse.
+func (cmd *Command) ToMan(section int) (string, error) {
+	var b strings.Builder
+	if err := cmd.ToManToWriter(&b, section); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// ToManToWriter writes the man page of ToMan to the writer while rendering
+// it
+func (cmd *Command) ToManToWriter(w io.Writer, section int) error {
+	cmd.loadCommands()
+
+	return newLineageDocsPage(cmd).writeMan(w, section, cmd.Root().Version)
+}
+
+// manPageName returns the name of the man page of the command
+func manPageName(p *docsPage) string {
+	return strings.Join(p.names, "-")
+}
+
+// man renders the page of the command in the roff format of man pages, the
+// version is the one of the root command
+func (p *docsPage) man(section int, version string) string {
+	var b strings.Builder
+	_ = p.writeMan(&b, section, version)
+	return b.String()
+}
+
+// writeMan writes the man page of the command to the writer
+func (p *docsPage) writeMan(w io.Writer, section int, version string) error {
+	b := bufio.NewWriter(w)
+	cmd := p.cmd
+
+	fmt.Fprintf(b, ".TH %s %d", manQuote(strings.ToUpper(manPageName(p))), section)
+	if version != "" {
+		fmt.Fprintf(b, " \"\" %s", manQuote(p.names[0]+" "+version))
+	}
+	b.WriteString("\n")
+
+	b.WriteString(".SH NAME\n")
+	b.WriteString(manEscape(manPageName(p)))
+	if cmd.Usage != "" {
+		b.WriteString(" \\- " + manEscape(cmd.Usage))
+	}
+	b.WriteString("\n")
+
+	b.WriteString(".SH SYNOPSIS\n")
+	if usage := strings.TrimSpace(cmd.UsageText); usage != "" {
+		b.WriteString(".nf\n" + manEscape(usage) + "\n.fi\n")
+	} else {
+		b.WriteString(".B " + manEscape(p.title()) + "\n")
+		if synopsis := p.synopsis(); len(synopsis) > 0 {
+			b.WriteString(manEscape(strings.Join(synopsis, " ")) + "\n")
+		}
+	}
+
+	if cmd.Description != "" {
+		b.WriteString(".SH DESCRIPTION\n")
+		// blank lines separate paragraphs
+		b.WriteString(strings.ReplaceAll(manEscape(strings.TrimSpace(cmd.Description)), "\n\n", "\n.PP\n") + "\n")
+	}
+
+	if len(cmd.Aliases) > 0 {
+		b.WriteString(".SH ALIASES\n")
+		b.WriteString(manEscape(strings.Join(cmd.Aliases, ", ")) + "\n")
+	}
+
+	if args := docsArguments(cmd); len(args) > 0 {
+		b.WriteString(".SH ARGUMENTS\n")
+		for _, arg := range args {
+			b.WriteString(".TP\n.I " + manEscape(arg.Usage) + "\n")
+			if arg.Description != "" {
+				b.WriteString(manEscape(arg.Description) + "\n")
+			}
+		}
+	}
+
+	if flags := cmd.VisibleFlags(); len(flags) > 0 {
+		b.WriteString(".SH OPTIONS\n")
+		for _, fl := range flags {
+			b.WriteString(manFlag(fl))
+		}
+	}
+
+	if flags := cmd.VisiblePersistentFlags(); len(flags) > 0 {
+		b.WriteString(".SH GLOBAL OPTIONS\n")
+		for _, fl := range flags {
+			b.WriteString(manFlag(fl))
+		}
+	}
+
+	if len(p.children) > 0 {
+		b.WriteString(".SH COMMANDS\n")
+		for _, category := range p.categories() {
+			if category.name != "" {
+				b.WriteString(".SS " + manEscape(category.name) + "\n")
+				if category.description != "" {
+					b.WriteString(manEscape(category.description) + "\n")
+				}
+			}
+			for _, child := range category.pages {
+				b.WriteString(".TP\n.B " + manEscape(child.cmd.Name) + "\n")
+				if child.cmd.Usage != "" {
+					b.WriteString(manEscape(child.cmd.Usage) + "\n")
+				}
+			}
+		}
+	}
+
+	if cmd.Since != "" {
+		b.WriteString(".SH HISTORY\n")
+		b.WriteString("Added in " + manEscape(cmd.Since) + "\n")
+	}
+
+	if len(cmd.Examples) > 0 {
+		b.WriteString(".SH EXAMPLES\n")
+		for _, ex := range cmd.Examples {
+			b.WriteString(".PP\n")
+			if ex.Description != "" {
+				b.WriteString(manEscape(ex.Description) + "\n")
+			}
+			lines := "$ " + ex.Command
+			if output := strings.TrimSpace(ex.Output); output != "" {
+				lines += "\n" + output
+			}
+			b.WriteString(".nf\n" + manEscape(lines) + "\n.fi\n")
+		}
+	}
+
+	var seeAlso []string
+	if len(p.names) > 1 {
+		parent := &docsPage{names: p.names[:len(p.names)-1]}
+		seeAlso = append(seeAlso, fmt.Sprintf(".BR %s (%d)", manEscape(manPageName(parent)), section))
+	}
+	for _, child := range p.children {
+		seeAlso = append(seeAlso, fmt.Sprintf(".BR %s (%d)", manEscape(manPageName(child)), section))
+	}
+	for _, ref := range cmd.SeeAlso {
+		seeAlso = append(seeAlso, manEscape(ref))
+	}
+	if len(seeAlso) > 0 {
+		b.WriteString(".SH SEE ALSO\n")
+		// all but the last reference are separated by commas
+		for i := range seeAlso[:len(seeAlso)-1] {
+			seeAlso[i] += " ,"
+		}
+		b.WriteString(strings.Join(seeAlso, "\n") + "\n")
+	}
+
+	return b.Flush()
+}
+
+// manFlag renders a flag as tagged paragraph
+func manFlag(fl Flag) string {
+	df, ok := fl.(DocGenerationFlag)
+	if !ok {
+		return ".TP\n.B " + manEscape(prefixedNames(fl.Names(), "")) + "\n"
+	}
+
+	placeholder, usage := unquoteUsage(df.GetUsage())
+	if df.TakesValue() && placeholder == "" {
+		placeholder = defaultPlaceholder
+	}
+	if !df.TakesValue() {
+		placeholder = ""
+	}
+
+	var details []string
+	if usage != "" {
+		details = append(details, usage)
+	}
+	if hint := strings.TrimSpace(flagChoicesHint(fl) + flagLayoutsHint(fl)); hint != "" {
+		details = append(details, hint)
+	}
+	if s := df.GetDefaultText(); s != "" {
+		details = append(details, fmt.Sprintf("(default: %s)", s))
+	}
+	if envVars := df.GetEnvVars(); len(envVars) > 0 {
+		details = append(details, fmt.Sprintf("[env: %s]", strings.Join(envVars, ", ")))
+	}
+	if hint := strings.TrimSpace(flagRelationsHint(fl) + flagMetadataHint(fl)); hint != "" {
+		details = append(details, hint)
+	}
+
+	item := ".TP\n.B " + manEscape(prefixedNames(flagDocNames(fl), placeholder)) + "\n"
+	if len(details) > 0 {
+		item += manEscape(strings.Join(details, " ")) + "\n"
+	}
+	return item
+}
+
+var manEscaper = strings.NewReplacer(`\`, `\e`, "-", `\-`)
+
+// manEscape escapes backslashes and dashes and the control characters at
+// the start of lines
+func manEscape(s string) string {
+	lines := strings.Split(manEscaper.Replace(s), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// manQuote quotes an argument of a request
+func manQuote(s string) string {
+	return `"` + strings.ReplaceAll(manEscape(s), `"`, `\(dq`) + `"`
+}
@@ -0,0 +1,94 @@
+//go:build !urfave_cli_no_docs
+
+package cli
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readManPage(t *testing.T, file string) string {
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Base(file[:len(file)-len(".gz")]), zr.Name)
+
+	data, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestToManPages(t *testing.T) {
+	cmd := buildExtendedTestCommand()
+	cmd.Version = "v1.2.0"
+	dir := filepath.Join(t.TempDir(), "man1")
+
+	require.NoError(t, cmd.ToManPages(dir, 1))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, []string{
+		"greet-config-sub-config.1.gz",
+		"greet-config.1.gz",
+		"greet-info.1.gz",
+		"greet-some-command.1.gz",
+		"greet-usage-sub-usage.1.gz",
+		"greet-usage.1.gz",
+		"greet.1.gz",
+	}, names)
+
+	expectFileContent(t, "testdata/expected-man-greet.1", readManPage(t, filepath.Join(dir, "greet.1.gz")))
+	expectFileContent(t, "testdata/expected-man-greet-config.1", readManPage(t, filepath.Join(dir, "greet-config.1.gz")))
+}
+
+func TestManEscape(t *testing.T) {
+	assert.Equal(t, `\&.hidden \e \-\-flag`+"\n"+`\&'quoted'`, manEscape(".hidden \\ --flag\n'quoted'"))
+	assert.Equal(t, `"say \(dqhi\(dq"`, manQuote(`say "hi"`))
+}
+
+func TestToMan(t *testing.T) {
+	cmd := buildGlobalOptionsTestCommand()
+	sub, err := cmd.Parse(buildTestContext(t), []string{"app", "config", "set"})
+	require.NoError(t, err)
+
+	page, err := sub.ToMan(1)
+	require.NoError(t, err)
+	assert.Equal(t, `.TH "APP\-CONFIG\-SET" 1 "" "app v1.0.0"
+.SH NAME
+app\-config\-set \- set a value
+.SH SYNOPSIS
+.B app config set
+[options]
+.SH OPTIONS
+.TP
+.B \-\-force
+overwrite the value (default: false)
+.TP
+.B \-\-help, \-h
+show help (default: false)
+.SH GLOBAL OPTIONS
+.TP
+.B \-\-profile value
+profile to use
+.SH SEE ALSO
+.BR app\-config (1)
+`, page)
+
+	unrun, err := buildGlobalOptionsTestCommand().Command("config").Command("set").ToMan(1)
+	require.NoError(t, err)
+	assert.Contains(t, unrun, ".SH SYNOPSIS\n.B app config set\n", "the ancestors are linked without a run")
+	assert.Contains(t, unrun, ".SH GLOBAL OPTIONS\n.TP\n.B \\-\\-profile value\n")
+}
@@ -0,0 +1,26 @@
+package cli
+
+// Use appends middleware wrapping the Action of the command and the ones of
+// all its subcommands. Middleware runs in the order it has been added, so
+//
+//	cmd.Use(logging, tracing)
+//
+// runs logging first, which calls into tracing, which calls the Action. The
+// middleware of a command wraps the one of its subcommands.
+func (cmd *Command) Use(middleware ...MiddlewareFunc) {
+	cmd.Middleware = append(cmd.Middleware, middleware...)
+}
+
+// wrappedAction returns the Action of the command wrapped in the middleware
+// of the command and its ancestors
+func (cmd *Command) wrappedAction() ActionFunc {
+	action := cmd.Action
+
+	for pCmd := cmd; pCmd != nil; pCmd = pCmd.parent {
+		for i := len(pCmd.Middleware) - 1; i >= 0; i-- {
+			action = pCmd.Middleware[i](action)
+		}
+	}
+
+	return action
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandMiddleware(t *testing.T) {
+	var calls []string
+	record := func(name string) MiddlewareFunc {
+		return func(next ActionFunc) ActionFunc {
+			return func(ctx context.Context, cmd *Command) error {
+				calls = append(calls, name+" "+cmd.Name)
+				return next(ctx, cmd)
+			}
+		}
+	}
+
+	cmd := &Command{
+		Name: "app",
+		Commands: []*Command{
+			{
+				Name:       "sub",
+				Middleware: []MiddlewareFunc{record("sub")},
+				Action: func(context.Context, *Command) error {
+					calls = append(calls, "action")
+					return nil
+				},
+			},
+		},
+		Action: func(context.Context, *Command) error {
+			calls = append(calls, "root action")
+			return nil
+		},
+	}
+	cmd.Use(record("first"), record("second"))
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "sub"}))
+	assert.Equal(t, []string{"first sub", "second sub", "sub sub", "action"}, calls)
+
+	calls = nil
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+	assert.Equal(t, []string{"first app", "second app", "root action"}, calls)
+}
+
+func TestCommandMiddlewareRecover(t *testing.T) {
+	recoverPanics := func(next ActionFunc) ActionFunc {
+		return func(ctx context.Context, cmd *Command) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("recovered: %v", r)
+				}
+			}()
+			return next(ctx, cmd)
+		}
+	}
+	skip := func(next ActionFunc) ActionFunc {
+		return func(context.Context, *Command) error {
+			return errors.New("skipped")
+		}
+	}
+
+	cmd := &Command{
+		Name:       "app",
+		Middleware: []MiddlewareFunc{recoverPanics},
+		Action: func(context.Context, *Command) error {
+			panic("boom")
+		},
+	}
+
+	assert.EqualError(t, cmd.Run(buildTestContext(t), []string{"app"}), "recovered: boom")
+
+	cmd.Use(skip)
+	assert.EqualError(t, cmd.Run(buildTestContext(t), []string{"app"}), "skipped")
+}
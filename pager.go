@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// defaultPager is the pager used if the PAGER environment variable is unset
+const defaultPager = "less"
+
+// pagedWriter returns a writer piping the help output through the pager if
+// the root command has EnablePager set and its Terminal is interactive,
+// along with the function waiting for the pager to exit once the output is
+// written. The output is returned unchanged otherwise.
+func (cmd *Command) pagedWriter(out io.Writer) (io.Writer, func()) {
+	if !cmd.Root().EnablePager || !runtimeCanExec || !cmd.IsTTY() {
+		return out, func() {}
+	}
+
+	pager, ok := cmd.LookupEnv("PAGER")
+	if !ok {
+		pager = defaultPager
+	}
+	args := strings.Fields(pager)
+	if len(args) == 0 || args[0] == "cat" {
+		return out, func() {}
+	}
+
+	p := exec.Command(args[0], args[1:]...)
+	p.Stdout = out
+	p.Stderr = cmd.errWriter()
+	p.Env = environ(cmd.environment())
+	// like git, let less quit if the output fits on the screen and keep
+	// the colors unless configured otherwise
+	if _, ok := cmd.LookupEnv("LESS"); !ok {
+		p.Env = append(p.Env, "LESS=FRX")
+	}
+
+	in, err := p.StdinPipe()
+	if err == nil {
+		err = p.Start()
+	}
+	if err != nil {
+		tracef("unable to start pager %[1]q: %[2]v (cmd=%[3]q)", pager, err, cmd.Name)
+		return out, func() {}
+	}
+
+	tracef("paging help through %[1]q (cmd=%[2]q)", pager, cmd.Name)
+
+	return in, func() {
+		_ = in.Close()
+		if err := p.Wait(); err != nil {
+			tracef("pager %[1]q failed: %[2]v (cmd=%[3]q)", pager, err, cmd.Name)
+		}
+	}
+}
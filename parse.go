@@ -1,8 +1,11 @@
 package cli
 
 import (
+	"context"
 	"flag"
+	"io"
 	"strings"
+	"unicode/utf8"
 )
 
 type iterativeParser interface {
@@ -21,7 +24,7 @@ func parseIter(set *flag.FlagSet, ip iterativeParser, args []string, shellComple
 		tracef("parsing args %[1]q with %[2]T (name=%[3]q)", args, set, set.Name())
 
 		err := set.Parse(args)
-		if !ip.useShortOptionHandling() || err == nil {
+		if err == nil || (!ip.useShortOptionHandling() && !isRepeatedCountFlagError(set, err)) {
 			if shellComplete {
 				tracef("returning nil due to shellComplete=true")
 
@@ -46,14 +49,15 @@ func parseIter(set *flag.FlagSet, ip iterativeParser, args []string, shellComple
 		for i, arg := range args {
 			tracef("skipping args that are not part of the error message (i=%[1]v arg=%[2]q)", i, arg)
 
-			if name := strings.TrimLeft(arg, "-"); name != trimmed {
+			// the cluster of -abc=value is reported as flag abc
+			if name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "="); name != trimmed {
 				continue
 			}
 
 			tracef("trying to split short option (arg=%[1]q)", arg)
 
 			shortOpts := splitShortOptions(set, arg)
-			if len(shortOpts) == 1 {
+			if len(shortOpts) == 1 && shortOpts[0] == arg {
 				return err
 			}
 
@@ -79,11 +83,76 @@ func parseIter(set *flag.FlagSet, ip iterativeParser, args []string, shellComple
 	}
 }
 
+// normalizeFlagSyntax rewrites the flags given with one of the prefixes or
+// separators to the syntax of the flag set, e.g. /out:file.txt to
+// -out=file.txt. Like the flag set it stops at the first argument which is
+// neither a flag nor the value of one, so the arguments of subcommands are
+// left to be normalized by them.
+func normalizeFlagSyntax(set *flag.FlagSet, args []string, prefixes, separators []string) []string {
+	normalized := make([]string, 0, len(args))
+	prefixes = append(append([]string{}, prefixes...), "--", "-")
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		prefix, name := "", ""
+		for _, p := range prefixes {
+			if len(arg) > len(p) && strings.HasPrefix(arg, p) {
+				prefix, name = p, arg[len(p):]
+				break
+			}
+		}
+		if prefix == "" || arg == "--" {
+			return append(normalized, args[i:]...)
+		}
+
+		value, hasValue := "", false
+		if n, v, ok := strings.Cut(name, "="); ok && set.Lookup(n) != nil {
+			name, value, hasValue = n, v, true
+		}
+		for _, sep := range separators {
+			if n, v, ok := strings.Cut(name, sep); !hasValue && ok && set.Lookup(n) != nil {
+				name, value, hasValue = n, v, true
+			}
+		}
+
+		f := set.Lookup(name)
+		if f == nil {
+			if prefix != "-" && prefix != "--" {
+				// an argument like /etc/hosts
+				return append(normalized, args[i:]...)
+			}
+			normalized = append(normalized, arg)
+			continue
+		}
+
+		if prefix != "--" {
+			prefix = "-"
+		}
+		if hasValue {
+			normalized = append(normalized, prefix+name+"="+value)
+			continue
+		}
+		normalized = append(normalized, prefix+name)
+
+		if b, ok := f.Value.(boolFlag); (!ok || !b.IsBoolFlag()) && i+1 < len(args) {
+			i++
+			normalized = append(normalized, args[i])
+		}
+	}
+
+	return normalized
+}
+
 const providedButNotDefinedErrMsg = "flag provided but not defined: -"
 
 // flagFromError tries to parse a provided flag from an error message. If the
 // parsing fials, it returns the input error and an empty string
 func flagFromError(err error) (string, error) {
+	if undefinedErr, ok := err.(*ErrFlagUndefined); ok {
+		return undefinedErr.Flag, nil
+	}
+
 	errStr := err.Error()
 	trimmed := strings.TrimPrefix(errStr, providedButNotDefinedErrMsg)
 	if errStr == trimmed {
@@ -92,28 +161,93 @@ func flagFromError(err error) (string, error) {
 	return trimmed, nil
 }
 
-func splitShortOptions(set *flag.FlagSet, arg string) []string {
-	shortFlagsExist := func(s string) bool {
-		for _, c := range s[1:] {
-			if f := set.Lookup(string(c)); f == nil {
-				return false
+// invalidFlagFromError tries to parse the name of a defined flag from an
+// error message of a flag set caused by an invalid or missing value
+func invalidFlagFromError(err error) (string, bool) {
+	errStr := err.Error()
+
+	if name := strings.TrimPrefix(errStr, "flag needs an argument: -"); name != errStr {
+		return name, true
+	}
+
+	for _, sep := range []string{" for flag -", " for -"} {
+		if !strings.HasPrefix(errStr, "invalid ") {
+			break
+		}
+
+		if i := strings.Index(errStr, sep); i >= 0 {
+			name := errStr[i+len(sep):]
+			if j := strings.Index(name, ":"); j >= 0 {
+				name = name[:j]
 			}
+			return name, true
 		}
-		return true
 	}
 
-	if !isSplittable(arg) || !shortFlagsExist(arg) {
+	return "", false
+}
+
+// splitShortOptions splits a cluster of single character flags like -abc
+// into -a -b -c. The rest of the cluster following a flag taking a value is
+// the value, so -ofile and -vo=file are split into -o=file and -v -o=file.
+func splitShortOptions(set *flag.FlagSet, arg string) []string {
+	if !isSplittable(arg) {
 		return []string{arg}
 	}
 
-	separated := make([]string, 0, len(arg)-1)
-	for _, flagChar := range arg[1:] {
-		separated = append(separated, "-"+string(flagChar))
+	cluster := arg[1:]
+	separated := make([]string, 0, len(cluster))
+	for i, flagChar := range cluster {
+		f := set.Lookup(string(flagChar))
+		if f == nil {
+			return []string{arg}
+		}
+
+		name := "-" + string(flagChar)
+		rest := cluster[i+utf8.RuneLen(flagChar):]
+		if strings.HasPrefix(rest, "=") {
+			return append(separated, name+rest)
+		}
+		if b, ok := f.Value.(boolFlag); (!ok || !b.IsBoolFlag()) && rest != "" {
+			return append(separated, name+"="+rest)
+		}
+
+		separated = append(separated, name)
 	}
 
 	return separated
 }
 
+// isRepeatedCountFlagError reports whether the error is caused by a
+// repeated count flag like -vvv
+func isRepeatedCountFlagError(set *flag.FlagSet, err error) bool {
+	name, fErr := flagFromError(err)
+	return fErr == nil && isRepeatedCountFlag(set, "-"+name)
+}
+
 func isSplittable(flagArg string) bool {
 	return strings.HasPrefix(flagArg, "-") && !strings.HasPrefix(flagArg, "--") && len(flagArg) > 2
 }
+
+// Parse parses the arguments like Run and returns the command which would
+// be run. No Before, After or Action functions, flag actions or prompts are
+// run, nothing is written and the program is never exited. Errors are
+// returned as they occurred instead of being handled, which makes Parse
+// suitable for fuzzing the flag definitions of a command. It is meant to be
+// called on the root command.
+func (cmd *Command) Parse(ctx context.Context, osArgs []string) (*Command, error) {
+	writer, errWriter := cmd.Writer, cmd.ErrWriter
+	cmd.Writer, cmd.ErrWriter = io.Discard, io.Discard
+	cmd.parseOnly = true
+
+	defer func() {
+		cmd.Writer, cmd.ErrWriter = writer, errWriter
+		cmd.parseOnly = false
+	}()
+
+	tracef("parsing arguments only (cmd=%[1]q)", cmd.Name)
+
+	err := cmd.Run(ctx, osArgs)
+
+	return cmd.invokedCommand, err
+}
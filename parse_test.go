@@ -0,0 +1,283 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildParseTestCommand(called *[]string) *Command {
+	record := func(name string) func(context.Context, *Command) error {
+		return func(context.Context, *Command) error {
+			*called = append(*called, name)
+			return nil
+		}
+	}
+
+	return &Command{
+		Name:   "app",
+		Before: record("before"),
+		After:  record("after"),
+		Action: record("action"),
+		Flags: []Flag{
+			&StringFlag{Name: "name", Aliases: []string{"n"}},
+			&BoolFlag{Name: "verbose", Aliases: []string{"v"}, Action: func(ctx context.Context, cmd *Command, _ bool) error {
+				return record("flag")(ctx, cmd)
+			}},
+			&IntFlag{Name: "count", Aliases: []string{"c"}},
+			&StringSliceFlag{Name: "tag"},
+			&DurationFlag{Name: "timeout"},
+		},
+		Commands: []*Command{
+			{
+				Name:   "sub",
+				Action: record("sub"),
+				Flags: []Flag{
+					&FloatFlag{Name: "ratio", Required: true},
+				},
+				Commands: []*Command{
+					{Name: "leaf", Action: record("leaf")},
+				},
+			},
+		},
+	}
+}
+
+func TestParse(t *testing.T) {
+	var called []string
+
+	out := &bytes.Buffer{}
+	cmd := buildParseTestCommand(&called)
+	cmd.Writer = out
+	cmd.ErrWriter = out
+
+	invoked, err := cmd.Parse(buildTestContext(t), []string{"app", "-v", "--name", "gopher", "sub", "--ratio", "0.5", "leaf"})
+	require.NoError(t, err)
+	require.NotNil(t, invoked)
+	assert.Equal(t, "leaf", invoked.Name)
+	assert.Equal(t, "gopher", invoked.String("name"))
+	assert.True(t, invoked.Bool("verbose"))
+	assert.Equal(t, 0.5, invoked.Float("ratio"))
+	assert.Empty(t, called)
+	assert.Empty(t, out.String())
+	assert.Same(t, out, cmd.Writer)
+	assert.Same(t, out, cmd.ErrWriter)
+}
+
+func TestParseErrors(t *testing.T) {
+	defer func() { OsExiter = fakeOsExiter }()
+	OsExiter = func(int) { t.Fatal("Parse must not exit") }
+
+	tests := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{name: "undefined flag", args: []string{"app", "--nope"}, err: "flag provided but not defined: -nope"},
+		{name: "invalid value", args: []string{"app", "--count", "abc"}, err: `invalid value "abc" for flag -count`},
+		{name: "required flag", args: []string{"app", "sub"}, err: `Required flag "ratio" not set`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var called []string
+
+			_, err := buildParseTestCommand(&called).Parse(buildTestContext(t), test.args)
+			require.ErrorContains(t, err, test.err)
+			assert.Empty(t, called)
+		})
+	}
+}
+
+func TestParseHelp(t *testing.T) {
+	var called []string
+
+	out := &bytes.Buffer{}
+	cmd := buildParseTestCommand(&called)
+	cmd.Writer = out
+
+	invoked, err := cmd.Parse(buildTestContext(t), []string{"app", "--help"})
+	require.NoError(t, err)
+	assert.Equal(t, "app", invoked.Name)
+	assert.Empty(t, out.String())
+	assert.Empty(t, called)
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"--name\ngopher",
+		"-n=gopher\n-v",
+		"-vc\n3",
+		"--tag\na\n--tag\nb,c",
+		"--timeout\n1h2m",
+		"sub\n--ratio\n0.5\nleaf",
+		"sub\nleaf\n--\n-v",
+		"--help",
+		"sub\n-h",
+		"--count\n-1\n--count=0x10",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		var called []string
+
+		args := append([]string{"app"}, strings.Split(input, "\n")...)
+
+		_, _ = buildParseTestCommand(&called).Parse(context.Background(), args)
+		if len(called) > 0 {
+			t.Fatalf("Parse ran %v for %q", called, args)
+		}
+	})
+}
+
+func BenchmarkParse(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		args []string
+	}{
+		{name: "root", args: []string{"app"}},
+		{name: "flags", args: []string{"app", "-v", "--name", "gopher", "--tag", "a,b", "--timeout", "1m"}},
+		{name: "subcommands", args: []string{"app", "sub", "--ratio", "0.5", "leaf"}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			var called []string
+
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				cmd := buildParseTestCommand(&called)
+				b.StartTimer()
+
+				if _, err := cmd.Parse(context.Background(), bm.args); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// buildManyFlagsTestCommand returns a command with n string flags of which
+// every tenth is required, and arguments setting all of them
+func buildManyFlagsTestCommand(n int) (*Command, []string) {
+	args := []string{"app"}
+	cmd := &Command{Name: "app"}
+
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("flag-%d", i)
+		cmd.Flags = append(cmd.Flags, &StringFlag{
+			Name:     name,
+			Aliases:  []string{fmt.Sprintf("f%d", i)},
+			Required: i%10 == 0,
+		})
+		args = append(args, "--"+name, "value")
+	}
+
+	cmd.Action = func(_ context.Context, cmd *Command) error {
+		for i := 0; i < n; i++ {
+			name := fmt.Sprintf("flag-%d", i)
+			if !cmd.IsSet(name) || cmd.String(name) != "value" {
+				return fmt.Errorf("flag %s not set", name)
+			}
+		}
+		return nil
+	}
+
+	return cmd, args
+}
+
+func BenchmarkRunManyFlags(b *testing.B) {
+	for _, n := range []int{10, 100, 500} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				cmd, args := buildManyFlagsTestCommand(n)
+				b.StartTimer()
+
+				if err := cmd.Run(context.Background(), args); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkRunSimple(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		cmd := &Command{
+			Name: "app",
+			Commands: []*Command{
+				{
+					Name: "prompt",
+					Flags: []Flag{
+						&StringFlag{Name: "format"},
+					},
+					Action: func(_ context.Context, cmd *Command) error {
+						if cmd.String("format") != "short" {
+							return fmt.Errorf("unexpected format %q", cmd.String("format"))
+						}
+						return nil
+					},
+				},
+			},
+		}
+		b.StartTimer()
+
+		if err := cmd.Run(context.Background(), []string{"app", "prompt", "--format", "short"}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestParseSlashFlags(t *testing.T) {
+	var called []string
+
+	cmd := buildParseTestCommand(&called)
+	cmd.UseSlashFlags = true
+
+	invoked, err := cmd.Parse(buildTestContext(t), []string{"app", "/v", "/name:gopher", "/count", "3", "--tag:a", "/tag=b", "sub", "/ratio:0.5", "/etc/hosts"})
+	require.NoError(t, err)
+	assert.Equal(t, "sub", invoked.Name)
+	assert.True(t, invoked.Bool("verbose"))
+	assert.Equal(t, "gopher", invoked.String("name"))
+	assert.Equal(t, int64(3), invoked.Int("count"))
+	assert.Equal(t, []string{"a", "b"}, invoked.StringSlice("tag"))
+	assert.Equal(t, 0.5, invoked.Float("ratio"))
+	assert.Equal(t, []string{"/etc/hosts"}, invoked.Args().Slice(), "arguments not naming a flag are kept")
+}
+
+func TestNormalizeFlagSyntax(t *testing.T) {
+	var called []string
+
+	cmd := buildParseTestCommand(&called)
+	set, err := cmd.newFlagSet()
+	require.NoError(t, err)
+
+	tests := []struct {
+		args     []string
+		expected []string
+	}{
+		{args: []string{"+verbose", "+name", "+x", "++count::2"}, expected: []string{"-verbose", "-name", "+x", "-count=2"}},
+		{args: []string{"--name::a", "-c::1", "--undefined::1", "arg", "+v"}, expected: []string{"--name=a", "-c=1", "--undefined::1", "arg", "+v"}},
+		{args: []string{"+v", "--", "+v"}, expected: []string{"-v", "--", "+v"}},
+		{args: []string{"++name=a::b"}, expected: []string{"-name=a::b"}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, normalizeFlagSyntax(set, test.args, []string{"++", "+"}, []string{"::"}), test.args)
+	}
+}
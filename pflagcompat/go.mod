@@ -0,0 +1,17 @@
+module github.com/urfave/cli/v3/pflagcompat
+
+go 1.18
+
+replace github.com/urfave/cli/v3 => ../
+
+require (
+	github.com/spf13/pflag v1.0.5
+	github.com/stretchr/testify v1.8.4
+	github.com/urfave/cli/v3 v3.0.0-alpha9
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,263 @@
+// Package pflagcompat adapts between urfave/cli flags and spf13/pflag, so
+// libraries written against pflag plug into commands without rewrites.
+//
+// It is a module of its own, so pflag is only a dependency of the programs
+// using this package and not of every program using urfave/cli.
+package pflagcompat
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/urfave/cli/v3"
+)
+
+// requiredAnnotation is the annotation cobra's MarkFlagRequired sets
+const requiredAnnotation = "cobra_annotation_bash_completion_one_required_flag"
+
+// Flags wraps the flags defined in a pflag flag set, e.g. by a library
+// registering its options, to be added to the Flags of a command. Usage
+// text, defaults, shorthands, hidden and required flags are preserved and
+// values are set on the original pflag values, marking them as changed.
+//
+//	fs := pflag.NewFlagSet("klog", pflag.ContinueOnError)
+//	klog.InitFlags(fs)
+//	cmd.Flags = append(cmd.Flags, pflagcompat.Flags(fs)...)
+func Flags(fs *pflag.FlagSet) []cli.Flag {
+	var flags []cli.Flag
+
+	fs.VisitAll(func(f *pflag.Flag) {
+		flags = append(flags, FromPFlag(f))
+	})
+
+	return flags
+}
+
+// PersistentFlags is like Flags but the flags are persistent, i.e. they
+// are applied to the subcommands of the command as well
+func PersistentFlags(fs *pflag.FlagSet) []cli.Flag {
+	var flags []cli.Flag
+
+	fs.VisitAll(func(f *pflag.Flag) {
+		flags = append(flags, &pFlag{f: f, persistent: true})
+	})
+
+	return flags
+}
+
+// FromPFlag wraps a single pflag flag, which allows to use any pflag.Value
+// implementation as the value of a cli flag
+//
+//	cmd.Flags = append(cmd.Flags, pflagcompat.FromPFlag(&pflag.Flag{
+//		Name:  "level",
+//		Usage: "log level",
+//		Value: &level,
+//	}))
+func FromPFlag(f *pflag.Flag) cli.Flag {
+	return &pFlag{f: f}
+}
+
+// pFlag is a cli.Flag backed by a pflag flag
+type pFlag struct {
+	f          *pflag.Flag
+	persistent bool
+}
+
+// pValue adapts the value of a pflag flag to flag.Value, marking it as a
+// bool flag if the flag may be given without a value
+type pValue struct {
+	f *pflag.Flag
+}
+
+func (v *pValue) String() string {
+	return v.f.Value.String()
+}
+
+func (v *pValue) Set(s string) error {
+	if err := v.f.Value.Set(s); err != nil {
+		return err
+	}
+
+	v.f.Changed = true
+
+	return nil
+}
+
+func (v *pValue) IsBoolFlag() bool {
+	return v.f.NoOptDefVal == "true"
+}
+
+func (pf *pFlag) Apply(set *flag.FlagSet) error {
+	// the flag is only changed by the run it is applied to
+	pf.f.Changed = false
+
+	for _, name := range pf.Names() {
+		set.Var(&pValue{f: pf.f}, name, pf.f.Usage)
+	}
+
+	return nil
+}
+
+func (pf *pFlag) Names() []string {
+	if pf.f.Shorthand == "" {
+		return []string{pf.f.Name}
+	}
+
+	return []string{pf.f.Name, pf.f.Shorthand}
+}
+
+func (pf *pFlag) IsSet() bool {
+	return pf.f.Changed
+}
+
+func (pf *pFlag) String() string {
+	return cli.FlagStringer(pf)
+}
+
+func (pf *pFlag) IsPersistent() bool {
+	return pf.persistent
+}
+
+func (pf *pFlag) IsRequired() bool {
+	req := pf.f.Annotations[requiredAnnotation]
+	return len(req) == 1 && req[0] == "true"
+}
+
+func (pf *pFlag) IsVisible() bool {
+	return !pf.f.Hidden
+}
+
+func (pf *pFlag) TakesValue() bool {
+	return pf.f.NoOptDefVal == ""
+}
+
+func (pf *pFlag) GetUsage() string {
+	return pf.f.Usage
+}
+
+func (pf *pFlag) GetValue() string {
+	return pf.f.Value.String()
+}
+
+func (pf *pFlag) GetDefaultText() string {
+	return pf.f.DefValue
+}
+
+func (pf *pFlag) GetEnvVars() []string {
+	return nil
+}
+
+// FlagSet exposes the flags of the command and its ancestors as a pflag
+// flag set, e.g. to pass the parsed options to a library reading them via
+// pflag. Values are read from and set on the command. The types of the
+// values are the ones of the cli flags, e.g. integers are int64 values and
+// must be read with GetInt64.
+func FlagSet(cmd *cli.Command) *pflag.FlagSet {
+	fs := pflag.NewFlagSet(cmd.Name, pflag.ContinueOnError)
+
+	for _, pCmd := range cmd.Lineage() {
+		for _, fl := range pCmd.Flags {
+			var name, shorthand string
+
+			for _, n := range fl.Names() {
+				switch {
+				case len(n) == 1 && shorthand == "":
+					shorthand = n
+				case len(n) > 1 && name == "":
+					name = n
+				}
+			}
+
+			if name == "" {
+				name, shorthand = shorthand, ""
+			}
+
+			if name == "" || fs.Lookup(name) != nil || (shorthand != "" && fs.ShorthandLookup(shorthand) != nil) {
+				continue
+			}
+
+			f := fs.VarPF(&cmdValue{cmd: cmd, name: name}, name, shorthand, "")
+			f.Changed = cmd.IsSet(name)
+
+			if df, ok := fl.(cli.DocGenerationFlag); ok {
+				f.Usage = df.GetUsage()
+				f.DefValue = df.GetValue()
+
+				if !df.TakesValue() {
+					f.NoOptDefVal = "true"
+				}
+			}
+
+			if vf, ok := fl.(cli.VisibleFlag); ok {
+				f.Hidden = !vf.IsVisible()
+			}
+		}
+	}
+
+	return fs
+}
+
+// cmdValue is a pflag.Value reading and setting a flag of a command
+type cmdValue struct {
+	cmd  *cli.Command
+	name string
+}
+
+func (v *cmdValue) String() string {
+	switch val := v.cmd.Value(v.name).(type) {
+	case nil:
+		return ""
+	case []string:
+		return "[" + strings.Join(val, ",") + "]"
+	case []int64, []uint64, []float64:
+		return strings.ReplaceAll(fmt.Sprint(val), " ", ",")
+	case map[string]string:
+		pairs := make([]string, 0, len(val))
+		for k, v := range val {
+			pairs = append(pairs, k+"="+v)
+		}
+		sort.Strings(pairs)
+
+		return "[" + strings.Join(pairs, ",") + "]"
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func (v *cmdValue) Set(s string) error {
+	return v.cmd.Set(v.name, s)
+}
+
+// Type returns the name pflag uses for the type of the value, which is
+// checked by the typed getters of pflag.FlagSet
+func (v *cmdValue) Type() string {
+	switch v.cmd.Value(v.name).(type) {
+	case bool:
+		return "bool"
+	case string:
+		return "string"
+	case int64:
+		return "int64"
+	case uint64:
+		return "uint64"
+	case float64:
+		return "float64"
+	case time.Duration:
+		return "duration"
+	case []string:
+		return "stringSlice"
+	case []int64:
+		return "int64Slice"
+	case []float64:
+		return "float64Slice"
+	case map[string]string:
+		return "stringToString"
+	default:
+		return fmt.Sprintf("%T", v.cmd.Value(v.name))
+	}
+}
@@ -0,0 +1,131 @@
+package pflagcompat
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/urfave/cli/v3"
+)
+
+func TestFlags(t *testing.T) {
+	fs := pflag.NewFlagSet("lib", pflag.ContinueOnError)
+	level := fs.IntP("level", "l", 1, "log level")
+	verbose := fs.BoolP("verbose", "V", false, "verbose output")
+	hosts := fs.StringSlice("hosts", []string{"localhost"}, "hosts to connect to")
+	fs.String("internal", "", "internal option")
+	require.NoError(t, fs.MarkHidden("internal"))
+
+	var action bool
+
+	out := &bytes.Buffer{}
+	cmd := &cli.Command{
+		Name:   "app",
+		Writer: out,
+		Flags:  Flags(fs),
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			action = cmd.IsSet("level")
+			return nil
+		},
+	}
+
+	require.NoError(t, cmd.Run(context.Background(), []string{"app", "-l", "3", "-V", "--hosts", "a,b"}))
+	assert.Equal(t, 3, *level)
+	assert.True(t, *verbose)
+	assert.Equal(t, []string{"a", "b"}, *hosts)
+	assert.True(t, action)
+
+	require.NoError(t, cmd.Run(context.Background(), []string{"app", "--help"}))
+	assert.Contains(t, out.String(), "--level value, -l value  log level (default: 1)")
+	assert.Contains(t, out.String(), "--verbose, -V            verbose output (default: false)")
+	assert.NotContains(t, out.String(), "internal")
+}
+
+func TestFlagSet(t *testing.T) {
+	var fs *pflag.FlagSet
+
+	cmd := &cli.Command{
+		Name: "app",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "name", Aliases: []string{"n"}, Usage: "your name", Persistent: true},
+			&cli.IntFlag{Name: "count", Value: 2},
+			&cli.StringSliceFlag{Name: "tag"},
+			&cli.StringMapFlag{Name: "label"},
+		},
+		Commands: []*cli.Command{
+			{
+				Name: "serve",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "tls"},
+					&cli.DurationFlag{Name: "timeout", Value: time.Second},
+				},
+				Action: func(_ context.Context, cmd *cli.Command) error {
+					fs = FlagSet(cmd)
+					return nil
+				},
+			},
+		},
+	}
+
+	require.NoError(t, cmd.Run(context.Background(), []string{
+		"app", "--tag", "a", "--tag", "b", "--label", "env=prod", "serve", "-n", "gopher", "--tls",
+	}))
+	require.NotNil(t, fs)
+
+	name, err := fs.GetString("name")
+	require.NoError(t, err)
+	assert.Equal(t, "gopher", name)
+	assert.Equal(t, "n", fs.Lookup("name").Shorthand)
+	assert.Equal(t, "your name", fs.Lookup("name").Usage)
+	assert.True(t, fs.Changed("name"))
+
+	count, err := fs.GetInt64("count")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.False(t, fs.Changed("count"))
+
+	tls, err := fs.GetBool("tls")
+	require.NoError(t, err)
+	assert.True(t, tls)
+
+	timeout, err := fs.GetDuration("timeout")
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, timeout)
+
+	tags, err := fs.GetStringSlice("tag")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, tags)
+
+	labels, err := fs.GetStringToString("label")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "prod"}, labels)
+
+	require.NoError(t, fs.Set("timeout", "1m"))
+	timeout, err = fs.GetDuration("timeout")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, timeout)
+}
+
+func TestPersistentFlags(t *testing.T) {
+	fs := pflag.NewFlagSet("lib", pflag.ContinueOnError)
+	config := fs.String("config", "", "config file")
+	fs.String("token", "", "api token")
+	require.NoError(t, fs.SetAnnotation("token", requiredAnnotation, []string{"true"}))
+
+	cmd := &cli.Command{
+		Name:     "app",
+		Flags:    PersistentFlags(fs),
+		Commands: []*cli.Command{{Name: "sub", Action: func(context.Context, *cli.Command) error { return nil }}},
+	}
+
+	err := cmd.Run(context.Background(), []string{"app", "sub", "--config", "app.yaml"})
+	require.EqualError(t, err, `Required flag "token" not set`)
+	assert.Equal(t, "app.yaml", *config)
+	assert.True(t, fs.Changed("config"))
+	assert.False(t, fs.Changed("token"))
+}
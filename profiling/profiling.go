@@ -0,0 +1,167 @@
+// Package profiling provides hidden flags to write CPU and memory profiles
+// as well as execution traces of a command run, so investigating the
+// performance of a CLI does not require temporary code changes.
+//
+// It lives in its own package to not add the size of the profiling
+// packages of the runtime to every binary using urfave/cli.
+package profiling
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+
+	"github.com/urfave/cli/v3"
+)
+
+// Enable adds the hidden persistent flags --cpuprofile, --memprofile and
+// --trace to the given command, usually the root command. Profiling starts
+// when the flags are processed and stops once the After function of the
+// command has run, wrapping any After function already set.
+func Enable(cmd *cli.Command) {
+	p := &profiler{}
+
+	cmd.Flags = append(cmd.Flags,
+		&cli.StringFlag{
+			Name:       "cpuprofile",
+			Usage:      "write a CPU profile to `FILE`",
+			Hidden:     true,
+			Persistent: true,
+			TakesFile:  true,
+			Action: func(_ context.Context, _ *cli.Command, path string) error {
+				return p.startCPUProfile(path)
+			},
+		},
+		&cli.StringFlag{
+			Name:       "memprofile",
+			Usage:      "write a memory profile to `FILE` once the command finished",
+			Hidden:     true,
+			Persistent: true,
+			TakesFile:  true,
+			Action: func(_ context.Context, _ *cli.Command, path string) error {
+				p.memProfile = path
+				return nil
+			},
+		},
+		&cli.StringFlag{
+			Name:       "trace",
+			Usage:      "write an execution trace to `FILE`",
+			Hidden:     true,
+			Persistent: true,
+			TakesFile:  true,
+			Action: func(_ context.Context, _ *cli.Command, path string) error {
+				return p.startTrace(path)
+			},
+		},
+	)
+
+	after := cmd.After
+	cmd.After = func(ctx context.Context, cmd *cli.Command) error {
+		var err error
+		if after != nil {
+			err = after(ctx, cmd)
+		}
+
+		if stopErr := p.stop(); err == nil {
+			err = stopErr
+		}
+
+		return err
+	}
+}
+
+// profiler holds the state of the profiles of a single run
+type profiler struct {
+	cpuProfile *os.File
+	traceFile  *os.File
+	memProfile string
+}
+
+func (p *profiler) startCPUProfile(path string) error {
+	// the action of a persistent flag may run for several commands
+	if p.cpuProfile != nil {
+		return nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := pprof.StartCPUProfile(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	p.cpuProfile = f
+	return nil
+}
+
+func (p *profiler) startTrace(path string) error {
+	if p.traceFile != nil {
+		return nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := trace.Start(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	p.traceFile = f
+	return nil
+}
+
+// stop stops all started profiles, writes the memory profile and resets the
+// profiler for the next run
+func (p *profiler) stop() error {
+	var errs []error
+
+	if p.cpuProfile != nil {
+		pprof.StopCPUProfile()
+		errs = append(errs, p.cpuProfile.Close())
+		p.cpuProfile = nil
+	}
+
+	if p.traceFile != nil {
+		trace.Stop()
+		errs = append(errs, p.traceFile.Close())
+		p.traceFile = nil
+	}
+
+	if p.memProfile != "" {
+		errs = append(errs, writeHeapProfile(p.memProfile))
+		p.memProfile = ""
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	// get up-to-date statistics
+	runtime.GC()
+
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,65 @@
+package profiling
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/urfave/cli/v3"
+)
+
+func TestEnable(t *testing.T) {
+	dir := t.TempDir()
+
+	afterCalled := false
+
+	cmd := &cli.Command{
+		Name: "app",
+		After: func(context.Context, *cli.Command) error {
+			afterCalled = true
+			return nil
+		},
+		Commands: []*cli.Command{
+			{
+				Name: "work",
+				Action: func(context.Context, *cli.Command) error {
+					return nil
+				},
+			},
+		},
+	}
+	Enable(cmd)
+
+	r := require.New(t)
+	r.NoError(cmd.Run(context.Background(), []string{
+		"app",
+		"--cpuprofile", filepath.Join(dir, "cpu.pprof"),
+		"work",
+		"--memprofile", filepath.Join(dir, "mem.pprof"),
+		"--trace", filepath.Join(dir, "trace.out"),
+	}))
+	r.True(afterCalled)
+
+	for _, name := range []string{"cpu.pprof", "mem.pprof", "trace.out"} {
+		fi, err := os.Stat(filepath.Join(dir, name))
+		r.NoError(err)
+		r.NotZero(fi.Size(), name)
+	}
+
+	// profiling has been stopped and can be started again
+	r.NoError(cmd.Run(context.Background(), []string{"app", "--cpuprofile", filepath.Join(dir, "cpu2.pprof"), "work"}))
+}
+
+func TestEnableHidden(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	cmd := &cli.Command{Name: "app", Writer: out}
+	Enable(cmd)
+
+	require.NoError(t, cmd.Run(context.Background(), []string{"app", "--help"}))
+	require.NotContains(t, out.String(), "profile")
+}
@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// EchoDisabler is implemented by readers able to stop echoing the input,
+// e.g. to script the input of sensitive values in tests
+type EchoDisabler interface {
+	// DisableEcho stops echoing the input until restore is called
+	DisableEcho() (restore func(), err error)
+}
+
+// disableReaderEcho turns off the echo of the input read from the reader
+// and returns a function restoring the previous state
+func disableReaderEcho(r io.Reader) (func(), error) {
+	if ed, ok := r.(EchoDisabler); ok {
+		return ed.DisableEcho()
+	}
+
+	f, ok := r.(*os.File)
+	if !ok {
+		return nil, errors.New("unable to disable echo for non-file input")
+	}
+
+	return disableEcho(f.Fd())
+}
+
+// Prompter asks for the values of missing required flags if PromptMissing
+// is enabled, e.g. to use a prompt library or a graphical dialog instead
+// of reading lines from the Reader of the root command
+type Prompter interface {
+	// Prompt returns the value entered for the flag. An empty value leaves
+	// the flag unset, io.EOF stops prompting for further flags.
+	Prompt(ctx context.Context, req PromptRequest) (string, error)
+}
+
+// PrompterFunc is an adapter to use a function as Prompter
+type PrompterFunc func(ctx context.Context, req PromptRequest) (string, error)
+
+// Prompt calls f(ctx, req)
+func (f PrompterFunc) Prompt(ctx context.Context, req PromptRequest) (string, error) {
+	return f(ctx, req)
+}
+
+// PromptRequest describes the flag a Prompter asks the value for
+type PromptRequest struct {
+	// Command the flag is missing for
+	Command *Command
+	// Flag which is missing
+	Flag Flag
+	// Name of the flag
+	Name string
+	// Usage of the flag, if any
+	Usage string
+	// Sensitive is true if the input must be hidden, e.g. for passwords
+	Sensitive bool
+	// Err is set if the flag is asked for again since the previous value
+	// was invalid
+	Err error
+}
+
+// terminalPrompter is the default Prompter reading lines from the Reader
+// of the root command, disabling echo for sensitive values
+type terminalPrompter struct{}
+
+func (terminalPrompter) Prompt(_ context.Context, req PromptRequest) (string, error) {
+	root := req.Command.Root()
+	if req.Err != nil {
+		_, _ = fmt.Fprintln(req.Command.errWriter(), req.Err)
+	}
+
+	prompt := req.Name + ": "
+	if req.Usage != "" {
+		prompt = fmt.Sprintf("%s (%s): ", req.Name, req.Usage)
+	}
+
+	return root.readPromptLine(req.Command.errWriter(), prompt, req.Sensitive)
+}
+
+// prompter returns the Prompter of the command or its nearest ancestor
+// setting one
+func (cmd *Command) prompter() Prompter {
+	for pCmd := cmd; pCmd != nil; pCmd = pCmd.parent {
+		if pCmd.Prompter != nil {
+			return pCmd.Prompter
+		}
+	}
+
+	return nil
+}
+
+// shouldPromptMissing returns true if the command or one of its ancestors
+// enabled PromptMissing and either sets a Prompter or the command runs in
+// an interactive terminal
+func (cmd *Command) shouldPromptMissing() bool {
+	if cmd.Root().parseOnly {
+		return false
+	}
+
+	for pCmd := cmd; pCmd != nil; pCmd = pCmd.parent {
+		if pCmd.PromptMissing {
+			return cmd.prompter() != nil || cmd.Root().terminal().IsTTY()
+		}
+	}
+
+	return false
+}
+
+// promptMissingFlags asks for the value of every required flag of the given
+// ones which has not been set yet, only considering either persistent or
+// non-persistent flags. Flags left empty stay unset and are reported by the
+// required flags check as usual.
+func (cmd *Command) promptMissingFlags(ctx context.Context, flags []Flag, persistent bool) {
+	if !cmd.shouldPromptMissing() {
+		return
+	}
+
+	prompter := cmd.prompter()
+	if prompter == nil {
+		prompter = terminalPrompter{}
+	}
+
+	for _, f := range flags {
+		if pf, ok := f.(PersistentFlag); (ok && pf.IsPersistent()) != persistent {
+			continue
+		}
+
+		ok, name := cmd.checkRequiredFlag(f)
+		if ok {
+			continue
+		}
+
+		req := PromptRequest{Command: cmd, Flag: f, Name: name}
+		if sf, ok := f.(SensitiveFlag); ok {
+			req.Sensitive = sf.IsSensitive()
+		}
+		if df, ok := f.(DocGenerationFlag); ok {
+			req.Usage = df.GetUsage()
+		}
+
+		for {
+			value, err := prompter.Prompt(ctx, req)
+			if errors.Is(err, io.EOF) {
+				return
+			} else if err != nil {
+				tracef("unable to prompt for flag %[1]q: %[2]v (cmd=%[3]q)", name, err, cmd.Name)
+				break
+			}
+
+			if value == "" {
+				break
+			}
+
+			if err := cmd.Set(name, value); err != nil {
+				req.Err = fmt.Errorf(tr("invalid value %[1]q for flag %[2]s: %[3]w"), value, name, err)
+				continue
+			}
+
+			break
+		}
+	}
+}
+
+// readPromptLine writes the prompt to the writer and reads a single line of
+// input, with echo disabled for sensitive values
+func (cmd *Command) readPromptLine(w io.Writer, prompt string, sensitive bool) (string, error) {
+	_, _ = fmt.Fprint(w, prompt)
+
+	if cmd.promptReader == nil {
+		cmd.promptReader = bufio.NewReader(cmd.Reader)
+	}
+
+	if sensitive {
+		restore, err := disableReaderEcho(cmd.Reader)
+		if err != nil {
+			_, _ = fmt.Fprintln(w)
+			return "", err
+		}
+
+		defer func() {
+			restore()
+			// the newline entered by the user has not been echoed
+			_, _ = fmt.Fprintln(w)
+		}()
+	}
+
+	line, err := cmd.promptReader.ReadString('\n')
+	if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,9 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package cli
+
+import "errors"
+
+func disableEcho(uintptr) (func(), error) {
+	return nil, errors.New("disabling echo is not supported on this platform")
+}
@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeTerminal(t *testing.T) {
+	orig := isTerminal
+	isTerminal = func(any) bool { return true }
+	t.Cleanup(func() { isTerminal = orig })
+}
+
+func TestPromptMissing(t *testing.T) {
+	fakeTerminal(t)
+
+	var (
+		name  string
+		count int64
+	)
+
+	errOut := &bytes.Buffer{}
+
+	cmd := &Command{
+		Name:          "app",
+		PromptMissing: true,
+		Reader:        strings.NewReader("alice\nabc\n3\n"),
+		ErrWriter:     errOut,
+		Flags: []Flag{
+			&StringFlag{Name: "name", Usage: "your name", Required: true},
+			&IntFlag{Name: "count", Required: true},
+			&StringFlag{Name: "optional"},
+		},
+		Action: func(_ context.Context, cmd *Command) error {
+			name = cmd.String("name")
+			count = cmd.Int("count")
+			return nil
+		},
+	}
+
+	r := require.New(t)
+	r.NoError(cmd.Run(buildTestContext(t), []string{"app"}))
+	r.Equal("alice", name)
+	r.Equal(int64(3), count)
+	r.Equal("name (your name): count: invalid value \"abc\" for flag count: "+
+		"parse error\ncount: ", strings.Replace(errOut.String(), `strconv.ParseInt: parsing "abc": invalid syntax`, "parse error", 1))
+}
+
+func TestPromptMissingSubcommandErrWriter(t *testing.T) {
+	fakeTerminal(t)
+
+	for _, ownErrWriter := range []bool{false, true} {
+		rootOut, subOut := &bytes.Buffer{}, &bytes.Buffer{}
+
+		sub := &Command{
+			Name:          "sub",
+			PromptMissing: true,
+			Flags: []Flag{
+				&StringFlag{Name: "name", Required: true},
+			},
+			Action: func(context.Context, *Command) error { return nil },
+		}
+		if ownErrWriter {
+			sub.ErrWriter = subOut
+		}
+
+		cmd := &Command{
+			Name:      "app",
+			Reader:    strings.NewReader("alice\n"),
+			ErrWriter: rootOut,
+			Commands:  []*Command{sub},
+		}
+
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "sub"}))
+		assert.Equal(t, "alice", sub.String("name"))
+		if ownErrWriter {
+			assert.Equal(t, "name: ", subOut.String())
+			assert.Empty(t, rootOut.String())
+		} else {
+			assert.Equal(t, "name: ", rootOut.String())
+		}
+	}
+}
+
+func TestPromptMissingEmptyAnswer(t *testing.T) {
+	fakeTerminal(t)
+
+	cmd := &Command{
+		Name:          "app",
+		PromptMissing: true,
+		Reader:        strings.NewReader("\n"),
+		ErrWriter:     &bytes.Buffer{},
+		Writer:        &bytes.Buffer{},
+		Flags: []Flag{
+			&StringFlag{Name: "name", Required: true},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	require.EqualError(t, cmd.Run(buildTestContext(t), []string{"app"}), `Required flag "name" not set`)
+}
+
+func TestPromptMissingNonInteractive(t *testing.T) {
+	errOut := &bytes.Buffer{}
+
+	cmd := &Command{
+		Name:          "app",
+		PromptMissing: true,
+		Reader:        strings.NewReader("alice\n"),
+		ErrWriter:     errOut,
+		Writer:        &bytes.Buffer{},
+		Flags: []Flag{
+			&StringFlag{Name: "name", Required: true},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	require.EqualError(t, cmd.Run(buildTestContext(t), []string{"app"}), `Required flag "name" not set`)
+	assert.Empty(t, errOut.String())
+}
+
+func TestPromptMissingSensitive(t *testing.T) {
+	fakeTerminal(t)
+
+	cmd := &Command{
+		Name:          "app",
+		PromptMissing: true,
+		Reader:        strings.NewReader("hunter2\n"),
+		ErrWriter:     &bytes.Buffer{},
+		Writer:        &bytes.Buffer{},
+		Flags: []Flag{
+			&StringFlag{Name: "password", Required: true, Sensitive: true},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	// echo can only be disabled for terminals, the value is never read
+	// from input which would be echoed
+	require.EqualError(t, cmd.Run(buildTestContext(t), []string{"app"}), `Required flag "password" not set`)
+}
+
+func TestPromptMissingPersistent(t *testing.T) {
+	fakeTerminal(t)
+
+	var token string
+
+	cmd := &Command{
+		Name:      "app",
+		Reader:    strings.NewReader("secret\n"),
+		ErrWriter: &bytes.Buffer{},
+		Flags: []Flag{
+			&StringFlag{Name: "token", Required: true, Persistent: true},
+		},
+		Commands: []*Command{
+			{
+				Name:          "login",
+				PromptMissing: true,
+				Action: func(_ context.Context, cmd *Command) error {
+					token = cmd.String("token")
+					return nil
+				},
+			},
+		},
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "login"}))
+	require.Equal(t, "secret", token)
+}
+
+func TestPromptMissingPrompter(t *testing.T) {
+	var requests []PromptRequest
+	answers := []string{"abc", "3", "hunter2"}
+
+	var (
+		count    int64
+		password string
+	)
+
+	cmd := &Command{
+		Name:          "app",
+		PromptMissing: true,
+		Prompter: PrompterFunc(func(_ context.Context, req PromptRequest) (string, error) {
+			requests = append(requests, req)
+			answer := answers[0]
+			answers = answers[1:]
+			return answer, nil
+		}),
+		Commands: []*Command{
+			{
+				Name: "setup",
+				Flags: []Flag{
+					&IntFlag{Name: "count", Usage: "number of workers", Required: true},
+					&StringFlag{Name: "password", Required: true, Sensitive: true},
+				},
+				Action: func(_ context.Context, cmd *Command) error {
+					count = cmd.Int("count")
+					password = cmd.String("password")
+					return nil
+				},
+			},
+		},
+	}
+
+	// the prompter is used although the input is no terminal
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "setup"}))
+	assert.Equal(t, int64(3), count)
+	assert.Equal(t, "hunter2", password)
+
+	require.Len(t, requests, 3)
+	assert.Equal(t, "count", requests[0].Name)
+	assert.Equal(t, "number of workers", requests[0].Usage)
+	assert.Equal(t, "setup", requests[0].Command.Name)
+	assert.NoError(t, requests[0].Err)
+	assert.ErrorContains(t, requests[1].Err, `invalid value "abc" for flag count`)
+	assert.Equal(t, "password", requests[2].Name)
+	assert.True(t, requests[2].Sensitive)
+}
+
+func TestPromptMissingPrompterEOF(t *testing.T) {
+	prompted := 0
+
+	cmd := &Command{
+		Name:          "app",
+		PromptMissing: true,
+		Prompter: PrompterFunc(func(context.Context, PromptRequest) (string, error) {
+			prompted++
+			return "", io.EOF
+		}),
+		Flags: []Flag{
+			&StringFlag{Name: "name", Required: true},
+			&StringFlag{Name: "email", Required: true},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	require.EqualError(t, cmd.Run(buildTestContext(t), []string{"app"}), `Required flags "name, email" not set`)
+	assert.Equal(t, 1, prompted)
+}
@@ -0,0 +1,29 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package cli
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// disableEcho turns off the echo of the terminal with the given file
+// descriptor and returns a function restoring the previous state
+func disableEcho(fd uintptr) (func(), error) {
+	var termios syscall.Termios
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, ioctlGetTermios, uintptr(unsafe.Pointer(&termios))); errno != 0 {
+		return nil, errno
+	}
+
+	previous := termios
+	termios.Lflag &^= syscall.ECHO
+	termios.Lflag |= syscall.ICANON | syscall.ISIG
+
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, ioctlSetTermios, uintptr(unsafe.Pointer(&termios))); errno != 0 {
+		return nil, errno
+	}
+
+	return func() {
+		_, _, _ = syscall.Syscall(syscall.SYS_IOCTL, fd, ioctlSetTermios, uintptr(unsafe.Pointer(&previous)))
+	}, nil
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// redactedValue replaces the values of sensitive flags in invocations
+var redactedValue = json.RawMessage(`"[redacted]"`)
+
+// Invocation is a resolved run of a command which can be serialized to
+// JSON, e.g. to replay it or compare parsing behavior in golden tests
+type Invocation struct {
+	// Args are the arguments the command was run with, including the
+	// program name
+	Args []string `json:"args"`
+	// Env are the environment variables values were read from, excluding
+	// the ones of sensitive flags
+	Env map[string]string `json:"env,omitempty"`
+	// Command is the full name of the command which would be run
+	Command string `json:"command"`
+	// Positional are the arguments remaining after parsing the flags
+	Positional []string `json:"positional"`
+	// Flags are the effective flag values keyed by their primary name
+	Flags map[string]InvocationFlag `json:"flags"`
+}
+
+// InvocationFlag is the recorded value of a flag and where it came from
+type InvocationFlag struct {
+	// Value is the JSON encoded value, redacted for sensitive flags
+	Value json.RawMessage `json:"value"`
+	// Origin describes where the value came from, see ResolvedFlag.Origin
+	Origin string `json:"origin"`
+}
+
+// Record resolves the arguments like Resolve and returns the invocation,
+// without running any hooks or actions. It is meant to be called on the
+// root command.
+func (cmd *Command) Record(ctx context.Context, osArgs []string) (*Invocation, error) {
+	res, err := cmd.Resolve(ctx, osArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	tracef("recording invocation (cmd=%[1]q)", res.Command.Name)
+
+	inv := &Invocation{
+		Args:       append([]string{}, osArgs...),
+		Env:        map[string]string{},
+		Command:    res.Command.FullName(),
+		Positional: res.Args,
+		Flags:      map[string]InvocationFlag{},
+	}
+
+	if inv.Positional == nil {
+		inv.Positional = []string{}
+	}
+
+	for name, rf := range res.Flags {
+		value := redactedValue
+
+		if !rf.Sensitive {
+			v := rf.Value
+			if fv, ok := v.(flag.Value); ok {
+				// adapted values are recorded like given on the command line
+				v = fv.String()
+			}
+
+			if value, err = json.Marshal(v); err != nil {
+				return nil, fmt.Errorf("unable to record value of flag %s: %w", name, err)
+			}
+
+			if ev, ok := rf.Source.(*envVarValueSource); ok {
+				key := strings.TrimSpace(ev.Key)
+				inv.Env[key], _ = cmd.LookupEnv(key)
+			}
+		}
+
+		inv.Flags[name] = InvocationFlag{Value: value, Origin: rf.Origin()}
+	}
+
+	return inv, nil
+}
+
+// Replay runs the command with the recorded arguments, looking up the
+// recorded environment variables before the Env of the command
+func (inv *Invocation) Replay(ctx context.Context, cmd *Command) error {
+	env := cmd.Env
+	cmd.Env = layeredEnv{MapEnv(inv.Env), cmd.environment()}
+
+	defer func() { cmd.Env = env }()
+
+	return cmd.Run(ctx, inv.Args)
+}
+
+// Diff records the recorded arguments again like Replay runs them and
+// returns the differences to the invocation, e.g. after upgrading the
+// package or changing the definition of the command
+func (inv *Invocation) Diff(ctx context.Context, cmd *Command) ([]string, error) {
+	env := cmd.Env
+	cmd.Env = layeredEnv{MapEnv(inv.Env), cmd.environment()}
+
+	got, err := cmd.Record(ctx, inv.Args)
+	cmd.Env = env
+
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []string
+
+	if got.Command != inv.Command {
+		diffs = append(diffs, fmt.Sprintf("command: %q != %q", inv.Command, got.Command))
+	}
+
+	if strings.Join(got.Positional, "\x00") != strings.Join(inv.Positional, "\x00") {
+		diffs = append(diffs, fmt.Sprintf("positional: %q != %q", inv.Positional, got.Positional))
+	}
+
+	names := map[string]struct{}{}
+	for name := range inv.Flags {
+		names[name] = struct{}{}
+	}
+	for name := range got.Flags {
+		names[name] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		want, wantOK := inv.Flags[name]
+		have, haveOK := got.Flags[name]
+
+		switch {
+		case !haveOK:
+			diffs = append(diffs, fmt.Sprintf("flag %s: removed", name))
+		case !wantOK:
+			diffs = append(diffs, fmt.Sprintf("flag %s: added", name))
+		case string(want.Value) != string(have.Value):
+			diffs = append(diffs, fmt.Sprintf("flag %s: value %s != %s", name, want.Value, have.Value))
+		case want.Origin != have.Origin:
+			diffs = append(diffs, fmt.Sprintf("flag %s: origin %s != %s", name, want.Origin, have.Origin))
+		}
+	}
+
+	return diffs, nil
+}
+
+// layeredEnv looks up variables in its environments in order
+type layeredEnv []Environment
+
+func (l layeredEnv) LookupEnv(key string) (string, bool) {
+	for _, env := range l {
+		if v, ok := env.LookupEnv(key); ok {
+			return v, ok
+		}
+	}
+
+	return "", false
+}
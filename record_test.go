@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildRecordTestCommand(port int64, action ActionFunc) *Command {
+	return &Command{
+		Name: "app",
+		Env:  MapEnv{"APP_TOKEN": "secret", "APP_REGION": "eu"},
+		Flags: []Flag{
+			&StringFlag{Name: "region", Sources: EnvVars("APP_REGION")},
+			&StringFlag{Name: "token", Sources: EnvVars("APP_TOKEN"), Sensitive: true},
+		},
+		Commands: []*Command{
+			{
+				Name:   "serve",
+				Action: action,
+				Flags: []Flag{
+					&IntFlag{Name: "port", Value: port},
+					&BoolFlag{Name: "tls"},
+				},
+			},
+		},
+	}
+}
+
+func TestRecord(t *testing.T) {
+	cmd := buildRecordTestCommand(80, nil)
+
+	inv, err := cmd.Record(buildTestContext(t), []string{"app", "serve", "--tls", "public"})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(inv)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"args": ["app", "serve", "--tls", "public"],
+		"env": {"APP_REGION": "eu"},
+		"command": "app serve",
+		"positional": ["public"],
+		"flags": {
+			"port": {"value": 80, "origin": "default"},
+			"region": {"value": "eu", "origin": "environment variable \"APP_REGION\""},
+			"tls": {"value": true, "origin": "command line"},
+			"token": {"value": "[redacted]", "origin": "environment variable \"APP_TOKEN\""}
+		}
+	}`, string(data))
+}
+
+func TestInvocationDiff(t *testing.T) {
+	inv, err := buildRecordTestCommand(80, nil).Record(buildTestContext(t), []string{"app", "serve"})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(inv)
+	require.NoError(t, err)
+
+	var loaded Invocation
+	require.NoError(t, json.Unmarshal(data, &loaded))
+
+	diffs, err := loaded.Diff(buildTestContext(t), buildRecordTestCommand(80, nil))
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+
+	changed := buildRecordTestCommand(8080, nil)
+	changed.Env = nil
+	changed.Commands[0].Flags = changed.Commands[0].Flags[:1]
+
+	diffs, err = loaded.Diff(buildTestContext(t), changed)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"flag port: value 80 != 8080",
+		"flag tls: removed",
+		`flag token: origin environment variable "APP_TOKEN" != default`,
+	}, diffs)
+	assert.Nil(t, changed.Env)
+}
+
+func TestInvocationReplay(t *testing.T) {
+	inv, err := buildRecordTestCommand(80, nil).Record(buildTestContext(t), []string{"app", "serve", "--port", "8080"})
+	require.NoError(t, err)
+
+	var region, token string
+	var port int64
+
+	cmd := buildRecordTestCommand(80, func(_ context.Context, cmd *Command) error {
+		region = cmd.String("region")
+		token = cmd.String("token")
+		port = cmd.Int("port")
+		return nil
+	})
+	cmd.Env = MapEnv{"APP_TOKEN": "other"}
+
+	require.NoError(t, inv.Replay(buildTestContext(t), cmd))
+	assert.Equal(t, "eu", region)
+	assert.Equal(t, "other", token)
+	assert.Equal(t, int64(8080), port)
+	assert.Equal(t, MapEnv{"APP_TOKEN": "other"}, cmd.Env)
+}
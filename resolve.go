@@ -0,0 +1,109 @@
+package cli
+
+import "context"
+
+// Resolution is the effective configuration of a run as determined by
+// Resolve
+type Resolution struct {
+	// Command is the command which would be run
+	Command *Command
+	// Args are the arguments remaining after parsing the flags
+	Args []string
+	// Flags are the flags of the command and its ancestors, keyed by the
+	// primary name of the flag
+	Flags map[string]ResolvedFlag
+}
+
+// ResolvedFlag is the effective value of a flag and where it came from
+type ResolvedFlag struct {
+	// Value is the effective value of the flag
+	Value any
+	// Source is the value source the value was read from, nil if the value
+	// was given on the command line or is the default
+	Source ValueSource
+	// Set is true if the value was given on the command line or read from
+	// a value source
+	Set bool
+	// Sensitive is true if the value must not be shown, e.g. secrets
+	Sensitive bool
+}
+
+// Origin returns a readable representation of where the value came from
+func (rf ResolvedFlag) Origin() string {
+	switch {
+	case rf.Source != nil:
+		return rf.Source.String()
+	case rf.Set:
+		return "command line"
+	default:
+		return "default"
+	}
+}
+
+// sourcedFlag is implemented by flags which record the value source their
+// value was read from
+type sourcedFlag interface {
+	valueSource() ValueSource
+}
+
+// valueSource returns the value source the value was read from, nil if not
+// set or set on the command line
+func (f *FlagBase[T, C, V]) valueSource() ValueSource {
+	return f.source
+}
+
+// Resolve parses the arguments like Parse, applying value sources and
+// defaults, and returns the effective configuration of the command which
+// would be run, e.g. for verifying configurations in tests or explaining
+// where a value came from. No hooks or actions are run. It is meant to be
+// called on the root command.
+func (cmd *Command) Resolve(ctx context.Context, osArgs []string) (*Resolution, error) {
+	invoked, err := cmd.Parse(ctx, osArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	tracef("resolving effective configuration (cmd=%[1]q)", invoked.Name)
+
+	res := &Resolution{
+		Command: invoked,
+		Args:    invoked.Args().Slice(),
+		Flags:   map[string]ResolvedFlag{},
+	}
+
+	for _, pCmd := range invoked.Lineage() {
+		for _, fl := range pCmd.Flags {
+			names := fl.Names()
+			if len(names) == 0 || isBuiltinFlag(fl) || fl == GenerateShellCompletionFlag {
+				continue
+			}
+
+			// flags of subcommands take precedence over the ones of
+			// their ancestors
+			if _, ok := res.Flags[names[0]]; ok {
+				continue
+			}
+
+			rf := ResolvedFlag{
+				Value: invoked.Value(names[0]),
+				Set:   invoked.lookupSetFlag(names) != nil,
+			}
+
+			if !rf.Set && fl.IsSet() {
+				rf.Set = true
+
+				if sf, ok := fl.(sourcedFlag); ok {
+					rf.Source = sf.valueSource()
+				}
+			}
+
+			if sf, ok := fl.(SensitiveFlag); ok {
+				rf.Sensitive = sf.IsSensitive()
+			}
+
+			res.Flags[names[0]] = rf
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	t.Setenv("APP_TIMEOUT", "1m")
+
+	var called bool
+	action := func(context.Context, *Command) error {
+		called = true
+		return nil
+	}
+
+	cmd := &Command{
+		Name:   "app",
+		Before: action,
+		Action: action,
+		Flags: []Flag{
+			&StringFlag{Name: "name", Aliases: []string{"n"}, Value: "world"},
+			&DurationFlag{Name: "timeout", Sources: EnvVars("APP_TIMEOUT")},
+			&StringFlag{Name: "token", Sensitive: true},
+		},
+		Commands: []*Command{
+			{
+				Name:   "serve",
+				Action: action,
+				Flags: []Flag{
+					&IntFlag{Name: "port", Value: 80},
+				},
+			},
+		},
+	}
+
+	res, err := cmd.Resolve(buildTestContext(t), []string{"app", "-n", "gopher", "serve", "--port", "8080", "extra"})
+	require.NoError(t, err)
+	assert.False(t, called)
+	assert.Equal(t, "serve", res.Command.Name)
+	assert.Equal(t, []string{"extra"}, res.Args)
+
+	require.Len(t, res.Flags, 4)
+
+	assert.Equal(t, "gopher", res.Flags["name"].Value)
+	assert.Equal(t, "command line", res.Flags["name"].Origin())
+
+	assert.Equal(t, time.Minute, res.Flags["timeout"].Value)
+	assert.True(t, res.Flags["timeout"].Set)
+	assert.Equal(t, `environment variable "APP_TIMEOUT"`, res.Flags["timeout"].Origin())
+
+	assert.Equal(t, "", res.Flags["token"].Value)
+	assert.True(t, res.Flags["token"].Sensitive)
+	assert.Equal(t, "default", res.Flags["token"].Origin())
+
+	assert.Equal(t, int64(8080), res.Flags["port"].Value)
+	assert.Equal(t, "command line", res.Flags["port"].Origin())
+}
+
+func TestResolveError(t *testing.T) {
+	cmd := &Command{
+		Name:  "app",
+		Flags: []Flag{&IntFlag{Name: "port"}},
+	}
+
+	res, err := cmd.Resolve(buildTestContext(t), []string{"app", "--port", "http"})
+	require.ErrorContains(t, err, `invalid value "http" for flag -port`)
+	assert.Nil(t, res)
+}
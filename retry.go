@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts    = 3
+	defaultRetryInitialBackoff = 100 * time.Millisecond
+	defaultRetryMultiplier     = 2
+)
+
+// RetryConfig configures the behavior of Retry
+type RetryConfig struct {
+	// MaxAttempts is the maximum number of times the action is run,
+	// defaults to 3
+	MaxAttempts int
+	// InitialBackoff is the time to wait before the first retry,
+	// defaults to 100ms
+	InitialBackoff time.Duration
+	// MaxBackoff caps the time to wait between retries, unlimited if zero
+	MaxBackoff time.Duration
+	// Multiplier is applied to the backoff after every retry, defaults to 2
+	Multiplier float64
+	// Retryable reports whether an error is transient and the action should
+	// be retried. If not set every error is retried.
+	Retryable func(error) bool
+	// OnRetry is called before waiting for the next attempt with the number
+	// of the failed attempt, its error and the time to wait
+	OnRetry func(ctx context.Context, cmd *Command, attempt int, err error, backoff time.Duration)
+}
+
+// Retry wraps the given action so that it is run again with an exponential
+// backoff as long as it returns an error classified as retryable and the
+// maximum number of attempts has not been reached. Waiting is aborted when
+// the context is done.
+func Retry(cfg RetryConfig, action ActionFunc) ActionFunc {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultRetryMaxAttempts
+	}
+
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = defaultRetryInitialBackoff
+	}
+
+	if cfg.Multiplier < 1 {
+		cfg.Multiplier = defaultRetryMultiplier
+	}
+
+	return func(ctx context.Context, cmd *Command) error {
+		backoff := cfg.InitialBackoff
+
+		for attempt := 1; ; attempt++ {
+			err := action(ctx, cmd)
+			if err == nil {
+				return nil
+			}
+
+			if attempt >= cfg.MaxAttempts || (cfg.Retryable != nil && !cfg.Retryable(err)) {
+				return err
+			}
+
+			tracef("retrying after attempt %[1]d failed with %[2]v (cmd=%[3]q)", attempt, err, cmd.Name)
+
+			if cfg.OnRetry != nil {
+				cfg.OnRetry(ctx, cmd, attempt, err, backoff)
+			}
+
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+
+			backoff = time.Duration(float64(backoff) * cfg.Multiplier)
+			if cfg.MaxBackoff > 0 && backoff > cfg.MaxBackoff {
+				backoff = cfg.MaxBackoff
+			}
+		}
+	}
+}
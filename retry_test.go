@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errTransient = errors.New("transient")
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name             string
+		failures         int
+		cfg              RetryConfig
+		expectedErr      error
+		expectedAttempts int
+	}{
+		{
+			name:             "succeeds first time",
+			cfg:              RetryConfig{InitialBackoff: time.Millisecond},
+			expectedAttempts: 1,
+		},
+		{
+			name:             "succeeds after retries",
+			failures:         2,
+			cfg:              RetryConfig{InitialBackoff: time.Millisecond},
+			expectedAttempts: 3,
+		},
+		{
+			name:             "gives up after max attempts",
+			failures:         10,
+			cfg:              RetryConfig{MaxAttempts: 4, InitialBackoff: time.Millisecond},
+			expectedErr:      errTransient,
+			expectedAttempts: 4,
+		},
+		{
+			name:     "does not retry permanent errors",
+			failures: 10,
+			cfg: RetryConfig{
+				InitialBackoff: time.Millisecond,
+				Retryable:      func(err error) bool { return !errors.Is(err, errTransient) },
+			},
+			expectedErr:      errTransient,
+			expectedAttempts: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attempts := 0
+
+			action := Retry(test.cfg, func(context.Context, *Command) error {
+				attempts++
+				if attempts <= test.failures {
+					return errTransient
+				}
+				return nil
+			})
+
+			err := action(context.Background(), &Command{})
+			require.Equal(t, test.expectedErr, err)
+			require.Equal(t, test.expectedAttempts, attempts)
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	var backoffs []time.Duration
+
+	action := Retry(RetryConfig{
+		MaxAttempts:    5,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     3 * time.Millisecond,
+		OnRetry: func(_ context.Context, _ *Command, _ int, _ error, backoff time.Duration) {
+			backoffs = append(backoffs, backoff)
+		},
+	}, func(context.Context, *Command) error {
+		return errTransient
+	})
+
+	require.ErrorIs(t, action(context.Background(), &Command{}), errTransient)
+	require.Equal(t, []time.Duration{
+		time.Millisecond,
+		2 * time.Millisecond,
+		3 * time.Millisecond,
+		3 * time.Millisecond,
+	}, backoffs)
+}
+
+func TestRetryContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+
+	action := Retry(RetryConfig{InitialBackoff: time.Hour}, func(context.Context, *Command) error {
+		attempts++
+		cancel()
+		return errTransient
+	})
+
+	require.ErrorIs(t, action(ctx, &Command{}), errTransient)
+	require.Equal(t, 1, attempts)
+}
+
+func TestRetryCommand(t *testing.T) {
+	attempts := 0
+
+	cmd := &Command{
+		Name: "fetch",
+		Action: Retry(RetryConfig{InitialBackoff: time.Millisecond}, func(context.Context, *Command) error {
+			attempts++
+			if attempts < 2 {
+				return errTransient
+			}
+			return nil
+		}),
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"fetch"}))
+	require.Equal(t, 2, attempts)
+}
@@ -0,0 +1,223 @@
+//go:build !urfave_cli_no_docs
+
+package cli
+
+import (
+	"io"
+	"strings"
+	"text/template"
+)
+
+// ReStructuredTextDocTemplate is the template used by ToReStructuredText.
+// The template is executed for the root command, the "command" template for
+// every visible command in turn. The commands provide Title, Level, Usage,
+// UsageText, Description, Version, Aliases, Arguments, Flags and Commands,
+// the arguments Name, Usage, Description, Type, Min and Max and the flags
+// Names, Usage, Choices, Default, EnvVars, Requires and Conflicts.
+var ReStructuredTextDocTemplate = `{{define "command"}}{{heading .Title .Level}}
+{{if .Usage}}
+{{escape .Usage}}
+{{end}}{{if .Version}}
+:Version: {{escape .Version}}
+{{end}}{{if .Aliases}}
+:Aliases: {{literals .Aliases}}
+{{end}}{{if .Description}}
+{{escape .Description}}
+{{end}}
+.. program:: {{.Title}}
+
+.. code-block:: text
+
+{{indent .UsageText 3}}
+{{range .Arguments}}
+.. describe:: {{.Usage}}
+{{if .Description}}
+   {{escape .Description}}
+{{end}}{{end}}{{range .Flags}}
+.. option:: {{.Names}}
+{{if .Usage}}
+   {{escape .Usage}}
+{{end}}{{if or .Choices .Default .EnvVars .Requires .Conflicts}}
+{{if .Choices}}   :Choices: {{literals .Choices}}
+{{end}}{{if .Default}}   :Default: {{literals .Default}}
+{{end}}{{if .EnvVars}}   :Environment: {{literals .EnvVars}}
+{{end}}{{if .Requires}}   :Requires: {{literals .Requires}}
+{{end}}{{if .Conflicts}}   :Conflicts with: {{literals .Conflicts}}
+{{end}}{{end}}{{end}}{{range .Commands}}
+{{template "command" .}}{{end}}{{end}}{{template "command" .}}`
+
+// rstCommand is the data of a command in ReStructuredTextDocTemplate
+type rstCommand struct {
+	Title       string
+	Level       int
+	Usage       string
+	UsageText   string
+	Description string
+	Version     string
+	Aliases     []string
+	Arguments   []docsArgument
+	Flags       []rstFlag
+	Commands    []rstCommand
+}
+
+// rstFlag is the data of a flag in ReStructuredTextDocTemplate
+type rstFlag struct {
+	Names     string
+	Usage     string
+	Choices   []string
+	Default   string
+	EnvVars   []string
+	Requires  []string
+	Conflicts []string
+}
+
+// rstHeadingChars are the characters underlining the section titles of the
+// nesting levels
+const rstHeadingChars = "=-~^\"'"
+
+// ToReStructuredText renders the command tree as a single reStructuredText
+// document using ReStructuredTextDocTemplate, e.g. to be included in Sphinx
+// documentation. Every visible command becomes a section nested in the
+// section of its parent, documenting its flags as option directives.
+func (cmd *Command) ToReStructuredText() (string, error) {
+	var b strings.Builder
+	if err := cmd.ToReStructuredTextToWriter(&b); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// ToReStructuredTextToWriter writes the document of ToReStructuredText to
+// the writer while rendering it, so large command trees are streamed
+// without building the whole document in memory.
+func (cmd *Command) ToReStructuredTextToWriter(w io.Writer) error {
+	cmd.loadCommands()
+
+	funcMap := template.FuncMap{
+		"heading":  rstHeading,
+		"escape":   rstEscape,
+		"literals": rstLiterals,
+		"indent":   rstIndent,
+	}
+	for name, fn := range cmd.lineageTemplateFuncs() {
+		funcMap[name] = fn
+	}
+
+	t, err := template.New("rst").Funcs(funcMap).Parse(ReStructuredTextDocTemplate)
+	if err != nil {
+		return err
+	}
+
+	return t.Execute(w, newRSTCommand(newDocsPage(cmd, nil, 1)))
+}
+
+func newRSTCommand(p *docsPage) rstCommand {
+	cmd := p.cmd
+	c := rstCommand{
+		Title:       p.title(),
+		Level:       len(p.names) - 1,
+		Usage:       cmd.Usage,
+		UsageText:   p.usageText(),
+		Description: strings.TrimSpace(cmd.Description),
+		Aliases:     cmd.Aliases,
+		Arguments:   docsArguments(cmd),
+	}
+
+	if len(p.names) == 1 {
+		c.Version = cmd.Version
+	}
+
+	for _, fl := range cmd.VisibleFlags() {
+		c.Flags = append(c.Flags, newRSTFlag(fl))
+	}
+
+	for _, child := range p.children {
+		c.Commands = append(c.Commands, newRSTCommand(child))
+	}
+
+	return c
+}
+
+func newRSTFlag(fl Flag) rstFlag {
+	df, ok := fl.(DocGenerationFlag)
+	if !ok {
+		return rstFlag{Names: prefixedNames(fl.Names(), "")}
+	}
+
+	placeholder, usage := unquoteUsage(df.GetUsage())
+	if df.TakesValue() && placeholder == "" {
+		placeholder = defaultPlaceholder
+	}
+	if !df.TakesValue() {
+		placeholder = ""
+	}
+
+	f := rstFlag{
+		Names:   prefixedNames(flagDocNames(fl), placeholder),
+		Usage:   usage,
+		Default: df.GetDefaultText(),
+		EnvVars: df.GetEnvVars(),
+	}
+	if cf, ok := fl.(ChoicesFlag); ok {
+		f.Choices = cf.GetChoices()
+	}
+	if rf, ok := fl.(RelatedFlag); ok {
+		for _, name := range rf.RequiresFlags() {
+			f.Requires = append(f.Requires, prefixFor(name)+name)
+		}
+		for _, name := range rf.ConflictingFlags() {
+			f.Conflicts = append(f.Conflicts, prefixFor(name)+name)
+		}
+	}
+	return f
+}
+
+// rstHeading renders the title as section title of the given level
+func rstHeading(title string, level int) string {
+	if level >= len(rstHeadingChars) {
+		level = len(rstHeadingChars) - 1
+	}
+	return title + "\n" + strings.Repeat(rstHeadingChars[level:level+1], len(title))
+}
+
+var rstEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"*", `\*`,
+	"`", "\\`",
+	"_", `\_`,
+	"|", `\|`,
+)
+
+// rstEscape escapes the characters starting inline markup
+func rstEscape(s string) string {
+	return rstEscaper.Replace(s)
+}
+
+// rstLiterals renders a string or a list of strings as inline literals
+func rstLiterals(v any) string {
+	var items []string
+	switch v := v.(type) {
+	case string:
+		items = []string{v}
+	case []string:
+		items = append([]string{}, v...)
+	}
+
+	for i, item := range items {
+		items[i] = "``" + item + "``"
+	}
+	return strings.Join(items, ", ")
+}
+
+// rstIndent indents all non-empty lines of the string by the given number
+// of spaces
+func rstIndent(s string, spaces int) string {
+	padding := strings.Repeat(" ", spaces)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = padding + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,78 @@
+//go:build !urfave_cli_no_docs
+
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToReStructuredText(t *testing.T) {
+	cmd := buildExtendedTestCommand()
+
+	res, err := cmd.ToReStructuredText()
+
+	require.NoError(t, err)
+	expectFileContent(t, "testdata/expected-doc-full.rst", res)
+}
+
+func TestToReStructuredTextEscaping(t *testing.T) {
+	cmd := &Command{
+		Name:    "app",
+		Usage:   "manage *all* the `things`",
+		Version: "v1.0.0",
+		Flags: []Flag{
+			&StringFlag{Name: "config_file", Usage: "load the `FILE` | more"},
+		},
+	}
+
+	res, err := cmd.ToReStructuredText()
+	require.NoError(t, err)
+
+	assert.Equal(t, `app
+===
+
+manage \*all\* the \`+"`"+`things\`+"`"+`
+
+:Version: v1.0.0
+
+.. program:: app
+
+.. code-block:: text
+
+   app [options]
+
+.. option:: --config_file FILE
+
+   load the FILE \| more
+`, res)
+}
+
+func TestToReStructuredTextTemplate(t *testing.T) {
+	defer func(old string) { ReStructuredTextDocTemplate = old }(ReStructuredTextDocTemplate)
+	ReStructuredTextDocTemplate = `{{define "command"}}{{.Title}}:{{.Level}}{{range .Commands}} {{template "command" .}}{{end}}{{end}}{{template "command" .}}`
+
+	res, err := buildExtendedTestCommand().ToReStructuredText()
+	require.NoError(t, err)
+	assert.Equal(t, "greet:0 greet config:1 greet config sub-config:2 greet info:1 greet some-command:1 greet usage:1 greet usage sub-usage:2", res)
+
+	ReStructuredTextDocTemplate = `{{.Missing`
+	_, err = buildExtendedTestCommand().ToReStructuredText()
+	assert.Error(t, err)
+}
+
+func TestToReStructuredTextTemplateFuncs(t *testing.T) {
+	defer func(old string) { ReStructuredTextDocTemplate = old }(ReStructuredTextDocTemplate)
+	ReStructuredTextDocTemplate = `{{upper .Title}} {{escape "*"}}`
+
+	cmd := buildExtendedTestCommand()
+	cmd.AddTemplateFunc("upper", strings.ToUpper)
+	cmd.AddTemplateFunc("escape", func(s string) string { return s + s })
+
+	res, err := cmd.ToReStructuredText()
+	require.NoError(t, err)
+	assert.Equal(t, "GREET **", res)
+}
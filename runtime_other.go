@@ -0,0 +1,13 @@
+//go:build !(js || wasip1)
+
+package cli
+
+const (
+	// runtimeCanExec reports whether the runtime can start child processes
+	runtimeCanExec = true
+	// runtimeCanSignal reports whether the runtime delivers signals
+	runtimeCanSignal = true
+	// runtimeHasTerminal reports whether the standard streams may be
+	// terminals
+	runtimeHasTerminal = true
+)
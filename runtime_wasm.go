@@ -0,0 +1,18 @@
+//go:build js || wasip1
+
+package cli
+
+// The capabilities of WebAssembly runtimes like browser terminals, which
+// can neither start child processes nor deliver signals and whose standard
+// streams are no terminals. Such embedders should set the Terminal and the
+// Exiter of the root command or use RunForTest, since exiting stops the
+// WebAssembly instance. Instance locks fail, as files cannot be locked.
+const (
+	// runtimeCanExec reports whether the runtime can start child processes
+	runtimeCanExec = false
+	// runtimeCanSignal reports whether the runtime delivers signals
+	runtimeCanSignal = false
+	// runtimeHasTerminal reports whether the standard streams may be
+	// terminals
+	runtimeHasTerminal = false
+)
@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const defaultShellPrompt = "> "
+
+// LineReader reads a single line of input for the interactive shell started
+// by RunShell. It can be backed by a line editing library to provide line
+// editing, persistent history and tab completion via ShellCompletions.
+type LineReader interface {
+	// ReadLine displays the prompt and returns the next line without the
+	// trailing newline, or io.EOF when there is no more input
+	ReadLine(prompt string) (string, error)
+}
+
+// scannerLineReader is the default LineReader reading lines from the
+// Reader of the command and writing prompts to its Writer
+type scannerLineReader struct {
+	scanner *bufio.Scanner
+	w       io.Writer
+}
+
+func (r *scannerLineReader) ReadLine(prompt string) (string, error) {
+	_, _ = fmt.Fprint(r.w, prompt)
+
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+
+	return r.scanner.Text(), nil
+}
+
+// RunShell starts an interactive shell in which every line read is run as
+// an invocation of this command, e.g. the line "db migrate --steps 2"
+// behaves like running the binary with those arguments. Errors are printed
+// and the shell continues with the next line instead of exiting.
+//
+// The built-in "exit" and "quit" commands end the shell, "history" lists
+// the lines entered so far. The shell also ends when the input is exhausted
+// or the context is done.
+func (cmd *Command) RunShell(ctx context.Context) error {
+	cmd.setupDefaults([]string{cmd.Name})
+
+	lr := cmd.ShellLineReader
+	if lr == nil {
+		lr = &scannerLineReader{scanner: bufio.NewScanner(cmd.Reader), w: cmd.Writer}
+	}
+
+	prompt := cmd.ShellPrompt
+	if prompt == "" {
+		prompt = cmd.Name + defaultShellPrompt
+	}
+
+	exitErrHandler := cmd.ExitErrHandler
+	cmd.ExitErrHandler = func(context.Context, *Command, error) {}
+	defer func() { cmd.ExitErrHandler = exitErrHandler }()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		line, err := lr.ReadLine(prompt)
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		args, err := splitShellWords(line)
+		if err != nil {
+			_, _ = fmt.Fprintln(cmd.errWriter(), err)
+			continue
+		}
+
+		if len(args) == 0 {
+			continue
+		}
+
+		cmd.shellHistory = append(cmd.shellHistory, line)
+
+		switch args[0] {
+		case "exit", "quit":
+			return nil
+		case "history":
+			for i, l := range cmd.shellHistory {
+				_, _ = fmt.Fprintf(cmd.Writer, "%5d  %s\n", i+1, l)
+			}
+			continue
+		}
+
+		tracef("running shell line %[1]q (cmd=%[2]q)", args, cmd.Name)
+
+		if err := cmd.Run(ctx, append([]string{cmd.Name}, args...)); err != nil {
+			_, _ = fmt.Fprintln(cmd.errWriter(), err)
+		}
+	}
+}
+
+// ShellCompletions returns the candidates completing the last word of the
+// given line, which are either the names of the subcommands or, if the last
+// word starts with a dash, the flags of the command addressed by the line.
+// It is meant to be plugged into the completion of a LineReader.
+func (cmd *Command) ShellCompletions(line string) []string {
+	words, err := splitShellWords(line)
+	if err != nil {
+		return nil
+	}
+
+	partial := ""
+	if len(words) > 0 && !strings.HasSuffix(line, " ") {
+		partial = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	current := cmd
+	for _, word := range words {
+		if sub := current.Command(word); sub != nil {
+			current = sub
+		}
+	}
+
+	var candidates []string
+
+	if strings.HasPrefix(partial, "-") {
+		for _, fl := range current.VisibleFlags() {
+			for _, name := range fl.Names() {
+				if candidate := prefixFor(name) + name; strings.HasPrefix(candidate, partial) {
+					candidates = append(candidates, candidate)
+				}
+			}
+		}
+		return candidates
+	}
+
+	for _, sub := range current.VisibleCommands() {
+		if strings.HasPrefix(sub.Name, partial) {
+			candidates = append(candidates, sub.Name)
+		}
+	}
+
+	return candidates
+}
+
+// splitShellWords splits a line into words honoring single and double
+// quotes as well as backslash escapes
+func splitShellWords(line string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote %q", quote)
+	}
+
+	if escaped {
+		return nil, errors.New("unterminated escape")
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
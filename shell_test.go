@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunShell(t *testing.T) {
+	var greeted []string
+
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+
+	cmd := &Command{
+		Name:      "app",
+		Reader:    strings.NewReader("greet --name \"big world\"\n\ngreet\nfail\nhistory\nexit\ngreet --name never\n"),
+		Writer:    out,
+		ErrWriter: errOut,
+		Commands: []*Command{
+			{
+				Name: "greet",
+				Flags: []Flag{
+					&StringFlag{Name: "name", Value: "world"},
+				},
+				Action: func(_ context.Context, cmd *Command) error {
+					greeted = append(greeted, cmd.String("name"))
+					return nil
+				},
+			},
+			{
+				Name: "fail",
+				Action: func(context.Context, *Command) error {
+					return Exit("boom", 3)
+				},
+			},
+		},
+	}
+
+	r := require.New(t)
+	r.NoError(cmd.RunShell(buildTestContext(t)))
+	r.Equal([]string{"big world", "world"}, greeted)
+	r.Contains(out.String(), "app> ")
+	r.Contains(out.String(), "    1  greet --name \"big world\"\n    2  greet\n    3  fail\n")
+	r.Contains(errOut.String(), "boom")
+	r.Nil(cmd.ExitErrHandler)
+}
+
+func TestRunShellEOF(t *testing.T) {
+	cmd := &Command{
+		Name:        "app",
+		ShellPrompt: "$ ",
+		Reader:      strings.NewReader("help"),
+		Writer:      &bytes.Buffer{},
+	}
+
+	require.NoError(t, cmd.RunShell(buildTestContext(t)))
+	assert.Contains(t, cmd.Writer.(*bytes.Buffer).String(), "$ ")
+}
+
+type fakeLineReader struct {
+	lines []string
+}
+
+func (r *fakeLineReader) ReadLine(string) (string, error) {
+	if len(r.lines) == 0 {
+		return "", errors.New("closed")
+	}
+
+	line := r.lines[0]
+	r.lines = r.lines[1:]
+
+	return line, nil
+}
+
+func TestRunShellLineReader(t *testing.T) {
+	ran := 0
+
+	cmd := &Command{
+		Name:            "app",
+		ShellLineReader: &fakeLineReader{lines: []string{"", "", ""}},
+		Action: func(context.Context, *Command) error {
+			ran++
+			return nil
+		},
+	}
+
+	require.EqualError(t, cmd.RunShell(buildTestContext(t)), "closed")
+	require.Equal(t, 0, ran)
+}
+
+func TestShellCompletions(t *testing.T) {
+	cmd := &Command{
+		Name: "app",
+		Commands: []*Command{
+			{
+				Name: "db",
+				Commands: []*Command{
+					{
+						Name: "migrate",
+						Flags: []Flag{
+							&IntFlag{Name: "steps"},
+							&BoolFlag{Name: "dry", Aliases: []string{"d"}},
+						},
+					},
+					{Name: "seed"},
+				},
+			},
+			{Name: "debug"},
+			{Name: "secret", Hidden: true},
+		},
+	}
+	cmd.setupDefaults([]string{"app"})
+
+	tests := []struct {
+		line     string
+		expected []string
+	}{
+		{line: "d", expected: []string{"db", "debug"}},
+		{line: "s", expected: nil},
+		{line: "db ", expected: []string{"migrate", "seed"}},
+		{line: "db m", expected: []string{"migrate"}},
+		{line: "db migrate --s", expected: []string{"--steps"}},
+		{line: "db migrate -", expected: []string{"--steps", "--dry", "-d"}},
+		{line: "\"db", expected: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			assert.Equal(t, test.expected, cmd.ShellCompletions(test.line))
+		})
+	}
+}
+
+func TestSplitShellWords(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected []string
+		err      string
+	}{
+		{line: "", expected: nil},
+		{line: "  a  b\tc ", expected: []string{"a", "b", "c"}},
+		{line: `a "b c" 'd e'`, expected: []string{"a", "b c", "d e"}},
+		{line: `a\ b "c\"d" 'e\f'`, expected: []string{"a b", `c"d`, `e\f`}},
+		{line: `""`, expected: []string{""}},
+		{line: `"abc`, err: "unterminated quote '\"'"},
+		{line: `abc\`, err: "unterminated escape"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			words, err := splitShellWords(test.line)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, words)
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const signalContextKey = contextKey("cli.signal")
+
+// signalContext records the signal which cancelled it
+type signalContext struct {
+	context.Context
+
+	mu  sync.Mutex
+	sig os.Signal
+}
+
+func (c *signalContext) Value(key any) any {
+	if key == signalContextKey {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		return c.sig
+	}
+
+	return c.Context.Value(key)
+}
+
+// NotifyContext returns a copy of the parent context which is cancelled when
+// one of the given signals arrives, os.Interrupt and SIGTERM by default. In
+// contrast to signal.NotifyContext the received signal is recorded, which
+// allows the command to exit with the status given by its CancelExitCode.
+// Runtimes without signals like WebAssembly never cancel it.
+func NotifyContext(parent context.Context, signals ...os.Signal) (context.Context, context.CancelFunc) {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	c := &signalContext{Context: ctx}
+
+	if !runtimeCanSignal {
+		tracef("not handling signals unsupported by the runtime")
+		return c, cancel
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+
+	go func() {
+		select {
+		case sig := <-ch:
+			tracef("received signal %[1]v", sig)
+
+			c.mu.Lock()
+			c.sig = sig
+			c.mu.Unlock()
+
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return c, func() {
+		cancel()
+		signal.Stop(ch)
+	}
+}
+
+// SignalFromContext returns the signal which cancelled a context created by
+// NotifyContext, or nil if it has not been cancelled by a signal
+func SignalFromContext(ctx context.Context) os.Signal {
+	sig, _ := ctx.Value(signalContextKey).(os.Signal)
+	return sig
+}
+
+// DefaultCancelExitCode returns the exit status shells use for processes
+// terminated by the signal, i.e. 128 plus the signal number such as 130 for
+// SIGINT.
+func DefaultCancelExitCode(_ context.Context, _ *Command, sig os.Signal) int {
+	if n, ok := signalNumber(sig); ok {
+		return 128 + n
+	}
+
+	return 1
+}
+
+// applyCancelExitCode wraps the error with the exit code for the signal
+// which cancelled the run, if any. Errors which already carry an exit code
+// are returned unchanged.
+func (cmd *Command) applyCancelExitCode(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+
+	if _, ok := err.(ExitCoder); ok {
+		return err
+	}
+
+	sig := SignalFromContext(ctx)
+	if sig == nil {
+		return err
+	}
+
+	exitCode := cmd.CancelExitCode
+	if exitCode == nil {
+		exitCode = DefaultCancelExitCode
+	}
+
+	code := exitCode(ctx, cmd, sig)
+	if code == 0 {
+		return err
+	}
+
+	tracef("mapped error %[1]q to exit code %[2]d for signal %[3]v (cmd=%[4]q)", err, code, sig, cmd.Name)
+
+	return Exit(err, code)
+}
+
+// pendingAfter is an After function of the current run which has not been
+// run yet, along with the context of its command
+type pendingAfter struct {
+	ctx context.Context
+	cmd *Command
+}
+
+// handleSignals returns a copy of the context which is cancelled by one of
+// the HandleSignals and forces the shutdown if the run does not return
+// within the ShutdownTimeout. The returned function stops the handling once
+// the run has returned.
+func (cmd *Command) handleSignals(ctx context.Context) (context.Context, func()) {
+	tracef("handling signals %[1]v (cmd=%[2]q)", cmd.HandleSignals, cmd.Name)
+
+	ctx, stop := NotifyContext(ctx, cmd.HandleSignals...)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+			return
+		}
+
+		if cmd.ShutdownTimeout <= 0 || SignalFromContext(ctx) == nil {
+			return
+		}
+
+		timer := time.NewTimer(cmd.ShutdownTimeout)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			cmd.forceShutdown(ctx)
+		case <-done:
+		}
+	}()
+
+	return ctx, func() {
+		close(done)
+		stop()
+	}
+}
+
+// forceShutdown runs the pending After functions and exits with the exit
+// code for the signal which cancelled the run
+func (cmd *Command) forceShutdown(ctx context.Context) {
+	sig := SignalFromContext(ctx)
+
+	tracef("forcing shutdown after %[1]v (cmd=%[2]q)", cmd.ShutdownTimeout, cmd.Name)
+
+	cmd.shutdownMu.Lock()
+	cmd.shutdownForced = true
+	pending := cmd.pendingAfter
+	cmd.pendingAfter = nil
+	cmd.shutdownMu.Unlock()
+
+	exitCode := cmd.CancelExitCode
+	if exitCode == nil {
+		exitCode = DefaultCancelExitCode
+	}
+	code := exitCode(ctx, cmd, sig)
+	if code == 0 {
+		code = 1
+	}
+
+	var err error = Exit(fmt.Sprintf("shutdown timed out after %v on signal %v", cmd.ShutdownTimeout, sig), code)
+	for i := len(pending) - 1; i >= 0; i-- {
+		p := pending[i]
+		if afterErr := p.cmd.After(p.ctx, p.cmd); afterErr != nil {
+			err = newMultiError(err, afterErr)
+		}
+	}
+
+	_ = cmd.exitWithError(ctx, cmd, err)
+}
+
+// pushAfter records the After function of the command as pending
+func (cmd *Command) pushAfter(ctx context.Context, aCmd *Command) {
+	cmd.shutdownMu.Lock()
+	defer cmd.shutdownMu.Unlock()
+
+	cmd.pendingAfter = append(cmd.pendingAfter, pendingAfter{ctx: ctx, cmd: aCmd})
+}
+
+// popAfter removes the After function pushed last, reporting false if it
+// has been run by a forced shutdown already
+func (cmd *Command) popAfter() bool {
+	cmd.shutdownMu.Lock()
+	defer cmd.shutdownMu.Unlock()
+
+	if cmd.shutdownForced {
+		return false
+	}
+
+	cmd.pendingAfter = cmd.pendingAfter[:len(cmd.pendingAfter)-1]
+	return true
+}
+
+// resetShutdown resets the state of the forced shutdown for a new run
+func (cmd *Command) resetShutdown() {
+	cmd.shutdownMu.Lock()
+	defer cmd.shutdownMu.Unlock()
+
+	cmd.pendingAfter = nil
+	cmd.shutdownForced = false
+}
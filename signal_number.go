@@ -0,0 +1,17 @@
+//go:build !plan9
+
+package cli
+
+import (
+	"os"
+	"syscall"
+)
+
+// signalNumber returns the number of the signal
+func signalNumber(sig os.Signal) (int, bool) {
+	if s, ok := sig.(syscall.Signal); ok {
+		return int(s), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,18 @@
+//go:build plan9
+
+package cli
+
+import "os"
+
+// signalNumber returns the number the signal has on other systems, as
+// notes on Plan 9 are strings. Only interrupts and kills are numbered.
+func signalNumber(sig os.Signal) (int, bool) {
+	switch sig {
+	case os.Interrupt:
+		return 2, true
+	case os.Kill:
+		return 9, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifyContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sending signals to the own process is not supported")
+	}
+
+	ctx, cancel := NotifyContext(context.Background(), syscall.SIGTERM)
+	defer cancel()
+
+	p, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, p.Signal(syscall.SIGTERM))
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("context was not cancelled by signal")
+	}
+
+	assert.Equal(t, syscall.SIGTERM, SignalFromContext(ctx))
+}
+
+func TestSignalFromContextNotCancelled(t *testing.T) {
+	ctx, cancel := NotifyContext(context.Background())
+	cancel()
+
+	assert.Nil(t, SignalFromContext(ctx))
+	assert.Nil(t, SignalFromContext(context.Background()))
+}
+
+func TestCancelExitCode(t *testing.T) {
+	signalled := func(sig os.Signal) context.Context {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		return &signalContext{Context: ctx, sig: sig}
+	}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		err      error
+		override CancelExitCodeFunc
+		expected int
+	}{
+		{
+			name:     "interrupt",
+			ctx:      signalled(syscall.SIGINT),
+			err:      context.Canceled,
+			expected: 130,
+		},
+		{
+			name:     "terminate",
+			ctx:      signalled(syscall.SIGTERM),
+			err:      errors.New("copy aborted"),
+			expected: 143,
+		},
+		{
+			name:     "exit code kept",
+			ctx:      signalled(syscall.SIGINT),
+			err:      Exit("failed", 7),
+			expected: 7,
+		},
+		{
+			name:     "not signalled",
+			ctx:      context.Background(),
+			err:      context.Canceled,
+			expected: 0,
+		},
+		{
+			name: "override",
+			ctx:  signalled(syscall.SIGINT),
+			err:  context.Canceled,
+			override: func(context.Context, *Command, os.Signal) int {
+				return 2
+			},
+			expected: 2,
+		},
+		{
+			name: "override disabled",
+			ctx:  signalled(syscall.SIGINT),
+			err:  context.Canceled,
+			override: func(context.Context, *Command, os.Signal) int {
+				return 0
+			},
+			expected: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			exitCode := 0
+			OsExiter = func(rc int) { exitCode = rc }
+			defer func() { OsExiter = fakeOsExiter }()
+
+			cmd := &Command{
+				Name:           "app",
+				Writer:         io.Discard,
+				ErrWriter:      io.Discard,
+				CancelExitCode: test.override,
+				Action: func(context.Context, *Command) error {
+					return test.err
+				},
+			}
+
+			_ = cmd.Run(test.ctx, []string{"app"})
+			assert.Equal(t, test.expected, exitCode)
+		})
+	}
+}
+
+func TestHandleSignals(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sending signals to the own process is not supported")
+	}
+
+	exitCode := make(chan int, 1)
+	var afterCalls int
+	var received os.Signal
+	cmd := &Command{
+		Name:          "app",
+		ErrWriter:     io.Discard,
+		HandleSignals: []os.Signal{syscall.SIGTERM},
+		Exiter:        ExiterFunc(func(code int) { exitCode <- code }),
+		Commands: []*Command{{
+			Name: "serve",
+			After: func(context.Context, *Command) error {
+				afterCalls++
+				return nil
+			},
+			Action: func(ctx context.Context, _ *Command) error {
+				p, err := os.FindProcess(os.Getpid())
+				require.NoError(t, err)
+				require.NoError(t, p.Signal(syscall.SIGTERM))
+
+				<-ctx.Done()
+				received = SignalFromContext(ctx)
+				return ctx.Err()
+			},
+		}},
+	}
+
+	err := cmd.Run(buildTestContext(t), []string{"app", "serve"})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, syscall.SIGTERM, received)
+	assert.Equal(t, 1, afterCalls)
+	assert.Equal(t, 143, <-exitCode)
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sending signals to the own process is not supported")
+	}
+
+	exitCode := make(chan int, 1)
+	release := make(chan struct{})
+	var after []string
+	errWriter := &bytes.Buffer{}
+	cmd := &Command{
+		Name:            "app",
+		ErrWriter:       errWriter,
+		HandleSignals:   []os.Signal{syscall.SIGTERM},
+		ShutdownTimeout: 10 * time.Millisecond,
+		Exiter: ExiterFunc(func(code int) {
+			exitCode <- code
+			close(release)
+		}),
+		After: func(context.Context, *Command) error {
+			after = append(after, "app")
+			return nil
+		},
+		Commands: []*Command{{
+			Name: "serve",
+			After: func(context.Context, *Command) error {
+				after = append(after, "serve")
+				return errors.New("unable to flush")
+			},
+			Action: func(context.Context, *Command) error {
+				p, err := os.FindProcess(os.Getpid())
+				require.NoError(t, err)
+				require.NoError(t, p.Signal(syscall.SIGTERM))
+
+				// the action ignores the cancellation
+				<-release
+				return nil
+			},
+		}},
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "serve"}))
+	assert.Equal(t, 143, <-exitCode)
+	assert.Equal(t, []string{"serve", "app"}, after, "the After functions run once")
+	assert.Equal(t, "shutdown timed out after 10ms on signal terminated\nunable to flush\n", errWriter.String())
+}
@@ -0,0 +1,340 @@
+//go:build !urfave_cli_no_docs
+
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CommandSpecSchemaVersion is the version of the schema of CommandSpec,
+// increased on incompatible changes of the schema
+const CommandSpecSchemaVersion = 1
+
+// CommandSpec is the machine-readable description of a command and its
+// subcommands as exported by ToJSON and ToYAML. Hidden commands and flags
+// are included and marked as hidden, the help command and the help and
+// version flags added by the package are not.
+type CommandSpec struct {
+	// Version of the schema, only set for the root command
+	SchemaVersion   int            `json:"schemaVersion,omitempty"`
+	Name            string         `json:"name"`
+	Aliases         []string       `json:"aliases,omitempty"`
+	Usage           string         `json:"usage,omitempty"`
+	UsageText       string         `json:"usageText,omitempty"`
+	ArgsUsage       string         `json:"argsUsage,omitempty"`
+	PassthroughArgs bool           `json:"passthroughArgs,omitempty"`
+	Description     string         `json:"description,omitempty"`
+	Version         string         `json:"version,omitempty"`
+	Category        string         `json:"category,omitempty"`
+	Hidden          bool           `json:"hidden,omitempty"`
+	Examples        []Example      `json:"examples,omitempty"`
+	Since           string         `json:"since,omitempty"`
+	SeeAlso         []string       `json:"seeAlso,omitempty"`
+	Arguments       []ArgumentSpec `json:"arguments,omitempty"`
+	Flags           []FlagSpec     `json:"flags,omitempty"`
+	Commands        []CommandSpec  `json:"commands,omitempty"`
+}
+
+// ArgumentSpec is the machine-readable description of an argument. Max is
+// -1 if the number of values is unlimited.
+type ArgumentSpec struct {
+	Name        string `json:"name"`
+	Usage       string `json:"usage,omitempty"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Min         int    `json:"min"`
+	Max         int    `json:"max"`
+}
+
+// FlagSpec is the machine-readable description of a flag
+type FlagSpec struct {
+	Name       string   `json:"name"`
+	Aliases    []string `json:"aliases,omitempty"`
+	Usage      string   `json:"usage,omitempty"`
+	TakesValue bool     `json:"takesValue,omitempty"`
+	MultiValue bool     `json:"multiValue,omitempty"`
+	Choices    []string `json:"choices,omitempty"`
+	Default    string   `json:"default,omitempty"`
+	EnvVars    []string `json:"envVars,omitempty"`
+	Category   string   `json:"category,omitempty"`
+	Required   bool     `json:"required,omitempty"`
+	Requires   []string `json:"requires,omitempty"`
+	Conflicts  []string `json:"conflicts,omitempty"`
+	Persistent bool     `json:"persistent,omitempty"`
+	Hidden     bool     `json:"hidden,omitempty"`
+	Examples   []string `json:"examples,omitempty"`
+	Since      string   `json:"since,omitempty"`
+	SeeAlso    []string `json:"seeAlso,omitempty"`
+}
+
+// ToSpec returns the machine-readable description of the command tree
+func (cmd *Command) ToSpec() *CommandSpec {
+	cmd.loadCommands()
+
+	spec := newCommandSpec(cmd, cmd.EnvPrefix)
+	spec.SchemaVersion = CommandSpecSchemaVersion
+	spec.Version = cmd.Version
+	return &spec
+}
+
+// ToJSON exports the command tree as indented JSON following the schema of
+// CommandSpec, e.g. for doc sites, completion generators or audit scripts.
+func (cmd *Command) ToJSON() (string, error) {
+	var b strings.Builder
+	if err := cmd.ToJSONToWriter(&b); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// ToJSONToWriter writes the JSON of ToJSON to the writer
+func (cmd *Command) ToJSONToWriter(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(cmd.ToSpec())
+}
+
+// ToYAML exports the command tree as YAML following the schema of
+// CommandSpec, with the same keys as ToJSON.
+func (cmd *Command) ToYAML() (string, error) {
+	var b strings.Builder
+	if err := cmd.ToYAMLToWriter(&b); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// ToYAMLToWriter writes the YAML of ToYAML to the writer while rendering
+// the command tree, so large trees are not built in memory first
+func (cmd *Command) ToYAMLToWriter(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	cmd.ToSpec().writeYAML(b, "", "")
+	return b.Flush()
+}
+
+func newCommandSpec(cmd *Command, envPrefix string) CommandSpec {
+	cmd.setupFlagGroups()
+	cmd.setupEnvPrefix(envPrefix)
+
+	spec := CommandSpec{
+		Name:            cmd.Name,
+		Aliases:         cmd.Aliases,
+		Usage:           cmd.Usage,
+		UsageText:       cmd.UsageText,
+		ArgsUsage:       cmd.ArgsUsage,
+		PassthroughArgs: cmd.EnablePassthroughArgs,
+		Description:     cmd.Description,
+		Category:        cmd.Category,
+		Hidden:          cmd.Hidden,
+		Examples:        cmd.Examples,
+		Since:           cmd.Since,
+		SeeAlso:         cmd.SeeAlso,
+	}
+
+	for _, arg := range docsArguments(cmd) {
+		spec.Arguments = append(spec.Arguments, ArgumentSpec(arg))
+	}
+
+	for _, fl := range cmd.Flags {
+		if fl == HelpFlag || fl == VersionFlag || len(fl.Names()) == 0 {
+			continue
+		}
+		spec.Flags = append(spec.Flags, newFlagSpec(fl))
+	}
+
+	for _, sub := range cmd.Commands {
+		if sub.isHelpCommand {
+			continue
+		}
+		sub.loadCommands()
+		spec.Commands = append(spec.Commands, newCommandSpec(sub, envPrefix))
+	}
+
+	return spec
+}
+
+func newFlagSpec(fl Flag) FlagSpec {
+	names := fl.Names()
+	spec := FlagSpec{Name: names[0], Aliases: names[1:]}
+
+	if df, ok := fl.(DocGenerationFlag); ok {
+		spec.Usage = df.GetUsage()
+		spec.TakesValue = df.TakesValue()
+		spec.Default = df.GetDefaultText()
+		spec.EnvVars = df.GetEnvVars()
+	}
+	if cf, ok := fl.(ChoicesFlag); ok {
+		spec.Choices = cf.GetChoices()
+	}
+	if mf, ok := fl.(DocGenerationMultiValueFlag); ok {
+		spec.MultiValue = mf.IsMultiValueFlag()
+	}
+	if cf, ok := fl.(CategorizableFlag); ok {
+		spec.Category = cf.GetCategory()
+	}
+	if rf, ok := fl.(RequiredFlag); ok {
+		spec.Required = rf.IsRequired()
+	}
+	if rf, ok := fl.(RelatedFlag); ok {
+		spec.Requires = rf.RequiresFlags()
+		spec.Conflicts = rf.ConflictingFlags()
+	}
+	if pf, ok := fl.(PersistentFlag); ok {
+		spec.Persistent = pf.IsPersistent()
+	}
+	if vf, ok := fl.(VisibleFlag); ok {
+		spec.Hidden = !vf.IsVisible()
+	}
+	if mf, ok := fl.(DocMetadataFlag); ok {
+		spec.Examples = mf.GetExamples()
+		spec.Since = mf.GetSince()
+		spec.SeeAlso = mf.GetSeeAlso()
+	}
+
+	if len(spec.Aliases) == 0 {
+		spec.Aliases = nil
+	}
+	if len(spec.EnvVars) == 0 {
+		spec.EnvVars = nil
+	}
+	if len(spec.Choices) == 0 {
+		spec.Choices = nil
+	}
+	if len(spec.Requires) == 0 {
+		spec.Requires = nil
+	}
+
+	return spec
+}
+
+// yamlWriter writes the fields of a mapping, the first one prefixed by
+// first and the others by indent, so mappings can be items of sequences
+type yamlWriter struct {
+	b      *bufio.Writer
+	first  string
+	indent string
+	n      int
+}
+
+func (w *yamlWriter) key(key string) {
+	if w.n == 0 {
+		w.b.WriteString(w.first)
+	} else {
+		w.b.WriteString(w.indent)
+	}
+	w.n++
+	w.b.WriteString(key + ":")
+}
+
+func (w *yamlWriter) str(key, v string) {
+	if v != "" {
+		w.key(key)
+		w.b.WriteString(" " + yamlString(v) + "\n")
+	}
+}
+
+func (w *yamlWriter) strs(key string, v []string) {
+	if len(v) > 0 {
+		w.key(key)
+		quoted := make([]string, len(v))
+		for i, s := range v {
+			quoted[i] = yamlString(s)
+		}
+		w.b.WriteString(" [" + strings.Join(quoted, ", ") + "]\n")
+	}
+}
+
+func (w *yamlWriter) int(key string, v int) {
+	w.key(key)
+	fmt.Fprintf(w.b, " %d\n", v)
+}
+
+func (w *yamlWriter) flag(key string, v bool) {
+	if v {
+		w.key(key)
+		w.b.WriteString(" true\n")
+	}
+}
+
+func (spec *CommandSpec) writeYAML(b *bufio.Writer, first, indent string) {
+	w := &yamlWriter{b: b, first: first, indent: indent}
+
+	if spec.SchemaVersion != 0 {
+		w.key("schemaVersion")
+		fmt.Fprintf(b, " %d\n", spec.SchemaVersion)
+	}
+	w.str("name", spec.Name)
+	w.strs("aliases", spec.Aliases)
+	w.str("usage", spec.Usage)
+	w.str("usageText", spec.UsageText)
+	w.str("argsUsage", spec.ArgsUsage)
+	w.flag("passthroughArgs", spec.PassthroughArgs)
+	w.str("description", spec.Description)
+	w.str("version", spec.Version)
+	w.str("category", spec.Category)
+	w.flag("hidden", spec.Hidden)
+	w.str("since", spec.Since)
+	w.strs("seeAlso", spec.SeeAlso)
+
+	if len(spec.Examples) > 0 {
+		w.key("examples")
+		b.WriteString("\n")
+		for _, ex := range spec.Examples {
+			ew := &yamlWriter{b: b, first: indent + "  - ", indent: indent + "    "}
+			ew.str("description", ex.Description)
+			ew.str("command", ex.Command)
+			ew.str("output", ex.Output)
+		}
+	}
+
+	if len(spec.Arguments) > 0 {
+		w.key("arguments")
+		b.WriteString("\n")
+		for _, arg := range spec.Arguments {
+			aw := &yamlWriter{b: b, first: indent + "  - ", indent: indent + "    "}
+			aw.str("name", arg.Name)
+			aw.str("usage", arg.Usage)
+			aw.str("description", arg.Description)
+			aw.str("type", arg.Type)
+			aw.int("min", arg.Min)
+			aw.int("max", arg.Max)
+		}
+	}
+
+	if len(spec.Flags) > 0 {
+		w.key("flags")
+		b.WriteString("\n")
+		for _, fl := range spec.Flags {
+			fw := &yamlWriter{b: b, first: indent + "  - ", indent: indent + "    "}
+			fw.str("name", fl.Name)
+			fw.strs("aliases", fl.Aliases)
+			fw.str("usage", fl.Usage)
+			fw.flag("takesValue", fl.TakesValue)
+			fw.flag("multiValue", fl.MultiValue)
+			fw.strs("choices", fl.Choices)
+			fw.str("default", fl.Default)
+			fw.strs("envVars", fl.EnvVars)
+			fw.str("category", fl.Category)
+			fw.flag("required", fl.Required)
+			fw.strs("requires", fl.Requires)
+			fw.strs("conflicts", fl.Conflicts)
+			fw.flag("persistent", fl.Persistent)
+			fw.flag("hidden", fl.Hidden)
+			fw.strs("examples", fl.Examples)
+			fw.str("since", fl.Since)
+			fw.strs("seeAlso", fl.SeeAlso)
+		}
+	}
+
+	if len(spec.Commands) > 0 {
+		w.key("commands")
+		b.WriteString("\n")
+		for i := range spec.Commands {
+			spec.Commands[i].writeYAML(b, indent+"  - ", indent+"    ")
+		}
+	}
+}
@@ -0,0 +1,241 @@
+//go:build !urfave_cli_no_docs
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToJSON(t *testing.T) {
+	cmd := buildExtendedTestCommand()
+	cmd.Version = "v1.0.0"
+
+	res, err := cmd.ToJSON()
+	require.NoError(t, err)
+	expectFileContent(t, "testdata/expected-spec.json", res)
+
+	var spec CommandSpec
+	require.NoError(t, json.Unmarshal([]byte(res), &spec))
+	assert.Equal(t, cmd.ToSpec(), &spec)
+}
+
+func TestToYAML(t *testing.T) {
+	cmd := buildExtendedTestCommand()
+	cmd.Version = "v1.0.0"
+
+	res, err := cmd.ToYAML()
+	require.NoError(t, err)
+	expectFileContent(t, "testdata/expected-spec.yaml", res)
+}
+
+// failingWriter fails to write anything
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestToWriter(t *testing.T) {
+	for name, tc := range map[string]struct {
+		render func(*Command) (string, error)
+		write  func(*Command, io.Writer) error
+	}{
+		"json": {(*Command).ToJSON, (*Command).ToJSONToWriter},
+		"yaml": {(*Command).ToYAML, (*Command).ToYAMLToWriter},
+		"rst":  {(*Command).ToReStructuredText, (*Command).ToReStructuredTextToWriter},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cmd := buildExtendedTestCommand()
+
+			expected, err := tc.render(cmd)
+			require.NoError(t, err)
+
+			var b bytes.Buffer
+			require.NoError(t, tc.write(cmd, &b))
+			assert.Equal(t, expected, b.String())
+
+			assert.EqualError(t, tc.write(cmd, failingWriter{}), "disk full")
+		})
+	}
+}
+
+func TestToSpecAfterRun(t *testing.T) {
+	cmd := &Command{
+		Name:    "app",
+		Usage:   "manage resources",
+		Version: "v1.0.0",
+		Flags: []Flag{
+			&StringFlag{Name: "region", Category: "cloud", Required: true, Persistent: true},
+			&StringSliceFlag{Name: "tag"},
+		},
+		Commands: []*Command{{Name: "list", Category: "read"}},
+		Action:   func(context.Context, *Command) error { return nil },
+	}
+	before := cmd.ToSpec()
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--region", "eu"}))
+
+	assert.Equal(t, before, cmd.ToSpec(), "the help command and flags are not exported")
+	assert.Equal(t, &CommandSpec{
+		SchemaVersion: CommandSpecSchemaVersion,
+		Name:          "app",
+		Usage:         "manage resources",
+		Version:       "v1.0.0",
+		Flags: []FlagSpec{
+			{Name: "region", TakesValue: true, Category: "cloud", Required: true, Persistent: true},
+			{Name: "tag", TakesValue: true, MultiValue: true},
+		},
+		Commands: []CommandSpec{{Name: "list", Category: "read"}},
+	}, before)
+}
+
+func TestToSpecFlagGroups(t *testing.T) {
+	spec := buildFlagGroupTestCommand().ToSpec()
+	assert.Equal(t, []string{"password"}, spec.Flags[0].Requires)
+	assert.Equal(t, []string{"tls-key", "tls-ca"}, spec.Flags[2].Requires)
+	assert.Nil(t, spec.Flags[3].Requires)
+}
+
+func TestToSpecChoices(t *testing.T) {
+	spec := buildChoiceTestCommand().ToSpec()
+	assert.Equal(t, []string{"text", "json", "yaml"}, spec.Flags[0].Choices)
+
+	out, err := buildChoiceTestCommand().ToYAML()
+	require.NoError(t, err)
+	assert.Contains(t, out, `choices: ["text", "json", "yaml"]`)
+
+	rst, err := buildChoiceTestCommand().ToReStructuredText()
+	require.NoError(t, err)
+	assert.Contains(t, rst, ":Choices: ``text``, ``json``, ``yaml``")
+	assert.Contains(t, docsFlag(buildChoiceTestCommand().Flags[0]), "(one of: `text`, `json`, `yaml`)")
+}
+
+func TestDocsFlagLayouts(t *testing.T) {
+	fl := &TimestampFlag{Name: "since", Config: TimestampConfig{Layouts: []string{"2006-01-02", "15:04"}}}
+	assert.Equal(t, "- `--since value` (formats: `2006-01-02`, `15:04`)\n", docsFlag(fl))
+	assert.Contains(t, manFlag(fl), `(formats: 2006\-01\-02, 15:04)`)
+}
+
+func TestDocsFlagNegatable(t *testing.T) {
+	fl := &BoolFlag{Name: "color", Aliases: []string{"c"}, Usage: "colorize", Config: BoolConfig{Negatable: true}}
+	assert.Equal(t, "- `--color, -c, --no-color`: colorize (default: `false`)\n", docsFlag(fl))
+	assert.Contains(t, manFlag(fl), `.B \-\-color, \-c, \-\-no\-color`)
+}
+
+func TestDocsDocMetadata(t *testing.T) {
+	fl := &StringFlag{Name: "tag", Examples: []string{"--tag v1"}, Since: "v1.3.0", SeeAlso: []string{"--dry-run"}}
+	assert.Equal(t, "- `--tag value` (e.g. `--tag v1`) (since v1.3.0) (see also: --dry-run)\n", docsFlag(fl))
+	assert.Contains(t, manFlag(fl), `(e.g. \-\-tag v1) (since v1.3.0) (see also: \-\-dry\-run)`)
+
+	cmd := &Command{
+		Name: "app",
+		Commands: []*Command{{
+			Name:     "deploy",
+			Since:    "v1.2.0",
+			Examples: []Example{{Description: "Deploy a tag", Command: "app deploy --tag v1", Output: "deployed v1\n"}},
+			SeeAlso:  []string{"https://example.com/deploy"},
+			Flags:    []Flag{fl},
+		}},
+	}
+
+	page := newDocsPage(cmd, nil, 1).children[0]
+	md := page.markdown()
+	assert.Contains(t, md, "Since: v1.2.0\n")
+	assert.Contains(t, md, "## Examples\n\nDeploy a tag\n\n```\n$ app deploy --tag v1\ndeployed v1\n```\n")
+	assert.Contains(t, md, "## See Also\n\n- https://example.com/deploy\n")
+
+	man := page.man(1, "")
+	assert.Contains(t, man, ".SH HISTORY\nAdded in v1.2.0\n")
+	assert.Contains(t, man, ".SH EXAMPLES\n.PP\nDeploy a tag\n.nf\n$ app deploy \\-\\-tag v1\ndeployed v1\n.fi\n")
+	assert.Contains(t, man, ".SH SEE ALSO\n.BR app (1) ,\nhttps://example.com/deploy\n")
+
+	spec := cmd.ToSpec()
+	assert.Equal(t, "v1.2.0", spec.Commands[0].Since)
+	assert.Equal(t, []string{"--tag v1"}, spec.Commands[0].Flags[0].Examples)
+
+	out, err := cmd.ToYAML()
+	require.NoError(t, err)
+	assert.Contains(t, out, `    examples:
+      - description: "Deploy a tag"
+        command: "app deploy --tag v1"
+        output: "deployed v1\n"
+`)
+	assert.Contains(t, out, `seeAlso: ["--dry-run"]`)
+}
+
+func TestDocsEnvPrefix(t *testing.T) {
+	cmd := &Command{
+		Name:      "app",
+		EnvPrefix: "MYAPP",
+		Flags:     []Flag{&StringFlag{Name: "log-level"}},
+		Commands:  []*Command{{Name: "serve", Flags: []Flag{&IntFlag{Name: "port"}}}},
+	}
+
+	spec := cmd.ToSpec()
+	assert.Equal(t, []string{"MYAPP_LOG_LEVEL"}, spec.Flags[0].EnvVars)
+	assert.Equal(t, []string{"MYAPP_PORT"}, spec.Commands[0].Flags[0].EnvVars)
+
+	site, err := cmd.ToMkDocs()
+	require.NoError(t, err)
+	assert.Contains(t, site.Files["app/serve.md"], "- `--port value` (default: `0`) [env: `MYAPP_PORT`]\n")
+}
+
+func TestDocsArguments(t *testing.T) {
+	cmd := &Command{
+		Name: "app",
+		Commands: []*Command{{
+			Name: "copy",
+			Arguments: []Argument{
+				&StringArg{Name: "src", Min: 1, Max: -1, Description: "files to copy"},
+				&StringArg{Name: "dst", Min: 1, Max: 1},
+			},
+		}},
+	}
+
+	spec := cmd.ToSpec()
+	assert.Equal(t, []ArgumentSpec{
+		{Name: "src", Usage: "src [src ...]", Description: "files to copy", Type: "string", Min: 1, Max: -1},
+		{Name: "dst", Usage: "dst", Type: "string", Min: 1, Max: 1},
+	}, spec.Commands[0].Arguments)
+
+	y, err := cmd.ToYAML()
+	require.NoError(t, err)
+	assert.Contains(t, y, `    arguments:
+      - name: "src"
+        usage: "src [src ...]"
+        description: "files to copy"
+        type: "string"
+        min: 1
+        max: -1
+`)
+
+	site, err := cmd.ToMkDocs()
+	require.NoError(t, err)
+	assert.Contains(t, site.Files["app/copy.md"], "app copy src [src ...] dst\n")
+	assert.Contains(t, site.Files["app/copy.md"], "## Arguments\n\n- `src [src ...]`: files to copy\n- `dst`\n")
+
+	man, err := cmd.Commands[0].ToMan(1)
+	require.NoError(t, err)
+	assert.Contains(t, man, ".SH ARGUMENTS\n.TP\n.I src [src ...]\nfiles to copy\n.TP\n.I dst\n")
+}
+
+func TestDocsPassthroughArgs(t *testing.T) {
+	cmd := &Command{
+		Name:     "app",
+		Commands: []*Command{{Name: "exec", EnablePassthroughArgs: true, ArgsUsage: "container"}},
+	}
+
+	assert.True(t, cmd.ToSpec().Commands[0].PassthroughArgs)
+
+	site, err := cmd.ToMkDocs()
+	require.NoError(t, err)
+	assert.Contains(t, site.Files["app/exec.md"], "app exec container [-- ARGS...]\n")
+}
@@ -1,9 +1,5 @@
 package cli
 
-import (
-	"math"
-)
-
 const suggestDidYouMeanTemplate = "Did you mean %q?"
 
 var (
@@ -16,132 +12,114 @@ type SuggestFlagFunc func(flags []Flag, provided string, hideHelp bool) string
 
 type SuggestCommandFunc func(commands []*Command, provided string) string
 
-// jaroDistance is the measure of similarity between two strings. It returns a
-// value between 0 and 1, where 1 indicates identical strings and 0 indicates
-// completely different strings.
-//
-// Adapted from https://github.com/xrash/smetrics/blob/5f08fbb34913bc8ab95bb4f2a89a0637ca922666/jaro.go.
-func jaroDistance(a, b string) float64 {
-	if len(a) == 0 && len(b) == 0 {
-		return 1
+func suggestFlag(flags []Flag, provided string, hideHelp bool) string {
+	var names []string
+	for _, flag := range flags {
+		names = append(names, flag.Names()...)
 	}
-	if len(a) == 0 || len(b) == 0 {
-		return 0
+	if len(names) > 0 && !hideHelp && HelpFlag != nil {
+		names = append(names, HelpFlag.Names()...)
 	}
 
-	lenA := float64(len(a))
-	lenB := float64(len(b))
-	hashA := make([]bool, len(a))
-	hashB := make([]bool, len(b))
-	maxDistance := int(math.Max(0, math.Floor(math.Max(lenA, lenB)/2.0)-1))
-
-	var matches float64
-	for i := 0; i < len(a); i++ {
-		start := int(math.Max(0, float64(i-maxDistance)))
-		end := int(math.Min(lenB-1, float64(i+maxDistance)))
+	suggestion := suggestName(names, provided)
 
-		for j := start; j <= end; j++ {
-			if hashB[j] {
-				continue
-			}
-			if a[i] == b[j] {
-				hashA[i] = true
-				hashB[j] = true
-				matches++
-				break
-			}
-		}
-	}
-	if matches == 0 {
-		return 0
+	if len(suggestion) == 1 {
+		suggestion = "-" + suggestion
+	} else if len(suggestion) > 1 {
+		suggestion = "--" + suggestion
 	}
 
-	var transpositions float64
-	var j int
-	for i := 0; i < len(a); i++ {
-		if !hashA[i] {
-			continue
-		}
-		for !hashB[j] {
-			j++
-		}
-		if a[i] != b[j] {
-			transpositions++
-		}
-		j++
+	return suggestion
+}
+
+// CommandSuggestions returns the names and aliases of the visible
+// subcommands the provided name is likely a typo or an abbreviation of,
+// closest first. They are suggested by the error for unknown commands if
+// Suggest is set, and can be used by CommandNotFound functions to suggest
+// commands themselves.
+func (cmd *Command) CommandSuggestions(provided string) []string {
+	var names []string
+	for _, subCmd := range cmd.VisibleCommands() {
+		names = append(names, subCmd.Names()...)
 	}
 
-	transpositions /= 2
-	return ((matches / lenA) + (matches / lenB) + ((matches - transpositions) / matches)) / 3.0
+	return suggestNames(names, provided)
 }
 
-// jaroWinkler is more accurate when strings have a common prefix up to a
-// defined maximum length.
-//
-// Adapted from https://github.com/xrash/smetrics/blob/5f08fbb34913bc8ab95bb4f2a89a0637ca922666/jaro-winkler.go.
-func jaroWinkler(a, b string) float64 {
-	const (
-		boostThreshold = 0.7
-		prefixSize     = 4
-	)
-	jaroDist := jaroDistance(a, b)
-	if jaroDist <= boostThreshold {
-		return jaroDist
+// FlagSuggestions returns the names of the visible flags of the command and
+// its ancestors the provided name is likely a typo or an abbreviation of,
+// closest first and prefixed with dashes. They are suggested by the error
+// for undefined flags if Suggest is set.
+func (cmd *Command) FlagSuggestions(provided string) []string {
+	var names []string
+	for _, f := range cmd.suggestableFlags() {
+		names = append(names, f.Names()...)
+	}
+
+	suggestions := suggestNames(names, provided)
+	for i, name := range suggestions {
+		suggestions[i] = prefixFor(name) + name
 	}
 
-	prefix := int(math.Min(float64(len(a)), math.Min(float64(prefixSize), float64(len(b)))))
+	return suggestions
+}
 
-	var prefixMatch float64
-	for i := 0; i < prefix; i++ {
-		if a[i] == b[i] {
-			prefixMatch++
-		} else {
-			break
+// suggestableFlags returns the visible flags of the command and its
+// ancestors
+func (cmd *Command) suggestableFlags() []Flag {
+	var flags []Flag
+	for _, pCmd := range cmd.Lineage() {
+		for _, f := range pCmd.Flags {
+			if vf, ok := f.(VisibleFlag); ok && !vf.IsVisible() {
+				continue
+			}
+			flags = append(flags, f)
 		}
 	}
-	return jaroDist + 0.1*prefixMatch*(1.0-jaroDist)
+
+	return flags
 }
 
-func suggestFlag(flags []Flag, provided string, hideHelp bool) string {
-	distance := 0.0
-	suggestion := ""
+// undefinedFlagSuggestions returns the suggestion of SuggestFlag for the
+// undefined flag followed by the other similar flags
+func (cmd *Command) undefinedFlagSuggestions(provided string) []string {
+	return withSuggestion(SuggestFlag(cmd.suggestableFlags(), provided, cmd.HideHelp), cmd.FlagSuggestions(provided))
+}
 
-	for _, flag := range flags {
-		flagNames := flag.Names()
-		if !hideHelp && HelpFlag != nil {
-			flagNames = append(flagNames, HelpFlag.Names()...)
-		}
-		for _, name := range flagNames {
-			newDistance := jaroWinkler(name, provided)
-			if newDistance > distance {
-				distance = newDistance
-				suggestion = name
-			}
-		}
+// unknownCommandSuggestions returns the suggestion of SuggestCommand for the
+// unknown command followed by the other similar commands
+func (cmd *Command) unknownCommandSuggestions(provided string) []string {
+	return withSuggestion(SuggestCommand(cmd.Commands, provided), cmd.CommandSuggestions(provided))
+}
+
+// withSuggestion puts the suggestion first, so the SuggestFlag and
+// SuggestCommand functions decide what is suggested, and the other similar
+// names after it. Nothing is suggested if the suggestion is empty.
+func withSuggestion(suggestion string, similar []string) []string {
+	if suggestion == "" {
+		return nil
 	}
 
-	if len(suggestion) == 1 {
-		suggestion = "-" + suggestion
-	} else if len(suggestion) > 1 {
-		suggestion = "--" + suggestion
+	suggestions := []string{suggestion}
+	for _, name := range similar {
+		if name != suggestion {
+			suggestions = append(suggestions, name)
+		}
 	}
 
-	return suggestion
+	return suggestions
 }
 
 // suggestCommand takes a list of commands and a provided string to suggest a
 // command name
-func suggestCommand(commands []*Command, provided string) (suggestion string) {
-	distance := 0.0
+func suggestCommand(commands []*Command, provided string) string {
+	var names []string
 	for _, command := range commands {
-		for _, name := range append(command.Names(), helpName, helpAlias) {
-			newDistance := jaroWinkler(name, provided)
-			if newDistance > distance {
-				distance = newDistance
-				suggestion = name
-			}
-		}
+		names = append(names, command.Names()...)
+	}
+	if len(commands) > 0 {
+		names = append(names, helpName, helpAlias)
 	}
 
-	return suggestion
+	return suggestName(names, provided)
 }
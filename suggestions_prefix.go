@@ -0,0 +1,46 @@
+//go:build urfave_cli_no_suggest
+
+package cli
+
+import "strings"
+
+// suggestName returns the name the provided string is an unambiguous prefix
+// of. Builds without the similarity matcher only resolve abbreviations, so
+// flags and commands are suggested and prefix matched without pulling in
+// the Jaro-Winkler implementation.
+func suggestName(names []string, provided string) string {
+	if provided == "" {
+		return ""
+	}
+
+	suggestion := ""
+	for _, name := range names {
+		if name == provided {
+			return name
+		}
+		if strings.HasPrefix(name, provided) {
+			if suggestion != "" && suggestion != name {
+				return ""
+			}
+			suggestion = name
+		}
+	}
+
+	return suggestion
+}
+
+// suggestNames returns the names the provided string is a prefix of
+func suggestNames(names []string, provided string) []string {
+	if provided == "" {
+		return nil
+	}
+
+	var suggestions []string
+	for _, name := range names {
+		if strings.HasPrefix(name, provided) && !checkStringSliceIncludes(name, suggestions) {
+			suggestions = append(suggestions, name)
+		}
+	}
+
+	return suggestions
+}
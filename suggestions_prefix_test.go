@@ -0,0 +1,50 @@
+//go:build urfave_cli_no_suggest
+
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestNamePrefix(t *testing.T) {
+	names := []string{"deploy", "delete", "status", "s"}
+
+	for _, testCase := range []struct {
+		provided, expected string
+	}{
+		{"dep", "deploy"},
+		{"de", ""},
+		{"s", "s"},
+		{"stat", "status"},
+		{"deplyo", ""},
+		{"", ""},
+	} {
+		assert.Equal(t, testCase.expected, suggestName(names, testCase.provided), testCase.provided)
+	}
+}
+
+func TestSuggestNamesPrefix(t *testing.T) {
+	names := []string{"deploy", "delete", "status", "deploy"}
+
+	assert.Equal(t, []string{"deploy", "delete"}, suggestNames(names, "de"))
+	assert.Equal(t, []string{"status"}, suggestNames(names, "stat"))
+	assert.Empty(t, suggestNames(names, "deplyo"))
+	assert.Empty(t, suggestNames(names, ""))
+}
+
+func TestSuggestCommandPrefixMatch(t *testing.T) {
+	var ran string
+	cmd := &Command{
+		PrefixMatchCommands: true,
+		Commands: []*Command{
+			{Name: "deploy", Action: func(_ context.Context, cmd *Command) error { ran = cmd.Name; return nil }},
+			{Name: "delete", Action: func(_ context.Context, cmd *Command) error { ran = cmd.Name; return nil }},
+		},
+	}
+
+	assert.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "dep"}))
+	assert.Equal(t, "deploy", ran)
+}
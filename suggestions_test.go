@@ -1,6 +1,10 @@
+//go:build !urfave_cli_no_suggest
+
 package cli
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"testing"
@@ -128,6 +132,100 @@ func TestSuggestFlagFromErrorNoSuggestion(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestSuggestUndefinedFlag(t *testing.T) {
+	// Given
+	errW := &bytes.Buffer{}
+	cmd := &Command{
+		Name:      "kubectl",
+		ErrWriter: errW,
+		Flags: []Flag{
+			&StringFlag{Name: "namespace"},
+		},
+		Commands: []*Command{
+			{
+				Name:     "get",
+				Suggest:  true,
+				HideHelp: true,
+				Flags: []Flag{
+					&StringFlag{Name: "output"},
+				},
+				Action: func(context.Context, *Command) error { return nil },
+			},
+		},
+	}
+
+	// When
+	err := cmd.Run(buildTestContext(t), []string{"kubectl", "get", "--namspace", "kube-system"})
+
+	// Then
+	var undefinedErr *ErrFlagUndefined
+	assert.True(t, errors.As(err, &undefinedErr))
+	assert.Equal(t, []string{"--namespace"}, undefinedErr.Suggestions)
+	assert.EqualError(t, err, `flag provided but not defined: -namspace. Did you mean "--namespace"?`)
+	assert.Equal(t, "Incorrect Usage: flag provided but not defined: -namspace\n\nDid you mean \"--namespace\"?\n\n", errW.String())
+}
+
+func TestSuggestUndefinedFlagCustomSuggestFlag(t *testing.T) {
+	defer func(f SuggestFlagFunc) { SuggestFlag = f }(SuggestFlag)
+	SuggestFlag = func([]Flag, string, bool) string { return "--custom" }
+
+	errW := &bytes.Buffer{}
+	cmd := &Command{
+		Name:      "kubectl",
+		Suggest:   true,
+		ErrWriter: errW,
+		Flags: []Flag{
+			&StringFlag{Name: "namespace"},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	err := cmd.Run(buildTestContext(t), []string{"kubectl", "--namspace", "kube-system"})
+
+	var undefinedErr *ErrFlagUndefined
+	assert.True(t, errors.As(err, &undefinedErr))
+	assert.Equal(t, []string{"--custom", "--namespace"}, undefinedErr.Suggestions)
+	assert.Contains(t, errW.String(), "Did you mean \"--custom\"?\n\n")
+	assert.NotContains(t, errW.String(), "Did you mean \"--namespace\"?")
+
+	SuggestFlag = func([]Flag, string, bool) string { return "" }
+	err = cmd.Run(buildTestContext(t), []string{"kubectl", "--namspace", "kube-system"})
+	assert.True(t, errors.As(err, &undefinedErr))
+	assert.Empty(t, undefinedErr.Suggestions)
+}
+
+func TestSuggestUnknownCommandCustomSuggestCommand(t *testing.T) {
+	defer func(f SuggestCommandFunc) { SuggestCommand = f }(SuggestCommand)
+	SuggestCommand = func([]*Command, string) string { return "custom" }
+
+	cmd := &Command{
+		Name:      "app",
+		Suggest:   true,
+		Writer:    &bytes.Buffer{},
+		ErrWriter: &bytes.Buffer{},
+		Commands: []*Command{
+			{Name: "migrate", Action: func(context.Context, *Command) error { return nil }},
+		},
+	}
+
+	err := cmd.Run(buildTestContext(t), []string{"app", "migrat"})
+	assert.EqualError(t, err, `No help topic for 'migrat'. Did you mean "custom"?`)
+
+	var notFoundErr *ErrCommandNotFound
+	assert.True(t, errors.As(err, &notFoundErr))
+	assert.Equal(t, []string{"custom", "migrate"}, notFoundErr.Suggestions)
+}
+
+func TestSuggestNamesAgreesWithSuggestName(t *testing.T) {
+	names := []string{"migrate", "m", "migration-status", "mirror", "help", "h"}
+	for _, provided := range []string{"migrat", "migrtae", "miror", "hlp", "mig"} {
+		suggestions := suggestNames(names, provided)
+		if assert.NotEmpty(t, suggestions, provided) {
+			assert.Equal(t, suggestName(names, provided), suggestions[0], provided)
+		}
+	}
+}
+
 func TestSuggestCommand(t *testing.T) {
 	// Given
 	app := buildExtendedTestCommand()
@@ -150,3 +248,113 @@ func TestSuggestCommand(t *testing.T) {
 		assert.Equal(t, testCase.expected, res)
 	}
 }
+
+func TestShorthandCommand(t *testing.T) {
+	af := func(p *int) ActionFunc {
+		return func(context.Context, *Command) error {
+			*p = *p + 1
+			return nil
+		}
+	}
+
+	var cmd1, cmd2 int
+
+	cmd := &Command{
+		PrefixMatchCommands: true,
+		Commands: []*Command{
+			{
+				Name:    "cthdisd",
+				Aliases: []string{"cth"},
+				Action:  af(&cmd1),
+			},
+			{
+				Name:    "cthertoop",
+				Aliases: []string{"cer"},
+				Action:  af(&cmd2),
+			},
+		},
+	}
+
+	err := cmd.Run(buildTestContext(t), []string{"foo", "cth"})
+	assert.NoError(t, err)
+	assert.True(t, cmd1 == 1 && cmd2 == 0, "Expected command1 to be trigerred once")
+
+	cmd1 = 0
+	cmd2 = 0
+
+	err = cmd.Run(buildTestContext(t), []string{"foo", "cthd"})
+	assert.NoError(t, err)
+	assert.True(t, cmd1 == 1 && cmd2 == 0, "Expected command1 to be trigerred once")
+
+	cmd1 = 0
+	cmd2 = 0
+
+	err = cmd.Run(buildTestContext(t), []string{"foo", "cthe"})
+	assert.NoError(t, err)
+	assert.True(t, cmd1 == 1 && cmd2 == 0, "Expected command1 to be trigerred once")
+
+	cmd1 = 0
+	cmd2 = 0
+
+	err = cmd.Run(buildTestContext(t), []string{"foo", "cthert"})
+	assert.NoError(t, err)
+	assert.True(t, cmd1 == 0 && cmd2 == 1, "Expected command1 to be trigerred once")
+
+	cmd1 = 0
+	cmd2 = 0
+
+	err = cmd.Run(buildTestContext(t), []string{"foo", "cthet"})
+	assert.NoError(t, err)
+	assert.True(t, cmd1 == 0 && cmd2 == 1, "Expected command1 to be trigerred once")
+}
+
+func TestCommandSuggestions(t *testing.T) {
+	cmd := &Command{
+		Name:    "app",
+		Suggest: true,
+		Flags: []Flag{
+			&StringFlag{Name: "config", Aliases: []string{"c"}},
+			&StringFlag{Name: "token", Hidden: true},
+		},
+		Commands: []*Command{
+			{Name: "migrate", Aliases: []string{"m"}},
+			{Name: "migration-status"},
+			{Name: "mirror"},
+			{Name: "secret", Hidden: true},
+		},
+	}
+	cmd.setupDefaults([]string{"app"})
+
+	assert.Equal(t, []string{"migrate", "migration-status"}, cmd.CommandSuggestions("migrat"))
+	assert.Equal(t, []string{"migrate"}, cmd.CommandSuggestions("migrtae"))
+	assert.Equal(t, []string{"mirror"}, cmd.CommandSuggestions("miror"))
+	assert.Empty(t, cmd.CommandSuggestions("secrt"))
+	assert.Empty(t, cmd.CommandSuggestions("deploy"))
+
+	assert.Equal(t, []string{"--config"}, cmd.FlagSuggestions("confg"))
+	assert.Equal(t, []string{"--help"}, cmd.FlagSuggestions("hlp"))
+	assert.Empty(t, cmd.FlagSuggestions("tokn"))
+}
+
+func TestCommandNotFoundSuggestions(t *testing.T) {
+	cmd := &Command{
+		Name:      "app",
+		Suggest:   true,
+		Writer:    &bytes.Buffer{},
+		ErrWriter: &bytes.Buffer{},
+		Commands: []*Command{
+			{Name: "migrate", Action: func(context.Context, *Command) error { return nil }},
+		},
+	}
+
+	err := cmd.Run(buildTestContext(t), []string{"app", "migrat"})
+	assert.EqualError(t, err, `No help topic for 'migrat'. Did you mean "migrate"?`)
+
+	var suggestions []string
+	cmd.CommandNotFound = func(_ context.Context, cmd *Command, name string) {
+		suggestions = cmd.CommandSuggestions(name)
+	}
+
+	assert.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "migrat"}))
+	assert.Equal(t, []string{"migrate"}, suggestions)
+}
@@ -0,0 +1,133 @@
+//go:build !urfave_cli_no_docs
+
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ToTabularMarkdown renders the command tree as a single Markdown document
+// documenting the flags of every visible command in tables, e.g. for the
+// README of a project. The appPath is the program name shown in the usage,
+// the name of the command if empty.
+func (cmd *Command) ToTabularMarkdown(appPath string) (string, error) {
+	var b strings.Builder
+	if err := cmd.ToTabularToWriter(appPath, &b); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// ToTabularToWriter writes the document of ToTabularMarkdown to the writer
+// while rendering it, so large command trees are streamed without building
+// the whole document in memory.
+func (cmd *Command) ToTabularToWriter(appPath string, w io.Writer) error {
+	cmd.loadCommands()
+
+	if appPath == "" {
+		appPath = cmd.Name
+	}
+
+	b := bufio.NewWriter(w)
+	root := newDocsPage(cmd, nil, 1)
+
+	fmt.Fprintf(b, "## CLI interface - %s\n\n", cmd.Name)
+	if cmd.Usage != "" {
+		fmt.Fprintf(b, "%s\n\n", cmd.Usage)
+	}
+	if cmd.Description != "" {
+		fmt.Fprintf(b, "%s\n\n", strings.TrimSpace(cmd.Description))
+	}
+
+	b.WriteString("Usage:\n\n```bash\n$ " + appPath)
+	if len(cmd.VisibleFlags()) > 0 {
+		b.WriteString(" [GLOBAL FLAGS]")
+	}
+	if len(root.children) > 0 {
+		b.WriteString(" [COMMAND] [COMMAND FLAGS]")
+	}
+	b.WriteString(" [ARGUMENTS...]\n```\n")
+
+	if flags := cmd.VisibleFlags(); len(flags) > 0 {
+		b.WriteString("\nGlobal flags:\n\n")
+		writeTabularFlags(b, flags)
+	}
+
+	var write func(p *docsPage)
+	write = func(p *docsPage) {
+		for _, child := range p.children {
+			writeTabularCommand(b, appPath, child)
+			write(child)
+		}
+	}
+	write(root)
+
+	return b.Flush()
+}
+
+// writeTabularCommand writes the section of a subcommand
+func writeTabularCommand(b *bufio.Writer, appPath string, p *docsPage) {
+	cmd := p.cmd
+	names := strings.Join(p.names[1:], " ")
+
+	fmt.Fprintf(b, "\n### `%s` command", names)
+	if len(cmd.Aliases) > 0 {
+		fmt.Fprintf(b, " (aliases: `%s`)", strings.Join(cmd.Aliases, "`, `"))
+	}
+	b.WriteString("\n\n")
+
+	if cmd.Usage != "" {
+		fmt.Fprintf(b, "%s\n\n", cmd.Usage)
+	}
+	if cmd.Description != "" {
+		fmt.Fprintf(b, "%s\n\n", strings.TrimSpace(cmd.Description))
+	}
+
+	fmt.Fprintf(b, "Usage:\n\n```bash\n$ %s [GLOBAL FLAGS] %s", appPath, names)
+	if len(cmd.VisibleFlags()) > 0 {
+		b.WriteString(" [COMMAND FLAGS]")
+	}
+	b.WriteString(" [ARGUMENTS...]\n```\n")
+
+	if flags := cmd.VisibleFlags(); len(flags) > 0 {
+		b.WriteString("\nThe following flags are supported:\n\n")
+		writeTabularFlags(b, flags)
+	}
+}
+
+// writeTabularFlags writes the table of the flags
+func writeTabularFlags(b *bufio.Writer, flags []Flag) {
+	b.WriteString("| Name | Description | Default value | Environment variables |\n")
+	b.WriteString("|------|-------------|:-------------:|:---------------------:|\n")
+
+	for _, fl := range flags {
+		name, usage, defaultValue, envVars := "`"+prefixedNames(flagDocNames(fl), "")+"`", "", "", "none"
+
+		if df, ok := fl.(DocGenerationFlag); ok {
+			placeholder, u := unquoteUsage(df.GetUsage())
+			if df.TakesValue() {
+				if placeholder == "" {
+					placeholder = defaultPlaceholder
+				}
+				name = "`" + prefixedNames(flagDocNames(fl), placeholder) + "`"
+			}
+			usage = u
+			if s := df.GetDefaultText(); s != "" {
+				defaultValue = "`" + s + "`"
+			}
+			if vars := df.GetEnvVars(); len(vars) > 0 {
+				envVars = "`" + strings.Join(vars, "`, `") + "`"
+			}
+		}
+
+		fmt.Fprintf(b, "| %s | %s | %s | %s |\n", tabularEscape(name), tabularEscape(usage), tabularEscape(defaultValue), envVars)
+	}
+}
+
+// tabularEscape escapes the pipes and line breaks ending table cells
+func tabularEscape(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+}
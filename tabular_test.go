@@ -0,0 +1,40 @@
+//go:build !urfave_cli_no_docs
+
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToTabularMarkdown(t *testing.T) {
+	cmd := buildExtendedTestCommand()
+
+	res, err := cmd.ToTabularMarkdown("app")
+
+	require.NoError(t, err)
+	expectFileContent(t, "testdata/expected-tabular-full.md", res)
+}
+
+func TestToTabularToWriter(t *testing.T) {
+	cmd := &Command{
+		Name: "app",
+		Flags: []Flag{
+			&StringFlag{Name: "format", Usage: "json | text", Value: "json", Sources: EnvVars("APP_FORMAT")},
+		},
+		Commands: []*Command{
+			{Name: "serve", Aliases: []string{"s"}, Flags: []Flag{&IntFlag{Name: "port", Value: 80}}},
+		},
+	}
+
+	var b strings.Builder
+	require.NoError(t, cmd.ToTabularToWriter("./bin/app", &b))
+
+	assert.Contains(t, b.String(), "$ ./bin/app [GLOBAL FLAGS] [COMMAND] [COMMAND FLAGS] [ARGUMENTS...]")
+	assert.Contains(t, b.String(), "| `--format value` | json \\| text | `\"json\"` | `APP_FORMAT` |\n")
+	assert.Contains(t, b.String(), "### `serve` command (aliases: `s`)")
+	assert.Contains(t, b.String(), "| `--port value` |  | `80` | none |\n")
+}
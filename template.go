@@ -1,9 +1,9 @@
 package cli
 
 var (
-	helpNameTemplate    = `{{$v := offset .FullName 6}}{{wrap .FullName 3}}{{if .Usage}} - {{wrap .Usage $v}}{{end}}`
-	argsTemplate        = `{{if .Arguments}}{{range .Arguments}}{{.Usage}}{{end}}{{end}}`
-	usageTemplate       = `{{if .UsageText}}{{wrap .UsageText 3}}{{else}}{{.FullName}}{{if .VisibleFlags}} [command [command options]]{{end}} {{if .ArgsUsage}}{{.ArgsUsage}}{{else}}{{template "argsTemplate" .}}{{end}}{{end}}`
+	helpNameTemplate    = `{{$v := offset .FullName 6}}{{styleCommand (wrap .FullName 3)}}{{if .Usage}} - {{wrap .Usage $v}}{{end}}{{if .Deprecated}} (DEPRECATED){{end}}`
+	argsTemplate        = `{{range $i, $e := .Arguments}}{{if $i}} {{end}}{{$e.Usage}}{{end}}`
+	usageTemplate       = `{{if .UsageText}}{{wrap .UsageText 3}}{{else}}{{.FullName}}{{if .VisibleFlags}} [command [command options]]{{end}} {{if .ArgsUsage}}{{.ArgsUsage}}{{else}}{{template "argsTemplate" .}}{{end}}{{if .EnablePassthroughArgs}} [-- ARGS...]{{end}}{{end}}`
 	descriptionTemplate = `{{wrap .Description 3}}`
 	authorsTemplate     = `{{with $length := len .Authors}}{{if ne 1 $length}}S{{end}}{{end}}:
    {{range $index, $author := .Authors}}{{if $index}}
@@ -11,98 +11,149 @@ var (
 )
 
 var visibleCommandTemplate = `{{ $cv := offsetCommands .VisibleCommands 5}}{{range .VisibleCommands}}
-   {{$s := join .Names ", "}}{{$s}}{{ $sp := subtract $cv (offset $s 3) }}{{ indent $sp ""}}{{wrap .Usage $cv}}{{end}}`
+   {{$s := join .Names ", "}}{{styleCommand $s}}{{ $sp := subtract $cv (offset $s 3) }}{{ indent $sp ""}}{{wrap .Usage $cv}}{{if .Deprecated}} (DEPRECATED){{end}}{{end}}`
 
 var visibleCommandCategoryTemplate = `{{range .VisibleCategories}}{{if .Name}}
 
-   {{.Name}}:{{range .VisibleCommands}}
-     {{join .Names ", "}}{{"\t"}}{{.Usage}}{{end}}{{else}}{{template "visibleCommandTemplate" .}}{{end}}{{end}}`
+   {{heading (printf "%s:" .Name)}}{{if .Description}}
+     {{wrap .Description 5}}
+{{end}}{{range .VisibleCommands}}
+     {{styleCommand (join .Names ", ")}}{{"\t"}}{{.Usage}}{{if .Deprecated}} (DEPRECATED){{end}}{{end}}{{else}}{{template "visibleCommandTemplate" .}}{{end}}{{end}}`
 
 var visibleFlagCategoryTemplate = `{{range .VisibleFlagCategories}}
-   {{if .Name}}{{.Name}}
+   {{if .Name}}{{heading .Name}}
 
-   {{end}}{{$flglen := len .Flags}}{{range $i, $e := .Flags}}{{if eq (subtract $flglen $i) 1}}{{$e}}
-{{else}}{{$e}}
+   {{end}}{{$flglen := len .Flags}}{{range $i, $e := .Flags}}{{if eq (subtract $flglen $i) 1}}{{wrapFlag (styleFlag $e)}}
+{{else}}{{wrapFlag (styleFlag $e)}}
    {{end}}{{end}}{{end}}`
 
 var visibleFlagTemplate = `{{range $i, $e := .VisibleFlags}}
-   {{wrap $e.String 6}}{{end}}`
+   {{wrap (styleFlag $e) 6}}{{end}}`
+
+var visiblePersistentFlagTemplate = `{{range $i, $e := .VisiblePersistentFlags}}
+   {{wrap (styleFlag $e) 6}}{{end}}`
 
 var versionTemplate = `{{if .Version}}{{if not .HideVersion}}
 
-VERSION:
+{{heading "VERSION:"}}
    {{.Version}}{{end}}{{end}}`
 
+var argumentsTemplate = `{{range .DescribedArguments}}
+   {{.GetName}}{{"\t"}}{{.GetDescription}}{{end}}`
+
 var copyrightTemplate = `{{wrap .Copyright 3}}`
 
+var exitStatusTemplate = `{{range .VisibleExitCodes}}
+   {{.Code}}{{"\t"}}{{.Description}}{{end}}`
+
+var examplesTemplate = `{{range $i, $e := .Examples}}{{if $i}}
+{{end}}{{if .Description}}
+   {{wrap .Description 3}}{{end}}
+   $ {{.Command}}{{if trim .Output}}{{nindent 3 (trim .Output)}}{{end}}{{end}}`
+
+var seeAlsoTemplate = `{{range .SeeAlso}}
+   {{wrap . 3}}{{end}}`
+
 // RootCommandHelpTemplate is the text template for the Default help topic.
 // cli.go uses text/template to render templates. You can
 // render custom help text by setting this variable.
-var RootCommandHelpTemplate = `NAME:
+var RootCommandHelpTemplate = `{{heading "NAME:"}}
    {{template "helpNameTemplate" .}}
 
-USAGE:
-   {{if .UsageText}}{{wrap .UsageText 3}}{{else}}{{.FullName}} {{if .VisibleFlags}}[global options]{{end}}{{if .VisibleCommands}} [command [command options]]{{end}} {{if .ArgsUsage}}{{.ArgsUsage}}{{else}}[arguments...]{{end}}{{end}}{{if .Version}}{{if not .HideVersion}}
+{{heading "USAGE:"}}
+   {{if .UsageText}}{{wrap .UsageText 3}}{{else}}{{.FullName}} {{if .VisibleFlags}}[global options]{{end}}{{if .VisibleCommands}} [command [command options]]{{end}} {{if .ArgsUsage}}{{.ArgsUsage}}{{else if .Arguments}}{{template "argsTemplate" .}}{{else}}[arguments...]{{end}}{{if .EnablePassthroughArgs}} [-- ARGS...]{{end}}{{end}}{{if .Version}}{{if not .HideVersion}}
 
-VERSION:
+{{heading "VERSION:"}}
    {{.Version}}{{end}}{{end}}{{if .Description}}
 
-DESCRIPTION:
-   {{template "descriptionTemplate" .}}{{end}}
+{{heading "DESCRIPTION:"}}
+   {{template "descriptionTemplate" .}}{{end}}{{if .DescribedArguments}}
+
+{{heading "ARGUMENTS:"}}{{template "argumentsTemplate" .}}{{end}}
 {{- if len .Authors}}
 
-AUTHOR{{template "authorsTemplate" .}}{{end}}{{if .VisibleCommands}}
+{{if eq (len .Authors) 1}}{{heading "AUTHOR:"}}{{else}}{{heading "AUTHORS:"}}{{end}}
+   {{range $index, $author := .Authors}}{{if $index}}
+   {{end}}{{$author}}{{end}}{{end}}{{if .VisibleCommands}}
+
+{{heading "COMMANDS:"}}{{template "visibleCommandCategoryTemplate" .}}{{end}}{{if .VisibleFlagCategories}}
+
+{{heading "GLOBAL OPTIONS:"}}{{template "visibleFlagCategoryTemplate" .}}{{else if .VisibleFlags}}
+
+{{heading "GLOBAL OPTIONS:"}}{{template "visibleFlagTemplate" .}}{{end}}{{if .Examples}}
 
-COMMANDS:{{template "visibleCommandCategoryTemplate" .}}{{end}}{{if .VisibleFlagCategories}}
+{{heading "EXAMPLES:"}}{{template "examplesTemplate" .}}{{end}}{{if .VisibleExitCodes}}
 
-GLOBAL OPTIONS:{{template "visibleFlagCategoryTemplate" .}}{{else if .VisibleFlags}}
+{{heading "EXIT STATUS:"}}{{template "exitStatusTemplate" .}}{{end}}{{if .Copyright}}
 
-GLOBAL OPTIONS:{{template "visibleFlagTemplate" .}}{{end}}{{if .Copyright}}
+{{heading "COPYRIGHT:"}}
+   {{template "copyrightTemplate" .}}{{end}}{{if .SeeAlso}}
 
-COPYRIGHT:
-   {{template "copyrightTemplate" .}}{{end}}
+{{heading "SEE ALSO:"}}{{template "seeAlsoTemplate" .}}{{end}}
 `
 
 // CommandHelpTemplate is the text template for the command help topic.
 // cli.go uses text/template to render templates. You can
 // render custom help text by setting this variable.
-var CommandHelpTemplate = `NAME:
+var CommandHelpTemplate = `{{heading "NAME:"}}
    {{template "helpNameTemplate" .}}
 
-USAGE:
+{{heading "USAGE:"}}
    {{template "usageTemplate" .}}{{if .Category}}
 
-CATEGORY:
-   {{.Category}}{{end}}{{if .Description}}
+{{heading "CATEGORY:"}}
+   {{.Category}}{{end}}{{if .Since}}
 
-DESCRIPTION:
-   {{template "descriptionTemplate" .}}{{end}}{{if .VisibleFlagCategories}}
+{{heading "SINCE:"}}
+   {{.Since}}{{end}}{{if .Description}}
 
-OPTIONS:{{template "visibleFlagCategoryTemplate" .}}{{else if .VisibleFlags}}
+{{heading "DESCRIPTION:"}}
+   {{template "descriptionTemplate" .}}{{end}}{{if .DescribedArguments}}
 
-OPTIONS:{{template "visibleFlagTemplate" .}}{{end}}
+{{heading "ARGUMENTS:"}}{{template "argumentsTemplate" .}}{{end}}{{if .VisibleFlagCategories}}
+
+{{heading "OPTIONS:"}}{{template "visibleFlagCategoryTemplate" .}}{{else if .VisibleFlags}}
+
+{{heading "OPTIONS:"}}{{template "visibleFlagTemplate" .}}{{end}}{{if .VisiblePersistentFlags}}
+
+{{heading "GLOBAL OPTIONS:"}}{{template "visiblePersistentFlagTemplate" .}}{{end}}{{if .Examples}}
+
+{{heading "EXAMPLES:"}}{{template "examplesTemplate" .}}{{end}}{{if .SeeAlso}}
+
+{{heading "SEE ALSO:"}}{{template "seeAlsoTemplate" .}}{{end}}
 `
 
 // SubcommandHelpTemplate is the text template for the subcommand help topic.
 // cli.go uses text/template to render templates. You can
 // render custom help text by setting this variable.
-var SubcommandHelpTemplate = `NAME:
+var SubcommandHelpTemplate = `{{heading "NAME:"}}
    {{template "helpNameTemplate" .}}
 
-USAGE:
-   {{if .UsageText}}{{wrap .UsageText 3}}{{else}}{{.FullName}} {{if .VisibleCommands}}[command [command options]] {{end}}{{if .ArgsUsage}}{{.ArgsUsage}}{{else}}[arguments...]{{end}}{{end}}{{if .Category}}
+{{heading "USAGE:"}}
+   {{if .UsageText}}{{wrap .UsageText 3}}{{else}}{{.FullName}} {{if .VisibleCommands}}[command [command options]] {{end}}{{if .ArgsUsage}}{{.ArgsUsage}}{{else if .Arguments}}{{template "argsTemplate" .}}{{else}}[arguments...]{{end}}{{if .EnablePassthroughArgs}} [-- ARGS...]{{end}}{{end}}{{if .Category}}
+
+{{heading "CATEGORY:"}}
+   {{.Category}}{{end}}{{if .Since}}
+
+{{heading "SINCE:"}}
+   {{.Since}}{{end}}{{if .Description}}
+
+{{heading "DESCRIPTION:"}}
+   {{template "descriptionTemplate" .}}{{end}}{{if .DescribedArguments}}
+
+{{heading "ARGUMENTS:"}}{{template "argumentsTemplate" .}}{{end}}{{if .VisibleCommands}}
+
+{{heading "COMMANDS:"}}{{template "visibleCommandTemplate" .}}{{end}}{{if .VisibleFlagCategories}}
 
-CATEGORY:
-   {{.Category}}{{end}}{{if .Description}}
+{{heading "OPTIONS:"}}{{template "visibleFlagCategoryTemplate" .}}{{else if .VisibleFlags}}
 
-DESCRIPTION:
-   {{template "descriptionTemplate" .}}{{end}}{{if .VisibleCommands}}
+{{heading "OPTIONS:"}}{{template "visibleFlagTemplate" .}}{{end}}{{if .VisiblePersistentFlags}}
 
-COMMANDS:{{template "visibleCommandTemplate" .}}{{end}}{{if .VisibleFlagCategories}}
+{{heading "GLOBAL OPTIONS:"}}{{template "visiblePersistentFlagTemplate" .}}{{end}}{{if .Examples}}
 
-OPTIONS:{{template "visibleFlagCategoryTemplate" .}}{{else if .VisibleFlags}}
+{{heading "EXAMPLES:"}}{{template "examplesTemplate" .}}{{end}}{{if .SeeAlso}}
 
-OPTIONS:{{template "visibleFlagTemplate" .}}{{end}}
+{{heading "SEE ALSO:"}}{{template "seeAlsoTemplate" .}}{{end}}
 `
 
 var FishCompletionTemplate = `# {{ .Command.Name }} fish shell completion
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// cachedTemplate returns a copy of the template parsed from the given text
+// with the given functions. Templates are parsed on first use and cached on
+// the root command, so showing help repeatedly, e.g. in the interactive
+// shell, doesn't parse the same templates again. Overriding the template
+// variables keeps working since templates are cached by their text.
+func (cmd *Command) cachedTemplate(text string, funcMap template.FuncMap, parse func() (*template.Template, error)) (*template.Template, error) {
+	root := cmd.Root()
+	key := templateCacheKey(text, funcMap)
+
+	root.templatesMu.Lock()
+	defer root.templatesMu.Unlock()
+
+	t, ok := root.templates[key]
+	if !ok {
+		tracef("parsing template for the cache (cmd=%[1]q)", root.Name)
+
+		var err error
+		if t, err = parse(); err != nil {
+			return nil, err
+		}
+
+		if root.templates == nil {
+			root.templates = map[string]*template.Template{}
+		}
+		root.templates[key] = t
+	}
+
+	// the functions may be closures over the data of a single call
+	clone, err := t.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	return clone.Funcs(funcMap), nil
+}
+
+// templateCacheKey returns the key of the template text parsed with
+// functions of the given names, templates using custom functions can only
+// be parsed if they are defined
+func templateCacheKey(text string, funcMap template.FuncMap) string {
+	names := make([]string, 0, len(funcMap))
+	for name := range funcMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ",") + "\x00" + text
+}
@@ -0,0 +1,37 @@
+package cli
+
+import "text/template"
+
+// AddTemplateFunc registers a function usable in the templates rendering
+// the help of the command and its subcommands, like CommandHelpTemplate or
+// a CustomHelpTemplate, and the documents generated from templates, like
+// ReStructuredTextDocTemplate and FishCompletionTemplate, e.g.
+//
+//	cmd.AddTemplateFunc("upper", strings.ToUpper)
+//
+// The function has to return one value, or two with the second being an
+// error, like the functions of text/template. It takes precedence over a
+// built-in function of the same name and over one registered on an
+// ancestor.
+func (cmd *Command) AddTemplateFunc(name string, fn any) {
+	if cmd.templateFuncs == nil {
+		cmd.templateFuncs = template.FuncMap{}
+	}
+
+	cmd.templateFuncs[name] = fn
+}
+
+// lineageTemplateFuncs returns the template functions registered on the
+// command and its ancestors, the ones of the command taking precedence
+func (cmd *Command) lineageTemplateFuncs() template.FuncMap {
+	funcs := template.FuncMap{}
+
+	lineage := cmd.Lineage()
+	for i := len(lineage) - 1; i >= 0; i-- {
+		for name, fn := range lineage[i].templateFuncs {
+			funcs[name] = fn
+		}
+	}
+
+	return funcs
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddTemplateFunc(t *testing.T) {
+	out := &bytes.Buffer{}
+	cmd := &Command{
+		Name:                          "app",
+		Usage:                         "does things",
+		Writer:                        out,
+		CustomRootCommandHelpTemplate: "{{upper .Name}}: {{quote .Usage}}\n",
+		Commands: []*Command{{
+			Name:               "serve",
+			Usage:              "serves things",
+			CustomHelpTemplate: "{{upper .Name}}: {{quote .Usage}}\n",
+		}},
+	}
+	cmd.AddTemplateFunc("upper", strings.ToUpper)
+	cmd.AddTemplateFunc("quote", func(s string) string { return `"` + s + `"` })
+	cmd.Commands[0].AddTemplateFunc("quote", func(s string) string { return "'" + s + "'" })
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--help"}))
+	assert.Equal(t, "APP: \"does things\"\n", out.String())
+
+	out.Reset()
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "serve", "--help"}))
+	assert.Equal(t, "SERVE: 'serves things'\n", out.String(), "the functions of subcommands take precedence")
+}
+
+func TestAddTemplateFuncOverride(t *testing.T) {
+	out := &bytes.Buffer{}
+	cmd := &Command{
+		Name:     "app",
+		Usage:    "does things",
+		Writer:   out,
+		HideHelp: true,
+	}
+	cmd.AddTemplateFunc("heading", strings.ToLower)
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+	assert.True(t, strings.HasPrefix(out.String(), "name:\n   app - does things\n\nusage:\n"), out.String())
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"os"
+	"strconv"
+)
+
+// Terminal describes the terminal a command interacts with. It allows to
+// fake an interactive terminal or a pipe in tests.
+type Terminal interface {
+	// IsTTY reports whether the input is an interactive terminal
+	IsTTY() bool
+	// Width returns the number of columns of the output, or 0 if unknown
+	Width() int
+	// ColorSupported reports whether the output may be colorized
+	ColorSupported() bool
+}
+
+// isTerminal reports whether the reader or writer is a terminal. It is a
+// variable to allow faking interactive use in tests.
+var isTerminal = func(v any) bool {
+	f, ok := v.(*os.File)
+	if !ok || !runtimeHasTerminal {
+		return false
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// osTerminal is the default Terminal inspecting the Reader and Writer of
+// the command and the environment
+type osTerminal struct {
+	cmd *Command
+}
+
+func (t osTerminal) IsTTY() bool {
+	return isTerminal(t.cmd.Reader)
+}
+
+func (t osTerminal) Width() int {
+	env, _ := t.cmd.LookupEnv("COLUMNS")
+	if columns, err := strconv.Atoi(env); err == nil && columns > 0 {
+		return columns
+	}
+
+	if f, ok := t.cmd.Writer.(*os.File); ok && isTerminal(f) {
+		return terminalWidth(f.Fd())
+	}
+
+	return 0
+}
+
+func (t osTerminal) ColorSupported() bool {
+	if _, ok := t.cmd.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+
+	if term, _ := t.cmd.LookupEnv("TERM"); term == "dumb" {
+		return false
+	}
+
+	return isTerminal(t.cmd.Writer)
+}
+
+// terminal returns the Terminal of the root command, defaulting to the one
+// of the operating system
+func (cmd *Command) terminal() Terminal {
+	root := cmd.Root()
+	if root.Terminal != nil {
+		return root.Terminal
+	}
+
+	return osTerminal{cmd: root}
+}
+
+// IsTTY reports whether the command runs in an interactive terminal
+// according to the Terminal of the root command
+func (cmd *Command) IsTTY() bool {
+	return cmd.terminal().IsTTY()
+}
+
+// TerminalWidth returns the number of columns of the terminal according to
+// the Terminal of the root command, or 0 if unknown
+func (cmd *Command) TerminalWidth() int {
+	return cmd.terminal().Width()
+}
+
+// ColorSupported reports whether the output may be colorized according to
+// the Terminal of the root command
+func (cmd *Command) ColorSupported() bool {
+	return cmd.terminal().ColorSupported()
+}
@@ -0,0 +1,7 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package cli
+
+func terminalWidth(uintptr) int {
+	return 0
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testTerminal struct {
+	tty bool
+}
+
+func (t testTerminal) IsTTY() bool          { return t.tty }
+func (t testTerminal) Width() int           { return 100 }
+func (t testTerminal) ColorSupported() bool { return t.tty }
+
+func TestOSTerminal(t *testing.T) {
+	cmd := &Command{Reader: &bytes.Buffer{}, Writer: &bytes.Buffer{}}
+
+	t.Setenv("COLUMNS", "120")
+	assert.False(t, cmd.IsTTY())
+	assert.Equal(t, 120, cmd.TerminalWidth())
+	assert.False(t, cmd.ColorSupported())
+
+	t.Setenv("COLUMNS", "")
+	assert.Equal(t, 0, cmd.TerminalWidth())
+
+	orig := isTerminal
+	isTerminal = func(any) bool { return true }
+	t.Cleanup(func() { isTerminal = orig })
+
+	assert.True(t, cmd.IsTTY())
+	t.Setenv("TERM", "xterm")
+	assert.True(t, cmd.ColorSupported())
+	t.Setenv("NO_COLOR", "1")
+	assert.False(t, cmd.ColorSupported())
+}
+
+func TestCommandTerminal(t *testing.T) {
+	cmd := &Command{
+		Name:     "app",
+		Terminal: testTerminal{tty: true},
+		Commands: []*Command{{Name: "sub"}},
+	}
+	cmd.Commands[0].parent = cmd
+
+	assert.True(t, cmd.Commands[0].IsTTY())
+	assert.Equal(t, 100, cmd.Commands[0].TerminalWidth())
+	assert.True(t, cmd.Commands[0].ColorSupported())
+}
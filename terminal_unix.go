@@ -0,0 +1,22 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package cli
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// terminalWidth returns the number of columns of the terminal with the
+// given file descriptor, or 0 if unknown
+func terminalWidth(fd uintptr) int {
+	var ws struct {
+		Row, Col, Xpixel, Ypixel uint16
+	}
+
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&ws))); errno != 0 {
+		return 0
+	}
+
+	return int(ws.Col)
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Theme styles the help output and the error messages of a command when
+// its output supports colors, see Command.ColorSupported. The styles are
+// SGR parameters like "1;36" for bold cyan, an empty style leaves the text
+// unstyled.
+type Theme struct {
+	// Style of the headings like USAGE: and of the category names
+	Heading string
+	// Style of the command names
+	Command string
+	// Style of the flag names
+	Flag string
+	// Style of the default values of flags
+	Default string
+	// Style of the error messages
+	Error string
+}
+
+var (
+	// DefaultTheme is a colorful theme for terminals with dark or light
+	// backgrounds
+	DefaultTheme = &Theme{
+		Heading: "1",
+		Command: "36",
+		Flag:    "32",
+		Default: "2",
+		Error:   "31",
+	}
+
+	// MonochromeTheme is a theme using bold and faint text only
+	MonochromeTheme = &Theme{
+		Heading: "1",
+		Command: "1",
+		Flag:    "1",
+		Default: "2",
+		Error:   "1",
+	}
+)
+
+// style wraps the text in the escape sequences of the style. The empty
+// text is wrapped as well, so cells of the help output consisting of it
+// are as wide as the styled ones.
+func (t *Theme) style(style, s string) string {
+	if style == "" {
+		return s
+	}
+
+	return "\x1b[" + style + "m" + s + "\x1b[0m"
+}
+
+// styleFlag returns the help text of the flag with its names and default
+// value styled
+func (t *Theme) styleFlag(f Flag) string {
+	s := f.String()
+
+	names, usage, found := strings.Cut(s, "\t")
+	if !found {
+		return s + flagSourceHint(f)
+	}
+
+	if df, ok := f.(DocGenerationFlag); ok && t.Default != "" {
+		if text := df.GetDefaultText(); text != "" {
+			defaultValue := strings.TrimSpace(fmt.Sprintf(formatDefault("%s"), text))
+			if i := strings.LastIndex(usage, defaultValue); i >= 0 {
+				usage = usage[:i] + t.style(t.Default, defaultValue) + usage[i+len(defaultValue):]
+			}
+		}
+	}
+
+	return t.style(t.Flag, names) + "\t" + usage + flagSourceHint(f)
+}
+
+// theme returns the Theme of the root command if the output supports
+// colors, nil otherwise
+func (cmd *Command) theme() *Theme {
+	theme := cmd.Root().Theme
+	if theme == nil || !cmd.ColorSupported() {
+		return nil
+	}
+
+	return theme
+}
+
+// styleError styles the error message with the theme, if any
+func (cmd *Command) styleError(s string) string {
+	theme := cmd.theme()
+	if theme == nil {
+		return s
+	}
+
+	return theme.style(theme.Error, s)
+}
+
+// themedErrorFormatter returns the errorFormatter of the command styling
+// the messages with the theme, if any
+func (cmd *Command) themedErrorFormatter() func(error) string {
+	format := cmd.errorFormatter()
+	if theme := cmd.theme(); theme == nil || theme.Error == "" {
+		return format
+	}
+
+	return func(err error) string {
+		if format != nil {
+			return cmd.styleError(format(err))
+		}
+		if _, ok := err.(ErrorFormatter); ok {
+			return cmd.styleError(fmt.Sprintf("%+v", err))
+		}
+		return cmd.styleError(errorText(err))
+	}
+}
+
+// displayWidth returns the width of the text without the escape sequences
+// of styles
+func displayWidth(s string) int {
+	width := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '[' {
+			if end := strings.IndexByte(s[i:], 'm'); end >= 0 {
+				i += end
+				continue
+			}
+		}
+		width++
+	}
+	return width
+}
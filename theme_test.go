@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildThemeTestCommand(out *bytes.Buffer, env MapEnv) *Command {
+	return &Command{
+		Name:      "app",
+		Usage:     "does things",
+		Theme:     DefaultTheme,
+		Env:       env,
+		Writer:    out,
+		ErrWriter: out,
+		Flags: []Flag{
+			&StringFlag{Name: "config", Aliases: []string{"c"}, Usage: "load the configuration from the given file", Value: "app.toml"},
+			&BoolFlag{Name: "verbose", Category: "output", Usage: "print the requests and responses to the standard error"},
+		},
+		Commands: []*Command{{
+			Name:   "serve",
+			Usage:  "serve the app",
+			Action: func(context.Context, *Command) error { return Exit("unable to listen", 3) },
+		}},
+	}
+}
+
+func TestThemeHelp(t *testing.T) {
+	fakeTerminal(t)
+
+	out := &bytes.Buffer{}
+	cmd := buildThemeTestCommand(out, MapEnv{})
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--help"}))
+
+	assert.Contains(t, out.String(), "\x1b[1mUSAGE:\x1b[0m\n")
+	assert.Contains(t, out.String(), "\x1b[36mapp\x1b[0m - does things")
+	assert.Contains(t, out.String(), "\x1b[36mserve\x1b[0m    serve the app")
+	assert.Contains(t, out.String(), "\x1b[32m--config value, -c value\x1b[0m  load the configuration from the given file \x1b[2m(default: \"app.toml\")\x1b[0m")
+	assert.Contains(t, out.String(), "\x1b[1moutput\x1b[0m")
+
+	t.Run("no color", func(t *testing.T) {
+		plain := &bytes.Buffer{}
+		cmd := buildThemeTestCommand(plain, MapEnv{"NO_COLOR": "1"})
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--help"}))
+		assert.NotContains(t, plain.String(), "\x1b[")
+		assert.Equal(t, regexp.MustCompile("\x1b\\[[0-9;]*m").ReplaceAllString(out.String(), ""), plain.String())
+	})
+}
+
+func TestThemeHelpWrapped(t *testing.T) {
+	fakeTerminal(t)
+
+	out := &bytes.Buffer{}
+	cmd := buildThemeTestCommand(out, MapEnv{"COLUMNS": "60"})
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--help"}))
+
+	plain := &bytes.Buffer{}
+	cmd = buildThemeTestCommand(plain, MapEnv{"COLUMNS": "60"})
+	cmd.Theme = nil
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--help"}))
+
+	assert.Equal(t, plain.String(), regexp.MustCompile("\x1b\\[[0-9;]*m").ReplaceAllString(out.String(), ""), "the styles keep the columns aligned")
+	assert.Contains(t, plain.String(), `   --config value, -c value  load the configuration from the
+                             given file (default:
+                             "app.toml")
+`)
+}
+
+func TestThemeErrors(t *testing.T) {
+	fakeTerminal(t)
+
+	out := &bytes.Buffer{}
+	cmd := buildThemeTestCommand(out, MapEnv{})
+	cmd.Exiter = ExiterFunc(func(int) {})
+
+	_ = cmd.Run(buildTestContext(t), []string{"app", "serve"})
+	assert.Equal(t, "\x1b[31munable to listen\x1b[0m\n", out.String())
+
+	out.Reset()
+	_ = cmd.Run(buildTestContext(t), []string{"app", "--port", "80"})
+	assert.Contains(t, out.String(), "\x1b[31mIncorrect Usage: flag provided but not defined: -port\x1b[0m\n")
+}
@@ -0,0 +1,8 @@
+//go:build !urfave_cli_tracing
+
+package cli
+
+// tracef is a no-op unless built with the urfave_cli_tracing tag. Keeping it
+// empty lets the compiler inline it, so the arguments of the many trace
+// calls on the hot path are neither boxed nor allocated.
+func tracef(format string, a ...any) {}
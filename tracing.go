@@ -0,0 +1,62 @@
+package cli
+
+import "context"
+
+// Tracer starts a span around the Action of every invoked command when set on
+// the root command. It is intentionally minimal so that it can be satisfied
+// by a thin adapter around an OpenTelemetry trace.Tracer without this package
+// depending on OpenTelemetry.
+type Tracer interface {
+	// Start creates a span named after the full command path and returns
+	// a context containing it, which is then passed on to the Action
+	Start(ctx context.Context, spanName string) (context.Context, Span)
+}
+
+// Span is a single traced command invocation
+type Span interface {
+	// SetAttribute sets a single attribute on the span
+	SetAttribute(key string, value any)
+	// RecordError records an error returned by the Action
+	RecordError(err error)
+	// End completes the span
+	End()
+}
+
+const (
+	// SpanAttributeCommandPath is the span attribute holding the full command path
+	SpanAttributeCommandPath = "cli.command.path"
+	// SpanAttributeFlagsSet is the span attribute holding the names of all set flags
+	SpanAttributeFlagsSet = "cli.flags.set"
+	// SpanAttributeExitCode is the span attribute holding the exit code
+	SpanAttributeExitCode = "cli.exit_code"
+)
+
+// runAction runs the Action of the command wrapped in its middleware, in a
+// span if a Tracer has been set on the root command
+func (cmd *Command) runAction(ctx context.Context) error {
+	action := cmd.wrappedAction()
+
+	tracer := cmd.Root().Tracer
+	if tracer == nil {
+		return action(ctx, cmd)
+	}
+
+	fullName := cmd.FullName()
+
+	tracef("starting span %[1]q (cmd=%[2]q)", fullName, cmd.Name)
+
+	ctx, span := tracer.Start(ctx, fullName)
+	defer span.End()
+
+	span.SetAttribute(SpanAttributeCommandPath, fullName)
+	span.SetAttribute(SpanAttributeFlagsSet, cmd.FlagNames())
+
+	err := action(ctx, cmd)
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	span.SetAttribute(SpanAttributeExitCode, exitCodeFromError(err))
+
+	return err
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSpanKey struct{}
+
+type fakeSpan struct {
+	name       string
+	attributes map[string]any
+	errs       []error
+	ended      bool
+}
+
+func (s *fakeSpan) SetAttribute(key string, value any) { s.attributes[key] = value }
+func (s *fakeSpan) RecordError(err error)              { s.errs = append(s.errs, err) }
+func (s *fakeSpan) End()                               { s.ended = true }
+
+type fakeTracer struct {
+	spans []*fakeSpan
+}
+
+func (tr *fakeTracer) Start(ctx context.Context, spanName string) (context.Context, Span) {
+	span := &fakeSpan{name: spanName, attributes: map[string]any{}}
+	tr.spans = append(tr.spans, span)
+	return context.WithValue(ctx, fakeSpanKey{}, span), span
+}
+
+func TestTracer(t *testing.T) {
+	tracer := &fakeTracer{}
+
+	cmd := &Command{
+		Name:   "app",
+		Tracer: tracer,
+		Flags: []Flag{
+			&BoolFlag{Name: "verbose"},
+		},
+		Commands: []*Command{
+			{
+				Name: "db",
+				Commands: []*Command{
+					{
+						Name: "migrate",
+						Flags: []Flag{
+							&IntFlag{Name: "steps"},
+						},
+						Action: func(ctx context.Context, _ *Command) error {
+							require.NotNil(t, ctx.Value(fakeSpanKey{}))
+							return Exit("migration failed", 4)
+						},
+					},
+				},
+			},
+		},
+		ExitErrHandler: func(context.Context, *Command, error) {},
+	}
+
+	err := cmd.Run(buildTestContext(t), []string{"app", "--verbose", "db", "migrate", "--steps", "2"})
+
+	r := require.New(t)
+	r.EqualError(err, "migration failed")
+	r.Len(tracer.spans, 1)
+
+	span := tracer.spans[0]
+	r.Equal("app db migrate", span.name)
+	r.True(span.ended)
+	r.Equal("app db migrate", span.attributes[SpanAttributeCommandPath])
+	r.Equal([]string{"verbose", "steps"}, span.attributes[SpanAttributeFlagsSet])
+	r.Equal(4, span.attributes[SpanAttributeExitCode])
+	r.Len(span.errs, 1)
+}
+
+func TestTracerSuccess(t *testing.T) {
+	tracer := &fakeTracer{}
+
+	cmd := &Command{
+		Name:   "app",
+		Tracer: tracer,
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	r := require.New(t)
+	r.NoError(cmd.Run(buildTestContext(t), []string{"app"}))
+	r.Len(tracer.spans, 1)
+	r.Equal(0, tracer.spans[0].attributes[SpanAttributeExitCode])
+	r.Empty(tracer.spans[0].errs)
+}
+
+func TestExitCodeFromError(t *testing.T) {
+	r := require.New(t)
+	r.Equal(0, exitCodeFromError(nil))
+	r.Equal(1, exitCodeFromError(errors.New("boom")))
+	r.Equal(5, exitCodeFromError(Exit("boom", 5)))
+	r.Equal(7, exitCodeFromError(newMultiError(Exit("a", 5), errors.New("b"), Exit("c", 7))))
+	r.Equal(1, exitCodeFromError(newMultiError(errors.New("a"), errors.New("b"))))
+}
@@ -0,0 +1,23 @@
+package cli
+
+import "fmt"
+
+// Translations maps the format strings of the messages produced by this
+// package, e.g. "Required flag %q not set", to translated format strings
+// taking the same arguments. Messages without a translation are produced
+// as is. The help output is translated by customizing its templates.
+var Translations = map[string]string{}
+
+// tr returns the translation of the format string, if any
+func tr(format string) string {
+	if translated, ok := Translations[format]; ok && translated != "" {
+		return translated
+	}
+
+	return format
+}
+
+// trf formats according to the translation of the format string
+func trf(format string, a ...any) string {
+	return fmt.Sprintf(tr(format), a...)
+}
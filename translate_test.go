@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslations(t *testing.T) {
+	Translations = map[string]string{
+		"Incorrect Usage: %s":                "Falsche Verwendung: %s",
+		"flag provided but not defined: -%s": "Option nicht definiert: -%s",
+		"Required flag %q not set":           "Erforderliche Option %q fehlt",
+	}
+	defer func() { Translations = map[string]string{} }()
+
+	errW := &bytes.Buffer{}
+	cmd := &Command{
+		Name:      "app",
+		ErrWriter: errW,
+		HideHelp:  true,
+		Flags: []Flag{
+			&StringFlag{Name: "name", Required: true},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	err := cmd.Run(buildTestContext(t), []string{"app", "--nope"})
+	assert.EqualError(t, err, "Option nicht definiert: -nope")
+	assert.Equal(t, "Falsche Verwendung: Option nicht definiert: -nope\n\n", errW.String())
+
+	err = cmd.Run(buildTestContext(t), []string{"app"})
+	assert.EqualError(t, err, `Erforderliche Option "name" fehlt`)
+
+	delete(Translations, "Required flag %q not set")
+
+	err = cmd.Run(buildTestContext(t), []string{"app"})
+	assert.EqualError(t, err, `Required flag "name" not set`)
+}
@@ -0,0 +1,364 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultUpdateCheckInterval is the time between two checks for a newer
+	// release if UpdateCheck.Interval is not set
+	DefaultUpdateCheckInterval = 24 * time.Hour
+	// DefaultUpdateCheckTimeout is the time a check for a newer release may
+	// take if UpdateCheck.Timeout is not set
+	DefaultUpdateCheckTimeout = 2 * time.Second
+
+	updateStateFileName = "state.json"
+	versionCommandName  = "version"
+)
+
+// UpdateCheck configures the first run detection and the periodic check for
+// newer releases of the root command. The state is kept in a file in the
+// cache directory of the user, failures to read or write it as well as
+// failed checks, e.g. while offline, never fail the run. The check runs
+// concurrently with the action, the run waits for it to finish within the
+// Timeout before returning. Commands without Version aren't checked.
+type UpdateCheck struct {
+	// Directory the state file is written to, defaults to the directory
+	// named after the root command in $XDG_CACHE_HOME or the cache
+	// directory of the operating system. Without any of them the state
+	// isn't kept and the subsystem is disabled.
+	StateDir string
+	// Notice written to the ErrWriter on the first run, e.g. to point to
+	// the documentation or to announce telemetry
+	FirstRunNotice string
+	// Latest returns the latest released version. The check is skipped if
+	// it is nil, so only the first run is detected. It is called
+	// concurrently with the action of the command.
+	Latest func(ctx context.Context) (string, error)
+	// Time between two checks, defaults to DefaultUpdateCheckInterval
+	Interval time.Duration
+	// Time a check may take, defaults to DefaultUpdateCheckTimeout
+	Timeout time.Duration
+	// Name of the environment variable disabling the subsystem when set to
+	// a non-empty value other than "0" or "false"
+	OptOutEnvVar string
+}
+
+// updateState is the content of the state file
+type updateState struct {
+	FirstRun  time.Time `json:"firstRun"`
+	LastCheck time.Time `json:"lastCheck,omitempty"`
+	Latest    string    `json:"latest,omitempty"`
+}
+
+// FirstRun reports whether the current run is the first run of the root
+// command according to its UpdateCheck state file
+func (cmd *Command) FirstRun() bool {
+	return cmd.Root().firstRun
+}
+
+// UpdateAvailable returns the latest released version known from the
+// checks of the UpdateCheck and whether it is newer than the Version of the
+// root command
+func (cmd *Command) UpdateAvailable() (string, bool) {
+	root := cmd.Root()
+	if root.latestVersion == "" || root.Version == "" {
+		return "", false
+	}
+
+	return root.latestVersion, newerVersion(root.latestVersion, root.Version)
+}
+
+// CheckForUpdates checks for a newer release right away regardless of the
+// interval, records the result in the state file and returns the latest
+// version and whether it is newer than the Version of the root command.
+func (cmd *Command) CheckForUpdates(ctx context.Context) (string, bool, error) {
+	root := cmd.Root()
+	uc := root.UpdateCheck
+	if uc == nil || uc.Latest == nil {
+		return "", false, errors.New(tr("update check is not configured"))
+	}
+
+	file := root.updateStateFile()
+	state, _ := readUpdateState(file)
+	if state.FirstRun.IsZero() {
+		state.FirstRun = root.Now()
+	}
+
+	if err := root.fetchLatestVersion(ctx, &state); err != nil {
+		return "", false, err
+	}
+	root.latestVersion = state.Latest
+	root.writeUpdateState(file, state)
+
+	latest, newer := root.UpdateAvailable()
+	return latest, newer, nil
+}
+
+// checkForUpdates detects the first run and starts the check for a newer
+// release if the interval has passed since the last check
+func (cmd *Command) checkForUpdates(ctx context.Context) {
+	cmd.firstRun = false
+	cmd.latestVersion = ""
+
+	uc := cmd.UpdateCheck
+	if uc == nil || cmd.shellCompletion || cmd.updateCheckOptedOut() {
+		return
+	}
+
+	file := cmd.updateStateFile()
+	if file == "" {
+		tracef("skipping update check without cache directory (cmd=%[1]q)", cmd.Name)
+		return
+	}
+
+	state, err := readUpdateState(file)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			tracef("ignoring unreadable update state %[1]q: %[2]v (cmd=%[3]q)", file, err, cmd.Name)
+		}
+
+		cmd.firstRun = true
+		state = updateState{FirstRun: cmd.Now()}
+
+		if uc.FirstRunNotice != "" {
+			fmt.Fprintln(cmd.errWriter(), uc.FirstRunNotice)
+		}
+	}
+
+	cmd.latestVersion = state.Latest
+
+	interval := uc.Interval
+	if interval <= 0 {
+		interval = DefaultUpdateCheckInterval
+	}
+
+	if uc.Latest == nil || cmd.Version == "" || cmd.Now().Sub(state.LastCheck) < interval {
+		if cmd.firstRun {
+			cmd.writeUpdateState(file, state)
+		}
+		return
+	}
+
+	done := make(chan updateState, 1)
+	cmd.updateCheckDone = done
+
+	go func() {
+		if err := cmd.fetchLatestVersion(ctx, &state); err != nil {
+			tracef("ignoring failed update check: %[1]v (cmd=%[2]q)", err, cmd.Name)
+		}
+		cmd.writeUpdateState(file, state)
+		done <- state
+	}()
+}
+
+// waitForUpdateCheck waits for the update check started by the run, if any,
+// which is bounded by the timeout of the UpdateCheck
+func (cmd *Command) waitForUpdateCheck() {
+	if cmd.updateCheckDone == nil {
+		return
+	}
+
+	state := <-cmd.updateCheckDone
+	cmd.updateCheckDone = nil
+	cmd.latestVersion = state.Latest
+}
+
+// fetchLatestVersion calls Latest with the timeout of the UpdateCheck. The
+// time of the check is recorded even if it failed, so being offline doesn't
+// slow down every run.
+func (cmd *Command) fetchLatestVersion(ctx context.Context, state *updateState) error {
+	uc := cmd.UpdateCheck
+
+	timeout := uc.Timeout
+	if timeout <= 0 {
+		timeout = DefaultUpdateCheckTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	state.LastCheck = cmd.Now()
+
+	latest, err := uc.Latest(ctx)
+	if err != nil {
+		return err
+	}
+
+	state.Latest = strings.TrimSpace(latest)
+	return nil
+}
+
+func (cmd *Command) updateCheckOptedOut() bool {
+	name := cmd.UpdateCheck.OptOutEnvVar
+	if name == "" {
+		return false
+	}
+
+	v, ok := cmd.LookupEnv(name)
+	if !ok || v == "" {
+		return false
+	}
+
+	disabled, err := strconv.ParseBool(v)
+	return err != nil || disabled
+}
+
+// updateStateFile returns the path of the state file or an empty string if
+// there is no directory to keep it in
+func (cmd *Command) updateStateFile() string {
+	dir := cmd.UpdateCheck.StateDir
+	if dir == "" {
+		cacheDir, ok := cmd.LookupEnv("XDG_CACHE_HOME")
+		if !ok || cacheDir == "" {
+			var err error
+			if cacheDir, err = os.UserCacheDir(); err != nil {
+				tracef("no cache directory for update state: %[1]v (cmd=%[2]q)", err, cmd.Name)
+				return ""
+			}
+		}
+		dir = filepath.Join(cacheDir, cmd.Name)
+	}
+
+	return filepath.Join(dir, updateStateFileName)
+}
+
+func readUpdateState(file string) (updateState, error) {
+	var state updateState
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return state, err
+	}
+
+	err = json.Unmarshal(b, &state)
+	return state, err
+}
+
+// writeUpdateState replaces the state file, failures are only traced
+func (cmd *Command) writeUpdateState(file string, state updateState) {
+	if file == "" {
+		return
+	}
+
+	b, err := json.Marshal(state)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(file), 0o755)
+	}
+	if err == nil {
+		tmp := file + ".tmp"
+		if err = os.WriteFile(tmp, b, 0o644); err == nil {
+			err = os.Rename(tmp, file)
+		}
+	}
+
+	if err != nil {
+		tracef("ignoring failure to write update state %[1]q: %[2]v (cmd=%[3]q)", file, err, cmd.Name)
+	}
+}
+
+// printUpdateNotice writes a notice about a newer release, if any
+func (cmd *Command) printUpdateNotice() {
+	if latest, newer := cmd.UpdateAvailable(); newer {
+		root := cmd.Root()
+		fmt.Fprintf(root.Writer, "\n%s\n", trf("A new version of %s is available: %s (current: %s)", root.Name, latest, root.Version))
+	}
+}
+
+// ensureVersionCommand appends the version command to the root command if
+// the UpdateCheck is configured, so `version --check` checks right away
+func (cmd *Command) ensureVersionCommand() {
+	if cmd.UpdateCheck == nil || cmd.UpdateCheck.Latest == nil || cmd.definedCommand(versionCommandName) != nil {
+		return
+	}
+
+	tracef("appending version command (cmd=%[1]q)", cmd.Name)
+	cmd.appendCommand(&Command{
+		Name:      versionCommandName,
+		Usage:     "print the version",
+		isBuiltin: true,
+		Flags: []Flag{
+			&BoolFlag{Name: "check", Usage: "check for a newer release"},
+		},
+		Action: versionCommandAction,
+	})
+}
+
+func versionCommandAction(ctx context.Context, cmd *Command) error {
+	root := cmd.Root()
+	VersionPrinter(root)
+
+	if !cmd.Bool("check") {
+		return nil
+	}
+
+	latest, newer, err := cmd.CheckForUpdates(ctx)
+	if err != nil {
+		return Exit(trf("checking for updates failed: %v", err), 1)
+	}
+
+	if newer {
+		fmt.Fprintln(root.Writer, trf("A new version of %s is available: %s", root.Name, latest))
+	} else {
+		fmt.Fprintln(root.Writer, trf("%s is up to date", root.Name))
+	}
+	return nil
+}
+
+// newerVersion reports whether the version a is newer than b, comparing
+// the dot separated numbers of versions like "v1.2.3". A release is newer
+// than a pre-release of the same version. Versions which are no such
+// numbers are only compared for equality.
+func newerVersion(a, b string) bool {
+	aNums, aPre, aOK := parseVersion(a)
+	bNums, bPre, bOK := parseVersion(b)
+	if !aOK || !bOK {
+		return a != b
+	}
+
+	for i := 0; i < len(aNums) || i < len(bNums); i++ {
+		var x, y int
+		if i < len(aNums) {
+			x = aNums[i]
+		}
+		if i < len(bNums) {
+			y = bNums[i]
+		}
+		if x != y {
+			return x > y
+		}
+	}
+
+	return aPre == "" && bPre != ""
+}
+
+func parseVersion(v string) ([]int, string, bool) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexByte(v, '+'); i >= 0 {
+		v = v[:i]
+	}
+
+	var pre string
+	if i := strings.IndexByte(v, '-'); i >= 0 {
+		v, pre = v[:i], v[i+1:]
+	}
+
+	var nums []int
+	for _, part := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, "", false
+		}
+		nums = append(nums, n)
+	}
+
+	return nums, pre, true
+}
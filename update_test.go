@@ -0,0 +1,253 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildUpdateTestCommand returns a command checking for updates with the
+// given release, counting the checks, at the time pointed to by now
+func buildUpdateTestCommand(t *testing.T, now *time.Time, latest *string, checks *int) (*Command, *bytes.Buffer, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+
+	cmd := &Command{
+		Name:      "app",
+		Version:   "v1.2.0",
+		Writer:    out,
+		ErrWriter: errOut,
+		Env:       MapEnv{},
+		Clock:     ClockFunc(func() time.Time { return *now }),
+		UpdateCheck: &UpdateCheck{
+			StateDir:       t.TempDir(),
+			FirstRunNotice: "welcome to app",
+			OptOutEnvVar:   "APP_NO_UPDATE_CHECK",
+			Latest: func(ctx context.Context) (string, error) {
+				*checks++
+				if *latest == "" {
+					return "", errors.New("offline")
+				}
+				return *latest, nil
+			},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	return cmd, out, errOut
+}
+
+func TestUpdateCheck(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	latest := "v1.2.0"
+	checks := 0
+	cmd, out, errOut := buildUpdateTestCommand(t, &now, &latest, &checks)
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+	assert.True(t, cmd.FirstRun())
+	assert.Equal(t, "welcome to app\n", errOut.String())
+	assert.Equal(t, 1, checks)
+	_, newer := cmd.UpdateAvailable()
+	assert.False(t, newer)
+
+	state, err := readUpdateState(filepath.Join(cmd.UpdateCheck.StateDir, "state.json"))
+	require.NoError(t, err)
+	assert.Equal(t, updateState{FirstRun: now, LastCheck: now, Latest: "v1.2.0"}, state)
+
+	// within the interval the last result is used
+	errOut.Reset()
+	latest = "v1.3.0"
+	now = now.Add(time.Hour)
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+	assert.False(t, cmd.FirstRun())
+	assert.Empty(t, errOut.String())
+	assert.Equal(t, 1, checks)
+
+	now = now.Add(DefaultUpdateCheckInterval)
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+	assert.Equal(t, 2, checks)
+	version, newer := cmd.UpdateAvailable()
+	assert.True(t, newer)
+	assert.Equal(t, "v1.3.0", version)
+
+	out.Reset()
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--help"}))
+	assert.Contains(t, out.String(), "\nA new version of app is available: v1.3.0 (current: v1.2.0)\n")
+}
+
+func TestUpdateCheckOffline(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	latest := ""
+	checks := 0
+	cmd, _, _ := buildUpdateTestCommand(t, &now, &latest, &checks)
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+	assert.Equal(t, 1, checks, "failed checks are not retried before the interval passed")
+
+	_, newer := cmd.UpdateAvailable()
+	assert.False(t, newer)
+}
+
+func TestUpdateCheckOptOut(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	latest := "v2.0.0"
+	checks := 0
+	cmd, _, errOut := buildUpdateTestCommand(t, &now, &latest, &checks)
+	cmd.Env = MapEnv{"APP_NO_UPDATE_CHECK": "1"}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+	assert.Equal(t, 0, checks)
+	assert.False(t, cmd.FirstRun())
+	assert.Empty(t, errOut.String())
+
+	_, err := os.Stat(filepath.Join(cmd.UpdateCheck.StateDir, "state.json"))
+	assert.True(t, os.IsNotExist(err))
+
+	cmd.Env = MapEnv{"APP_NO_UPDATE_CHECK": "false"}
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+	assert.Equal(t, 1, checks)
+}
+
+func TestUpdateCheckStateDir(t *testing.T) {
+	cacheDir := t.TempDir()
+	cmd := &Command{
+		Name:        "app",
+		Env:         MapEnv{"XDG_CACHE_HOME": cacheDir},
+		UpdateCheck: &UpdateCheck{},
+		Action:      func(context.Context, *Command) error { return nil },
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+	assert.True(t, cmd.FirstRun())
+	assert.FileExists(t, filepath.Join(cacheDir, "app", "state.json"))
+	assert.Nil(t, cmd.Command("version"), "no version command without Latest")
+}
+
+func TestUpdateCheckNoCacheDir(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		t.Skip("the cache directory doesn't depend on $HOME")
+	}
+
+	t.Setenv("XDG_CACHE_HOME", "")
+	t.Setenv("HOME", "")
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	dir := t.TempDir()
+	require.NoError(t, os.Chdir(dir))
+	defer func() { require.NoError(t, os.Chdir(wd)) }()
+
+	checks := 0
+	cmd := &Command{
+		Name:    "app",
+		Version: "v1.2.0",
+		Env:     MapEnv{},
+		UpdateCheck: &UpdateCheck{
+			Latest: func(context.Context) (string, error) {
+				checks++
+				return "v1.3.0", nil
+			},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+	assert.False(t, cmd.FirstRun())
+	assert.Equal(t, 0, checks)
+	assert.NoDirExists(t, filepath.Join(dir, "app"), "the state isn't written to the working directory")
+}
+
+func TestUpdateCheckWithoutVersion(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	latest := "v1.3.0"
+	checks := 0
+	cmd, out, _ := buildUpdateTestCommand(t, &now, &latest, &checks)
+	cmd.Version = ""
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--help"}))
+	assert.True(t, cmd.FirstRun())
+	assert.Equal(t, 0, checks)
+	assert.NotContains(t, out.String(), "A new version")
+
+	_, newer := cmd.UpdateAvailable()
+	assert.False(t, newer)
+}
+
+func TestUpdateCheckConcurrent(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	latest := "v1.3.0"
+	checks := 0
+	cmd, _, _ := buildUpdateTestCommand(t, &now, &latest, &checks)
+
+	started := make(chan struct{})
+	check := cmd.UpdateCheck.Latest
+	cmd.UpdateCheck.Latest = func(ctx context.Context) (string, error) {
+		select {
+		case <-started:
+			return check(ctx)
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	cmd.Action = func(context.Context, *Command) error {
+		close(started)
+		return nil
+	}
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+	assert.Equal(t, 1, checks, "the run waits for the check")
+	version, newer := cmd.UpdateAvailable()
+	assert.True(t, newer)
+	assert.Equal(t, "v1.3.0", version)
+}
+
+func TestVersionCommandCheck(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	latest := "v1.2.0"
+	checks := 0
+	cmd, out, _ := buildUpdateTestCommand(t, &now, &latest, &checks)
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "version"}))
+	assert.Equal(t, "app version v1.2.0\n", out.String())
+
+	out.Reset()
+	latest = "v1.10.0"
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "version", "--check"}))
+	assert.Equal(t, "app version v1.2.0\nA new version of app is available: v1.10.0\n", out.String())
+	assert.Equal(t, 2, checks, "the check ignores the interval")
+
+	out.Reset()
+	latest = ""
+	err := cmd.Run(buildTestContext(t), []string{"app", "version", "--check"})
+	assert.EqualError(t, err, "checking for updates failed: offline")
+}
+
+func TestNewerVersion(t *testing.T) {
+	for _, testCase := range []struct {
+		a, b     string
+		expected bool
+	}{
+		{"v1.2.3", "v1.2.3", false},
+		{"v1.2.4", "v1.2.3", true},
+		{"1.10.0", "v1.9.9", true},
+		{"v1.2", "v1.2.0", false},
+		{"v1.2.1", "v1.2", true},
+		{"v2.0.0", "v10.0.0", false},
+		{"v1.2.3", "v1.2.3-rc.1", true},
+		{"v1.2.3-rc.2", "v1.2.3", false},
+		{"v1.2.3+build.5", "v1.2.3", false},
+		{"nightly-2", "nightly-1", true},
+		{"nightly-1", "nightly-1", false},
+	} {
+		assert.Equal(t, testCase.expected, newerVersion(testCase.a, testCase.b), "%s > %s", testCase.a, testCase.b)
+	}
+}
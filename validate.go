@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// DefinitionError is a problem with the definition of a command found by
+// Validate
+type DefinitionError struct {
+	// Command is the full name of the command the problem was found on
+	Command string
+	// Message describes the problem
+	Message string
+}
+
+func (e *DefinitionError) Error() string {
+	return e.Command + ": " + e.Message
+}
+
+// Validate checks the definition of the command and its subcommands for
+// problems like duplicate flag names, conflicting shorthands, commands and
+// flags shadowing the built-in ones, required flags with defaults and
+// unreachable commands. All problems found are returned as a MultiError of
+// DefinitionError, or nil if there are none, e.g. to assert a clean tree in
+// tests. Lazy subcommands are built to validate the whole tree.
+func (cmd *Command) Validate() error {
+	cmd.loadCommands()
+
+	var errs []error
+	cmd.validate(&errs)
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return newMultiError(errs...)
+}
+
+func (cmd *Command) validate(errs *[]error) {
+	fail := func(format string, a ...any) {
+		*errs = append(*errs, &DefinitionError{Command: cmd.FullName(), Message: fmt.Sprintf(format, a...)})
+	}
+
+	tracef("validating definition (cmd=%[1]q)", cmd.Name)
+
+	// owners of the flag names which can be used on this command
+	owners := map[string]string{}
+	claim := func(fl Flag, owner string) {
+		for _, name := range fl.Names() {
+			if other, ok := owners[name]; ok && other != owner {
+				if len(name) == 1 {
+					fail("shorthand -%s is used by both %s and %s", name, other, owner)
+				} else {
+					fail("flag name --%s is used by both %s and %s", name, other, owner)
+				}
+				continue
+			}
+			owners[name] = owner
+		}
+	}
+
+	builtins := cmd.builtinFlags()
+	for _, fl := range builtins {
+		claim(fl, "the built-in "+flagLabel(fl))
+	}
+
+	for _, pCmd := range cmd.Lineage()[1:] {
+		for _, fl := range pCmd.Flags {
+			if pf, ok := fl.(PersistentFlag); ok && pf.IsPersistent() && !isBuiltinFlag(fl) {
+				claim(fl, fmt.Sprintf("%s of %q", flagLabel(fl), pCmd.FullName()))
+			}
+		}
+	}
+
+	for _, fl := range cmd.Flags {
+		if isBuiltinFlag(fl) {
+			continue
+		}
+
+		claim(fl, flagLabel(fl))
+
+		if rf, ok := fl.(RequiredFlag); ok && rf.IsRequired() && hasDefaultValue(fl) {
+			fail("required %s has a default value, which is never used", flagLabel(fl))
+		}
+	}
+
+	helpCommand := !cmd.HideHelp && !cmd.HideHelpCommand
+
+	commands := map[string]*Command{}
+	for _, subCmd := range cmd.Commands {
+		if subCmd.isHelpCommand {
+			continue
+		}
+
+		if subCmd.Name == "" && len(subCmd.Aliases) == 0 {
+			fail("command without name is unreachable")
+			continue
+		}
+
+		for _, name := range subCmd.Names() {
+			if name == "" {
+				continue
+			}
+
+			if helpCommand && (name == helpName || name == helpAlias) {
+				fail("command %q shadows the built-in help command", subCmd.Name)
+			}
+
+			if other, ok := commands[name]; ok && other != subCmd {
+				fail("command name %q is used by both %q and %q, the latter is unreachable", name, other.Name, subCmd.Name)
+				continue
+			}
+			commands[name] = subCmd
+		}
+	}
+
+	if cmd.DefaultCommand != "" {
+		if _, ok := commands[cmd.DefaultCommand]; !ok {
+			fail("default command %q does not exist", cmd.DefaultCommand)
+		}
+	}
+
+	for _, subCmd := range cmd.Commands {
+		if subCmd.isHelpCommand {
+			continue
+		}
+
+		// the parent is only set up when running
+		parent := subCmd.parent
+		subCmd.parent = cmd
+		subCmd.validate(errs)
+		subCmd.parent = parent
+	}
+}
+
+// builtinFlags returns the flags appended to the command when run
+func (cmd *Command) builtinFlags() []Flag {
+	root := cmd.Root()
+
+	var flags []Flag
+	add := func(enabled bool, fls ...Flag) {
+		for _, fl := range fls {
+			if enabled && fl != nil {
+				flags = append(flags, fl)
+			}
+		}
+	}
+
+	add(!cmd.HideHelp, HelpFlag)
+	add(cmd.parent == nil && cmd.Version != "" && !cmd.HideVersion, VersionFlag)
+	add(root.EnableDryRun, DryRunFlag)
+	add(root.EnableLogging, loggingFlags()...)
+	add(root.EnableErrorFormat, ErrorFormatFlag)
+	add(root.EnableStrict, StrictFlag)
+
+	return flags
+}
+
+// isBuiltinFlag returns true if the flag is one of the built-in flags
+func isBuiltinFlag(fl Flag) bool {
+	for _, builtin := range append([]Flag{HelpFlag, VersionFlag, DryRunFlag, ErrorFormatFlag, StrictFlag}, loggingFlags()...) {
+		if builtin != nil && fl == builtin {
+			return true
+		}
+	}
+
+	return false
+}
+
+func flagLabel(fl Flag) string {
+	names := fl.Names()
+	if len(names) == 0 {
+		return "flag"
+	}
+
+	if len(names[0]) == 1 {
+		return "flag -" + names[0]
+	}
+
+	return "flag --" + names[0]
+}
+
+// hasDefaultValue returns true if the value of the flag is not the zero
+// value of its type
+func hasDefaultValue(fl Flag) bool {
+	v := reflect.ValueOf(fl)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return false
+	}
+
+	value := v.FieldByName("Value")
+	if !value.IsValid() {
+		return false
+	}
+
+	return !value.IsZero()
+}
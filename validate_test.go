@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	cmd := &Command{
+		Name:           "app",
+		Version:        "1.0.0",
+		DefaultCommand: "serve",
+		Flags: []Flag{
+			&StringFlag{Name: "config", Aliases: []string{"c"}, Persistent: true},
+			&BoolFlag{Name: "verbose", Aliases: []string{"v"}},
+			&StringFlag{Name: "token", Required: true, Value: "secret"},
+		},
+		Commands: []*Command{
+			{
+				Name: "deploy",
+				Flags: []Flag{
+					&StringFlag{Name: "cluster", Aliases: []string{"c"}},
+					&StringFlag{Name: "env"},
+					&StringFlag{Name: "environment", Aliases: []string{"env"}},
+				},
+			},
+			{Name: "destroy", Aliases: []string{"d"}},
+			{Name: "delete", Aliases: []string{"d"}},
+			{Name: "h"},
+			{},
+		},
+	}
+
+	err := cmd.Validate()
+	require.Error(t, err)
+
+	var messages []string
+	for _, err := range err.(MultiError).Errors() {
+		messages = append(messages, err.Error())
+	}
+
+	assert.Equal(t, []string{
+		"app: shorthand -v is used by both the built-in flag --version and flag --verbose",
+		"app: required flag --token has a default value, which is never used",
+		`app: command name "d" is used by both "destroy" and "delete", the latter is unreachable`,
+		`app: command "h" shadows the built-in help command`,
+		"app: command without name is unreachable",
+		`app: default command "serve" does not exist`,
+		`app deploy: shorthand -c is used by both flag --config of "app" and flag --cluster`,
+		"app deploy: flag name --env is used by both flag --env and flag --environment",
+	}, messages)
+}
+
+func TestValidateClean(t *testing.T) {
+	cmd := &Command{
+		Name:    "app",
+		Version: "1.0.0",
+		Flags: []Flag{
+			&StringFlag{Name: "config", Aliases: []string{"c"}, Persistent: true},
+			&StringFlag{Name: "token", Required: true},
+		},
+		Commands: []*Command{
+			{
+				Name:   "deploy",
+				Flags:  []Flag{&StringFlag{Name: "env"}},
+				Action: func(context.Context, *Command) error { return nil },
+			},
+		},
+	}
+
+	require.NoError(t, cmd.Validate())
+
+	// the built-in flags and commands appended when running are no problem
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--token", "x", "deploy"}))
+	require.NoError(t, cmd.Validate())
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -17,6 +18,20 @@ type ValueSource interface {
 	Lookup() (string, bool)
 }
 
+// FallibleValueSource is a ValueSource whose lookup can fail, e.g. because
+// a remote backend is unreachable or a config file is broken. The run of
+// the command fails with the error instead of treating the value as
+// missing, but only if the value is needed, i.e. the flag is not set on the
+// command line and the command is run rather than showing its help or
+// completing its arguments.
+type FallibleValueSource interface {
+	ValueSource
+
+	// LookupWithError returns the value from the source and if it was
+	// found, or the error looking it up
+	LookupWithError() (string, bool, error)
+}
+
 // ValueSourceChain contains an ordered series of ValueSource that
 // allows for lookup where the first ValueSource to resolve is
 // returned
@@ -71,6 +86,9 @@ func (vsc *ValueSourceChain) Lookup() (string, bool) {
 	return s, ok
 }
 
+// LookupWithSource returns the value of the first source of the chain the
+// value is found in and the source. Sources failing to be looked up are
+// skipped.
 func (vsc *ValueSourceChain) LookupWithSource() (string, ValueSource, bool) {
 	for _, src := range vsc.Chain {
 		if value, found := src.Lookup(); found {
@@ -81,13 +99,70 @@ func (vsc *ValueSourceChain) LookupWithSource() (string, ValueSource, bool) {
 	return "", nil, false
 }
 
+// contextValueSource is a value source looked up with the context of the
+// run, e.g. a RemoteValueSource
+type contextValueSource interface {
+	LookupContext(ctx context.Context) (string, bool, error)
+}
+
+// lookupWithSourceIn is like LookupWithSource but looks up environment
+// variables in the given environment, the process environment if nil, and
+// stops at the first source failing to be looked up. Without a context,
+// the sources needing one and the sources failing to be looked up are
+// left to be looked up once the value is needed, the sources of the chain
+// from the first of them on are returned as pending.
+func (vsc *ValueSourceChain) lookupWithSourceIn(ctx context.Context, env Environment) (string, ValueSource, bool, []ValueSource, error) {
+	return lookupSourcesIn(ctx, env, vsc.Chain)
+}
+
+func lookupSourcesIn(ctx context.Context, env Environment, chain []ValueSource) (string, ValueSource, bool, []ValueSource, error) {
+	for i, src := range chain {
+		var value string
+		var found bool
+		var err error
+
+		switch src := src.(type) {
+		case *envVarValueSource:
+			if env == nil {
+				env = osEnvironment{}
+			}
+			value, found = src.lookupIn(env)
+		case contextValueSource:
+			if ctx == nil {
+				return "", nil, false, chain[i:], nil
+			}
+			value, found, err = src.LookupContext(ctx)
+		case FallibleValueSource:
+			value, found, err = src.LookupWithError()
+		default:
+			value, found = src.Lookup()
+		}
+
+		if err != nil {
+			if ctx == nil {
+				return "", nil, false, chain[i:], nil
+			}
+			return "", src, false, nil, err
+		}
+		if found {
+			return value, src, true, nil, nil
+		}
+	}
+
+	return "", nil, false, nil, nil
+}
+
 // envVarValueSource encapsulates a ValueSource from an environment variable
 type envVarValueSource struct {
 	Key string
 }
 
 func (e *envVarValueSource) Lookup() (string, bool) {
-	return os.LookupEnv(strings.TrimSpace(string(e.Key)))
+	return e.lookupIn(osEnvironment{})
+}
+
+func (e *envVarValueSource) lookupIn(env Environment) (string, bool) {
+	return env.LookupEnv(strings.TrimSpace(string(e.Key)))
 }
 
 func (e *envVarValueSource) String() string { return fmt.Sprintf("environment variable %[1]q", e.Key) }
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultRemoteLookupTimeout is the time a lookup of a RemoteValueSource
+// may take if its Timeout is not set
+var DefaultRemoteLookupTimeout = 5 * time.Second
+
+// RemoteProvider is a backend flag values are resolved from, e.g. a
+// key-value store like Consul or etcd or a secret store like Vault. The
+// clients of the backends are provided by the application, so this package
+// doesn't depend on them.
+type RemoteProvider interface {
+	// Get returns the value stored at the key and whether it exists. An
+	// error, e.g. because the backend is unreachable, fails applying the
+	// flag.
+	Get(ctx context.Context, key string) (string, bool, error)
+}
+
+// RemoteProviderFunc is an adapter to use a function as RemoteProvider
+type RemoteProviderFunc func(ctx context.Context, key string) (string, bool, error)
+
+// Get calls f(ctx, key)
+func (f RemoteProviderFunc) Get(ctx context.Context, key string) (string, bool, error) {
+	return f(ctx, key)
+}
+
+// RemoteValueSource is a value source resolving the value of a key from a
+// RemoteProvider. The provider is only asked with the context of the run
+// when the command with the flag is run, the flag is not set on the command
+// line and no preceding source of the chain provided a value, so
+//
+//	&cli.StringFlag{
+//		Name:      "db-password",
+//		Sensitive: true,
+//		Sources:   cli.NewValueSourceChain(cli.EnvVar("DB_PASSWORD"), cli.Remote(vault, "secret/data/db#password")),
+//	}
+//
+// only reaches out to Vault if DB_PASSWORD is not set and neither for the
+// help nor the shell completion. Errors of the provider fail the run of the
+// command before its action.
+type RemoteValueSource struct {
+	// Provider the key is looked up in
+	Provider RemoteProvider
+	// Key of the value in the backend, e.g. a path of a secret
+	Key string
+	// Name of the backend used in messages, e.g. "vault"
+	Name string
+	// Time the lookup may take, defaults to DefaultRemoteLookupTimeout
+	Timeout time.Duration
+}
+
+// Remote returns a value source resolving the key from the provider
+func Remote(provider RemoteProvider, key string) *RemoteValueSource {
+	return &RemoteValueSource{Provider: provider, Key: key}
+}
+
+// Lookup returns the value of the key and whether it was found. Failed
+// lookups are reported as not found, see LookupWithError.
+func (s *RemoteValueSource) Lookup() (string, bool) {
+	value, found, _ := s.LookupWithError()
+	return value, found
+}
+
+// LookupWithError returns the value of the key and whether it was found,
+// or the error of the provider
+func (s *RemoteValueSource) LookupWithError() (string, bool, error) {
+	return s.LookupContext(context.Background())
+}
+
+// LookupContext is like LookupWithError but cancels the lookup with the
+// context, which is the one of the run when flags are resolved
+func (s *RemoteValueSource) LookupContext(ctx context.Context) (string, bool, error) {
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = DefaultRemoteLookupTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	value, found, err := s.Provider.Get(ctx, s.Key)
+	if err != nil {
+		return "", false, fmt.Errorf("%s: %w", s, err)
+	}
+
+	tracef("looked up remote key %[1]q found=%[2]v", s.Key, found)
+	return value, found, nil
+}
+
+func (s *RemoteValueSource) String() string {
+	name := s.Name
+	if name == "" {
+		name = "remote provider"
+	}
+	return fmt.Sprintf("key %[1]q of %[2]s", s.Key, name)
+}
+
+func (s *RemoteValueSource) GoString() string {
+	return fmt.Sprintf("&RemoteValueSource{Key:%[1]q,Name:%[2]q}", s.Key, s.Name)
+}
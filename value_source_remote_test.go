@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteValueSource(t *testing.T) {
+	var looked []string
+	vault := RemoteProviderFunc(func(ctx context.Context, key string) (string, bool, error) {
+		looked = append(looked, key)
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline)
+
+		switch key {
+		case "secret/db":
+			return "s3cret", true, nil
+		case "secret/down":
+			return "", false, errors.New("connection refused")
+		}
+		return "", false, nil
+	})
+
+	newCommand := func(env MapEnv, key string) *Command {
+		return &Command{
+			Name: "app",
+			Env:  env,
+			Flags: []Flag{
+				&StringFlag{
+					Name:      "db-password",
+					Sensitive: true,
+					Value:     "default",
+					Sources:   NewValueSourceChain(EnvVar("DB_PASSWORD"), &RemoteValueSource{Provider: vault, Key: key, Name: "vault"}),
+				},
+			},
+			Action: func(context.Context, *Command) error { return nil },
+		}
+	}
+
+	t.Run("resolved", func(t *testing.T) {
+		looked = nil
+		cmd := newCommand(MapEnv{}, "secret/db")
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+		assert.Equal(t, "s3cret", cmd.String("db-password"))
+		assert.Equal(t, []string{"secret/db"}, looked)
+	})
+
+	t.Run("preceding source wins", func(t *testing.T) {
+		looked = nil
+		cmd := newCommand(MapEnv{"DB_PASSWORD": "from-env"}, "secret/down")
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+		assert.Equal(t, "from-env", cmd.String("db-password"))
+		assert.Empty(t, looked, "the provider is not asked")
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		cmd := newCommand(MapEnv{}, "secret/missing")
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+		assert.Equal(t, "default", cmd.String("db-password"))
+	})
+
+	t.Run("failing", func(t *testing.T) {
+		errOut := &bytes.Buffer{}
+		cmd := newCommand(MapEnv{}, "secret/down")
+		cmd.ErrWriter = errOut
+		err := cmd.Run(buildTestContext(t), []string{"app"})
+		assert.EqualError(t, err, `could not look up value for flag db-password: key "secret/down" of vault: connection refused`)
+		assert.NotContains(t, errOut.String(), "Incorrect Usage", "an unreachable provider is no usage error")
+	})
+
+	t.Run("set on the command line", func(t *testing.T) {
+		looked = nil
+		cmd := newCommand(MapEnv{}, "secret/down")
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--db-password", "given"}))
+		assert.Equal(t, "given", cmd.String("db-password"))
+		assert.Empty(t, looked)
+	})
+
+	t.Run("not needed", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"app", "--help"},
+			{"app", "help"},
+			{"app", "other"},
+			{"app", "other", "--help"},
+			{"app", "--generate-shell-completion"},
+		} {
+			looked = nil
+			cmd := newCommand(MapEnv{}, "secret/down")
+			cmd.Writer = &bytes.Buffer{}
+			cmd.EnableShellCompletion = true
+			cmd.Commands = []*Command{{Name: "other", Action: func(context.Context, *Command) error { return nil }}}
+			require.NoError(t, cmd.Run(buildTestContext(t), args), args)
+			assert.Empty(t, looked, args)
+		}
+	})
+}
+
+func TestRemoteValueSourceRunContext(t *testing.T) {
+	type key struct{}
+
+	var got any
+	cmd := &Command{
+		Name: "app",
+		Flags: []Flag{
+			&StringFlag{
+				Name: "token",
+				Sources: NewValueSourceChain(Remote(RemoteProviderFunc(func(ctx context.Context, _ string) (string, bool, error) {
+					got = ctx.Value(key{})
+					return "t0ken", true, nil
+				}), "token")),
+				Persistent: true,
+			},
+		},
+		Commands: []*Command{{Name: "sub", Action: func(context.Context, *Command) error { return nil }}},
+	}
+
+	ctx := context.WithValue(buildTestContext(t), key{}, "run")
+	require.NoError(t, cmd.Run(ctx, []string{"app", "sub"}))
+	assert.Equal(t, "run", got)
+	assert.Equal(t, "t0ken", cmd.Command("sub").String("token"))
+}
+
+func TestRemoteValueSourceTimeout(t *testing.T) {
+	src := Remote(RemoteProviderFunc(func(ctx context.Context, key string) (string, bool, error) {
+		<-ctx.Done()
+		return "", false, ctx.Err()
+	}), "slow")
+	src.Timeout = time.Millisecond
+
+	_, _, err := src.LookupWithError()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, found := src.Lookup()
+	assert.False(t, found)
+
+	chain := NewValueSourceChain(src)
+	_, _, found = chain.LookupWithSource()
+	assert.False(t, found)
+	assert.Equal(t, `key "slow" of remote provider`, src.String())
+}
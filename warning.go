@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WarningPolicy determines how warnings emitted via Warn are handled
+type WarningPolicy int
+
+const (
+	// WarningsPrint writes warnings to the ErrWriter of the command
+	WarningsPrint WarningPolicy = iota
+	// WarningsCollect records warnings to be retrieved via Warnings
+	WarningsCollect
+	// WarningsError records warnings and fails the run with a WarningError
+	// once it has finished
+	WarningsError
+)
+
+// StrictFlag is the flag appended to every command of the graph when
+// EnableStrict is set on the root command. When set, warnings are escalated
+// to errors regardless of the WarningPolicy. Set to nil to disable the flag.
+var StrictFlag Flag = &BoolFlag{
+	Name:  "strict",
+	Usage: "treat warnings as errors",
+}
+
+// WarningError is returned by a run which emitted warnings while they are
+// escalated to errors
+type WarningError struct {
+	Warnings []string
+}
+
+func (e *WarningError) Error() string {
+	msgs := make([]string, 0, len(e.Warnings))
+	for _, w := range e.Warnings {
+		msgs = append(msgs, trf("warning: %s", w))
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (cmd *Command) ensureStrict() {
+	if StrictFlag == nil || !cmd.Root().EnableStrict {
+		return
+	}
+
+	tracef("appending StrictFlag (cmd=%[1]q)", cmd.Name)
+	cmd.appendFlag(StrictFlag)
+}
+
+// Warn emits a non-fatal warning, handled according to the WarningPolicy of
+// the root command
+func (cmd *Command) Warn(format string, a ...any) {
+	msg := fmt.Sprintf(format, a...)
+
+	root := cmd.Root()
+	policy := root.WarningPolicy
+	if cmd.strict() {
+		policy = WarningsError
+	}
+
+	tracef("emitting warning %[1]q with policy %[2]d (cmd=%[3]q)", msg, policy, cmd.Name)
+
+	if policy == WarningsPrint {
+		fmt.Fprintln(cmd.errWriter(), trf("warning: %s", msg))
+		return
+	}
+
+	root.warningsMu.Lock()
+	defer root.warningsMu.Unlock()
+
+	root.warnings = append(root.warnings, msg)
+	if policy == WarningsError {
+		root.warningsFail = true
+	}
+}
+
+// Warnings returns the warnings recorded during the current or last run
+func (cmd *Command) Warnings() []string {
+	root := cmd.Root()
+
+	root.warningsMu.Lock()
+	defer root.warningsMu.Unlock()
+
+	return append([]string(nil), root.warnings...)
+}
+
+// strict returns true if the strict flag has been set on this command or
+// any of its ancestors or via the sources of the flag
+func (cmd *Command) strict() bool {
+	if StrictFlag == nil || !cmd.Root().EnableStrict {
+		return false
+	}
+
+	if v := cmd.lookupFlagValue(StrictFlag.Names()); v != nil {
+		b, _ := valueAs[bool](v)
+		return b
+	}
+
+	return false
+}
+
+// warnDeprecated emits warnings if the command or any of the flags set for
+// it are deprecated
+func (cmd *Command) warnDeprecated() {
+	if cmd.Deprecated != "" {
+		cmd.Warn("command %q is deprecated, %s", cmd.Name, cmd.Deprecated)
+	}
+
+	for _, fl := range cmd.appliedFlags {
+		df, ok := fl.(DeprecatedFlag)
+		if !ok || df.GetDeprecated() == "" || !cmd.localFlagWasSet(fl) {
+			continue
+		}
+
+		cmd.Warn("flag %q is deprecated, %s", prefixedNames(fl.Names()[:1], ""), df.GetDeprecated())
+	}
+}
+
+// resetWarnings clears the warnings of the previous run
+func (cmd *Command) resetWarnings() {
+	cmd.warningsMu.Lock()
+	defer cmd.warningsMu.Unlock()
+
+	cmd.warnings = nil
+	cmd.warningsFail = false
+}
+
+// warningError returns a WarningError if warnings have been escalated to
+// errors during the run
+func (cmd *Command) warningError() error {
+	cmd.warningsMu.Lock()
+	defer cmd.warningsMu.Unlock()
+
+	if !cmd.warningsFail {
+		return nil
+	}
+
+	return &WarningError{Warnings: append([]string(nil), cmd.warnings...)}
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarn(t *testing.T) {
+	warn := func(_ context.Context, cmd *Command) error {
+		cmd.Warn("option %q is ignored", "x")
+		cmd.Warn("cache is stale")
+		return nil
+	}
+
+	t.Run("print", func(t *testing.T) {
+		errW := &bytes.Buffer{}
+		cmd := &Command{
+			Name:      "app",
+			ErrWriter: errW,
+			Commands:  []*Command{{Name: "sub", Action: warn}},
+		}
+
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "sub"}))
+		assert.Equal(t, "warning: option \"x\" is ignored\nwarning: cache is stale\n", errW.String())
+		assert.Empty(t, cmd.Warnings())
+	})
+
+	t.Run("collect", func(t *testing.T) {
+		errW := &bytes.Buffer{}
+		cmd := &Command{
+			Name:          "app",
+			ErrWriter:     errW,
+			WarningPolicy: WarningsCollect,
+			Commands:      []*Command{{Name: "sub", Action: warn}},
+		}
+
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "sub"}))
+		assert.Empty(t, errW.String())
+		assert.Equal(t, []string{"option \"x\" is ignored", "cache is stale"}, cmd.Warnings())
+
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+		assert.Empty(t, cmd.Warnings())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		cmd := &Command{
+			Name:          "app",
+			ErrWriter:     io.Discard,
+			WarningPolicy: WarningsError,
+			Action:        warn,
+		}
+
+		err := cmd.Run(buildTestContext(t), []string{"app"})
+
+		var warnErr *WarningError
+		require.True(t, errors.As(err, &warnErr))
+		assert.Equal(t, []string{"option \"x\" is ignored", "cache is stale"}, warnErr.Warnings)
+		assert.EqualError(t, err, "warning: option \"x\" is ignored\nwarning: cache is stale")
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		errW := &bytes.Buffer{}
+		cmd := &Command{
+			Name:         "app",
+			ErrWriter:    errW,
+			EnableStrict: true,
+			Commands:     []*Command{{Name: "sub", Action: warn}},
+		}
+
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "sub"}))
+		assert.NotEmpty(t, errW.String())
+
+		err := cmd.Run(buildTestContext(t), []string{"app", "--strict", "sub"})
+		assert.ErrorContains(t, err, "warning: cache is stale")
+
+		cmd.EnvPrefix = "APP"
+		cmd.Env = MapEnv{"APP_STRICT": "true"}
+		err = cmd.Run(buildTestContext(t), []string{"app", "sub"})
+		assert.ErrorContains(t, err, "warning: cache is stale", "set via env")
+	})
+
+	t.Run("error kept", func(t *testing.T) {
+		cmd := &Command{
+			Name:          "app",
+			ErrWriter:     io.Discard,
+			WarningPolicy: WarningsError,
+			Action: func(ctx context.Context, cmd *Command) error {
+				_ = warn(ctx, cmd)
+				return errors.New("failed")
+			},
+		}
+
+		assert.EqualError(t, cmd.Run(buildTestContext(t), []string{"app"}), "failed")
+	})
+}
+
+func buildDeprecationTestCommand() *Command {
+	return &Command{
+		Name:          "app",
+		WarningPolicy: WarningsCollect,
+		Env:           MapEnv{},
+		Flags: []Flag{
+			&StringSliceFlag{Name: "output", Aliases: []string{"o"}},
+			&StringSliceFlag{Name: "out", ReplacedBy: "output", Sources: EnvVars("APP_OUT")},
+			&BoolFlag{Name: "fast", Deprecated: "it is the default now"},
+		},
+		Commands: []*Command{
+			{Name: "legacy", Usage: "run the old way", Deprecated: "use run instead"},
+			{Name: "run", Usage: "run the new way"},
+		},
+		Action: func(context.Context, *Command) error { return nil },
+	}
+}
+
+func TestDeprecated(t *testing.T) {
+	t.Run("flags", func(t *testing.T) {
+		cmd := buildDeprecationTestCommand()
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--out", "a", "--fast", "-o", "b", "--out", "c"}))
+		assert.Equal(t, []string{
+			`flag "--out" is deprecated, use --output instead`,
+			`flag "--fast" is deprecated, it is the default now`,
+		}, cmd.Warnings())
+		assert.Equal(t, []string{"a", "b", "c"}, cmd.StringSlice("output"))
+		assert.Equal(t, []string{"a", "c"}, cmd.StringSlice("out"))
+		assert.True(t, cmd.IsSet("output"))
+	})
+
+	t.Run("flag from env var", func(t *testing.T) {
+		cmd := buildDeprecationTestCommand()
+		cmd.Env = MapEnv{"APP_OUT": "a"}
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app"}))
+		assert.Equal(t, []string{`flag "--out" is deprecated, use --output instead`}, cmd.Warnings())
+	})
+
+	t.Run("not used", func(t *testing.T) {
+		cmd := buildDeprecationTestCommand()
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "-o", "a", "run"}))
+		assert.Empty(t, cmd.Warnings())
+	})
+
+	t.Run("command", func(t *testing.T) {
+		errW := &bytes.Buffer{}
+		cmd := buildDeprecationTestCommand()
+		cmd.WarningPolicy = WarningsPrint
+		cmd.ErrWriter = errW
+		require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "legacy"}))
+		assert.Equal(t, "warning: command \"legacy\" is deprecated, use run instead\n", errW.String())
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		cmd := buildDeprecationTestCommand()
+		cmd.EnableStrict = true
+		err := cmd.Run(buildTestContext(t), []string{"app", "--strict", "legacy"})
+		assert.EqualError(t, err, `warning: command "legacy" is deprecated, use run instead`)
+	})
+}
+
+func TestDeprecatedHelp(t *testing.T) {
+	out := &bytes.Buffer{}
+	cmd := buildDeprecationTestCommand()
+	cmd.Writer = out
+
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "--help"}))
+	assert.Contains(t, out.String(), "legacy   run the old way (DEPRECATED)\n")
+	assert.Contains(t, out.String(), "run      run the new way\n")
+	assert.Contains(t, out.String(), "--out value [ --out value ]                            (DEPRECATED) [$APP_OUT]\n")
+	assert.Contains(t, out.String(), "--fast                                                 (default: false) (DEPRECATED)\n")
+
+	out.Reset()
+	require.NoError(t, cmd.Run(buildTestContext(t), []string{"app", "legacy", "--help"}))
+	assert.Contains(t, out.String(), "app legacy - run the old way (DEPRECATED)\n")
+}